@@ -0,0 +1,149 @@
+// tdex-signer holds the mnemonic and the keys of the tdexd wallet in a
+// separate process and blinds and signs on its behalf only the transactions
+// allowed by its policy: swaps that respect the terms of the accepted swap
+// request at a price within the bounds of their markets, and withdrawals to
+// whitelisted addresses. It's configured with the same TDEX_* environment
+// variables, or tdexd.conf file, of tdexd, in the specific TDEX_NETWORK,
+// TDEX_BASE_ASSET, TDEX_DATA_DIR_PATH, TDEX_SIGNER_ADDRESS,
+// TDEX_SIGNER_WITHDRAWAL_WHITELIST, TDEX_SIGNER_PRICE_BOUNDS,
+// TDEX_SIGNER_TLS_CERT, TDEX_SIGNER_TLS_KEY, TDEX_SIGNER_TLS_CA and
+// TDEX_AUTO_LOCK_TIMEOUT.
+//
+// The mnemonic is given only to tdex-signer, that stores it encrypted with
+// the passphrase in its data dir, by running 'tdex-signer init' once. tdexd
+// is then initialized and unlocked with the passphrase only.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/keystore"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/signer"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/tdex-network/tdex-daemon/pkg/wallet"
+	"golang.org/x/crypto/ssh/terminal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func main() {
+	log.SetLevel(log.Level(config.GetInt(config.LogLevelKey)))
+
+	vault := signer.NewFileVault(
+		filepath.Join(config.GetString(config.DataDirPathKey), signer.VaultFileName),
+	)
+
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := initVault(vault); err != nil {
+			log.WithError(err).Fatal("error while initializing signer vault")
+		}
+		return
+	}
+
+	address := config.GetString(config.SignerAddressKey)
+	if address == "" {
+		log.Fatal("signer address must be set")
+	}
+
+	policy, err := signer.NewPolicy(
+		strings.Split(config.GetString(config.SignerWithdrawalWhitelistKey), ","),
+		config.GetSignerPriceBounds(),
+	)
+	if err != nil {
+		log.WithError(err).Fatal("error while parsing withdrawal whitelist")
+	}
+
+	tlsConfig, err := signer.NewServerTLSConfig(
+		config.GetString(config.SignerTLSCertKey),
+		config.GetString(config.SignerTLSKeyKey),
+		config.GetString(config.SignerTLSCAKey),
+	)
+	if err != nil {
+		log.WithError(err).Fatal("error while loading signer TLS config")
+	}
+
+	signerKeystore := keystore.NewKeystore(
+		time.Duration(config.GetInt(config.AutoLockTimeoutKey)) * time.Second,
+	)
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	rpcext.RegisterSignerServer(
+		grpcServer, signer.NewServer(signerKeystore, vault, policy),
+	)
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		log.WithError(err).Fatal("error while listening on signer address")
+	}
+	go grpcServer.Serve(lis)
+	log.Debug("signer is listening on " + address)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+	<-sigChan
+
+	grpcServer.Stop()
+	signerKeystore.Lock()
+	log.Debug("shutting down signer")
+}
+
+// initVault reads the mnemonic and the passphrase from stdin and stores the
+// mnemonic encrypted in the signer vault. A new mnemonic is generated and
+// printed if none is given.
+func initVault(vault *signer.FileVault) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Fprint(os.Stderr, "mnemonic (leave empty to generate a new one): ")
+	line, err := readSecret(reader)
+	if err != nil {
+		return err
+	}
+	mnemonic := strings.Fields(line)
+	if len(mnemonic) <= 0 {
+		mnemonic, err = wallet.NewMnemonic(wallet.NewMnemonicOpts{EntropySize: 256})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(
+			os.Stderr,
+			"new mnemonic, write it down and keep it safe:\n%s\n",
+			strings.Join(mnemonic, " "),
+		)
+	}
+
+	fmt.Fprint(os.Stderr, "passphrase: ")
+	passphrase, err := readSecret(reader)
+	if err != nil {
+		return err
+	}
+
+	if err := vault.Init(mnemonic, passphrase); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "signer vault initialized")
+	return nil
+}
+
+// readSecret reads a line from stdin without echoing it if it's a terminal
+func readSecret(reader *bufio.Reader) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		secret, err := terminal.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(secret)), err
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/urfave/cli/v2"
+	"github.com/vulpemventures/go-elements/network"
+
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	tradeclient "github.com/tdex-network/tdex-daemon/pkg/trade/client"
+)
+
+var (
+	networkFlag = cli.StringFlag{
+		Name:    "network",
+		Aliases: []string{"n"},
+		Usage:   "the network the provider is running on: liquid or regtest",
+		Value:   network.Regtest.Name,
+	}
+
+	rpcFlag = cli.StringFlag{
+		Name:  "rpcserver",
+		Usage: "tdexd provider trade interface address host:port",
+		Value: "localhost:9945",
+	}
+
+	explorerFlag = cli.StringFlag{
+		Name:  "explorer",
+		Usage: "the explorer endpoint used to fetch the wallet unspents",
+		Value: "http://127.0.0.1:3001",
+	}
+
+	tlsFlag = cli.BoolFlag{
+		Name:  "tls",
+		Usage: "connect to the provider through TLS",
+	}
+
+	tlsCertFlag = cli.StringFlag{
+		Name:  "tls_cert",
+		Usage: "the path of the provider TLS certificate to trust, if not signed by a system root CA",
+		Value: "",
+	}
+
+	grpcWebFlag = cli.BoolFlag{
+		Name:  "grpc_web",
+		Usage: "talk grpc-web over HTTP/1.1 with the provider",
+	}
+
+	timeoutFlag = cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "the deadline of every call to the provider, no deadline if 0",
+		Value: 0,
+	}
+
+	tdexDataDir = btcutil.AppDataDir("tdex-trader", false)
+	statePath   = path.Join(tdexDataDir, "state.json")
+)
+
+func main() {
+	app := cli.NewApp()
+
+	app.Version = "0.0.1" //TODO use goreleaser for setting version
+	app.Name = "tdex trader CLI"
+	app.Usage = "Command line interface for trading against tdexd providers"
+	app.Flags = []cli.Flag{
+		&networkFlag,
+		&rpcFlag,
+		&explorerFlag,
+		&tlsFlag,
+		&tlsCertFlag,
+		&grpcWebFlag,
+		&timeoutFlag,
+	}
+	app.Commands = append(
+		app.Commands,
+		&initwallet,
+		&address,
+		&listmarkets,
+		&market,
+		&preview,
+		&buy,
+		&sell,
+	)
+
+	err := app.Run(os.Args)
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func getState() (map[string]string, error) {
+	data := map[string]string{}
+
+	file, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(file, &data)
+
+	return data, nil
+}
+
+func setState(data map[string]string) error {
+	if _, err := os.Stat(tdexDataDir); os.IsNotExist(err) {
+		os.Mkdir(tdexDataDir, os.ModeDir|0700)
+	}
+
+	file, err := os.OpenFile(statePath, os.O_RDONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	currentData, err := getState()
+	if err != nil {
+		return err
+	}
+
+	mergedData := merge(currentData, data)
+
+	jsonString, err := json.Marshal(mergedData)
+	if err != nil {
+		return err
+	}
+	// the state contains the private keys of the wallet, therefore it must be
+	// readable by the owner only
+	err = ioutil.WriteFile(statePath, jsonString, 0600)
+	if err != nil {
+		return fmt.Errorf("writing to file: %w", err)
+	}
+
+	return nil
+}
+
+func merge(maps ...map[string]string) map[string]string {
+	merge := make(map[string]string, 0)
+	for _, m := range maps {
+		for k, v := range m {
+			merge[k] = v
+		}
+	}
+	return merge
+}
+
+func printRespJSON(resp interface{}) {
+	jsonMarshaler := &jsonpb.Marshaler{
+		EmitDefaults: true,
+		OrigName:     true,
+		Indent:       "\t",
+	}
+
+	jsonStr, err := jsonMarshaler.MarshalToString(resp.(proto.Message))
+	if err != nil {
+		fmt.Println("unable to decode response: ", err)
+		return
+	}
+
+	fmt.Println(jsonStr)
+}
+
+func getNetwork(ctx *cli.Context) (*network.Network, error) {
+	switch ctx.String("network") {
+	case network.Liquid.Name:
+		return &network.Liquid, nil
+	case network.Regtest.Name:
+		return &network.Regtest, nil
+	default:
+		return nil, trade.ErrInvalidChain
+	}
+}
+
+func getTradeClient(ctx *cli.Context) (*tradeclient.Client, func(), error) {
+	host, portStr, err := net.SplitHostPort(ctx.String("rpcserver"))
+	if err != nil {
+		return nil, nil, trade.ErrInvalidProviderURL
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, nil, trade.ErrInvalidProviderURL
+	}
+
+	var tlsCert []byte
+	if certPath := ctx.String("tls_cert"); len(certPath) > 0 {
+		tlsCert, err = ioutil.ReadFile(certPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read TLS certificate: %v", err)
+		}
+	}
+
+	client, err := tradeclient.NewTradeClientWithOpts(tradeclient.NewTradeClientOpts{
+		Host:    host,
+		Port:    port,
+		TLS:     ctx.Bool("tls"),
+		TLSCert: tlsCert,
+		Timeout: ctx.Duration("timeout"),
+		GrpcWeb: ctx.Bool("grpc_web"),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to connect to RPC server: %v", err)
+	}
+	cleanup := func() { _ = client.CloseConnection() }
+
+	return client, cleanup, nil
+}
+
+func getTrade(ctx *cli.Context) (*trade.Trade, func(), error) {
+	client, cleanup, err := getTradeClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t, err := trade.NewTrade(trade.NewTradeOpts{
+		Chain:       ctx.String("network"),
+		ExplorerURL: ctx.String("explorer"),
+		Client:      client,
+	})
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return t, cleanup, nil
+}
+
+type invalidUsageError struct {
+	ctx     *cli.Context
+	command string
+}
+
+func (e *invalidUsageError) Error() string {
+	return fmt.Sprintf("invalid usage of command %s", e.command)
+}
+
+func fatal(err error) {
+	var e *invalidUsageError
+	if errors.As(err, &e) {
+		_ = cli.ShowCommandHelp(e.ctx, e.command)
+	} else {
+		_, _ = fmt.Fprintf(os.Stderr, "[tdex-trader] %v\n", err)
+	}
+	os.Exit(1)
+}
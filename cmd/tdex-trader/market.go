@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
+	"github.com/urfave/cli/v2"
+)
+
+var listmarkets = cli.Command{
+	Name:   "listmarkets",
+	Usage:  "list all the tradable markets of the provider",
+	Action: listMarketsAction,
+}
+
+var market = cli.Command{
+	Name:  "market",
+	Usage: "select a market to trade against",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "base_asset",
+			Usage:    "the base asset hash of a provider market",
+			Value:    "",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "quote_asset",
+			Usage:    "the quote asset hash of a provider market",
+			Value:    "",
+			Required: true,
+		},
+	},
+	Action: marketAction,
+}
+
+func listMarketsAction(ctx *cli.Context) error {
+	client, cleanup, err := getTradeClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.Markets()
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+func marketAction(ctx *cli.Context) error {
+	mkt := trademarket.Market{
+		BaseAsset:  ctx.String("base_asset"),
+		QuoteAsset: ctx.String("quote_asset"),
+	}
+	if err := mkt.Validate(); err != nil {
+		return err
+	}
+
+	err := setMarketIntoState(mkt)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("market has been selected")
+	return nil
+}
+
+func getMarketFromState() (trademarket.Market, error) {
+	state, err := getState()
+	if err != nil {
+		return trademarket.Market{}, errors.New("a market must be selected")
+	}
+	mkt := trademarket.Market{
+		BaseAsset:  state["base_asset"],
+		QuoteAsset: state["quote_asset"],
+	}
+	if err := mkt.Validate(); err != nil {
+		return trademarket.Market{}, errors.New("a market must be selected")
+	}
+
+	return mkt, nil
+}
+
+func setMarketIntoState(mkt trademarket.Market) error {
+	return setState(map[string]string{
+		"base_asset":  mkt.BaseAsset,
+		"quote_asset": mkt.QuoteAsset,
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
+	"github.com/urfave/cli/v2"
+)
+
+var amountFlag = cli.Uint64Flag{
+	Name:     "amount",
+	Usage:    "the amount in satoshi of base asset to buy or sell",
+	Required: true,
+}
+
+var limitPriceFlag = cli.StringFlag{
+	Name:  "limit_price",
+	Usage: "the max (buy) or min (sell) acceptable price in quote asset per unit of base asset, if omitted a market order is made",
+	Value: "",
+}
+
+var preview = cli.Command{
+	Name:  "preview",
+	Usage: "preview the amounts to send and receive for trading on the selected market",
+	Flags: []cli.Flag{
+		&amountFlag,
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "the trade type: buy or sell",
+			Value: "buy",
+		},
+	},
+	Action: previewAction,
+}
+
+var buy = cli.Command{
+	Name:   "buy",
+	Usage:  "buy some base asset of the selected market with the wallet funds",
+	Flags:  []cli.Flag{&amountFlag, &limitPriceFlag},
+	Action: buyAction,
+}
+
+var sell = cli.Command{
+	Name:   "sell",
+	Usage:  "sell some base asset of the selected market from the wallet funds",
+	Flags:  []cli.Flag{&amountFlag, &limitPriceFlag},
+	Action: sellAction,
+}
+
+func previewAction(ctx *cli.Context) error {
+	var tradeType tradetype.TradeType
+	switch ctx.String("type") {
+	case "buy":
+		tradeType = tradetype.Buy
+	case "sell":
+		tradeType = tradetype.Sell
+	default:
+		return &invalidUsageError{ctx, ctx.Command.Name}
+	}
+
+	mkt, err := getMarketFromState()
+	if err != nil {
+		return err
+	}
+
+	t, cleanup, err := getTrade(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	result, err := t.Preview(trade.PreviewOpts{
+		Market:    mkt,
+		TradeType: int(tradeType),
+		Amount:    ctx.Uint64("amount"),
+	})
+	if err != nil {
+		return err
+	}
+
+	jsonStr, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonStr))
+
+	return nil
+}
+
+func buyAction(ctx *cli.Context) error {
+	return tradeAction(ctx, tradetype.Buy)
+}
+
+func sellAction(ctx *cli.Context) error {
+	return tradeAction(ctx, tradetype.Sell)
+}
+
+func tradeAction(ctx *cli.Context, tradeType tradetype.TradeType) error {
+	mkt, err := getMarketFromState()
+	if err != nil {
+		return err
+	}
+
+	w, err := getWalletFromState(ctx)
+	if err != nil {
+		return err
+	}
+
+	t, cleanup, err := getTrade(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if limitPriceStr := ctx.String("limit_price"); len(limitPriceStr) > 0 {
+		limitPrice, err := decimal.NewFromString(limitPriceStr)
+		if err != nil {
+			return fmt.Errorf("invalid limit price: %w", err)
+		}
+		return limitOrder(t, trade.LimitOrderOpts{
+			Market:      mkt,
+			Amount:      ctx.Uint64("amount"),
+			LimitPrice:  limitPrice,
+			PrivateKey:  w.PrivateKey(),
+			BlindingKey: w.BlindingKey(),
+		}, tradeType)
+	}
+
+	opts := trade.BuyOrSellAndCompleteOpts{
+		Market:      mkt,
+		TradeType:   int(tradeType),
+		Amount:      ctx.Uint64("amount"),
+		PrivateKey:  w.PrivateKey(),
+		BlindingKey: w.BlindingKey(),
+	}
+
+	var txID string
+	if tradeType.IsBuy() {
+		txID, err = t.BuyAndComplete(opts)
+	} else {
+		txID, err = t.SellAndComplete(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	printTxID(txID)
+	return nil
+}
+
+func limitOrder(
+	t *trade.Trade,
+	opts trade.LimitOrderOpts,
+	tradeType tradetype.TradeType,
+) error {
+	var txID string
+	var err error
+	if tradeType.IsBuy() {
+		txID, err = t.LimitBuy(opts)
+	} else {
+		txID, err = t.LimitSell(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	printTxID(txID)
+	return nil
+}
+
+func printTxID(txID string) {
+	fmt.Println()
+	fmt.Println("trade completed with txid:")
+	fmt.Println(txID)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	"github.com/urfave/cli/v2"
+)
+
+var initwallet = cli.Command{
+	Name:  "init",
+	Usage: "create a new wallet with random keys or import an existing one",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "private_key",
+			Usage: "the hex encoded signing private key to import",
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name:  "blinding_key",
+			Usage: "the hex encoded blinding private key to import",
+			Value: "",
+		},
+	},
+	Action: initWalletAction,
+}
+
+var address = cli.Command{
+	Name:   "address",
+	Usage:  "print the confidential address of the wallet to fund",
+	Action: addressAction,
+}
+
+func initWalletAction(ctx *cli.Context) error {
+	net, err := getNetwork(ctx)
+	if err != nil {
+		return err
+	}
+
+	privateKeyHex := ctx.String("private_key")
+	blindingKeyHex := ctx.String("blinding_key")
+	if (len(privateKeyHex) > 0) != (len(blindingKeyHex) > 0) {
+		return &invalidUsageError{ctx, ctx.Command.Name}
+	}
+
+	var w *trade.Wallet
+	if len(privateKeyHex) > 0 {
+		privateKey, err := hex.DecodeString(privateKeyHex)
+		if err != nil {
+			return fmt.Errorf("invalid private key: %w", err)
+		}
+		blindingKey, err := hex.DecodeString(blindingKeyHex)
+		if err != nil {
+			return fmt.Errorf("invalid blinding key: %w", err)
+		}
+		w = trade.NewWalletFromKey(privateKey, blindingKey, net)
+	} else {
+		w, err = trade.NewRandomWallet(net)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := setWalletIntoState(w); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("wallet has been initialized, fund it at address:")
+	fmt.Println(w.Address())
+	return nil
+}
+
+func addressAction(ctx *cli.Context) error {
+	w, err := getWalletFromState(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(w.Address())
+	return nil
+}
+
+func getWalletFromState(ctx *cli.Context) (*trade.Wallet, error) {
+	net, err := getNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := getState()
+	if err != nil {
+		return nil, errors.New("wallet must be initialized")
+	}
+	privateKey, err := hex.DecodeString(state["private_key"])
+	if err != nil || len(privateKey) <= 0 {
+		return nil, errors.New("wallet must be initialized")
+	}
+	blindingKey, err := hex.DecodeString(state["blinding_key"])
+	if err != nil || len(blindingKey) <= 0 {
+		return nil, errors.New("wallet must be initialized")
+	}
+
+	return trade.NewWalletFromKey(privateKey, blindingKey, net), nil
+}
+
+func setWalletIntoState(w *trade.Wallet) error {
+	return setState(map[string]string{
+		"private_key":  hex.EncodeToString(w.PrivateKey()),
+		"blinding_key": hex.EncodeToString(w.BlindingKey()),
+	})
+}
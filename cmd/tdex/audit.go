@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/urfave/cli/v2"
+)
+
+var audit = cli.Command{
+	Name:  "audit",
+	Usage: "query and verify the audit log of the calls made on the operator interface",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "list the audit log entries of the selected range",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "from",
+					Usage: "the first day (YYYY-MM-DD, UTC) of the range",
+					Value: "",
+				},
+				&cli.StringFlag{
+					Name:  "to",
+					Usage: "the last day (YYYY-MM-DD, UTC) of the range, defaults to today",
+					Value: "",
+				},
+			},
+			Action: listAuditEntriesAction,
+		},
+		{
+			Name:   "verify",
+			Usage:  "verify the hash chain of the audit log",
+			Action: verifyAuditLogAction,
+		},
+	},
+}
+
+func listAuditEntriesAction(ctx *cli.Context) error {
+	fromTime, toTime, err := parseDateRange(ctx.String("from"), ctx.String("to"))
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ListAuditEntries(
+		context.Background(), &rpcext.ListAuditEntriesRequest{
+			FromTimeUnix: fromTime,
+			ToTimeUnix:   toTime,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp.Entries)
+}
+
+func verifyAuditLogAction(ctx *cli.Context) error {
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.VerifyAuditLog(
+		context.Background(), &rpcext.VerifyAuditLogRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := printJSON(resp); err != nil {
+		return err
+	}
+	if !resp.Valid {
+		return fmt.Errorf(
+			"audit log is corrupted from entry %d: %s",
+			resp.FirstInvalidIndex, resp.Reason,
+		)
+	}
+	return nil
+}
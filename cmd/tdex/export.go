@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	formatCSV       = "csv"
+	formatJSONLines = "jsonl"
+	dateLayout      = "2006-01-02"
+)
+
+var csvHeader = []string{
+	"kind",
+	"id",
+	"time_unix",
+	"account_index",
+	"base_asset",
+	"quote_asset",
+	"asset_in",
+	"amount_in",
+	"asset_out",
+	"amount_out",
+	"price",
+	"fee_asset",
+	"fee_amount",
+	"txid",
+	"request_time_unix",
+	"accept_time_unix",
+	"complete_time_unix",
+}
+
+var export = cli.Command{
+	Name:  "export",
+	Usage: "export completed trades, deposits and withdrawals for accounting",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "the output format, either csv or jsonl (JSON lines)",
+			Value: formatCSV,
+		},
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "the first day (YYYY-MM-DD, UTC) of the range to export",
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "the last day (YYYY-MM-DD, UTC) of the range to export, defaults to today",
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name:  "out",
+			Usage: "the path of the file where to write the export, defaults to stdout",
+			Value: "",
+		},
+	},
+	Action: exportAction,
+}
+
+func exportAction(ctx *cli.Context) error {
+	format := ctx.String("format")
+	if format != formatCSV && format != formatJSONLines {
+		return fmt.Errorf("format must be either '%s' or '%s'", formatCSV, formatJSONLines)
+	}
+
+	fromTime, toTime, err := parseDateRange(ctx.String("from"), ctx.String("to"))
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	stream, err := client.Export(
+		context.Background(), &rpcext.ExportRequest{
+			FromTimeUnix: fromTime,
+			ToTimeUnix:   toTime,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if path := ctx.String("out"); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	write := newJSONLinesWriter(out)
+	flush := func() error { return nil }
+	if format == formatCSV {
+		csvWriter := csv.NewWriter(out)
+		if err := csvWriter.Write(csvHeader); err != nil {
+			return err
+		}
+		write = newCSVWriter(csvWriter)
+		flush = func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	}
+
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := write(reply.Record); err != nil {
+			return err
+		}
+	}
+
+	return flush()
+}
+
+// parseDateRange returns the unix timestamps of the beginning of the from
+// day and of the end of the to day
+func parseDateRange(from, to string) (uint64, uint64, error) {
+	var fromTime, toTime uint64
+
+	if from != "" {
+		t, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid from date: %w", err)
+		}
+		fromTime = uint64(t.Unix())
+	}
+	if to != "" {
+		t, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid to date: %w", err)
+		}
+		toTime = uint64(t.AddDate(0, 0, 1).Unix()) - 1
+	}
+	if toTime > 0 && fromTime > toTime {
+		return 0, 0, fmt.Errorf("from date must not be after to date")
+	}
+
+	return fromTime, toTime, nil
+}
+
+func newJSONLinesWriter(w io.Writer) func(*rpcext.ExportRecord) error {
+	encoder := json.NewEncoder(w)
+	return func(record *rpcext.ExportRecord) error {
+		return encoder.Encode(record)
+	}
+}
+
+func newCSVWriter(w *csv.Writer) func(*rpcext.ExportRecord) error {
+	return func(r *rpcext.ExportRecord) error {
+		return w.Write([]string{
+			r.Kind,
+			r.ID,
+			strconv.FormatUint(r.TimeUnix, 10),
+			strconv.Itoa(r.AccountIndex),
+			r.BaseAsset,
+			r.QuoteAsset,
+			r.AssetIn,
+			strconv.FormatUint(r.AmountIn, 10),
+			r.AssetOut,
+			strconv.FormatUint(r.AmountOut, 10),
+			r.Price,
+			r.FeeAsset,
+			strconv.FormatUint(r.FeeAmount, 10),
+			r.TxID,
+			strconv.FormatUint(r.RequestTimeUnix, 10),
+			strconv.FormatUint(r.AcceptTimeUnix, 10),
+			strconv.FormatUint(r.CompleteTimeUnix, 10),
+		})
+	}
+}
@@ -20,7 +20,7 @@ var initwallet = cli.Command{
 		},
 		&cli.StringFlag{
 			Name:  "seed",
-			Usage: "the mnemonic seed of the daemon wallet",
+			Usage: "the mnemonic seed of the daemon wallet, not given in remote signer mode",
 		},
 	},
 	Action: initWalletAction,
@@ -38,11 +38,12 @@ func initWalletAction(ctx *cli.Context) error {
 	password := ctx.String("password")
 	seed := ctx.String("seed")
 
-	if len(password) > 0 && len(seed) > 0 {
-		req = &pbwallet.InitWalletRequest{
-			WalletPassword: []byte(password),
-			SeedMnemonic:   strings.Split(seed, " "),
-		}
+	if len(password) > 0 {
+		req.WalletPassword = []byte(password)
+	}
+	// in remote signer mode the seed is given to tdex-signer only
+	if len(seed) > 0 {
+		req.SeedMnemonic = strings.Split(seed, " ")
 	}
 
 	stream, err := client.InitWallet(
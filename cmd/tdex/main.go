@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"github.com/vulpemventures/go-elements/network"
 	"google.golang.org/grpc"
 
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
 	pboperator "github.com/tdex-network/tdex-protobuf/generated/go/operator"
 	pbwallet "github.com/tdex-network/tdex-protobuf/generated/go/wallet"
 )
@@ -71,6 +73,11 @@ func main() {
 		&closemarket,
 		&updatestrategy,
 		&updateprice,
+		&export,
+		&report,
+		&whitelist,
+		&payments,
+		&audit,
 	)
 
 	err := app.Run(os.Args)
@@ -161,7 +168,7 @@ func getOperatorClient(ctx *cli.Context) (pboperator.OperatorClient, func(),
 
 	rpcServer := ctx.String("rpcserver")
 
-	var macaroonHex = ""
+	macaroonHex := ctx.String("macaroon")
 
 	conn, err := getClientConn(rpcServer, macaroonHex)
 	if err != nil {
@@ -172,12 +179,30 @@ func getOperatorClient(ctx *cli.Context) (pboperator.OperatorClient, func(),
 	return pboperator.NewOperatorClient(conn), cleanup, nil
 }
 
+func getOperatorExtensionClient(ctx *cli.Context) (
+	rpcext.OperatorExtensionClient,
+	func(),
+	error,
+) {
+	rpcServer := ctx.String("rpcserver")
+
+	macaroonHex := ctx.String("macaroon")
+
+	conn, err := getClientConn(rpcServer, macaroonHex)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = conn.Close() }
+
+	return rpcext.NewOperatorExtensionClient(conn), cleanup, nil
+}
+
 func getWalletClient(ctx *cli.Context) (pbwallet.WalletClient, func(),
 	error) {
 
 	rpcServer := ctx.String("rpcserver")
 
-	var macaroonHex = ""
+	macaroonHex := ctx.String("macaroon")
 
 	conn, err := getClientConn(rpcServer, macaroonHex)
 	if err != nil {
@@ -192,6 +217,9 @@ func getClientConn(address, macaroonHex string) (*grpc.ClientConn,
 	error) {
 
 	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(maxMsgRecvSize), grpc.WithInsecure()}
+	if macaroonHex != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(macaroonCredential(macaroonHex)))
+	}
 
 	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
@@ -202,6 +230,21 @@ func getClientConn(address, macaroonHex string) (*grpc.ClientConn,
 	return conn, nil
 }
 
+// macaroonCredential attaches the hex encoded macaroon to the metadata of
+// every call
+type macaroonCredential string
+
+func (m macaroonCredential) GetRequestMetadata(
+	ctx context.Context,
+	uri ...string,
+) (map[string]string, error) {
+	return map[string]string{"macaroon": string(m)}, nil
+}
+
+func (m macaroonCredential) RequireTransportSecurity() bool {
+	return false
+}
+
 type invalidUsageError struct {
 	ctx     *cli.Context
 	command string
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/urfave/cli/v2"
+)
+
+var payments = cli.Command{
+	Name:   "payments",
+	Usage:  "list the audit records of every outgoing payment",
+	Action: paymentsAction,
+}
+
+func paymentsAction(ctx *cli.Context) error {
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ListPayments(
+		context.Background(), &rpcext.ListPaymentsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp.Payments)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/urfave/cli/v2"
+)
+
+var report = cli.Command{
+	Name:  "report",
+	Usage: "get reserves, volume, fees and impermanent loss of the selected market",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "the first day (YYYY-MM-DD, UTC) of the reported range",
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "the last day (YYYY-MM-DD, UTC) of the reported range, defaults to today",
+			Value: "",
+		},
+	},
+	Action: reportAction,
+}
+
+func reportAction(ctx *cli.Context) error {
+	fromTime, toTime, err := parseDateRange(ctx.String("from"), ctx.String("to"))
+	if err != nil {
+		return err
+	}
+
+	baseAsset, quoteAsset, err := getMarketFromState()
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.MarketReport(
+		context.Background(), &rpcext.MarketReportRequest{
+			BaseAsset:    baseAsset,
+			QuoteAsset:   quoteAsset,
+			FromTimeUnix: fromTime,
+			ToTimeUnix:   toTime,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	jsonStr, err := json.MarshalIndent(resp.Report, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonStr))
+
+	return nil
+}
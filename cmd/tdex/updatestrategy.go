@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
 	pboperator "github.com/tdex-network/tdex-protobuf/generated/go/operator"
 	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
 
@@ -19,21 +21,41 @@ var updatestrategy = cli.Command{
 			Usage: "set the strategy as pluggable",
 			Value: false,
 		},
+		&cli.BoolFlag{
+			Name:  "unbalanced",
+			Usage: "set the strategy as unbalanced, with the target ratio given by --base-weight",
+			Value: false,
+		},
+		&cli.Uint64Flag{
+			Name:  "base-weight",
+			Usage: "the target percentage [1, 99] of the reserves value held in base asset for the unbalanced strategy",
+			Value: 50,
+		},
 	},
 	Action: updateStrategyAction,
 }
 
 func updateStrategyAction(ctx *cli.Context) error {
-	client, cleanup, err := getOperatorClient(ctx)
+	if ctx.Bool("pluggable") && ctx.Bool("unbalanced") {
+		return errors.New("--pluggable and --unbalanced are mutually exclusive")
+	}
+
+	baseAsset, quoteAsset, err := getMarketFromState()
 	if err != nil {
 		return err
 	}
-	defer cleanup()
 
-	baseAsset, quoteAsset, err := getMarketFromState()
+	if ctx.Bool("unbalanced") {
+		return updateStrategyUnbalanced(
+			ctx, baseAsset, quoteAsset, ctx.Uint64("base-weight"),
+		)
+	}
+
+	client, cleanup, err := getOperatorClient(ctx)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
 	strategy := pboperator.StrategyType_BALANCED
 	if ctx.Bool("pluggable") {
@@ -57,3 +79,30 @@ func updateStrategyAction(ctx *cli.Context) error {
 	fmt.Println("strategy has been updated")
 	return nil
 }
+
+func updateStrategyUnbalanced(
+	ctx *cli.Context,
+	baseAsset, quoteAsset string,
+	baseAssetWeight uint64,
+) error {
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, err = client.UpdateMarketStrategyUnbalanced(
+		context.Background(), &rpcext.UpdateMarketStrategyUnbalancedRequest{
+			BaseAsset:       baseAsset,
+			QuoteAsset:      quoteAsset,
+			BaseAssetWeight: baseAssetWeight,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("strategy has been updated")
+	return nil
+}
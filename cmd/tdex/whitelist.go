@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/urfave/cli/v2"
+)
+
+var whitelist = cli.Command{
+	Name:  "whitelist",
+	Usage: "manage the addresses funds can be withdrawn to",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "add",
+			Usage: "whitelist an address, usable only once its cool-down is over",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "address",
+					Usage:    "the confidential address to whitelist",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "label",
+					Usage: "an optional label for the address",
+					Value: "",
+				},
+				&cli.StringFlag{
+					Name:     "password",
+					Usage:    "the password used to encrypt the mnemonic",
+					Required: true,
+				},
+			},
+			Action: addWithdrawalAddressAction,
+		},
+		{
+			Name:  "remove",
+			Usage: "remove an address from the whitelist",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "address",
+					Usage:    "the whitelisted address to remove",
+					Required: true,
+				},
+			},
+			Action: removeWithdrawalAddressAction,
+		},
+		{
+			Name:   "list",
+			Usage:  "list the whitelisted addresses",
+			Action: listWithdrawalAddressesAction,
+		},
+	},
+}
+
+func addWithdrawalAddressAction(ctx *cli.Context) error {
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.AddWithdrawalAddress(
+		context.Background(), &rpcext.AddWithdrawalAddressRequest{
+			Address:    ctx.String("address"),
+			Label:      ctx.String("label"),
+			Passphrase: ctx.String("password"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp.WithdrawalAddress)
+}
+
+func removeWithdrawalAddressAction(ctx *cli.Context) error {
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := client.RemoveWithdrawalAddress(
+		context.Background(), &rpcext.RemoveWithdrawalAddressRequest{
+			Address: ctx.String("address"),
+		},
+	); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("Address removed from whitelist")
+	return nil
+}
+
+func listWithdrawalAddressesAction(ctx *cli.Context) error {
+	client, cleanup, err := getOperatorExtensionClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ListWithdrawalAddresses(
+		context.Background(), &rpcext.ListWithdrawalAddressesRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(resp.WithdrawalAddresses)
+}
+
+func printJSON(v interface{}) error {
+	jsonStr, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonStr))
+	return nil
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,22 +17,43 @@ import (
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"github.com/soheilhy/cmux"
 	"github.com/tdex-network/tdex-daemon/internal/core/application"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/auditlog"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/keystore"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/signer"
 	grpchandler "github.com/tdex-network/tdex-daemon/internal/interfaces/grpc/handler"
 	"github.com/tdex-network/tdex-daemon/internal/interfaces/grpc/interceptor"
+	"github.com/tdex-network/tdex-daemon/internal/interfaces/health"
+	"github.com/tdex-network/tdex-daemon/internal/interfaces/logging"
+	"github.com/tdex-network/tdex-daemon/internal/interfaces/metrics"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
 	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/pkg/crawler"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	pboperator "github.com/tdex-network/tdex-protobuf/generated/go/operator"
 	pbtrader "github.com/tdex-network/tdex-protobuf/generated/go/trade"
 	pbwallet "github.com/tdex-network/tdex-protobuf/generated/go/wallet"
 )
 
+// healthCheckInterval is the interval at which the status of the gRPC health
+// service is updated
+const healthCheckInterval = 10 * time.Second
+
 func main() {
-	log.SetLevel(log.Level(config.GetInt(config.LogLevelKey)))
+	if err := config.Load(os.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			return
+		}
+		log.WithError(err).Fatal("error while loading config")
+	}
+	initLogger()
 
 	dbDir := filepath.Join(config.GetString(config.DataDirPathKey), "db")
 	dbManager, err := dbbadger.NewDbManager(dbDir, log.New())
@@ -42,14 +65,49 @@ func main() {
 	vaultRepository := dbbadger.NewVaultRepositoryImpl(dbManager)
 	marketRepository := dbbadger.NewMarketRepositoryImpl(dbManager)
 	tradeRepository := dbbadger.NewTradeRepositoryImpl(dbManager)
+	depositRepository := dbbadger.NewDepositRepositoryImpl(dbManager)
+	withdrawalRepository := dbbadger.NewWithdrawalRepositoryImpl(dbManager)
+	withdrawalAddressRepository := dbbadger.NewWithdrawalAddressRepositoryImpl(dbManager)
+	paymentRepository := dbbadger.NewPaymentRepositoryImpl(dbManager)
 
-	explorerSvc := explorer.NewService(config.GetString(config.ExplorerEndpointKey))
+	// the health checker is created once the wallet service is, but before the
+	// crawler gets started by the blockchain listener
+	var healthChecker *health.Checker
+	explorerSvc := metrics.NewExplorerService(
+		explorer.NewService(config.GetString(config.ExplorerEndpointKey)),
+	)
 	crawlerSvc := crawler.NewService(crawler.Opts{
 		ExplorerSvc:            explorerSvc,
 		Observables:            []crawler.Observable{},
 		ErrorHandler:           func(err error) { log.Warn(err) },
 		IntervalInMilliseconds: config.GetInt(config.CrawlIntervalKey),
+		CycleHandler: func(observables int, duration time.Duration) {
+			metrics.ObserveCrawlerCycle(observables, duration)
+			healthChecker.ObserveCrawlerCycle(observables, duration)
+		},
 	})
+	// the keystore holds the keys of the unlocked wallet. It's locked and
+	// unlocked by the wallet service, while the others can only use it to sign.
+	// In remote signer mode, the mnemonic and the keys are held by tdex-signer
+	// instead, and the daemon only forwards it the passphrase to unlock them.
+	walletKeystore := keystore.NewKeystore(
+		time.Duration(config.GetInt(config.AutoLockTimeoutKey)) * time.Second,
+	)
+	if signerAddr := config.GetString(config.SignerAddressKey); signerAddr != "" {
+		tlsConfig, err := signer.NewClientTLSConfig(
+			config.GetString(config.SignerTLSCertKey),
+			config.GetString(config.SignerTLSKeyKey),
+			config.GetString(config.SignerTLSCAKey),
+		)
+		if err != nil {
+			log.WithError(err).Panic("error while loading signer TLS config")
+		}
+		walletKeystore, err = signer.NewRemoteKeystore(signerAddr, tlsConfig)
+		if err != nil {
+			log.WithError(err).Panic("error while connecting to remote signer")
+		}
+		log.Infof("blinding and signing delegated to remote signer %s", signerAddr)
+	}
 	traderSvc := application.NewTradeService(
 		marketRepository,
 		tradeRepository,
@@ -57,21 +115,38 @@ func main() {
 		unspentRepository,
 		explorerSvc,
 		crawlerSvc,
+		walletKeystore,
 	)
 	walletSvc := application.NewWalletService(
 		vaultRepository,
 		unspentRepository,
+		withdrawalAddressRepository,
+		paymentRepository,
 		crawlerSvc,
 		explorerSvc,
+		walletKeystore,
 	)
+	healthChecker = health.NewChecker(health.CheckerOpts{
+		WalletSvc:        walletSvc,
+		DbStatus:         dbManager.Status,
+		ExplorerEndpoint: config.GetString(config.ExplorerEndpointKey),
+		CrawlInterval: time.Duration(
+			config.GetInt(config.CrawlIntervalKey),
+		) * time.Millisecond,
+	})
 
 	blockchainListener := application.NewBlockchainListener(
 		unspentRepository,
 		marketRepository,
 		vaultRepository,
+		tradeRepository,
+		depositRepository,
 		crawlerSvc,
 		explorerSvc,
 		dbManager,
+		func(trade domain.Trade, spendingTxID string) {
+			metrics.ObserveDoubleSpentTrade(trade.MarketQuoteAsset)
+		},
 	)
 	blockchainListener.ObserveBlockchain()
 
@@ -80,62 +155,184 @@ func main() {
 		vaultRepository,
 		tradeRepository,
 		unspentRepository,
+		depositRepository,
+		withdrawalRepository,
+		withdrawalAddressRepository,
+		paymentRepository,
 		explorerSvc,
 		crawlerSvc,
+		walletKeystore,
 	)
 
 	// Ports
 	traderAddress := fmt.Sprintf(":%+v", config.GetInt(config.TraderListeningPortKey))
 	operatorAddress := fmt.Sprintf(":%+v", config.GetInt(config.OperatorListeningPortKey))
+	rateLimiter, err := interceptor.NewRateLimiter(interceptor.RateLimiterOpts{
+		TradeProposalsPerIP:       config.GetInt(config.TradeProposalsPerIPKey),
+		TradeProposalsPerMarket:   config.GetInt(config.TradeProposalsPerMarketKey),
+		MaxPendingTradesPerMarket: config.GetInt(config.MaxPendingTradesPerMarketKey),
+		BanList:                   strings.Split(config.GetString(config.BanListKey), ","),
+		BaseAsset:                 config.GetString(config.BaseAssetKey),
+	})
+	if err != nil {
+		log.WithError(err).Panic("error while parsing ban list")
+	}
+	if err := rateLimiter.LoadPendingTrades(
+		context.Background(), tradeRepository,
+	); err != nil {
+		log.WithError(err).Panic("error while loading pending trades")
+	}
+
+	// every call made on the operator interface is recorded in the audit log
+	auditLogPath := filepath.Join(
+		config.GetString(config.DataDirPathKey), "audit.log",
+	)
+	auditLog, err := auditlog.NewAuditLog(auditLogPath)
+	if err != nil {
+		log.WithError(err).Panic("error while opening audit log")
+	}
+	auditor := interceptor.NewAuditor(auditLog)
+
 	// Grpc Server
 	traderGrpcServer := grpc.NewServer(
-		interceptor.UnaryInterceptor(dbManager),
-		interceptor.StreamInterceptor(dbManager),
+		interceptor.TraderUnaryInterceptor(dbManager, rateLimiter),
+		interceptor.TraderStreamInterceptor(dbManager, rateLimiter),
 	)
 	operatorGrpcServer := grpc.NewServer(
-		interceptor.UnaryInterceptor(dbManager),
-		interceptor.StreamInterceptor(dbManager),
+		interceptor.UnaryInterceptor(dbManager, auditor),
+		interceptor.StreamInterceptor(dbManager, auditor),
 	)
 
 	traderHandler := grpchandler.NewTraderHandler(traderSvc, dbManager)
+	traderExtHandler := grpchandler.NewTraderExtensionHandler(
+		traderSvc,
+		dbManager,
+	)
 	walletHandler := grpchandler.NewWalletHandler(walletSvc, dbManager)
-	operatorHandler := grpchandler.NewOperatorHandler(operatorSvc, dbManager)
+	operatorHandler := grpchandler.NewOperatorHandler(
+		operatorSvc,
+		dbManager,
+		auditLog,
+	)
+	operatorExtHandler := grpchandler.NewOperatorExtensionHandler(
+		operatorSvc,
+		dbManager,
+		auditLog,
+	)
 
 	// Register proto implementations on Trader interface
 	pbtrader.RegisterTradeServer(traderGrpcServer, traderHandler)
+	rpcext.RegisterTradeExtensionServer(traderGrpcServer, traderExtHandler)
 	// Register proto implementations on Operator interface
 	pboperator.RegisterOperatorServer(operatorGrpcServer, operatorHandler)
 	pbwallet.RegisterWalletServer(operatorGrpcServer, walletHandler)
+	rpcext.RegisterOperatorExtensionServer(operatorGrpcServer, operatorExtHandler)
+	// Register the gRPC health service on both interfaces
+	healthpb.RegisterHealthServer(traderGrpcServer, healthChecker.GRPCServer())
+	healthpb.RegisterHealthServer(operatorGrpcServer, healthChecker.GRPCServer())
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go healthChecker.Watch(watchCtx, healthCheckInterval)
+
+	if err := metrics.Register(metrics.NewStateCollector(
+		metrics.StateCollectorOpts{
+			TradeRepository:   tradeRepository,
+			VaultRepository:   vaultRepository,
+			UnspentRepository: unspentRepository,
+			RejectedCalls:     rateLimiter.RejectedCalls,
+		},
+	)); err != nil {
+		log.WithError(err).Panic("error while registering metrics")
+	}
 
 	log.Debug("starting daemon")
 
 	defer stop(
 		dbManager,
+		auditLog,
 		blockchainListener,
 		traderGrpcServer,
 		operatorGrpcServer,
 	)
 
 	// Serve grpc and grpc-web multiplexed on the same port
-	if err := serveMux(traderAddress, traderGrpcServer); err != nil {
+	if err := serveMux(
+		traderAddress, traderGrpcServer, healthChecker.HTTPHandler(),
+	); err != nil {
 		log.WithError(err).Panic("error listening on trader interface")
 	}
-	if err := serveMux(operatorAddress, operatorGrpcServer); err != nil {
+	if err := serveMux(
+		operatorAddress, operatorGrpcServer, healthChecker.HTTPHandler(),
+	); err != nil {
 		log.WithError(err).Panic("error listening on operator interface")
 	}
 
+	if port := config.GetInt(config.MetricsListeningPortKey); port > 0 {
+		metricsAddress := fmt.Sprintf(":%+v", port)
+		if err := metrics.Serve(metricsAddress); err != nil {
+			log.WithError(err).Panic("error listening on metrics interface")
+		}
+		log.Debug("metrics are served on " + metricsAddress + "/metrics")
+	}
+
 	log.Debug("trader interface is listening on " + traderAddress)
 	log.Debug("operator interface is listening on " + operatorAddress)
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
-	<-sigChan
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	for sig := <-sigChan; sig == syscall.SIGHUP; sig = <-sigChan {
+		reloadConfig(crawlerSvc, healthChecker)
+	}
 
 	log.Debug("shutting down daemon")
 }
 
+// initLogger configures the format of the logs and, unless disabled, makes
+// them written also to a rotating file in the data dir
+func initLogger() {
+	opts := logging.Opts{
+		Level:      log.Level(config.GetInt(config.LogLevelKey)),
+		Format:     config.GetString(config.LogFormatKey),
+		MaxSize:    config.GetInt(config.LogFileMaxSizeKey),
+		MaxBackups: config.GetInt(config.LogFileMaxBackupsKey),
+		MaxAge:     config.GetInt(config.LogFileMaxAgeKey),
+	}
+	if opts.MaxSize > 0 {
+		opts.FilePath = filepath.Join(
+			config.GetString(config.DataDirPathKey), "tdexd.log",
+		)
+	}
+	logging.Init(opts)
+}
+
+// reloadConfig loads again the configuration and applies the changes of the
+// keys that don't require a restart. Price slippage and fee account balance
+// threshold are read at every use, so they don't need to be applied here.
+func reloadConfig(crawlerSvc crawler.Service, healthChecker *health.Checker) {
+	changed, err := config.Reload()
+	if err != nil {
+		log.WithError(err).Warn("config not reloaded")
+		return
+	}
+
+	for _, key := range changed {
+		switch key {
+		case config.LogLevelKey:
+			log.SetLevel(log.Level(config.GetInt(config.LogLevelKey)))
+		case config.CrawlIntervalKey:
+			interval := config.GetInt(config.CrawlIntervalKey)
+			crawlerSvc.SetInterval(interval)
+			healthChecker.SetCrawlInterval(
+				time.Duration(interval) * time.Millisecond,
+			)
+		}
+	}
+	log.Infof("config reloaded, changed keys: [%s]", strings.Join(changed, ", "))
+}
+
 func stop(
 	dbManager *dbbadger.DbManager,
+	auditLog ports.AuditLog,
 	blockchainListener application.BlockchainListener,
 	traderServer *grpc.Server,
 	operatorServer *grpc.Server,
@@ -157,10 +354,19 @@ func stop(
 	dbManager.UnspentStore.Close()
 	dbManager.PriceStore.Close()
 	log.Debug("closed connection with database")
+
+	auditLog.Close()
+	log.Debug("closed audit log")
 	log.Debug("exiting")
 }
 
-func serveMux(address string, grpcServer *grpc.Server) error {
+// serveMux serves gRPC and, over HTTP/1, grpc-web and the given handler on
+// the same port
+func serveMux(
+	address string,
+	grpcServer *grpc.Server,
+	httpHandler http.Handler,
+) error {
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		return err
@@ -175,7 +381,9 @@ func serveMux(address string, grpcServer *grpc.Server) error {
 	go http.Serve(httpL, http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 		if grpcWebServer.IsGrpcWebRequest(req) {
 			grpcWebServer.ServeHTTP(resp, req)
+			return
 		}
+		httpHandler.ServeHTTP(resp, req)
 	}))
 
 	go mux.Serve()
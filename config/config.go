@@ -1,16 +1,19 @@
 package config
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/btcsuite/btcutil"
 
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/vulpemventures/go-elements/network"
 )
@@ -40,40 +43,320 @@ const (
 	TradeExpiryTimeKey = "TRADE_EXPIRY_TIME"
 	// PriceSlippageKey ...
 	PriceSlippageKey = "PRICE_SLIPPAGE"
-	// MnemonicKey ...
-	MnemonicKey = "MNEMONIC"
 	//UnspentTtlKey ...
 	UnspentTtlKey = "UNSPENT_TTL"
+	// EnableMultiAssetSwapsKey enables trades with more than one asset sent
+	// and/or received by the trader
+	EnableMultiAssetSwapsKey = "ENABLE_MULTI_ASSET_SWAPS"
+	// QuoteExpiryTimeKey is the number of seconds a quoted price is honoured
+	// for
+	QuoteExpiryTimeKey = "QUOTE_EXPIRY_TIME"
+	// TradeProposalsPerIPKey is the max number of trade proposals per minute
+	// accepted from the same client address, 0 means no limit
+	TradeProposalsPerIPKey = "TRADE_PROPOSALS_PER_IP"
+	// TradeProposalsPerMarketKey is the max number of trade proposals per
+	// minute accepted for the same market, 0 means no limit
+	TradeProposalsPerMarketKey = "TRADE_PROPOSALS_PER_MARKET"
+	// MaxPendingTradesPerMarketKey is the max number of trades of a market
+	// accepted and not yet completed or expired, 0 means no limit
+	MaxPendingTradesPerMarketKey = "MAX_PENDING_TRADES_PER_MARKET"
+	// BanListKey is a comma separated list of IP addresses or CIDR blocks
+	// whose calls to the trader interface are rejected
+	BanListKey = "BAN_LIST"
+	// AutoLockTimeoutKey is the number of seconds after which the unlocked
+	// wallet is locked again if not used, 0 means never
+	AutoLockTimeoutKey = "AUTO_LOCK_TIMEOUT"
+	// SignerAddressKey is the host:port of the tdex-signer holding the keys of
+	// the wallet. If set, tdexd delegates blinding and signing to it, while
+	// tdex-signer listens on it
+	SignerAddressKey = "SIGNER_ADDRESS"
+	// SignerWithdrawalWhitelistKey is the comma separated list of the only
+	// addresses tdex-signer allows withdrawals to
+	SignerWithdrawalWhitelistKey = "SIGNER_WITHDRAWAL_WHITELIST"
+	// SignerPriceBoundsKey is the comma separated list of the min and max base
+	// price, in the form quote_asset:min:max, of the swaps that tdex-signer
+	// allows for every market. Swaps of markets not listed are rejected
+	SignerPriceBoundsKey = "SIGNER_PRICE_BOUNDS"
+	// SignerTLSCertKey and SignerTLSKeyKey are the paths of the TLS
+	// certificate and key that tdex-signer serves and tdexd presents as client
+	SignerTLSCertKey = "SIGNER_TLS_CERT"
+	SignerTLSKeyKey  = "SIGNER_TLS_KEY"
+	// SignerTLSCAKey is the path of the certificate of the CA that signs the
+	// certificates of both tdexd and tdex-signer, so that each one verifies
+	// the other
+	SignerTLSCAKey = "SIGNER_TLS_CA"
+	// KDFKey is the key derivation function used to derive the key that
+	// encrypts the mnemonic from the passphrase, either scrypt or argon2id.
+	// Vaults encrypted with a different function or params are migrated on
+	// next unlock
+	KDFKey = "KDF"
+	// ScryptNKey is the CPU/memory cost of scrypt, must be a power of 2
+	ScryptNKey = "SCRYPT_N"
+	// Argon2TimeKey is the number of passes over the memory of argon2id
+	Argon2TimeKey = "ARGON2_TIME"
+	// Argon2MemoryKey is the memory in KiB used by argon2id
+	Argon2MemoryKey = "ARGON2_MEMORY"
+	// Argon2ThreadsKey is the degree of parallelism of argon2id
+	Argon2ThreadsKey = "ARGON2_THREADS"
+	// PassphraseMinLengthKey is the min number of characters of the
+	// passphrase set when initializing the wallet or changing its passphrase
+	PassphraseMinLengthKey = "PASSPHRASE_MIN_LENGTH"
+	// PassphraseMinCharClassesKey is the min number of character classes
+	// (lowercase, uppercase, digits, symbols) the passphrase must mix
+	PassphraseMinCharClassesKey = "PASSPHRASE_MIN_CHAR_CLASSES"
+	// WithdrawalAddressCoolDownKey is the number of seconds after which a
+	// newly whitelisted withdrawal address can be paid
+	WithdrawalAddressCoolDownKey = "WITHDRAWAL_ADDRESS_COOL_DOWN"
+	// WithdrawalDailyLimitsKey is the comma separated list of asset:amount
+	// pairs defining the max amount of an asset (in satoshis) that can be
+	// withdrawn or sent in 24 hours. Assets not listed are not limited
+	WithdrawalDailyLimitsKey = "WITHDRAWAL_DAILY_LIMITS"
+	// MetricsListeningPortKey is the port of the HTTP listener serving the
+	// prometheus metrics at /metrics, 0 means disabled
+	MetricsListeningPortKey = "METRICS_LISTENING_PORT"
+	// LogFormatKey is the format of the log lines, either text or json
+	LogFormatKey = "LOG_FORMAT"
+	// LogFileMaxSizeKey is the size in MB after which the log file in the
+	// data dir is rotated, 0 means no log file
+	LogFileMaxSizeKey = "LOG_FILE_MAX_SIZE"
+	// LogFileMaxBackupsKey is the max number of rotated log files retained,
+	// 0 means all
+	LogFileMaxBackupsKey = "LOG_FILE_MAX_BACKUPS"
+	// LogFileMaxAgeKey is the max number of days rotated log files are
+	// retained for, 0 means forever
+	LogFileMaxAgeKey = "LOG_FILE_MAX_AGE"
 )
 
-var vip *viper.Viper
-var defaultDataDir = btcutil.AppDataDir("tdex-daemon", false)
+// ConfigFileName is the name of the TOML config file read from the data dir
+const ConfigFileName = "tdexd.conf"
+
+// ReloadableKeys are the keys whose changes are applied by Reload without
+// restarting the daemon
+var ReloadableKeys = []string{
+	LogLevelKey,
+	CrawlIntervalKey,
+	PriceSlippageKey,
+	FeeAccountBalanceThresholdKey,
+}
+
+var (
+	vip            *viper.Viper
+	vipLock        = &sync.RWMutex{}
+	flags          *pflag.FlagSet
+	defaultDataDir = btcutil.AppDataDir("tdex-daemon", false)
+)
+
+// usages describes every key, both as command line flag and as entry of the
+// config file
+var usages = map[string]string{
+	TraderListeningPortKey:        "port of the trader interface",
+	OperatorListeningPortKey:      "port of the operator interface",
+	ExplorerEndpointKey:           "url of the esplora explorer",
+	DataDirPathKey:                "path of the data dir, can't be set in the config file",
+	LogLevelKey:                   "log level, from 0 (panic) to 6 (trace)",
+	DefaultFeeKey:                 "default percentage fee of new markets",
+	NetworkKey:                    "network, either liquid or regtest",
+	BaseAssetKey:                  "hash of the base asset of the markets",
+	CrawlIntervalKey:              "milliseconds between two cycles of the blockchain crawler",
+	FeeAccountBalanceThresholdKey: "min balance in satoshis of the fee account to serve trades",
+	TradeExpiryTimeKey:            "seconds after which an accepted trade not completed expires",
+	PriceSlippageKey:              "max slippage of the price of a trade, from 0 to 1",
+	UnspentTtlKey:                 "seconds after which unspents locked by a trade are unlocked",
+	EnableMultiAssetSwapsKey:      "enable trades with more than one asset sent or received",
+	QuoteExpiryTimeKey:            "seconds a quoted price is honoured for",
+	TradeProposalsPerIPKey:        "max trade proposals per minute from the same address, 0 means no limit",
+	TradeProposalsPerMarketKey:    "max trade proposals per minute for the same market, 0 means no limit",
+	MaxPendingTradesPerMarketKey:  "max pending trades per market, 0 means no limit",
+	BanListKey:                    "comma separated list of IP addresses or CIDR blocks banned from the trader interface",
+	AutoLockTimeoutKey:            "seconds after which the unlocked wallet is locked if not used, 0 means never",
+	SignerAddressKey:              "host:port of the tdex-signer holding the keys of the wallet",
+	SignerWithdrawalWhitelistKey:  "comma separated list of the addresses tdex-signer allows withdrawals to",
+	SignerPriceBoundsKey:          "comma separated list of quote_asset:min:max base prices of the swaps tdex-signer allows",
+	SignerTLSCertKey:              "path of the TLS certificate of the connection between tdexd and tdex-signer",
+	SignerTLSKeyKey:               "path of the TLS key of the connection between tdexd and tdex-signer",
+	SignerTLSCAKey:                "path of the certificate of the CA of both tdexd and tdex-signer",
+	KDFKey:                        "key derivation function of the vault, either scrypt or argon2id",
+	ScryptNKey:                    "CPU/memory cost of scrypt, power of 2",
+	Argon2TimeKey:                 "number of passes over the memory of argon2id",
+	Argon2MemoryKey:               "memory in KiB used by argon2id",
+	Argon2ThreadsKey:              "degree of parallelism of argon2id",
+	PassphraseMinLengthKey:        "min number of characters of the wallet passphrase",
+	PassphraseMinCharClassesKey:   "min number of character classes of the wallet passphrase, from 1 to 4",
+	WithdrawalAddressCoolDownKey:  "seconds after which a whitelisted withdrawal address can be paid",
+	WithdrawalDailyLimitsKey:      "comma separated list of asset:amount max withdrawable in 24 hours",
+	MetricsListeningPortKey:       "port of the prometheus metrics listener, 0 means disabled",
+	LogFormatKey:                  "format of the log lines, either text or json",
+	LogFileMaxSizeKey:             "size in MB after which the log file is rotated, 0 means no log file",
+	LogFileMaxBackupsKey:          "max number of rotated log files retained, 0 means all",
+	LogFileMaxAgeKey:              "max number of days rotated log files are retained for, 0 means forever",
+}
 
 func init() {
-	vip = viper.New()
-	vip.SetEnvPrefix("TDEX")
-	vip.AutomaticEnv()
-
-	vip.SetDefault(TraderListeningPortKey, 9945)
-	vip.SetDefault(OperatorListeningPortKey, 9000)
-	vip.SetDefault(ExplorerEndpointKey, "http://127.0.0.1:3001")
-	vip.SetDefault(LogLevelKey, 5)
-	vip.SetDefault(DefaultFeeKey, 0.25)
-	vip.SetDefault(CrawlIntervalKey, 1000)              //TODO check this value
-	vip.SetDefault(FeeAccountBalanceThresholdKey, 1000) //TODO check this value
-	vip.SetDefault(NetworkKey, network.Regtest.Name)
-	vip.SetDefault(BaseAssetKey, network.Regtest.AssetID)
-	vip.SetDefault(TradeExpiryTimeKey, 120)
-	vip.SetDefault(DataDirPathKey, defaultDataDir)
-	vip.SetDefault(PriceSlippageKey, 0.05)
-	vip.SetDefault(UnspentTtlKey, 120)
-
-	validate()
+	v, err := newViper(nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	vip = v
 
 	if err := initDataDir(); err != nil {
 		log.WithError(err).Panic("error while init data dir")
 	}
-	vip.Set(MnemonicKey, "")
+}
+
+// Load parses the given command line arguments and loads again the
+// configuration, so that flags take precedence over any other source
+func Load(args []string) error {
+	flagSet := pflag.NewFlagSet("tdexd", pflag.ContinueOnError)
+	for key, usage := range usages {
+		flagSet.String(flagName(key), "", usage)
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	v, err := newViper(flagSet)
+	if err != nil {
+		return err
+	}
+
+	vipLock.Lock()
+	vip = v
+	flags = flagSet
+	vipLock.Unlock()
+
+	return initDataDir()
+}
+
+// Reload loads again the configuration and applies the changes of the
+// reloadable keys, returning those changed. Changes of any other key are
+// ignored until restart. The configuration is left untouched if not valid.
+func Reload() ([]string, error) {
+	vipLock.RLock()
+	flagSet := flags
+	vipLock.RUnlock()
+
+	v, err := newViper(flagSet)
+	if err != nil {
+		return nil, err
+	}
+
+	vipLock.Lock()
+	defer vipLock.Unlock()
+
+	changed := make([]string, 0)
+	for key := range usages {
+		if fmt.Sprint(v.Get(key)) == fmt.Sprint(vip.Get(key)) {
+			continue
+		}
+		if !isReloadable(key) {
+			log.Warnf("change of %s requires a restart to be applied", key)
+			continue
+		}
+		vip.Set(key, v.Get(key))
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// newViper returns the configuration of the daemon. The value of every key is
+// taken from, in order of precedence:
+//  1. the command line flag, ie. --trader-listening-port;
+//  2. the TDEX_ prefixed environment variable, ie. TDEX_TRADER_LISTENING_PORT;
+//  3. the tdexd.conf TOML file in the data dir, ie. trader_listening_port;
+//  4. the default value.
+//
+// The data dir itself can't be set in the config file since it contains it.
+func newViper(flagSet *pflag.FlagSet) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetEnvPrefix("TDEX")
+	v.AutomaticEnv()
+	setDefaults(v)
+
+	if flagSet != nil {
+		for key := range usages {
+			if err := v.BindPFlag(key, flagSet.Lookup(flagName(key))); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	configFile := filepath.Join(v.GetString(DataDirPathKey), ConfigFileName)
+	if _, err := os.Stat(configFile); err == nil {
+		v.SetConfigFile(configFile)
+		v.SetConfigType("toml")
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error while reading %s: %w", configFile, err)
+		}
+		if v.InConfig(strings.ToLower(DataDirPathKey)) {
+			return nil, fmt.Errorf(
+				"%s can't be set in the config file", strings.ToLower(DataDirPathKey),
+			)
+		}
+	}
+
+	if err := validate(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault(TraderListeningPortKey, 9945)
+	v.SetDefault(OperatorListeningPortKey, 9000)
+	v.SetDefault(ExplorerEndpointKey, "http://127.0.0.1:3001")
+	v.SetDefault(LogLevelKey, 5)
+	v.SetDefault(DefaultFeeKey, 0.25)
+	v.SetDefault(CrawlIntervalKey, 1000)              //TODO check this value
+	v.SetDefault(FeeAccountBalanceThresholdKey, 1000) //TODO check this value
+	v.SetDefault(NetworkKey, network.Regtest.Name)
+	v.SetDefault(BaseAssetKey, network.Regtest.AssetID)
+	v.SetDefault(TradeExpiryTimeKey, 120)
+	v.SetDefault(DataDirPathKey, defaultDataDir)
+	v.SetDefault(PriceSlippageKey, 0.05)
+	v.SetDefault(UnspentTtlKey, 120)
+	v.SetDefault(EnableMultiAssetSwapsKey, false)
+	v.SetDefault(QuoteExpiryTimeKey, 10)
+	v.SetDefault(TradeProposalsPerIPKey, 10)
+	v.SetDefault(TradeProposalsPerMarketKey, 60)
+	v.SetDefault(MaxPendingTradesPerMarketKey, 20)
+	v.SetDefault(BanListKey, "")
+	v.SetDefault(AutoLockTimeoutKey, 0)
+	v.SetDefault(SignerAddressKey, "")
+	v.SetDefault(SignerWithdrawalWhitelistKey, "")
+	v.SetDefault(SignerPriceBoundsKey, "")
+	v.SetDefault(SignerTLSCertKey, "")
+	v.SetDefault(SignerTLSKeyKey, "")
+	v.SetDefault(SignerTLSCAKey, "")
+	v.SetDefault(KDFKey, "scrypt")
+	v.SetDefault(ScryptNKey, 1048576)
+	v.SetDefault(Argon2TimeKey, 3)
+	v.SetDefault(Argon2MemoryKey, 64*1024)
+	v.SetDefault(Argon2ThreadsKey, 4)
+	v.SetDefault(PassphraseMinLengthKey, 12)
+	v.SetDefault(PassphraseMinCharClassesKey, 3)
+	v.SetDefault(WithdrawalAddressCoolDownKey, 24*60*60)
+	v.SetDefault(WithdrawalDailyLimitsKey, "")
+	v.SetDefault(MetricsListeningPortKey, 0)
+	v.SetDefault(LogFormatKey, "text")
+	v.SetDefault(LogFileMaxSizeKey, 100)
+	v.SetDefault(LogFileMaxBackupsKey, 5)
+	v.SetDefault(LogFileMaxAgeKey, 30)
+}
+
+// flagName returns the name of the command line flag of the given key, ie.
+// trader-listening-port for TRADER_LISTENING_PORT
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+func isReloadable(key string) bool {
+	for _, k := range ReloadableKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
 }
 
 func makeDirectoryIfNotExists(path string) error {
@@ -85,100 +368,77 @@ func makeDirectoryIfNotExists(path string) error {
 
 //GetString ...
 func GetString(key string) string {
+	vipLock.RLock()
+	defer vipLock.RUnlock()
 	return vip.GetString(key)
 }
 
 //GetInt ...
 func GetInt(key string) int {
+	vipLock.RLock()
+	defer vipLock.RUnlock()
 	return vip.GetInt(key)
 }
 
 //GetFloat ...
 func GetFloat(key string) float64 {
+	vipLock.RLock()
+	defer vipLock.RUnlock()
 	return vip.GetFloat64(key)
 }
 
 //GetDuration ...
 func GetDuration(key string) time.Duration {
+	vipLock.RLock()
+	defer vipLock.RUnlock()
 	return vip.GetDuration(key)
 }
 
 //GetBool ...
 func GetBool(key string) bool {
+	vipLock.RLock()
+	defer vipLock.RUnlock()
 	return vip.GetBool(key)
 }
 
 //GetNetwork ...
 func GetNetwork() *network.Network {
-	if vip.GetString(NetworkKey) == network.Regtest.Name {
-		return &network.Regtest
-	}
-	return &network.Liquid
+	return networkByName(GetString(NetworkKey))
 }
 
-// Set a value for the given key
-func Set(key string, value interface{}) {
-	vip.Set(key, value)
+// GetWithdrawalDailyLimits returns the max amount withdrawable in 24 hours
+// for every limited asset
+func GetWithdrawalDailyLimits() map[string]uint64 {
+	limits, _ := parseWithdrawalDailyLimits(GetString(WithdrawalDailyLimitsKey))
+	return limits
 }
 
-// GetMnemonic returns the current set mnemonic
-func GetMnemonic() []string {
-	var mnemonic []string
-	if vip.GetString(MnemonicKey) != "" {
-		mnemonic = strings.Split(vip.GetString(MnemonicKey), " ")
-	}
-
-	return mnemonic
-}
-
-// Validate method of config will panic
-func validate() {
-	if err := validateDefaultFee(vip.GetFloat64(DefaultFeeKey)); err != nil {
-		log.Fatalln(err)
-	}
-	if err := validateDefaultNetwork(vip.GetString(NetworkKey)); err != nil {
-		log.Fatalln(err)
-	}
-	path := vip.GetString(DataDirPathKey)
-	if path != defaultDataDir {
-		if err := validatePath(path); err != nil {
-			log.Fatalln(err)
-		}
-	}
+// PriceBounds are the min and max base price, that is the amount of quote
+// asset per unit of base asset, of the swaps of a market
+type PriceBounds struct {
+	Min decimal.Decimal
+	Max decimal.Decimal
 }
 
-func validateDefaultFee(fee float64) error {
-	if fee < 0.01 || fee > 99 {
-		return errors.New("percentage of the fee on each swap must be > 0.01 and < 99")
-	}
-
-	return nil
+// GetSignerPriceBounds returns the price bounds of the swaps allowed by
+// tdex-signer for every market, by quote asset
+func GetSignerPriceBounds() map[string]PriceBounds {
+	bounds, _ := parseSignerPriceBounds(GetString(SignerPriceBoundsKey))
+	return bounds
 }
 
-func validateDefaultNetwork(net string) error {
-	if net != network.Liquid.Name && net != network.Regtest.Name {
-		return fmt.Errorf(
-			"network must be either '%s' or '%s'",
-			network.Liquid.Name,
-			network.Regtest.Name,
-		)
-	}
-	return nil
+// Set a value for the given key
+func Set(key string, value interface{}) {
+	vipLock.Lock()
+	defer vipLock.Unlock()
+	vip.Set(key, value)
 }
 
-func validatePath(path string) error {
-	if path != "" {
-		stat, err := os.Stat(path)
-		if err != nil {
-			return err
-		}
-
-		if !stat.IsDir() {
-			return errors.New("not a directory")
-		}
+func networkByName(name string) *network.Network {
+	if name == network.Regtest.Name {
+		return &network.Regtest
 	}
-
-	return nil
+	return &network.Liquid
 }
 
 func initDataDir() error {
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulpemventures/go-elements/network"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	dataDir := newTestDataDir(t)
+	defer cleanup(dataDir)
+
+	writeConfigFile(t, dataDir, `
+crawl_interval = 2000
+price_slippage = 0.1
+trader_listening_port = 9999
+`)
+	os.Setenv("TDEX_TRADER_LISTENING_PORT", "9998")
+	defer os.Unsetenv("TDEX_TRADER_LISTENING_PORT")
+
+	err := Load([]string{
+		"--data-dir-path", dataDir,
+		"--price-slippage", "0.2",
+	})
+	require.NoError(t, err)
+
+	// flag > env > config file > default
+	assert.Equal(t, 0.2, GetFloat(PriceSlippageKey))
+	assert.Equal(t, 9998, GetInt(TraderListeningPortKey))
+	assert.Equal(t, 2000, GetInt(CrawlIntervalKey))
+	assert.Equal(t, 0.25, GetFloat(DefaultFeeKey))
+	assert.Equal(t, dataDir, GetString(DataDirPathKey))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dataDir := newTestDataDir(t)
+	defer cleanup(dataDir)
+
+	tests := []struct {
+		name       string
+		configFile string
+		args       []string
+	}{
+		{
+			name:       "data dir in config file",
+			configFile: `data_dir_path = "/tmp"`,
+		},
+		{
+			name:       "malformed config file",
+			configFile: `crawl_interval = `,
+		},
+		{
+			name:       "invalid value in config file",
+			configFile: `log_level = 10`,
+		},
+		{
+			name: "invalid flag value",
+			args: []string{"--price-slippage", "high"},
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfigFile(t, dataDir, tt.configFile)
+			args := append([]string{"--data-dir-path", dataDir}, tt.args...)
+			assert.Error(t, Load(args))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key   string
+		value interface{}
+	}{
+		{TraderListeningPortKey, 0},
+		{OperatorListeningPortKey, "port"},
+		{MetricsListeningPortKey, 9945},
+		{ExplorerEndpointKey, "127.0.0.1:3001"},
+		{LogLevelKey, 7},
+		{DefaultFeeKey, 100},
+		{NetworkKey, "testnet"},
+		{BaseAssetKey, "5ac9f6"},
+		{CrawlIntervalKey, 0},
+		{PriceSlippageKey, 1.5},
+		{TradeExpiryTimeKey, -1},
+		{EnableMultiAssetSwapsKey, "maybe"},
+		{BanListKey, "10.0.0.1,10.0.0.0/33"},
+		{SignerAddressKey, "localhost"},
+		{SignerAddressKey, "localhost:9999"},
+		{SignerWithdrawalWhitelistKey, "el1qq"},
+		{SignerPriceBoundsKey, "asset:1:2"},
+		{SignerPriceBoundsKey, strings.Repeat("ab", 32) + ":2:1"},
+		{KDFKey, "pbkdf2"},
+		{ScryptNKey, 1000},
+		{Argon2ThreadsKey, 256},
+		{PassphraseMinCharClassesKey, 5},
+		{WithdrawalDailyLimitsKey, "asset"},
+		{LogFormatKey, "xml"},
+		{LogFileMaxSizeKey, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			v, err := newViper(nil)
+			require.NoError(t, err)
+			require.NoError(t, validate(v))
+
+			v.Set(tt.key, tt.value)
+			assert.Error(t, validate(v))
+		})
+	}
+
+	v, err := newViper(nil)
+	require.NoError(t, err)
+	v.Set(NetworkKey, network.Liquid.Name)
+	v.Set(BaseAssetKey, network.Liquid.AssetID)
+	v.Set(BanListKey, "10.0.0.1, 10.0.0.0/24")
+	v.Set(SignerAddressKey, "localhost:9999")
+	v.Set(SignerTLSCertKey, "tls/cert.pem")
+	v.Set(SignerTLSKeyKey, "tls/key.pem")
+	v.Set(SignerTLSCAKey, "tls/ca.pem")
+	v.Set(SignerPriceBoundsKey, strings.Repeat("ab", 32)+":30000:50000")
+	assert.NoError(t, validate(v))
+}
+
+func TestReload(t *testing.T) {
+	dataDir := newTestDataDir(t)
+	defer cleanup(dataDir)
+
+	writeConfigFile(t, dataDir, `
+crawl_interval = 2000
+trader_listening_port = 9999
+`)
+	require.NoError(t, Load([]string{"--data-dir-path", dataDir}))
+
+	writeConfigFile(t, dataDir, `
+crawl_interval = 5000
+log_level = 4
+trader_listening_port = 9998
+`)
+	changed, err := Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{CrawlIntervalKey, LogLevelKey}, changed)
+	assert.Equal(t, 5000, GetInt(CrawlIntervalKey))
+	assert.Equal(t, 4, GetInt(LogLevelKey))
+	// not reloadable, applied only on restart
+	assert.Equal(t, 9999, GetInt(TraderListeningPortKey))
+
+	// an invalid config file leaves the configuration untouched
+	writeConfigFile(t, dataDir, `crawl_interval = 0`)
+	changed, err = Reload()
+	assert.Error(t, err)
+	assert.Nil(t, changed)
+	assert.Equal(t, 5000, GetInt(CrawlIntervalKey))
+}
+
+func newTestDataDir(t *testing.T) string {
+	dataDir, err := ioutil.TempDir("", "tdexd")
+	require.NoError(t, err)
+	return dataDir
+}
+
+func writeConfigFile(t *testing.T, dataDir, content string) {
+	err := ioutil.WriteFile(
+		filepath.Join(dataDir, ConfigFileName), []byte(content), 0600,
+	)
+	require.NoError(t, err)
+}
+
+// cleanup removes the given data dir and restores the configuration loaded
+// at init
+func cleanup(dataDir string) {
+	os.RemoveAll(dataDir)
+
+	v, _ := newViper(nil)
+	vipLock.Lock()
+	vip = v
+	flags = nil
+	vipLock.Unlock()
+}
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"github.com/vulpemventures/go-elements/address"
+	"github.com/vulpemventures/go-elements/network"
+)
+
+// validate makes sure that the value of every key of the given configuration
+// is valid
+func validate(v *viper.Viper) error {
+	intRanges := []struct {
+		key      string
+		min, max int
+	}{
+		{TraderListeningPortKey, 1, math.MaxUint16},
+		{OperatorListeningPortKey, 1, math.MaxUint16},
+		{MetricsListeningPortKey, 0, math.MaxUint16},
+		{LogLevelKey, 0, 6},
+		{CrawlIntervalKey, 1, math.MaxInt32},
+		{FeeAccountBalanceThresholdKey, 0, math.MaxInt32},
+		{TradeExpiryTimeKey, 1, math.MaxInt32},
+		{UnspentTtlKey, 1, math.MaxInt32},
+		{QuoteExpiryTimeKey, 1, math.MaxInt32},
+		{TradeProposalsPerIPKey, 0, math.MaxInt32},
+		{TradeProposalsPerMarketKey, 0, math.MaxInt32},
+		{MaxPendingTradesPerMarketKey, 0, math.MaxInt32},
+		{AutoLockTimeoutKey, 0, math.MaxInt32},
+		{ScryptNKey, 2, math.MaxInt32},
+		{Argon2TimeKey, 1, math.MaxInt32},
+		{Argon2MemoryKey, 1, math.MaxInt32},
+		{Argon2ThreadsKey, 1, math.MaxUint8},
+		{PassphraseMinLengthKey, 1, math.MaxInt32},
+		{PassphraseMinCharClassesKey, 1, 4},
+		{WithdrawalAddressCoolDownKey, 0, math.MaxInt32},
+		{LogFileMaxSizeKey, 0, math.MaxInt32},
+		{LogFileMaxBackupsKey, 0, math.MaxInt32},
+		{LogFileMaxAgeKey, 0, math.MaxInt32},
+	}
+	for _, r := range intRanges {
+		if err := validateIntRange(v, r.key, r.min, r.max); err != nil {
+			return err
+		}
+	}
+	if _, err := cast.ToBoolE(v.Get(EnableMultiAssetSwapsKey)); err != nil {
+		return fmt.Errorf("%s must be either true or false", EnableMultiAssetSwapsKey)
+	}
+
+	if err := validateListeningPorts(
+		v.GetInt(TraderListeningPortKey),
+		v.GetInt(OperatorListeningPortKey),
+		v.GetInt(MetricsListeningPortKey),
+	); err != nil {
+		return err
+	}
+	if err := validateExplorerEndpoint(v.GetString(ExplorerEndpointKey)); err != nil {
+		return err
+	}
+	fee, err := cast.ToFloat64E(v.Get(DefaultFeeKey))
+	if err != nil {
+		return fmt.Errorf("%s must be a number", DefaultFeeKey)
+	}
+	if err := validateDefaultFee(fee); err != nil {
+		return err
+	}
+	slippage, err := cast.ToFloat64E(v.Get(PriceSlippageKey))
+	if err != nil {
+		return fmt.Errorf("%s must be a number", PriceSlippageKey)
+	}
+	if err := validatePriceSlippage(slippage); err != nil {
+		return err
+	}
+	if err := validateDefaultNetwork(v.GetString(NetworkKey)); err != nil {
+		return err
+	}
+	if err := validateBaseAsset(v.GetString(BaseAssetKey)); err != nil {
+		return err
+	}
+	if err := validateBanList(v.GetString(BanListKey)); err != nil {
+		return err
+	}
+	if err := validateSignerAddress(v.GetString(SignerAddressKey)); err != nil {
+		return err
+	}
+	if err := validateSignerTLS(
+		v.GetString(SignerAddressKey),
+		v.GetString(SignerTLSCertKey),
+		v.GetString(SignerTLSKeyKey),
+		v.GetString(SignerTLSCAKey),
+	); err != nil {
+		return err
+	}
+	if _, err := parseSignerPriceBounds(
+		v.GetString(SignerPriceBoundsKey),
+	); err != nil {
+		return err
+	}
+	if err := validateWithdrawalWhitelist(
+		v.GetString(SignerWithdrawalWhitelistKey),
+		networkByName(v.GetString(NetworkKey)),
+	); err != nil {
+		return err
+	}
+	if err := validateKDF(v.GetString(KDFKey)); err != nil {
+		return err
+	}
+	if err := validateScryptN(v.GetInt(ScryptNKey)); err != nil {
+		return err
+	}
+	if err := validateLogFormat(v.GetString(LogFormatKey)); err != nil {
+		return err
+	}
+	if _, err := parseWithdrawalDailyLimits(
+		v.GetString(WithdrawalDailyLimitsKey),
+	); err != nil {
+		return err
+	}
+	path := v.GetString(DataDirPathKey)
+	if path != defaultDataDir {
+		if err := validatePath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateIntRange(v *viper.Viper, key string, min, max int) error {
+	n, err := cast.ToIntE(v.Get(key))
+	if err != nil || n < min || n > max {
+		return fmt.Errorf("%s must be an integer between %d and %d", key, min, max)
+	}
+	return nil
+}
+
+func validateListeningPorts(traderPort, operatorPort, metricsPort int) error {
+	if traderPort == operatorPort {
+		return errors.New("trader and operator interfaces must listen on different ports")
+	}
+	if metricsPort == traderPort || metricsPort == operatorPort {
+		return errors.New("metrics must be served on a port not used by other interfaces")
+	}
+	return nil
+}
+
+func validateExplorerEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("explorer endpoint '%s' must be a valid http(s) url", endpoint)
+	}
+	return nil
+}
+
+func validatePriceSlippage(slippage float64) error {
+	if slippage < 0 || slippage >= 1 {
+		return errors.New("price slippage must be >= 0 and < 1")
+	}
+	return nil
+}
+
+func validateBaseAsset(asset string) error {
+	if buf, err := hex.DecodeString(asset); err != nil || len(buf) != 32 {
+		return fmt.Errorf("base asset '%s' must be a 32-byte hex string", asset)
+	}
+	return nil
+}
+
+func validateBanList(banList string) error {
+	for _, entry := range strings.Split(banList, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) <= 0 {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("ban list entry '%s' is not a valid CIDR block", entry)
+			}
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			return fmt.Errorf("ban list entry '%s' is not a valid IP address", entry)
+		}
+	}
+	return nil
+}
+
+func validateSignerAddress(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("signer address '%s' must be in the form host:port", addr)
+	}
+	return nil
+}
+
+// validateSignerTLS makes sure that, if the signer address is set, the
+// connection to the signer is mutually authenticated
+func validateSignerTLS(addr, cert, key, ca string) error {
+	if addr == "" {
+		return nil
+	}
+	if cert == "" || key == "" || ca == "" {
+		return fmt.Errorf(
+			"%s, %s and %s must be set along with %s",
+			SignerTLSCertKey, SignerTLSKeyKey, SignerTLSCAKey, SignerAddressKey,
+		)
+	}
+	return nil
+}
+
+func validateWithdrawalWhitelist(whitelist string, params *network.Network) error {
+	for _, addr := range strings.Split(whitelist, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, err := address.ToOutputScript(addr, *params); err != nil {
+			return fmt.Errorf("invalid whitelisted address '%s': %w", addr, err)
+		}
+	}
+	return nil
+}
+
+func validateScryptN(n int) error {
+	if n&(n-1) != 0 {
+		return errors.New("scrypt cost must be a power of 2")
+	}
+	return nil
+}
+
+func validateDefaultFee(fee float64) error {
+	if fee < 0.01 || fee > 99 {
+		return errors.New("percentage of the fee on each swap must be > 0.01 and < 99")
+	}
+
+	return nil
+}
+
+func validateDefaultNetwork(net string) error {
+	if net != network.Liquid.Name && net != network.Regtest.Name {
+		return fmt.Errorf(
+			"network must be either '%s' or '%s'",
+			network.Liquid.Name,
+			network.Regtest.Name,
+		)
+	}
+	return nil
+}
+
+func validateKDF(kdf string) error {
+	if kdf != "scrypt" && kdf != "argon2id" {
+		return errors.New("key derivation function must be either 'scrypt' or 'argon2id'")
+	}
+	return nil
+}
+
+func validateLogFormat(format string) error {
+	if format != "text" && format != "json" {
+		return errors.New("log format must be either 'text' or 'json'")
+	}
+	return nil
+}
+
+func parseWithdrawalDailyLimits(str string) (map[string]uint64, error) {
+	limits := map[string]uint64{}
+	if str == "" {
+		return limits, nil
+	}
+
+	for _, pair := range strings.Split(str, ",") {
+		assetAndAmount := strings.Split(strings.TrimSpace(pair), ":")
+		if len(assetAndAmount) != 2 {
+			return nil, fmt.Errorf(
+				"withdrawal daily limit '%s' must be in the form asset:amount", pair,
+			)
+		}
+		amount, err := strconv.ParseUint(assetAndAmount[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"withdrawal daily limit amount '%s' must be a number of satoshis",
+				assetAndAmount[1],
+			)
+		}
+		limits[assetAndAmount[0]] = amount
+	}
+	return limits, nil
+}
+
+func parseSignerPriceBounds(str string) (map[string]PriceBounds, error) {
+	bounds := map[string]PriceBounds{}
+	if str == "" {
+		return bounds, nil
+	}
+
+	for _, entry := range strings.Split(str, ",") {
+		assetAndBounds := strings.Split(strings.TrimSpace(entry), ":")
+		if len(assetAndBounds) != 3 {
+			return nil, fmt.Errorf(
+				"signer price bounds '%s' must be in the form quote_asset:min:max",
+				entry,
+			)
+		}
+		if err := validateBaseAsset(assetAndBounds[0]); err != nil {
+			return nil, fmt.Errorf(
+				"signer price bounds asset '%s' is not valid", assetAndBounds[0],
+			)
+		}
+		min, err := decimal.NewFromString(assetAndBounds[1])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"signer min price '%s' must be a number", assetAndBounds[1],
+			)
+		}
+		max, err := decimal.NewFromString(assetAndBounds[2])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"signer max price '%s' must be a number", assetAndBounds[2],
+			)
+		}
+		if !min.IsPositive() || max.LessThan(min) {
+			return nil, fmt.Errorf(
+				"signer price bounds '%s' must have 0 < min <= max", entry,
+			)
+		}
+		bounds[assetAndBounds[0]] = PriceBounds{min, max}
+	}
+	return bounds, nil
+}
+
+func validatePath(path string) error {
+	if path != "" {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if !stat.IsDir() {
+			return errors.New("not a directory")
+		}
+	}
+
+	return nil
+}
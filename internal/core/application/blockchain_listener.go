@@ -2,13 +2,16 @@ package application
 
 import (
 	"context"
+	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
 	"github.com/tdex-network/tdex-daemon/pkg/crawler"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/vulpemventures/go-elements/transaction"
 )
 
 const readOnlyTx = true
@@ -18,13 +21,21 @@ type BlockchainListener interface {
 	StopObserveBlockchain()
 }
 
+// DoubleSpendHandler is called by the blockchain listener once it's been
+// confirmed that the transaction of a completed trade has been double-spent
+// by the one with the given hash and the trade has been set failed
+type DoubleSpendHandler func(trade domain.Trade, spendingTxID string)
+
 type blockchainListener struct {
 	unspentRepository domain.UnspentRepository
 	marketRepository  domain.MarketRepository
 	vaultRepository   domain.VaultRepository
+	tradeRepository   domain.TradeRepository
+	depositRepository domain.DepositRepository
 	crawlerSvc        crawler.Service
 	explorerSvc       explorer.Service
 	dbManager         ports.DbManager
+	onDoubleSpend     DoubleSpendHandler
 	feeDepositLogged  bool
 }
 
@@ -32,17 +43,23 @@ func NewBlockchainListener(
 	unspentRepository domain.UnspentRepository,
 	marketRepository domain.MarketRepository,
 	vaultRepository domain.VaultRepository,
+	tradeRepository domain.TradeRepository,
+	depositRepository domain.DepositRepository,
 	crawlerSvc crawler.Service,
 	explorerSvc explorer.Service,
 	dbManager ports.DbManager,
+	onDoubleSpend DoubleSpendHandler,
 ) BlockchainListener {
 	return newBlockchainListener(
 		unspentRepository,
 		marketRepository,
 		vaultRepository,
+		tradeRepository,
+		depositRepository,
 		crawlerSvc,
 		explorerSvc,
 		dbManager,
+		onDoubleSpend,
 	)
 }
 
@@ -50,17 +67,23 @@ func newBlockchainListener(
 	unspentRepository domain.UnspentRepository,
 	marketRepository domain.MarketRepository,
 	vaultRepository domain.VaultRepository,
+	tradeRepository domain.TradeRepository,
+	depositRepository domain.DepositRepository,
 	crawlerSvc crawler.Service,
 	explorerSvc explorer.Service,
 	dbManager ports.DbManager,
+	onDoubleSpend DoubleSpendHandler,
 ) *blockchainListener {
 	return &blockchainListener{
 		unspentRepository: unspentRepository,
 		marketRepository:  marketRepository,
 		vaultRepository:   vaultRepository,
+		tradeRepository:   tradeRepository,
+		depositRepository: depositRepository,
 		crawlerSvc:        crawlerSvc,
 		explorerSvc:       explorerSvc,
 		dbManager:         dbManager,
+		onDoubleSpend:     onDoubleSpend,
 	}
 }
 
@@ -75,47 +98,246 @@ func (b *blockchainListener) StopObserveBlockchain() {
 
 func (b *blockchainListener) handleBlockChainEvents() {
 	for event := range b.crawlerSvc.GetEventChannel() {
-		e := event.(crawler.AddressEvent)
-		unspents := unspentsFromEvent(e)
-		ctx := context.Background()
+		switch e := event.(type) {
+		case crawler.AddressEvent:
+			b.handleAddressEvent(e)
+		case crawler.TransactionEvent:
+			b.handleTransactionEvent(e)
+		}
+	}
+}
+
+func (b *blockchainListener) handleAddressEvent(event crawler.AddressEvent) {
+	unspents := unspentsFromEvent(event)
+	ctx := context.Background()
 
-		if _, err := b.dbManager.RunUnspentsTransaction(
+	res, err := b.dbManager.RunUnspentsTransaction(
+		ctx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			added, confirmed, err := b.updateUnspentsForAddress(
+				ctx, unspents, event.Address,
+			)
+			if err != nil {
+				return nil, err
+			}
+			return [][]domain.Unspent{added, confirmed}, nil
+		},
+	)
+	if err != nil {
+		log.Warnf("trying to update unspents for address %s: %s\n", event.Address, err.Error())
+		return
+	}
+
+	updated := res.([][]domain.Unspent)
+	addedUnspents, confirmedUnspents := updated[0], updated[1]
+	if len(addedUnspents) > 0 || len(confirmedUnspents) > 0 {
+		if _, err := b.dbManager.RunTransaction(
 			ctx,
 			!readOnlyTx,
 			func(ctx context.Context) (interface{}, error) {
-				return nil, b.updateUnspentsForAddress(ctx, unspents, e.Address)
+				return nil, b.registerDeposits(
+					ctx, event, addedUnspents, confirmedUnspents,
+				)
 			},
 		); err != nil {
-			log.Warnf("trying to update unspents for address %s: %s\n", e.Address, err.Error())
-			break
+			log.Warnf("trying to register deposits for address %s: %s\n", event.Address, err.Error())
 		}
+	}
 
-		switch event.Type() {
-		case crawler.FeeAccountDeposit:
-			if _, err := b.dbManager.RunTransaction(
-				ctx,
-				readOnlyTx,
-				func(ctx context.Context) (interface{}, error) {
-					return nil, b.checkFeeAccountBalance(ctx, event)
-				},
-			); err != nil {
-				log.Warnf("trying to check balance for fee account: %s\n", err.Error())
-				break
-			}
+	switch event.Type() {
+	case crawler.FeeAccountDeposit:
+		if _, err := b.dbManager.RunTransaction(
+			ctx,
+			readOnlyTx,
+			func(ctx context.Context) (interface{}, error) {
+				return nil, b.checkFeeAccountBalance(ctx, event)
+			},
+		); err != nil {
+			log.Warnf("trying to check balance for fee account: %s\n", err.Error())
+		}
+
+	case crawler.MarketAccountDeposit:
+		if _, err := b.dbManager.RunTransaction(
+			ctx,
+			!readOnlyTx,
+			func(ctx context.Context) (interface{}, error) {
+				return nil, b.checkMarketAccountFundings(ctx, event.AccountIndex)
+			},
+		); err != nil {
+			log.Warnf("trying to check fundings for market account %d: %s\n", event.AccountIndex, err.Error())
+		}
+	}
+}
+
+// handleTransactionEvent settles the trade whose transaction has been
+// confirmed and updates the related unspents. The transaction stops being
+// observed once everything has been updated.
+func (b *blockchainListener) handleTransactionEvent(event crawler.TransactionEvent) {
+	switch event.Type() {
+	case crawler.TransactionConfirmed:
+		b.handleTransactionConfirmed(event)
+	case crawler.TransactionNotFound:
+		b.handleTransactionNotFound(event)
+	}
+}
+
+func (b *blockchainListener) handleTransactionConfirmed(event crawler.TransactionEvent) {
+	ctx := context.Background()
+
+	res, err := b.dbManager.RunTransaction(
+		ctx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return b.settleTrade(ctx, event)
+		},
+	)
+	if err != nil {
+		log.Warnf("trying to settle trade with txid %s: %s\n", event.TxID, err.Error())
+		return
+	}
+	trade := res.(*domain.Trade)
+
+	if _, err := b.dbManager.RunUnspentsTransaction(
+		ctx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return nil, b.updateUnspentsForTrade(ctx, trade)
+		},
+	); err != nil {
+		tradeLogger(ctx, trade.ID).WithError(err).Warnf(
+			"trying to update unspents for trade with txid %s", event.TxID,
+		)
+		return
+	}
+
+	b.crawlerSvc.RemoveObservable(&crawler.TransactionObservable{TxID: event.TxID})
+	tradeLogger(ctx, trade.ID).Info("trade settled")
+}
+
+// handleTransactionNotFound takes care of a completed trade whose transaction
+// is not known by the explorer anymore. If it has just been dropped from the
+// mempool, it's published again. Otherwise, if it can't be broadcasted and
+// any of its inputs turns out to be spent by another transaction, it's been
+// double-spent: the trade is set failed, the unspents it spent are restored,
+// those it would have created are marked as spent and the double spend
+// handler is notified.
+func (b *blockchainListener) handleTransactionNotFound(event crawler.TransactionEvent) {
+	ctx := context.Background()
+
+	trade, err := b.tradeRepository.GetTradeByTxID(ctx, event.TxID)
+	if err != nil {
+		log.Warnf("trying to get trade with txid %s: %s\n", event.TxID, err.Error())
+		return
+	}
+	if !trade.IsCompleted() || trade.IsSettled() {
+		b.crawlerSvc.RemoveObservable(&crawler.TransactionObservable{TxID: event.TxID})
+		return
+	}
+
+	_, broadcastErr := b.explorerSvc.BroadcastTransaction(trade.TxHex)
+	if broadcastErr == nil {
+		tradeLogger(ctx, trade.ID).Warn("trade transaction has been published again")
+		return
+	}
+	// make sure the failure isn't due to the explorer being unreachable
+	if _, err := b.explorerSvc.GetTransactionHex(event.TxID); err != explorer.ErrTransactionNotFound {
+		tradeLogger(ctx, trade.ID).WithError(broadcastErr).Warn(
+			"trying to publish again trade transaction",
+		)
+		return
+	}
+	// a transaction can be rejected for other reasons than a double spend, like
+	// a fee too low for the current mempool, therefore the trade is set failed
+	// only if it's confirmed that its inputs have been spent by another one.
+	spendingTxID, err := b.findDoubleSpendingTx(trade)
+	if err != nil {
+		tradeLogger(ctx, trade.ID).WithError(err).Warn(
+			"trying to check whether trade transaction has been double-spent",
+		)
+		return
+	}
+	if spendingTxID == "" {
+		tradeLogger(ctx, trade.ID).WithError(broadcastErr).Warn(
+			"trade transaction can't be published again but its inputs are " +
+				"still unspent",
+		)
+		return
+	}
+
+	// the unspents are restored before setting the trade failed: if restoring
+	// them fails, the trade is still completed and the whole process, including
+	// notifying the double spend, is retried at the next event. If setting the
+	// trade failed fails instead, restoring the unspents again is harmless.
+	if _, err := b.dbManager.RunUnspentsTransaction(
+		ctx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return nil, b.restoreUnspentsForTrade(ctx, trade)
+		},
+	); err != nil {
+		tradeLogger(ctx, trade.ID).WithError(err).Warn(
+			"trying to restore unspents for trade",
+		)
+		return
+	}
 
-		case crawler.MarketAccountDeposit:
-			if _, err := b.dbManager.RunTransaction(
+	if _, err := b.dbManager.RunTransaction(
+		ctx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return nil, b.tradeRepository.UpdateTrade(
 				ctx,
-				!readOnlyTx,
-				func(ctx context.Context) (interface{}, error) {
-					return nil, b.checkMarketAccountFundings(ctx, e.AccountIndex)
+				&trade.ID,
+				func(t *domain.Trade) (*domain.Trade, error) {
+					reason := fmt.Sprintf("double-spent by tx %s", spendingTxID)
+					if err := t.FailAfterComplete(reason); err != nil {
+						return nil, err
+					}
+					return t, nil
 				},
-			); err != nil {
-				log.Warnf("trying to check fundings for market account %d: %s\n", e.AccountIndex, err.Error())
-				break
-			}
+			)
+		},
+	); err != nil {
+		tradeLogger(ctx, trade.ID).WithError(err).Warn("trying to set failed trade")
+		return
+	}
+
+	b.crawlerSvc.RemoveObservable(&crawler.TransactionObservable{TxID: event.TxID})
+	tradeLogger(ctx, trade.ID).Errorf(
+		"transaction %s of completed trade has been double-spent by %s. The "+
+			"trade has been set failed and the spent unspents have been restored",
+		event.TxID, spendingTxID,
+	)
+	if b.onDoubleSpend != nil {
+		b.onDoubleSpend(*trade, spendingTxID)
+	}
+}
+
+// findDoubleSpendingTx returns the hash of the transaction spending any of
+// the inputs of the trade transaction in its place, or an empty string if
+// none of them has been spent by another transaction
+func (b *blockchainListener) findDoubleSpendingTx(
+	trade *domain.Trade,
+) (string, error) {
+	inputKeys, _, err := unspentKeysForTrade(trade)
+	if err != nil {
+		return "", err
+	}
+
+	for _, key := range inputKeys {
+		spendingTxID, err := b.explorerSvc.GetSpendingTransactionID(
+			key.TxID,
+			key.VOut,
+		)
+		if err != nil {
+			return "", err
+		}
+		if spendingTxID != "" && spendingTxID != trade.TxID {
+			return spendingTxID, nil
 		}
 	}
+	return "", nil
 }
 
 func (b *blockchainListener) checkFeeAccountBalance(ctx context.Context, event crawler.Event) error {
@@ -230,17 +452,155 @@ func (b *blockchainListener) checkMarketAccountFundings(ctx context.Context, acc
 	return nil
 }
 
+// registerDeposits keeps track of those of the given new unspents that
+// have been received on an external address by a tx not related to any trade.
+// The timestamp of a deposit is the time of the block including its tx, and
+// it's set or updated when the tx gets confirmed.
+func (b *blockchainListener) registerDeposits(
+	ctx context.Context,
+	event crawler.AddressEvent,
+	addedUnspents, confirmedUnspents []domain.Unspent,
+) error {
+	externalAddresses, err := b.vaultRepository.
+		GetAllDerivedExternalAddressesForAccount(ctx, event.AccountIndex)
+	if err != nil {
+		return err
+	}
+	if !containsAddress(externalAddresses, event.Address) {
+		return nil
+	}
+
+	blockTimes := make(map[domain.UnspentKey]uint64)
+	for _, utxo := range event.Utxos {
+		if utxo.IsConfirmed() {
+			key := domain.UnspentKey{TxID: utxo.Hash(), VOut: utxo.Index()}
+			blockTimes[key] = utxo.BlockTime()
+		}
+	}
+
+	deposits := make([]domain.Deposit, 0, len(addedUnspents))
+	for _, u := range addedUnspents {
+		if trade, _ := b.tradeRepository.GetTradeByTxID(ctx, u.TxID); trade != nil {
+			continue
+		}
+		deposits = append(deposits, domain.Deposit{
+			AccountIndex: event.AccountIndex,
+			TxID:         u.TxID,
+			VOut:         u.VOut,
+			Asset:        u.AssetHash,
+			Value:        u.Value,
+			Timestamp:    blockTimes[u.Key()],
+		})
+	}
+	if len(deposits) > 0 {
+		if _, err := b.depositRepository.AddDeposits(ctx, deposits); err != nil {
+			return err
+		}
+	}
+
+	// unspents not referring to deposits are ignored by the repository
+	for _, u := range confirmedUnspents {
+		blockTime, ok := blockTimes[u.Key()]
+		if !ok {
+			continue
+		}
+		if err := b.depositRepository.ConfirmDeposits(
+			ctx, []domain.UnspentKey{u.Key()}, blockTime,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settleTrade sets the blocktime of the trade identified by the txid of the
+// given event and marks it as settled, if not already
+func (b *blockchainListener) settleTrade(
+	ctx context.Context,
+	event crawler.TransactionEvent,
+) (*domain.Trade, error) {
+	trade, err := b.tradeRepository.GetTradeByTxID(ctx, event.TxID)
+	if err != nil {
+		return nil, err
+	}
+	if trade.IsSettled() {
+		return trade, nil
+	}
+
+	if err := b.tradeRepository.UpdateTrade(
+		ctx,
+		&trade.ID,
+		func(t *domain.Trade) (*domain.Trade, error) {
+			if err := t.Settle(uint64(event.BlockTime)); err != nil {
+				return nil, err
+			}
+			return t, nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return b.tradeRepository.GetTradeByTxID(ctx, event.TxID)
+}
+
+// restoreUnspentsForTrade marks as unspent the unspents used as inputs of the
+// double-spent trade transaction and as spent those that would have been
+// received with its outputs
+func (b *blockchainListener) restoreUnspentsForTrade(
+	ctx context.Context,
+	trade *domain.Trade,
+) error {
+	inputKeys, outputKeys, err := unspentKeysForTrade(trade)
+	if err != nil {
+		return err
+	}
+
+	if err := b.unspentRepository.UnspendUnspents(ctx, inputKeys); err != nil {
+		return err
+	}
+	return b.unspentRepository.SpendUnspents(ctx, outputKeys)
+}
+
+// updateUnspentsForTrade marks as spent the unspents used as inputs of the
+// trade transaction and as confirmed those received with its outputs.
+// Keys of unspents not owned by the daemon are just ignored.
+func (b *blockchainListener) updateUnspentsForTrade(
+	ctx context.Context,
+	trade *domain.Trade,
+) error {
+	unspentsToMarkAsSpent, unspentsToMarkAsConfirmed, err :=
+		unspentKeysForTrade(trade)
+	if err != nil {
+		return err
+	}
+
+	if err := b.unspentRepository.SpendUnspents(
+		ctx,
+		unspentsToMarkAsSpent,
+	); err != nil {
+		return err
+	}
+	return b.unspentRepository.ConfirmUnspents(ctx, unspentsToMarkAsConfirmed)
+}
+
+// updateUnspentsForAddress syncs the unspents stored for the given address
+// with those found in the blockchain and returns the newly added ones and
+// those that have just been confirmed or moved to another block.
+// Since the crawled unspents always reflect the current best chain, the stored
+// ones are re-validated at every crawl: those whose block has been reorganized
+// out of the chain are downgraded to unconfirmed or moved to the new block,
+// while those whose spending tx has been reverted are marked unspent again.
 func (b *blockchainListener) updateUnspentsForAddress(
 	ctx context.Context,
 	unspents []domain.Unspent,
 	address string,
-) error {
+) (added, confirmed []domain.Unspent, err error) {
 	existingUnspents, err := b.unspentRepository.GetAllUnspentsForAddresses(
 		ctx,
 		[]string{address},
 	)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// check for unspents to add to the storage
@@ -253,33 +613,101 @@ func (b *blockchainListener) updateUnspentsForAddress(
 
 	//update spent
 	unspentsToMarkAsSpent := make([]domain.UnspentKey, 0)
-	unspentsToMarkAsConfirmed := make([]domain.UnspentKey, 0)
+	unspentsToMarkAsUnspent := make([]domain.UnspentKey, 0)
+	unspentsToMarkAsUnconfirmed := make([]domain.UnspentKey, 0)
+	unspentsToMarkAsConfirmed := make([]domain.Unspent, 0)
 	for _, existingUnspent := range existingUnspents {
-		if index := findUnspent(unspents, existingUnspent); index < 0 {
-			unspentsToMarkAsSpent = append(unspentsToMarkAsSpent, existingUnspent.Key())
-		} else {
-			if existingUnspent.IsConfirmed() != unspents[index].IsConfirmed() {
-				unspentsToMarkAsConfirmed = append(unspentsToMarkAsConfirmed, existingUnspent.Key())
+		index := findUnspent(unspents, existingUnspent)
+		if index < 0 {
+			if !existingUnspent.IsSpent() {
+				unspentsToMarkAsSpent = append(unspentsToMarkAsSpent, existingUnspent.Key())
+			}
+			continue
+		}
+
+		unspent := unspents[index]
+		if existingUnspent.IsSpent() {
+			unspentsToMarkAsUnspent = append(unspentsToMarkAsUnspent, existingUnspent.Key())
+		}
+		if !unspent.IsConfirmed() {
+			if existingUnspent.IsConfirmed() {
+				unspentsToMarkAsUnconfirmed = append(unspentsToMarkAsUnconfirmed, existingUnspent.Key())
 			}
+			continue
+		}
+		if !existingUnspent.IsConfirmed() ||
+			(unspent.BlockHash != "" && unspent.BlockHash != existingUnspent.BlockHash) {
+			unspentsToMarkAsConfirmed = append(unspentsToMarkAsConfirmed, unspent)
 		}
 	}
 
 	if len(unspentsToAdd) > 0 {
 		if err := b.unspentRepository.AddUnspents(ctx, unspentsToAdd); err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 	if len(unspentsToMarkAsSpent) > 0 {
 		if err := b.unspentRepository.SpendUnspents(ctx, unspentsToMarkAsSpent); err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
-	if len(unspentsToMarkAsConfirmed) > 0 {
-		if err := b.unspentRepository.ConfirmUnspents(ctx, unspentsToMarkAsConfirmed); err != nil {
-			return err
+	if len(unspentsToMarkAsUnspent) > 0 {
+		if err := b.unspentRepository.UnspendUnspents(ctx, unspentsToMarkAsUnspent); err != nil {
+			return nil, nil, err
 		}
 	}
-	return nil
+	if len(unspentsToMarkAsUnconfirmed) > 0 {
+		if err := b.unspentRepository.UnconfirmUnspents(ctx, unspentsToMarkAsUnconfirmed); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, u := range unspentsToMarkAsConfirmed {
+		if err := b.unspentRepository.ConfirmUnspentsInBlock(
+			ctx,
+			[]domain.UnspentKey{u.Key()},
+			u.BlockHeight,
+			u.BlockHash,
+		); err != nil {
+			return nil, nil, err
+		}
+	}
+	return unspentsToAdd, unspentsToMarkAsConfirmed, nil
+}
+
+// unspentKeysForTrade returns the keys of the unspents spent and of those
+// created by the trade transaction
+func unspentKeysForTrade(
+	trade *domain.Trade,
+) (inputKeys, outputKeys []domain.UnspentKey, err error) {
+	tx, err := transaction.NewTxFromHex(trade.TxHex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputKeys = make([]domain.UnspentKey, 0, len(tx.Inputs))
+	for _, in := range tx.Inputs {
+		inputKeys = append(inputKeys, domain.UnspentKey{
+			TxID: bufferutil.TxIDFromBytes(in.Hash),
+			VOut: in.Index,
+		})
+	}
+	outputKeys = make([]domain.UnspentKey, 0, len(tx.Outputs))
+	for i := range tx.Outputs {
+		outputKeys = append(outputKeys, domain.UnspentKey{
+			TxID: trade.TxID,
+			VOut: uint32(i),
+		})
+	}
+	return inputKeys, outputKeys, nil
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, addr := range addresses {
+		if addr == address {
+			return true
+		}
+	}
+	return false
 }
 
 func unspentsFromEvent(event crawler.AddressEvent) []domain.Unspent {
@@ -297,6 +725,8 @@ func unspentsFromEvent(event crawler.AddressEvent) []domain.Unspent {
 			RangeProof:      utxo.RangeProof(),
 			SurjectionProof: utxo.SurjectionProof(),
 			Confirmed:       utxo.IsConfirmed(),
+			BlockHeight:     utxo.BlockHeight(),
+			BlockHash:       utxo.BlockHash(),
 			Address:         event.Address,
 		}
 		unspents = append(unspents, u)
@@ -2,11 +2,17 @@ package application
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/tdex-network/tdex-daemon/internal/infrastructure/storage/db/inmemory"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	"github.com/tdex-network/tdex-daemon/pkg/crawler"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/vulpemventures/go-elements/transaction"
 )
 
 func TestUpdateUnspentsForAddress(t *testing.T) {
@@ -20,7 +26,10 @@ func TestUpdateUnspentsForAddress(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		dbManager)
+		nil,
+		nil,
+		dbManager,
+		nil)
 
 	unspents := []domain.Unspent{
 		{
@@ -49,7 +58,7 @@ func TestUpdateUnspentsForAddress(t *testing.T) {
 		},
 	}
 
-	err := l.updateUnspentsForAddress(
+	addedUnspents, _, err := l.updateUnspentsForAddress(
 		ctx,
 		unspents,
 		"a",
@@ -57,6 +66,7 @@ func TestUpdateUnspentsForAddress(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, 2, len(addedUnspents))
 
 	unsp, err := unspentRepository.GetAllUnspentsForAddresses(
 		ctx,
@@ -95,7 +105,7 @@ func TestUpdateUnspentsForAddress(t *testing.T) {
 		},
 	}
 
-	err = l.updateUnspentsForAddress(
+	addedUnspents, _, err = l.updateUnspentsForAddress(
 		ctx,
 		unspents,
 		"a",
@@ -103,6 +113,7 @@ func TestUpdateUnspentsForAddress(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, 1, len(addedUnspents))
 
 	unsp, err = unspentRepository.GetAllUnspentsForAddresses(
 		ctx,
@@ -123,3 +134,447 @@ func TestUpdateUnspentsForAddress(t *testing.T) {
 
 	assert.Equal(t, 1, count)
 }
+
+func TestUpdateUnspentsForAddressWithReorg(t *testing.T) {
+	dbManager := newTestDb()
+	unspentRepository := inmemory.NewUnspentRepositoryImpl(dbManager)
+	ctx := context.Background()
+	explorerSvc := &mockExplorer{}
+
+	l := newBlockchainListener(
+		unspentRepository,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		explorerSvc,
+		dbManager,
+		nil)
+
+	crawl := func(utxos ...explorer.Utxo) map[string]*domain.Unspent {
+		explorerSvc.utxos = utxos
+		crawledUtxos, err := explorerSvc.GetUnspents("a", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := crawler.AddressEvent{Address: "a", Utxos: crawledUtxos}
+		if _, _, err := l.updateUnspentsForAddress(
+			ctx,
+			unspentsFromEvent(event),
+			"a",
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		unspents, err := unspentRepository.GetAllUnspentsForAddresses(
+			ctx,
+			[]string{"a"},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unspentsByTxID := make(map[string]*domain.Unspent)
+		for i := range unspents {
+			unspentsByTxID[unspents[i].TxID] = &unspents[i]
+		}
+		return unspentsByTxID
+	}
+	newUtxo := func(txID string, blockHeight uint64, blockHash string) explorer.Utxo {
+		return explorer.NewWitnessUtxo(
+			txID, 0, 100, baseAsset, "", "", nil, nil, nil, nil,
+			len(blockHash) > 0, blockHeight, blockHash,
+		)
+	}
+
+	// both unspents are confirmed in block A
+	unspents := crawl(newUtxo("1", 10, "A"), newUtxo("2", 10, "A"))
+	assert.Equal(t, 2, len(unspents))
+	for _, u := range unspents {
+		assert.True(t, u.IsConfirmed())
+		assert.Equal(t, uint64(10), u.BlockHeight)
+		assert.Equal(t, "A", u.BlockHash)
+	}
+
+	// block A is reorganized out: the first unspent is back in mempool, while
+	// the second one gets included in the competing block B
+	unspents = crawl(newUtxo("1", 0, ""), newUtxo("2", 10, "B"))
+	assert.False(t, unspents["1"].IsConfirmed())
+	assert.Equal(t, uint64(0), unspents["1"].BlockHeight)
+	assert.Equal(t, "", unspents["1"].BlockHash)
+	assert.True(t, unspents["2"].IsConfirmed())
+	assert.Equal(t, "B", unspents["2"].BlockHash)
+
+	// the first unspent gets confirmed again, the second one is spent
+	unspents = crawl(newUtxo("1", 11, "C"))
+	assert.True(t, unspents["1"].IsConfirmed())
+	assert.Equal(t, uint64(11), unspents["1"].BlockHeight)
+	assert.Equal(t, "C", unspents["1"].BlockHash)
+	assert.True(t, unspents["2"].IsSpent())
+
+	// the tx spending the second unspent is reorganized out
+	unspents = crawl(newUtxo("1", 11, "C"), newUtxo("2", 10, "B"))
+	assert.False(t, unspents["2"].IsSpent())
+	assert.True(t, unspents["2"].IsConfirmed())
+	assert.Equal(t, 2, len(unspents))
+}
+
+func TestHandleTransactionEvent(t *testing.T) {
+	dbManager := newTestDb()
+	unspentRepository := inmemory.NewUnspentRepositoryImpl(dbManager)
+	tradeRepository := inmemory.NewTradeRepositoryImpl(dbManager)
+	crawlerSvc := crawler.NewService(crawler.Opts{
+		Observables:            []crawler.Observable{},
+		ErrorHandler:           func(err error) {},
+		IntervalInMilliseconds: 100,
+	})
+	ctx := context.Background()
+
+	l := newBlockchainListener(
+		unspentRepository,
+		nil,
+		nil,
+		tradeRepository,
+		nil,
+		crawlerSvc,
+		nil,
+		dbManager,
+		nil)
+
+	tradeID, txID, prevoutTxID := addCompletedTradeForTest(
+		t,
+		tradeRepository,
+		unspentRepository,
+	)
+	crawlerSvc.AddObservable(&crawler.TransactionObservable{TxID: txID})
+
+	// unconfirmed events are ignored
+	l.handleTransactionEvent(crawler.TransactionEvent{
+		TxID:      txID,
+		EventType: crawler.TransactionUnConfirmed,
+	})
+	trade, err := tradeRepository.GetOrCreateTrade(ctx, &tradeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, trade.IsSettled())
+
+	l.handleTransactionEvent(crawler.TransactionEvent{
+		TxID:      txID,
+		EventType: crawler.TransactionConfirmed,
+		BlockHash: "blockhash",
+		BlockTime: 1600000000,
+	})
+
+	trade, err = tradeRepository.GetOrCreateTrade(ctx, &tradeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, trade.IsSettled())
+	assert.Equal(t, uint64(1600000000), trade.SwapCompleteTime())
+
+	spent, err := unspentRepository.GetUnspentForKey(
+		ctx,
+		domain.UnspentKey{TxID: prevoutTxID, VOut: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, spent.IsSpent())
+
+	received, err := unspentRepository.GetUnspentForKey(
+		ctx,
+		domain.UnspentKey{TxID: txID, VOut: 0},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, received.IsConfirmed())
+	assert.Equal(t, false, received.IsSpent())
+}
+
+func TestHandleTransactionNotFound(t *testing.T) {
+	tests := []struct {
+		name                        string
+		broadcastErr                error
+		spendingTxID                string
+		expectedFailedAfterComplete bool
+	}{
+		{"dropped transaction is published again", nil, "", false},
+		{
+			"rejected transaction with unspent inputs",
+			errors.New("min relay fee not met"),
+			"",
+			false,
+		},
+		{
+			"double-spent transaction",
+			errors.New("bad-txns-inputs-missingorspent"),
+			"doublespend",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbManager := newTestDb()
+			unspentRepository := inmemory.NewUnspentRepositoryImpl(dbManager)
+			tradeRepository := inmemory.NewTradeRepositoryImpl(dbManager)
+			crawlerSvc := crawler.NewService(crawler.Opts{
+				Observables:            []crawler.Observable{},
+				ErrorHandler:           func(err error) {},
+				IntervalInMilliseconds: 100,
+			})
+			explorerSvc := &mockExplorer{
+				broadcastErr: tt.broadcastErr,
+				spendingTxID: tt.spendingTxID,
+			}
+			ctx := context.Background()
+			doubleSpends := make([]string, 0)
+
+			l := newBlockchainListener(
+				unspentRepository,
+				nil,
+				nil,
+				tradeRepository,
+				nil,
+				crawlerSvc,
+				explorerSvc,
+				dbManager,
+				func(trade domain.Trade, spendingTxID string) {
+					doubleSpends = append(doubleSpends, spendingTxID)
+				})
+
+			tradeID, txID, prevoutTxID := addCompletedTradeForTest(
+				t,
+				tradeRepository,
+				unspentRepository,
+			)
+			inputKey := domain.UnspentKey{TxID: prevoutTxID, VOut: 1}
+			outputKey := domain.UnspentKey{TxID: txID, VOut: 0}
+			if err := unspentRepository.SpendUnspents(
+				ctx,
+				[]domain.UnspentKey{inputKey},
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			l.handleTransactionEvent(crawler.TransactionEvent{
+				TxID:      txID,
+				EventType: crawler.TransactionNotFound,
+			})
+
+			trade, err := tradeRepository.GetOrCreateTrade(ctx, &tradeID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedFailedAfterComplete, trade.IsFailedAfterComplete())
+			assert.Equal(t, !tt.expectedFailedAfterComplete, trade.IsCompleted())
+			assert.Equal(t, 1, explorerSvc.broadcastCount)
+			if tt.expectedFailedAfterComplete {
+				assert.Equal(t, []string{tt.spendingTxID}, doubleSpends)
+			} else {
+				assert.Equal(t, 0, len(doubleSpends))
+			}
+
+			input, err := unspentRepository.GetUnspentForKey(ctx, inputKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := unspentRepository.GetUnspentForKey(ctx, outputKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, !tt.expectedFailedAfterComplete, input.IsSpent())
+			assert.Equal(t, tt.expectedFailedAfterComplete, output.IsSpent())
+		})
+	}
+}
+
+func TestHandleTransactionNotFoundRetriesRestoringUnspents(t *testing.T) {
+	dbManager := newTestDb()
+	unspentRepository := &failingUnspentRepository{
+		UnspentRepository: inmemory.NewUnspentRepositoryImpl(dbManager),
+		failures:          1,
+	}
+	tradeRepository := inmemory.NewTradeRepositoryImpl(dbManager)
+	crawlerSvc := crawler.NewService(crawler.Opts{
+		Observables:            []crawler.Observable{},
+		ErrorHandler:           func(err error) {},
+		IntervalInMilliseconds: 100,
+	})
+	explorerSvc := &mockExplorer{
+		broadcastErr: errors.New("bad-txns-inputs-missingorspent"),
+		spendingTxID: "doublespend",
+	}
+	ctx := context.Background()
+	doubleSpends := make([]string, 0)
+
+	l := newBlockchainListener(
+		unspentRepository,
+		nil,
+		nil,
+		tradeRepository,
+		nil,
+		crawlerSvc,
+		explorerSvc,
+		dbManager,
+		func(trade domain.Trade, spendingTxID string) {
+			doubleSpends = append(doubleSpends, spendingTxID)
+		})
+
+	tradeID, txID, _ := addCompletedTradeForTest(
+		t,
+		tradeRepository,
+		unspentRepository,
+	)
+	event := crawler.TransactionEvent{
+		TxID:      txID,
+		EventType: crawler.TransactionNotFound,
+	}
+
+	// the trade is left completed if its unspents can't be restored...
+	l.handleTransactionEvent(event)
+	trade, err := tradeRepository.GetOrCreateTrade(ctx, &tradeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, trade.IsCompleted())
+	assert.Equal(t, 0, len(doubleSpends))
+
+	// ...so that the next event restores them and notifies the double spend
+	l.handleTransactionEvent(event)
+	trade, err = tradeRepository.GetOrCreateTrade(ctx, &tradeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, trade.IsFailedAfterComplete())
+	assert.Equal(t, []string{"doublespend"}, doubleSpends)
+}
+
+// failingUnspentRepository fails to unspend unspents the given number of
+// times before relying on the wrapped repository
+type failingUnspentRepository struct {
+	domain.UnspentRepository
+	failures int
+}
+
+func (r *failingUnspentRepository) UnspendUnspents(
+	ctx context.Context,
+	keys []domain.UnspentKey,
+) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("failed to unspend unspents")
+	}
+	return r.UnspentRepository.UnspendUnspents(ctx, keys)
+}
+
+// addCompletedTradeForTest adds to the repositories a completed trade, the
+// unspent spent by its tx and the one created by it.
+func addCompletedTradeForTest(
+	t *testing.T,
+	tradeRepository domain.TradeRepository,
+	unspentRepository domain.UnspentRepository,
+) (tradeID uuid.UUID, txID, prevoutTxID string) {
+	ctx := context.Background()
+
+	prevoutHash := make([]byte, 32)
+	prevoutHash[0] = 1
+	tx := transaction.NewTx(2)
+	tx.AddInput(transaction.NewTxInput(prevoutHash, 1))
+	tx.AddOutput(transaction.NewTxOutput(
+		append([]byte{0x01}, make([]byte, 32)...),
+		[]byte{0x01, 0, 0, 0, 0, 0, 0, 0x03, 0xe8},
+		[]byte{0x00, 0x14},
+	))
+	txHex, err := tx.ToHex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txID = tx.TxHash().String()
+	prevoutTxID = bufferutil.TxIDFromBytes(prevoutHash)
+
+	if err := unspentRepository.AddUnspents(ctx, []domain.Unspent{
+		{TxID: prevoutTxID, VOut: 1, Address: "a", Confirmed: true},
+		{TxID: txID, VOut: 0, Address: "a", Confirmed: false},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			tradeID = trade.ID
+			trade.Status = domain.CompletedStatus
+			trade.TxID = txID
+			trade.TxHex = txHex
+			return trade, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// mockExplorer is an explorer.Service that doesn't know any transaction,
+// returns the given utxos for any address, fails to broadcast with
+// broadcastErr, if defined, and reports any output as spent by spendingTxID
+type mockExplorer struct {
+	utxos          []explorer.Utxo
+	broadcastErr   error
+	broadcastCount int
+	spendingTxID   string
+}
+
+func (m *mockExplorer) GetUnspents(addr string, blindKeys [][]byte) ([]explorer.Utxo, error) {
+	return m.utxos, nil
+}
+
+func (m *mockExplorer) GetTransactionHex(hash string) (string, error) {
+	return "", explorer.ErrTransactionNotFound
+}
+
+func (m *mockExplorer) IsTransactionConfirmed(txID string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockExplorer) GetTransactionStatus(txID string) (map[string]interface{}, error) {
+	return map[string]interface{}{"confirmed": false}, nil
+}
+
+func (m *mockExplorer) GetTransactionsForAddress(address string) ([]explorer.Transaction, error) {
+	return nil, nil
+}
+
+func (m *mockExplorer) BroadcastTransaction(txHex string) (string, error) {
+	m.broadcastCount++
+	if m.broadcastErr != nil {
+		return "", m.broadcastErr
+	}
+	return "", nil
+}
+
+func (m *mockExplorer) GetSpendingTransactionID(
+	txID string,
+	vout uint32,
+) (string, error) {
+	return m.spendingTxID, nil
+}
+
+func (m *mockExplorer) Faucet(address string) (string, error) {
+	return "", nil
+}
+
+func (m *mockExplorer) Mint(address string, amount int) (string, string, error) {
+	return "", "", nil
+}
+
+func (m *mockExplorer) GetUnspentsForAddresses(
+	addresses []string,
+	blindingKeys [][]byte,
+) ([]explorer.Utxo, error) {
+	return nil, nil
+}
@@ -4,3 +4,13 @@ const (
 	TradeBuy = iota
 	TradeSell
 )
+
+const (
+	ExportKindTrade      = "trade"
+	ExportKindDeposit    = "deposit"
+	ExportKindWithdrawal = "withdrawal"
+
+	// exportPageSize is the number of items of every kind read at once from
+	// the storage while exporting
+	exportPageSize = 100
+)
@@ -4,3 +4,45 @@ import "errors"
 
 // ErrCrawlerDoesNotObserveAddresses occurs when the crawler does not observe any address
 var	ErrCrawlerDoesNotObserveFeeAccount = errors.New("fee account needs to be funded to open a market")
+
+// ErrInvalidTimeRange is returned when the start of a time range is after its end
+var ErrInvalidTimeRange = errors.New("start time must not be after end time")
+
+// ErrInvalidQuote is returned when a quote ID is malformed or has not been
+// issued by the daemon
+var ErrInvalidQuote = errors.New("quote is not valid")
+
+// ErrQuoteExpired is returned when a quote is used after its expiry time
+var ErrQuoteExpired = errors.New("quote has expired")
+
+// ErrQuoteAlreadyUsed is returned when a quote is used for more than one
+// trade proposal
+var ErrQuoteAlreadyUsed = errors.New("quote has been already used")
+
+// ErrQuoteMismatch is returned when a trade proposal doesn't match the
+// market, the trade type or the amounts of the quote it references
+var ErrQuoteMismatch = errors.New("swap request does not match the quote")
+
+// ErrSwapNotFound is returned when no trade is identified by a swap request
+// or accept id
+var ErrSwapNotFound = errors.New("swap not found")
+
+// ErrMultiAssetSwapsDisabled is returned when a multi-asset swap is proposed
+// but the daemon is not configured to accept them
+var ErrMultiAssetSwapsDisabled = errors.New("multi-asset swaps are not enabled")
+
+// ErrBaseAssetLegToSend is returned when a multi-asset swap proposes to send
+// base asset to the daemon, that can only be received as part of the trade
+var ErrBaseAssetLegToSend = errors.New("base asset can only be received in a multi-asset swap")
+
+// ErrWithdrawalAddressNotWhitelisted is returned when sending funds to an
+// address that is not in the withdrawal whitelist
+var ErrWithdrawalAddressNotWhitelisted = errors.New("address is not whitelisted for withdrawals")
+
+// ErrWithdrawalAddressCoolingDown is returned when sending funds to a
+// whitelisted address whose cool-down period is not over yet
+var ErrWithdrawalAddressCoolingDown = errors.New("whitelisted address can not be paid until its cool-down period is over")
+
+// ErrWithdrawalDailyLimitExceeded is returned when sending funds would make
+// the amount of an asset sent in the last 24 hours exceed its daily limit
+var ErrWithdrawalDailyLimitExceeded = errors.New("withdrawal exceeds the daily limit")
@@ -0,0 +1,505 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/crawler"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	mm "github.com/tdex-network/tdex-daemon/pkg/marketmaking"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+)
+
+// multiAssetMarketTrade is the part of a multi-asset trade that involves a
+// single market, ie. the conversion of one leg of the trade from or into the
+// base asset.
+type multiAssetMarketTrade struct {
+	market          *domain.Market
+	accountIndex    int
+	unspents        []domain.Unspent
+	utxos           []explorer.Utxo
+	blindingKeys    map[string][]byte
+	derivationPaths map[string]string
+	leg             pkgswap.Leg
+	// isLegSent tells whether the leg is sent by the trader, meaning that the
+	// market receives its quote asset and pays base asset, or vice versa
+	isLegSent bool
+	// baseAmount is the amount of base asset either paid or received by the
+	// market
+	baseAmount uint64
+}
+
+// marketSwap returns the inputs and outputs that the market adds to the swap
+// transaction
+func (m *multiAssetMarketTrade) marketSwap(
+	outputDerivationPath, changeDerivationPath string,
+) domain.MarketSwapOpts {
+	opts := domain.MarketSwapOpts{
+		Unspents:             m.utxos,
+		InputAsset:           m.market.QuoteAsset,
+		InputAmount:          m.leg.Amount,
+		OutputAsset:          m.market.BaseAsset,
+		OutputAmount:         m.baseAmount,
+		OutputDerivationPath: outputDerivationPath,
+		ChangeDerivationPath: changeDerivationPath,
+	}
+	if m.isLegSent {
+		opts.InputAsset, opts.OutputAsset = opts.OutputAsset, opts.InputAsset
+		opts.InputAmount, opts.OutputAmount = opts.OutputAmount, opts.InputAmount
+	}
+	return opts
+}
+
+// marketTrade returns the part of the trade settled by the market as recorded
+// in the trade
+func (m *multiAssetMarketTrade) marketTrade() domain.MarketTrade {
+	return domain.MarketTrade{
+		QuoteAsset:  m.market.QuoteAsset,
+		QuoteAmount: m.leg.Amount,
+		BaseAmount:  m.baseAmount,
+		IsQuoteSent: !m.isLegSent,
+		Fee:         m.market.Fee,
+		FeeAsset:    m.market.FeeAsset,
+	}
+}
+
+// TradeProposeMultiAsset is the domain controller for the multi-asset version
+// of the TradePropose RPC. Every leg of the trade, other than one of base
+// asset received by the trader, is converted from or into base asset through
+// the market of its asset. The trade is accepted if the value of the legs
+// sent by the trader matches, within the price slippage, that of the legs it
+// receives. It's recorded under the market of the first leg sent, along with
+// the part settled by each market it touches, so that it's accounted by all
+// of them.
+func (t *tradeService) TradeProposeMultiAsset(
+	ctx context.Context,
+	swapRequest *pb.SwapRequest,
+	legsP, legsR []pkgswap.Leg,
+) (
+	swapAccept *pb.SwapAccept,
+	swapFail *pb.SwapFail,
+	swapExpiryTime uint64,
+	err error,
+) {
+	if !config.GetBool(config.EnableMultiAssetSwapsKey) {
+		err = ErrMultiAssetSwapsDisabled
+		return
+	}
+
+	if len(legsP) <= 0 || len(legsR) <= 0 {
+		err = pkgswap.ErrNullLegs
+		return
+	}
+
+	if trade := t.getTradeBySwapRequest(ctx, swapRequest); trade != nil {
+		tradeLogger(ctx, trade.ID).Debug("replying to duplicated swap request")
+		swapAccept, swapFail, swapExpiryTime =
+			replyToDuplicatedSwapRequest(trade, swapRequest, legsP, legsR)
+		return
+	}
+
+	baseAsset := config.GetString(config.BaseAssetKey)
+	var baseAmountR uint64
+	trades := make([]*multiAssetMarketTrade, 0, len(legsP)+len(legsR))
+	for i, legs := range [][]pkgswap.Leg{legsP, legsR} {
+		isLegSent := i == 0
+		for _, leg := range legs {
+			if leg.Asset == baseAsset {
+				if isLegSent {
+					err = ErrBaseAssetLegToSend
+					return
+				}
+				baseAmountR = leg.Amount
+				continue
+			}
+
+			trade, _err := t.getMultiAssetMarketTrade(ctx, leg, isLegSent)
+			if _err != nil {
+				err = _err
+				return
+			}
+			trades = append(trades, trade)
+		}
+	}
+
+	// ... and the same for fee account (we'll need to top-up fees)
+	feeUnspents, feeUtxos, feeBlindingKeysByScript, feeDerivationPaths, _err :=
+		t.getUnspentsBlindingsAndDerivationPathsForAccount(ctx, domain.FeeAccount)
+	if _err != nil {
+		err = _err
+		return
+	}
+	// Check we got at least one
+	if len(feeUnspents) == 0 || len(feeUtxos) == 0 {
+		err = errors.New("fee account is not funded")
+		return
+	}
+
+	isValidPrice := isValidMultiAssetTradePrice(trades, baseAmountR) &&
+		balanceMultiAssetTrade(trades, baseAmountR)
+
+	var tradeID uuid.UUID
+	var selectedUnspents []explorer.Utxo
+	var marketSwaps []domain.MarketSwapOpts
+	var feeChangeDerivationPath string
+	outputBlindingKeysByScript := map[string][]byte{}
+	type blindKeyAndAccountIndex struct {
+		blindkey     []byte
+		accountIndex int
+	}
+	addressesToObserve := map[string]blindKeyAndAccountIndex{}
+
+	// derive output and change addresses for every market, and change address
+	// for fee account
+	if err := t.vaultRepository.UpdateVault(
+		ctx,
+		nil,
+		"",
+		func(v *domain.Vault) (*domain.Vault, error) {
+			for _, trade := range trades {
+				outputAddress, outputScript, outputBlindKey, err :=
+					v.DeriveNextExternalAddressForAccount(t.signer, trade.accountIndex)
+				if err != nil {
+					return nil, err
+				}
+				changeAddress, changeScript, changeBlindKey, err :=
+					v.DeriveNextInternalAddressForAccount(t.signer, trade.accountIndex)
+				if err != nil {
+					return nil, err
+				}
+				marketAccount, _ := v.AccountByIndex(trade.accountIndex)
+
+				outputBlindingKeysByScript[outputScript] = outputBlindKey
+				outputBlindingKeysByScript[changeScript] = changeBlindKey
+				addressesToObserve[outputAddress] =
+					blindKeyAndAccountIndex{outputBlindKey, trade.accountIndex}
+				addressesToObserve[changeAddress] =
+					blindKeyAndAccountIndex{changeBlindKey, trade.accountIndex}
+				marketSwaps = append(marketSwaps, trade.marketSwap(
+					marketAccount.DerivationPathByScript[outputScript],
+					marketAccount.DerivationPathByScript[changeScript],
+				))
+			}
+
+			feeChangeAddress, feeChangeScript, feeChangeBlindKey, err :=
+				v.DeriveNextInternalAddressForAccount(t.signer, domain.FeeAccount)
+			if err != nil {
+				return nil, err
+			}
+			feeAccount, _ := v.AccountByIndex(domain.FeeAccount)
+			addressesToObserve[feeChangeAddress] =
+				blindKeyAndAccountIndex{feeChangeBlindKey, domain.FeeAccount}
+			feeChangeDerivationPath, _ = feeAccount.DerivationPathByScript[feeChangeScript]
+
+			return v, nil
+		}); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// the trade is recorded under the market of the first leg sent by the
+	// trader, that is never the base asset
+	mkt := trades[0].market
+	marketTrades := make([]domain.MarketTrade, 0, len(trades))
+	marketBlindingKeysByScript := make([]map[string][]byte, 0, len(trades))
+	marketDerivationPaths := make([]map[string]string, 0, len(trades))
+	for _, trade := range trades {
+		marketTrades = append(marketTrades, trade.marketTrade())
+		marketBlindingKeysByScript = append(marketBlindingKeysByScript, trade.blindingKeys)
+		marketDerivationPaths = append(marketDerivationPaths, trade.derivationPaths)
+	}
+
+	// parse swap proposal and possibly accept
+	if err := t.tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			ok, err := trade.ProposeMultiAsset(
+				swapRequest, legsP, legsR, marketTrades, nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			tradeID = trade.ID
+			if !ok {
+				swapFail = trade.SwapFailMessage()
+				return trade, nil
+			}
+
+			if !isValidPrice {
+				trade.Fail(
+					swapRequest.GetId(),
+					domain.ProposalRejectedStatus,
+					pkgswap.ErrCodeInvalidSwapRequest,
+					"bad pricing",
+				)
+				swapFail = trade.SwapFailMessage()
+				return trade, nil
+			}
+
+			acceptSwapResult, err := t.signer.AcceptSwap(domain.AcceptSwapOpts{
+				SwapRequest:                swapRequest,
+				LegsP:                      legsP,
+				LegsR:                      legsR,
+				MarketSwaps:                marketSwaps,
+				FeeUnspents:                feeUtxos,
+				MarketBlindingKeysByScript: mergeBlindingKeys(marketBlindingKeysByScript...),
+				FeeBlindingKeysByScript:    feeBlindingKeysByScript,
+				OutputBlindingKeysByScript: outputBlindingKeysByScript,
+				MarketDerivationPaths:      mergeDerivationPaths(marketDerivationPaths...),
+				FeeDerivationPaths:         feeDerivationPaths,
+				FeeChangeDerivationPath:    feeChangeDerivationPath,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			ok, err = trade.Accept(
+				acceptSwapResult.PsetBase64,
+				acceptSwapResult.InputBlindingKeys,
+				acceptSwapResult.OutputBlindingKeys,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				swapFail = trade.SwapFailMessage()
+			} else {
+				swapAccept = trade.SwapAcceptMessage()
+				swapExpiryTime = trade.SwapExpiryTime()
+				selectedUnspents = acceptSwapResult.SelectedUnspents
+			}
+
+			trade.MarketFee = mkt.Fee
+			trade.MarketFeeAsset = mkt.FeeAsset
+
+			return trade, nil
+		}); err != nil {
+		if errors.Is(err, domain.ErrDuplicatedSwapRequest) {
+			return t.replyToConcurrentSwapRequest(ctx, swapRequest, legsP, legsR)
+		}
+		return nil, nil, 0, err
+	}
+	logTradeProposal(ctx, tradeID, mkt.QuoteAsset, swapAccept, swapFail)
+
+	selectedUnspentKeys := getUnspentKeys(selectedUnspents)
+	if err := t.unspentRepository.LockUnspents(
+		ctx,
+		selectedUnspentKeys,
+		tradeID,
+	); err != nil {
+		return nil, nil, 0, err
+	}
+
+	for addr, info := range addressesToObserve {
+		t.crawlerSvc.AddObservable(&crawler.AddressObservable{
+			AccountIndex: info.accountIndex,
+			Address:      addr,
+			BlindingKey:  info.blindkey,
+		})
+	}
+
+	return
+}
+
+// getMultiAssetMarketTrade returns the part of a multi-asset trade that
+// converts the given leg through the market of its asset, along with the
+// amount of base asset that the market pays or gets for it
+func (t *tradeService) getMultiAssetMarketTrade(
+	ctx context.Context,
+	leg pkgswap.Leg,
+	isLegSent bool,
+) (*multiAssetMarketTrade, error) {
+	if err := validateAssetString(leg.Asset); err != nil {
+		return nil, domain.ErrInvalidQuoteAsset
+	}
+
+	mkt, accountIndex, err := t.marketRepository.GetMarketByAsset(ctx, leg.Asset)
+	if err != nil {
+		return nil, err
+	}
+	if accountIndex < 0 {
+		return nil, domain.ErrMarketNotExist
+	}
+	if !mkt.IsTradable() {
+		return nil, domain.ErrMarketIsClosed
+	}
+
+	unspents, utxos, blindingKeys, derivationPaths, err :=
+		t.getUnspentsBlindingsAndDerivationPathsForAccount(ctx, accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(unspents) == 0 || len(utxos) == 0 {
+		return nil, errors.New("market account is not funded")
+	}
+
+	var baseAmount uint64
+	if isLegSent {
+		baseAmount, err = previewBaseAmountForQuoteIn(unspents, mkt, leg.Amount)
+	} else {
+		baseAmount, err = previewBaseAmountForQuoteOut(unspents, mkt, leg.Amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &multiAssetMarketTrade{
+		market:          mkt,
+		accountIndex:    accountIndex,
+		unspents:        unspents,
+		utxos:           utxos,
+		blindingKeys:    blindingKeys,
+		derivationPaths: derivationPaths,
+		leg:             leg,
+		isLegSent:       isLegSent,
+		baseAmount:      baseAmount,
+	}, nil
+}
+
+// previewBaseAmountForQuoteIn returns the amount of base asset that the given
+// market pays for receiving the given amount of its quote asset, market fees
+// included. It's the inverse of the preview of a BUY trade.
+func previewBaseAmountForQuoteIn(
+	unspents []domain.Unspent,
+	market *domain.Market,
+	quoteAmount uint64,
+) (uint64, error) {
+	if market.IsStrategyPluggable() {
+		feePercentage := decimal.NewFromInt(market.Fee).Div(decimal.NewFromInt(100))
+		// baseAmount = quoteAmount / (price * (1 + feePercentage))
+		divisor := market.QuoteAssetPrice().Mul(decimal.NewFromInt(1).Add(feePercentage))
+		if !divisor.IsPositive() {
+			return 0, domain.ErrNotPriced
+		}
+		baseAmount := decimal.NewFromInt(int64(quoteAmount)).Div(divisor)
+		return baseAmount.BigInt().Uint64(), nil
+	}
+
+	balances := getBalanceByAsset(unspents)
+	baseWeight, quoteWeight := market.ReservesWeights()
+	return market.Strategy.Formula().OutGivenIn(
+		&mm.FormulaOpts{
+			BalanceIn:           balances[market.QuoteAsset],
+			BalanceOut:          balances[market.BaseAsset],
+			WeightIn:            quoteWeight,
+			WeightOut:           baseWeight,
+			Fee:                 uint64(market.Fee),
+			ChargeFeeOnTheWayIn: market.FeeAsset == market.BaseAsset,
+		},
+		quoteAmount,
+	)
+}
+
+// previewBaseAmountForQuoteOut returns the amount of base asset that the
+// given market wants for sending the given amount of its quote asset, market
+// fees included. It's the inverse of the preview of a SELL trade.
+func previewBaseAmountForQuoteOut(
+	unspents []domain.Unspent,
+	market *domain.Market,
+	quoteAmount uint64,
+) (uint64, error) {
+	if market.IsStrategyPluggable() {
+		feePercentage := decimal.NewFromInt(market.Fee).Div(decimal.NewFromInt(100))
+		// baseAmount = quoteAmount / (price * (1 - feePercentage))
+		divisor := market.QuoteAssetPrice().Mul(decimal.NewFromInt(1).Sub(feePercentage))
+		if !divisor.IsPositive() {
+			return 0, domain.ErrNotPriced
+		}
+		baseAmount := decimal.NewFromInt(int64(quoteAmount)).Div(divisor)
+		return baseAmount.BigInt().Uint64(), nil
+	}
+
+	balances := getBalanceByAsset(unspents)
+	baseWeight, quoteWeight := market.ReservesWeights()
+	return market.Strategy.Formula().InGivenOut(
+		&mm.FormulaOpts{
+			BalanceIn:           balances[market.BaseAsset],
+			BalanceOut:          balances[market.QuoteAsset],
+			WeightIn:            baseWeight,
+			WeightOut:           quoteWeight,
+			Fee:                 uint64(market.Fee),
+			ChargeFeeOnTheWayIn: market.FeeAsset == market.QuoteAsset,
+		},
+		quoteAmount,
+	)
+}
+
+// getMultiAssetTradeValues returns the value in base asset of the legs sent
+// and of those received by the trader
+func getMultiAssetTradeValues(
+	trades []*multiAssetMarketTrade,
+	baseAmountR uint64,
+) (valueP, valueR uint64) {
+	valueR = baseAmountR
+	for _, trade := range trades {
+		if trade.isLegSent {
+			valueP += trade.baseAmount
+		} else {
+			valueR += trade.baseAmount
+		}
+	}
+	return
+}
+
+// isValidMultiAssetTradePrice works like isValidTradePrice by comparing the
+// value of the legs sent by the trader with that of the legs it receives
+func isValidMultiAssetTradePrice(
+	trades []*multiAssetMarketTrade,
+	baseAmountR uint64,
+) bool {
+	valueP, valueR := getMultiAssetTradeValues(trades, baseAmountR)
+
+	amountToCheck := decimal.NewFromInt(int64(valueP))
+	slippage := decimal.NewFromFloat(config.GetFloat(config.PriceSlippageKey))
+	expectedAmount := decimal.NewFromInt(int64(valueR))
+	lowerBound := expectedAmount.Sub(expectedAmount.Mul(slippage))
+	upperBound := expectedAmount.Add(expectedAmount.Mul(slippage))
+
+	return amountToCheck.GreaterThanOrEqual(lowerBound) && amountToCheck.LessThanOrEqual(upperBound)
+}
+
+// balanceMultiAssetTrade adjusts the amounts of base asset paid or received by
+// the markets of a multi-asset trade so that what is paid equals what is
+// received plus the amount of base asset sent to the trader, if any.
+// The difference between the values of the legs, accepted within the price
+// slippage, goes to the first market receiving base asset or, if none, it's
+// subtracted from the amounts paid by the markets, in order. It returns false
+// if the difference can't be covered.
+func balanceMultiAssetTrade(
+	trades []*multiAssetMarketTrade,
+	baseAmountR uint64,
+) bool {
+	valueP, valueR := getMultiAssetTradeValues(trades, baseAmountR)
+	diff := int64(valueP) - int64(valueR)
+
+	for _, trade := range trades {
+		if !trade.isLegSent {
+			amount := int64(trade.baseAmount) + diff
+			if amount <= 0 {
+				return false
+			}
+			trade.baseAmount = uint64(amount)
+			return true
+		}
+	}
+
+	if diff < 0 {
+		trades[0].baseAmount += uint64(-diff)
+		return true
+	}
+	for _, trade := range trades {
+		// every market must keep paying something
+		amount := diff
+		if amount > int64(trade.baseAmount)-1 {
+			amount = int64(trade.baseAmount) - 1
+		}
+		trade.baseAmount -= uint64(amount)
+		diff -= amount
+	}
+	return diff == 0
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/tdex-network/tdex-daemon/pkg/crawler"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	mm "github.com/tdex-network/tdex-daemon/pkg/marketmaking"
+	"github.com/tdex-network/tdex-daemon/pkg/mathutil"
 )
 
 // OperatorService defines the methods of the application layer for the operator service.
@@ -68,15 +73,44 @@ type OperatorService interface {
 		ctx context.Context,
 		market Market,
 	) (*ReportMarketFee, error)
+	Export(
+		ctx context.Context,
+		req ExportReq,
+		handler func(record ExportRecord) error,
+	) error
+	GetMarketReport(
+		ctx context.Context,
+		req MarketReportReq,
+	) (*MarketReport, error)
+	AddWithdrawalAddress(
+		ctx context.Context,
+		req AddWithdrawalAddressReq,
+	) (*WithdrawalAddress, error)
+	RemoveWithdrawalAddress(
+		ctx context.Context,
+		address string,
+	) error
+	ListWithdrawalAddresses(
+		ctx context.Context,
+	) ([]WithdrawalAddress, error)
+	ListPayments(
+		ctx context.Context,
+	) ([]Payment, error)
 }
 
 type operatorService struct {
-	marketRepository  domain.MarketRepository
-	vaultRepository   domain.VaultRepository
-	tradeRepository   domain.TradeRepository
-	unspentRepository domain.UnspentRepository
-	explorerSvc       explorer.Service
-	crawlerSvc        crawler.Service
+	marketRepository            domain.MarketRepository
+	vaultRepository             domain.VaultRepository
+	tradeRepository             domain.TradeRepository
+	unspentRepository           domain.UnspentRepository
+	depositRepository           domain.DepositRepository
+	withdrawalRepository        domain.WithdrawalRepository
+	withdrawalAddressRepository domain.WithdrawalAddressRepository
+	paymentRepository           domain.PaymentRepository
+	explorerSvc                 explorer.Service
+	crawlerSvc                  crawler.Service
+	signer                      domain.Signer
+	withdrawalPolicy            withdrawalPolicy
 }
 
 // NewOperatorService is a constructor function for OperatorService.
@@ -85,16 +119,30 @@ func NewOperatorService(
 	vaultRepository domain.VaultRepository,
 	tradeRepository domain.TradeRepository,
 	unspentRepository domain.UnspentRepository,
+	depositRepository domain.DepositRepository,
+	withdrawalRepository domain.WithdrawalRepository,
+	withdrawalAddressRepository domain.WithdrawalAddressRepository,
+	paymentRepository domain.PaymentRepository,
 	explorerSvc explorer.Service,
 	crawlerSvc crawler.Service,
+	signer domain.Signer,
 ) OperatorService {
 	return &operatorService{
-		marketRepository:  marketRepository,
-		vaultRepository:   vaultRepository,
-		tradeRepository:   tradeRepository,
-		unspentRepository: unspentRepository,
-		explorerSvc:       explorerSvc,
-		crawlerSvc:        crawlerSvc,
+		marketRepository:            marketRepository,
+		vaultRepository:             vaultRepository,
+		tradeRepository:             tradeRepository,
+		unspentRepository:           unspentRepository,
+		depositRepository:           depositRepository,
+		withdrawalRepository:        withdrawalRepository,
+		withdrawalAddressRepository: withdrawalAddressRepository,
+		paymentRepository:           paymentRepository,
+		explorerSvc:                 explorerSvc,
+		crawlerSvc:                  crawlerSvc,
+		signer:                      signer,
+		withdrawalPolicy: withdrawalPolicy{
+			withdrawalAddressRepository: withdrawalAddressRepository,
+			paymentRepository:           paymentRepository,
+		},
 	}
 }
 
@@ -166,6 +214,7 @@ func (o *operatorService) DepositMarket(
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
 			addr, _, blindingKey, err := v.DeriveNextExternalAddressForAccount(
+				o.signer,
 				accountIndex,
 			)
 			if err != nil {
@@ -198,6 +247,7 @@ func (o *operatorService) DepositFeeAccount(
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
 			addr, _, blindKey, err := v.DeriveNextExternalAddressForAccount(
+				o.signer,
 				domain.FeeAccount,
 			)
 			if err != nil {
@@ -464,7 +514,7 @@ func (o *operatorService) UpdateMarketStrategy(
 		return domain.ErrMarketNotExist
 	}
 
-	//For now we support only BALANCED or PLUGGABLE (ie. price feed)
+	//For now we support BALANCED, UNBALANCED or PLUGGABLE (ie. price feed)
 	requestStrategy := req.Strategy
 	//Updates the strategy
 	return o.marketRepository.UpdateMarket(
@@ -484,6 +534,15 @@ func (o *operatorService) UpdateMarketStrategy(
 					return nil, err
 				}
 
+			case domain.StrategyTypeUnbalanced:
+				baseAssetWeight := req.BaseAssetWeight
+				if baseAssetWeight == 0 {
+					baseAssetWeight, _ = m.ReservesWeights()
+				}
+				if err := m.MakeStrategyUnbalanced(baseAssetWeight); err != nil {
+					return nil, err
+				}
+
 			default:
 				return nil, errors.New("strategy not supported")
 			}
@@ -601,15 +660,20 @@ func (o *operatorService) GetCollectedMarketFee(
 	fees := make([]Fee, 0)
 	total := make(map[string]int64)
 	for _, v := range trades {
+		feeAsset, fee := v.MarketFeeAsset, v.MarketFee
+		// multi-asset trades pay the fee of each market they touch
+		if mt, ok := v.MarketTrade(market.QuoteAsset); ok {
+			feeAsset, fee = mt.FeeAsset, mt.Fee
+		}
 		fees = append(fees, Fee{
-			FeeAsset:   v.MarketFeeAsset,
-			BasisPoint: v.MarketFee,
+			FeeAsset:   feeAsset,
+			BasisPoint: fee,
 		})
 
-		if val, ok := total[v.MarketFeeAsset]; ok {
-			total[v.MarketFeeAsset] = val + v.MarketFee
+		if val, ok := total[feeAsset]; ok {
+			total[feeAsset] = val + fee
 		} else {
-			total[v.MarketFeeAsset] = v.MarketFee
+			total[feeAsset] = fee
 		}
 	}
 
@@ -619,6 +683,382 @@ func (o *operatorService) GetCollectedMarketFee(
 	}, nil
 }
 
+// Export passes to the given handler, one at a time and sorted by timestamp,
+// the records of the completed trades, of the confirmed deposits and of the
+// withdrawals that took place in the requested time range. Records are read
+// from the repositories a page at a time, therefore the export never holds
+// more than a few pages in memory.
+func (o *operatorService) Export(
+	ctx context.Context,
+	req ExportReq,
+	handler func(record ExportRecord) error,
+) error {
+	toTime := req.ToTime
+	if toTime == 0 {
+		toTime = uint64(time.Now().Unix())
+	}
+	if req.FromTime > toTime {
+		return ErrInvalidTimeRange
+	}
+
+	accountsByQuoteAsset := map[string]int{}
+	marketAccount := func(quoteAsset string) (int, error) {
+		if accountIndex, ok := accountsByQuoteAsset[quoteAsset]; ok {
+			return accountIndex, nil
+		}
+		_, accountIndex, err := o.marketRepository.GetMarketByAsset(
+			ctx,
+			quoteAsset,
+		)
+		if err != nil {
+			return 0, err
+		}
+		accountsByQuoteAsset[quoteAsset] = accountIndex
+		return accountIndex, nil
+	}
+	trades := &exportCursor{
+		fetchPage: func(offset, limit int) ([]ExportRecord, int, error) {
+			trades, err := o.tradeRepository.GetTradesByCompleteTime(
+				ctx, req.FromTime, toTime, offset, limit,
+			)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			records := make([]ExportRecord, 0, len(trades))
+			for _, trade := range trades {
+				if !trade.IsCompleted() {
+					continue
+				}
+
+				// a multi-asset trade has a record for every market it touches
+				if len(trade.MarketTrades) > 0 {
+					for _, mt := range trade.MarketTrades {
+						accountIndex, err := marketAccount(mt.QuoteAsset)
+						if err != nil {
+							return nil, 0, err
+						}
+						records = append(
+							records, marketTradeToExportRecord(trade, mt, accountIndex),
+						)
+					}
+					continue
+				}
+
+				accountIndex, err := marketAccount(trade.MarketQuoteAsset)
+				if err != nil {
+					return nil, 0, err
+				}
+				records = append(records, tradeToExportRecord(trade, accountIndex))
+			}
+			return records, len(trades), nil
+		},
+	}
+
+	deposits := &exportCursor{
+		fetchPage: func(offset, limit int) ([]ExportRecord, int, error) {
+			deposits, err := o.depositRepository.GetDepositsByTime(
+				ctx, req.FromTime, toTime, offset, limit,
+			)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			records := make([]ExportRecord, 0, len(deposits))
+			for _, deposit := range deposits {
+				records = append(records, depositToExportRecord(deposit))
+			}
+			return records, len(deposits), nil
+		},
+	}
+
+	withdrawals := &exportCursor{
+		fetchPage: func(offset, limit int) ([]ExportRecord, int, error) {
+			withdrawals, err := o.withdrawalRepository.GetWithdrawalsByTime(
+				ctx, req.FromTime, toTime, offset, limit,
+			)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			records := make([]ExportRecord, 0, 2*len(withdrawals))
+			for _, withdrawal := range withdrawals {
+				records = append(records, withdrawalToExportRecords(withdrawal)...)
+			}
+			return records, len(withdrawals), nil
+		},
+	}
+
+	// merge the records of the cursors by always picking the oldest one
+	cursors := []*exportCursor{trades, deposits, withdrawals}
+	for {
+		var next *exportCursor
+		var nextRecord *ExportRecord
+		for _, c := range cursors {
+			record, err := c.peek()
+			if err != nil {
+				return err
+			}
+			if record == nil {
+				continue
+			}
+			if nextRecord == nil || record.Timestamp < nextRecord.Timestamp {
+				next, nextRecord = c, record
+			}
+		}
+		if next == nil {
+			return nil
+		}
+
+		if err := handler(*nextRecord); err != nil {
+			return err
+		}
+		next.pop()
+	}
+}
+
+// exportCursor iterates over the records of one kind, sorted by timestamp,
+// fetching them from the storage a page at a time. fetchPage returns the
+// records for the page of stored items identified by offset and limit,
+// together with the number of items read.
+type exportCursor struct {
+	fetchPage func(offset, limit int) ([]ExportRecord, int, error)
+	records   []ExportRecord
+	offset    int
+	done      bool
+}
+
+// peek returns the next record without consuming it, nil if there are no
+// more records
+func (c *exportCursor) peek() (*ExportRecord, error) {
+	for len(c.records) <= 0 && !c.done {
+		records, count, err := c.fetchPage(c.offset, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		c.offset += count
+		c.done = count < exportPageSize
+		c.records = records
+	}
+	if len(c.records) <= 0 {
+		return nil, nil
+	}
+	return &c.records[0], nil
+}
+
+// pop consumes the next record
+func (c *exportCursor) pop() {
+	c.records = c.records[1:]
+}
+
+// GetMarketReport returns the report of the given market for the requested
+// time range. The completed trades of the market, together with the deposits
+// and withdrawals of its account, are used to reconstruct the reserves at the
+// boundaries of the range starting from the current balance.
+// The price history is not stored, therefore the prices of a market with
+// pluggable strategy are always the current ones, while for the others the
+// spot price is calculated with the strategy formula from the reserves.
+func (o *operatorService) GetMarketReport(
+	ctx context.Context,
+	req MarketReportReq,
+) (*MarketReport, error) {
+	toTime := req.ToTime
+	if toTime == 0 {
+		toTime = uint64(time.Now().Unix())
+	}
+	if req.FromTime > toTime {
+		return nil, ErrInvalidTimeRange
+	}
+
+	market, accountIndex, err := o.marketRepository.GetMarketByAsset(
+		ctx,
+		req.QuoteAsset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if market == nil || market.BaseAsset != req.BaseAsset {
+		return nil, domain.ErrMarketNotExist
+	}
+
+	addresses, _, err := o.vaultRepository.
+		GetAllDerivedAddressesAndBlindingKeysForAccount(ctx, accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	baseBalance, err := o.unspentRepository.GetBalance(
+		ctx,
+		addresses,
+		market.BaseAsset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	quoteBalance, err := o.unspentRepository.GetBalance(
+		ctx,
+		addresses,
+		market.QuoteAsset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	flows := make([]marketFlow, 0)
+
+	trades, err := o.tradeRepository.GetCompletedTradesByMarket(
+		ctx,
+		market.QuoteAsset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, trade := range trades {
+		flows = append(flows, tradeToMarketFlow(trade, market))
+	}
+
+	deposits, err := o.depositRepository.GetAllDeposits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := uint64(time.Now().Unix())
+	for _, deposit := range deposits {
+		if deposit.AccountIndex != accountIndex {
+			continue
+		}
+		// unconfirmed deposits are already part of the current balance
+		if deposit.Timestamp == 0 {
+			deposit.Timestamp = now
+		}
+		flows = append(flows, depositToMarketFlow(deposit, market))
+	}
+
+	withdrawals, err := o.withdrawalRepository.GetAllWithdrawals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, withdrawal := range withdrawals {
+		if withdrawal.AccountIndex != accountIndex {
+			continue
+		}
+		flows = append(flows, withdrawalToMarketFlow(withdrawal))
+	}
+
+	currentBalance := Balance{
+		BaseAmount:  int64(baseBalance),
+		QuoteAmount: int64(quoteBalance),
+	}
+
+	return newMarketReport(market, currentBalance, flows, req.FromTime, toTime), nil
+}
+
+// AddWithdrawalAddress whitelists the given confidential address as
+// destination of withdrawals if the given wallet passphrase is valid. The
+// address can be paid only once the configured cool-down period is over
+func (o *operatorService) AddWithdrawalAddress(
+	ctx context.Context,
+	req AddWithdrawalAddressReq,
+) (*WithdrawalAddress, error) {
+	vault, err := o.vaultRepository.GetOrCreateVault(ctx, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if !vault.IsValidPassphrase(o.signer, req.Passphrase) {
+		return nil, domain.ErrInvalidPassphrase
+	}
+
+	withdrawalAddress, err := domain.NewWithdrawalAddress(
+		req.Address,
+		req.Label,
+		time.Duration(config.GetInt(config.WithdrawalAddressCoolDownKey))*time.Second,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.withdrawalAddressRepository.AddWithdrawalAddress(
+		ctx,
+		*withdrawalAddress,
+	); err != nil {
+		return nil, err
+	}
+
+	info := withdrawalAddressToInfo(*withdrawalAddress)
+	return &info, nil
+}
+
+// RemoveWithdrawalAddress removes the given address from the whitelist
+func (o *operatorService) RemoveWithdrawalAddress(
+	ctx context.Context,
+	address string,
+) error {
+	script, _, err := parseConfidentialAddress(address)
+	if err != nil {
+		return domain.ErrInvalidWithdrawalAddress
+	}
+	return o.withdrawalAddressRepository.RemoveWithdrawalAddress(
+		ctx,
+		hex.EncodeToString(script),
+	)
+}
+
+// ListWithdrawalAddresses returns the whitelisted withdrawal addresses
+func (o *operatorService) ListWithdrawalAddresses(
+	ctx context.Context,
+) ([]WithdrawalAddress, error) {
+	addresses, err := o.withdrawalAddressRepository.GetAllWithdrawalAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]WithdrawalAddress, 0, len(addresses))
+	for _, a := range addresses {
+		list = append(list, withdrawalAddressToInfo(a))
+	}
+	return list, nil
+}
+
+// ListPayments returns the audit records of all the payments made by the
+// daemon, either withdrawals from markets or sends from the wallet account
+func (o *operatorService) ListPayments(
+	ctx context.Context,
+) ([]Payment, error) {
+	payments, err := o.paymentRepository.GetAllPayments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Payment, 0, len(payments))
+	for _, p := range payments {
+		outs := make([]TxOut, 0, len(p.Outputs))
+		for _, out := range p.Outputs {
+			outs = append(outs, TxOut{
+				Asset:   out.Asset,
+				Value:   int64(out.Amount),
+				Address: out.Address,
+			})
+		}
+		list = append(list, Payment{
+			TxID:            p.TxID,
+			AccountIndex:    p.AccountIndex,
+			Outputs:         outs,
+			MillisatPerByte: p.MillisatPerByte,
+			Broadcasted:     p.Broadcasted,
+			Timestamp:       p.Timestamp,
+		})
+	}
+	return list, nil
+}
+
+func withdrawalAddressToInfo(
+	withdrawalAddress domain.WithdrawalAddress,
+) WithdrawalAddress {
+	return WithdrawalAddress{
+		Address:    withdrawalAddress.Address,
+		Label:      withdrawalAddress.Label,
+		CreatedAt:  withdrawalAddress.CreatedAt,
+		ActiveFrom: withdrawalAddress.ActiveFrom,
+	}
+}
+
 func (o *operatorService) getMarketsForTrades(
 	ctx context.Context,
 	trades []*domain.Trade,
@@ -674,6 +1114,393 @@ func tradesToSwapInfo(
 	return swapInfos
 }
 
+// tradeTime returns the time at which a trade has been settled, falling
+// back to the time of acceptance if the blocktime is not known yet
+func tradeTime(trade *domain.Trade) uint64 {
+	if trade.SwapCompleteTime() > 0 {
+		return trade.SwapCompleteTime()
+	}
+	return trade.SwapAcceptTime()
+}
+
+func tradeToExportRecord(trade *domain.Trade, accountIndex int) ExportRecord {
+	requestMsg := trade.SwapRequestMessage()
+	baseAsset := config.GetString(config.BaseAssetKey)
+
+	baseAmount, quoteAmount := requestMsg.GetAmountR(), requestMsg.GetAmountP()
+	if requestMsg.GetAssetP() == baseAsset {
+		baseAmount, quoteAmount = requestMsg.GetAmountP(), requestMsg.GetAmountR()
+	}
+	price := decimal.Zero
+	if baseAmount > 0 {
+		price = mathutil.Div(quoteAmount, baseAmount)
+	}
+
+	// the fee is taken on the amount of fee asset exchanged with the trade
+	feeAssetAmount := requestMsg.GetAmountR()
+	if requestMsg.GetAssetP() == trade.MarketFeeAsset {
+		feeAssetAmount = requestMsg.GetAmountP()
+	}
+	_, feeAmount := mathutil.LessFee(feeAssetAmount, uint64(trade.MarketFee))
+
+	return ExportRecord{
+		Kind:         ExportKindTrade,
+		ID:           trade.ID.String(),
+		Timestamp:    tradeTime(trade),
+		AccountIndex: accountIndex,
+		BaseAsset:    baseAsset,
+		QuoteAsset:   trade.MarketQuoteAsset,
+		AssetIn:      requestMsg.GetAssetP(),
+		AmountIn:     requestMsg.GetAmountP(),
+		AssetOut:     requestMsg.GetAssetR(),
+		AmountOut:    requestMsg.GetAmountR(),
+		Price:        price,
+		FeeAsset:     trade.MarketFeeAsset,
+		FeeAmount:    feeAmount,
+		TxID:         trade.TxID,
+		RequestTime:  trade.SwapRequestTime(),
+		AcceptTime:   trade.SwapAcceptTime(),
+		CompleteTime: trade.SwapCompleteTime(),
+	}
+}
+
+// marketTradeToExportRecord works like tradeToExportRecord for the part of a
+// multi-asset trade settled by the given market
+func marketTradeToExportRecord(
+	trade *domain.Trade,
+	mt domain.MarketTrade,
+	accountIndex int,
+) ExportRecord {
+	baseAsset := config.GetString(config.BaseAssetKey)
+
+	assetIn, amountIn := mt.QuoteAsset, mt.QuoteAmount
+	assetOut, amountOut := baseAsset, mt.BaseAmount
+	if mt.IsQuoteSent {
+		assetIn, amountIn, assetOut, amountOut =
+			assetOut, amountOut, assetIn, amountIn
+	}
+	price := decimal.Zero
+	if mt.BaseAmount > 0 {
+		price = mathutil.Div(mt.QuoteAmount, mt.BaseAmount)
+	}
+
+	feeAssetAmount := mt.QuoteAmount
+	if mt.FeeAsset == baseAsset {
+		feeAssetAmount = mt.BaseAmount
+	}
+	_, feeAmount := mathutil.LessFee(feeAssetAmount, uint64(mt.Fee))
+
+	return ExportRecord{
+		Kind:         ExportKindTrade,
+		ID:           trade.ID.String(),
+		Timestamp:    tradeTime(trade),
+		AccountIndex: accountIndex,
+		BaseAsset:    baseAsset,
+		QuoteAsset:   mt.QuoteAsset,
+		AssetIn:      assetIn,
+		AmountIn:     amountIn,
+		AssetOut:     assetOut,
+		AmountOut:    amountOut,
+		Price:        price,
+		FeeAsset:     mt.FeeAsset,
+		FeeAmount:    feeAmount,
+		TxID:         trade.TxID,
+		RequestTime:  trade.SwapRequestTime(),
+		AcceptTime:   trade.SwapAcceptTime(),
+		CompleteTime: trade.SwapCompleteTime(),
+	}
+}
+
+func depositToExportRecord(deposit domain.Deposit) ExportRecord {
+	return ExportRecord{
+		Kind:         ExportKindDeposit,
+		ID:           fmt.Sprintf("%s:%d", deposit.TxID, deposit.VOut),
+		Timestamp:    deposit.Timestamp,
+		AccountIndex: deposit.AccountIndex,
+		AssetIn:      deposit.Asset,
+		AmountIn:     deposit.Value,
+		TxID:         deposit.TxID,
+	}
+}
+
+// withdrawalToExportRecords returns a record for every asset withdrawn
+func withdrawalToExportRecords(withdrawal domain.Withdrawal) []ExportRecord {
+	records := make([]ExportRecord, 0, 2)
+	amounts := []struct {
+		asset  string
+		amount uint64
+	}{
+		{withdrawal.BaseAsset, withdrawal.BaseAmount},
+		{withdrawal.QuoteAsset, withdrawal.QuoteAmount},
+	}
+	for _, a := range amounts {
+		if a.amount == 0 {
+			continue
+		}
+		records = append(records, ExportRecord{
+			Kind:         ExportKindWithdrawal,
+			ID:           withdrawal.TxID,
+			Timestamp:    withdrawal.Timestamp,
+			AccountIndex: withdrawal.AccountIndex,
+			BaseAsset:    withdrawal.BaseAsset,
+			QuoteAsset:   withdrawal.QuoteAsset,
+			AssetOut:     a.asset,
+			AmountOut:    a.amount,
+			TxID:         withdrawal.TxID,
+		})
+	}
+	return records
+}
+
+// marketFlow is a change of the reserves of a market caused by a completed
+// trade, a deposit or a withdrawal
+type marketFlow struct {
+	kind      string
+	timestamp uint64
+	change    Balance
+	// volume and fee are set only for trades
+	volume Balance
+	fee    Balance
+}
+
+func tradeToMarketFlow(trade *domain.Trade, market *domain.Market) marketFlow {
+	if mt, ok := trade.MarketTrade(market.QuoteAsset); ok {
+		return marketTradeToMarketFlow(trade, mt, market)
+	}
+
+	requestMsg := trade.SwapRequestMessage()
+
+	// the provider receives the proposer's AssetP and sends AssetR
+	change := Balance{}
+	volume := Balance{}
+	if requestMsg.GetAssetP() == market.BaseAsset {
+		change.BaseAmount = int64(requestMsg.GetAmountP())
+		change.QuoteAmount = -int64(requestMsg.GetAmountR())
+		volume.BaseAmount = int64(requestMsg.GetAmountP())
+		volume.QuoteAmount = int64(requestMsg.GetAmountR())
+	} else {
+		change.BaseAmount = -int64(requestMsg.GetAmountR())
+		change.QuoteAmount = int64(requestMsg.GetAmountP())
+		volume.BaseAmount = int64(requestMsg.GetAmountR())
+		volume.QuoteAmount = int64(requestMsg.GetAmountP())
+	}
+
+	return marketFlow{
+		kind:      ExportKindTrade,
+		timestamp: tradeTime(trade),
+		change:    change,
+		volume:    volume,
+		fee:       marketFeeForVolume(volume, trade.MarketFee, trade.MarketFeeAsset, market),
+	}
+}
+
+// marketTradeToMarketFlow works like tradeToMarketFlow for the part of a
+// multi-asset trade settled by the given market
+func marketTradeToMarketFlow(
+	trade *domain.Trade,
+	mt domain.MarketTrade,
+	market *domain.Market,
+) marketFlow {
+	volume := Balance{
+		BaseAmount:  int64(mt.BaseAmount),
+		QuoteAmount: int64(mt.QuoteAmount),
+	}
+	change := Balance{
+		BaseAmount:  -volume.BaseAmount,
+		QuoteAmount: volume.QuoteAmount,
+	}
+	if mt.IsQuoteSent {
+		change.BaseAmount, change.QuoteAmount = volume.BaseAmount, -volume.QuoteAmount
+	}
+
+	return marketFlow{
+		kind:      ExportKindTrade,
+		timestamp: tradeTime(trade),
+		change:    change,
+		volume:    volume,
+		fee:       marketFeeForVolume(volume, mt.Fee, mt.FeeAsset, market),
+	}
+}
+
+// marketFeeForVolume returns the fee collected by the market with the given
+// fee and fee asset over the given volume
+func marketFeeForVolume(
+	volume Balance,
+	marketFee int64,
+	marketFeeAsset string,
+	market *domain.Market,
+) Balance {
+	fee := Balance{}
+	if marketFeeAsset == market.BaseAsset {
+		_, feeAmount := mathutil.LessFee(
+			uint64(volume.BaseAmount),
+			uint64(marketFee),
+		)
+		fee.BaseAmount = int64(feeAmount)
+	} else {
+		_, feeAmount := mathutil.LessFee(
+			uint64(volume.QuoteAmount),
+			uint64(marketFee),
+		)
+		fee.QuoteAmount = int64(feeAmount)
+	}
+	return fee
+}
+
+func depositToMarketFlow(
+	deposit domain.Deposit,
+	market *domain.Market,
+) marketFlow {
+	change := Balance{}
+	switch deposit.Asset {
+	case market.BaseAsset:
+		change.BaseAmount = int64(deposit.Value)
+	case market.QuoteAsset:
+		change.QuoteAmount = int64(deposit.Value)
+	}
+
+	return marketFlow{
+		kind:      ExportKindDeposit,
+		timestamp: deposit.Timestamp,
+		change:    change,
+	}
+}
+
+func withdrawalToMarketFlow(withdrawal domain.Withdrawal) marketFlow {
+	return marketFlow{
+		kind:      ExportKindWithdrawal,
+		timestamp: withdrawal.Timestamp,
+		change: Balance{
+			BaseAmount:  -int64(withdrawal.BaseAmount),
+			QuoteAmount: -int64(withdrawal.QuoteAmount),
+		},
+	}
+}
+
+// newMarketReport calculates the report of a market for the given time range,
+// by reverting the flows that took place after the start and after the end of
+// the range from the current reserves
+func newMarketReport(
+	market *domain.Market,
+	currentBalance Balance,
+	flows []marketFlow,
+	fromTime, toTime uint64,
+) *MarketReport {
+	startBalance, endBalance := currentBalance, currentBalance
+	tradesChange := Balance{}
+	report := &MarketReport{
+		Market: Market{
+			BaseAsset:  market.BaseAsset,
+			QuoteAsset: market.QuoteAsset,
+		},
+		FromTime:       fromTime,
+		ToTime:         toTime,
+		CurrentBalance: currentBalance,
+	}
+
+	for _, flow := range flows {
+		if flow.timestamp > toTime {
+			endBalance = subBalance(endBalance, flow.change)
+		}
+		if flow.timestamp < fromTime {
+			continue
+		}
+		startBalance = subBalance(startBalance, flow.change)
+
+		if flow.timestamp <= toTime && flow.kind == ExportKindTrade {
+			report.TradesCount++
+			tradesChange = addBalance(tradesChange, flow.change)
+			report.Volume = addBalance(report.Volume, flow.volume)
+			report.CollectedFees = addBalance(report.CollectedFees, flow.fee)
+		}
+	}
+
+	report.StartBalance = startBalance
+	report.EndBalance = endBalance
+	report.StartPrice = marketPriceForBalance(market, startBalance)
+	report.EndPrice = marketPriceForBalance(market, endBalance)
+	report.CurrentPrice = marketPriceForBalance(market, currentBalance)
+
+	endPrice := report.EndPrice.QuotePrice
+	report.CollectedFeesValue = valueInBaseAsset(report.CollectedFees, endPrice)
+	report.HoldValue = valueInBaseAsset(startBalance, endPrice)
+	report.TradedValue = valueInBaseAsset(
+		addBalance(startBalance, tradesChange),
+		endPrice,
+	)
+	report.ImpermanentLoss =
+		report.HoldValue - (report.TradedValue - report.CollectedFeesValue)
+	report.InventoryValue = valueInBaseAsset(
+		currentBalance,
+		report.CurrentPrice.QuotePrice,
+	)
+
+	return report
+}
+
+// marketPriceForBalance returns the prices of the market for the given
+// reserves. A zero price is returned if it can't be calculated, for example
+// if any of the reserves is empty.
+func marketPriceForBalance(market *domain.Market, balance Balance) Price {
+	if market.IsStrategyPluggable() {
+		return Price{
+			BasePrice:  market.BaseAssetPrice(),
+			QuotePrice: market.QuoteAssetPrice(),
+		}
+	}
+	if balance.BaseAmount <= 0 || balance.QuoteAmount <= 0 {
+		return Price{BasePrice: decimal.Zero, QuotePrice: decimal.Zero}
+	}
+
+	baseWeight, quoteWeight := market.ReservesWeights()
+	formula := market.Strategy.Formula()
+	basePrice, err := formula.SpotPrice(&mm.FormulaOpts{
+		BalanceIn:  uint64(balance.QuoteAmount),
+		BalanceOut: uint64(balance.BaseAmount),
+		WeightIn:   quoteWeight,
+		WeightOut:  baseWeight,
+	})
+	if err != nil {
+		return Price{BasePrice: decimal.Zero, QuotePrice: decimal.Zero}
+	}
+	quotePrice, err := formula.SpotPrice(&mm.FormulaOpts{
+		BalanceIn:  uint64(balance.BaseAmount),
+		BalanceOut: uint64(balance.QuoteAmount),
+		WeightIn:   baseWeight,
+		WeightOut:  quoteWeight,
+	})
+	if err != nil {
+		return Price{BasePrice: decimal.Zero, QuotePrice: decimal.Zero}
+	}
+
+	return Price{BasePrice: basePrice, QuotePrice: quotePrice}
+}
+
+// valueInBaseAsset returns the value of the given balance in base asset,
+// given the amount of quote asset per unit of base asset. The quote amount
+// is not taken into account if the price is zero.
+func valueInBaseAsset(balance Balance, quotePrice decimal.Decimal) int64 {
+	value := decimal.NewFromInt(balance.BaseAmount)
+	if !quotePrice.IsZero() {
+		value = value.Add(decimal.NewFromInt(balance.QuoteAmount).Div(quotePrice))
+	}
+	return value.Round(0).IntPart()
+}
+
+func addBalance(a, b Balance) Balance {
+	return Balance{
+		BaseAmount:  a.BaseAmount + b.BaseAmount,
+		QuoteAmount: a.QuoteAmount + b.QuoteAmount,
+	}
+}
+
+func subBalance(a, b Balance) Balance {
+	return Balance{
+		BaseAmount:  a.BaseAmount - b.BaseAmount,
+		QuoteAmount: a.QuoteAmount - b.QuoteAmount,
+	}
+}
+
 func (o *operatorService) WithdrawMarketFunds(
 	ctx context.Context,
 	req WithdrawMarketReq,
@@ -719,6 +1546,13 @@ func (o *operatorService) WithdrawMarketFunds(
 		return nil, err
 	}
 
+	paymentsLock.Lock()
+	defer paymentsLock.Unlock()
+
+	if err := o.withdrawalPolicy.check(ctx, outs); err != nil {
+		return nil, err
+	}
+
 	marketUnspents, err := o.getAllUnspentsForAccount(ctx, market.AccountIndex)
 	if err != nil {
 		return nil, err
@@ -736,15 +1570,12 @@ func (o *operatorService) WithdrawMarketFunds(
 	}
 
 	var addressesToObserve []*crawler.AddressObservable
+	var txID, signedTxID string
 	err = o.vaultRepository.UpdateVault(
 		ctx,
 		nil,
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
-			mnemonic, err := v.GetMnemonicSafe()
-			if err != nil {
-				return nil, err
-			}
 			marketAccount, err := v.AccountByIndex(market.AccountIndex)
 			if err != nil {
 				return nil, err
@@ -759,6 +1590,7 @@ func (o *operatorService) WithdrawMarketFunds(
 			for _, asset := range getAssetsOfOutputs(outputs) {
 				addr, script, blindkey, err :=
 					v.DeriveNextInternalAddressForAccount(
+						o.signer,
 						market.AccountIndex,
 					)
 				if err != nil {
@@ -778,7 +1610,7 @@ func (o *operatorService) WithdrawMarketFunds(
 			}
 
 			feeAddress, script, feeBlindkey, err :=
-				v.DeriveNextInternalAddressForAccount(domain.FeeAccount)
+				v.DeriveNextInternalAddressForAccount(o.signer, domain.FeeAccount)
 			if err != nil {
 				return nil, err
 			}
@@ -794,26 +1626,27 @@ func (o *operatorService) WithdrawMarketFunds(
 				},
 			)
 
-			txHex, _, err := sendToMany(sendToManyOpts{
-				mnemonic:              mnemonic,
-				unspents:              marketUnspents,
-				feeUnspents:           feeUnspents,
-				outputs:               outputs,
-				outputsBlindingKeys:   outputsBlindingKeys,
-				changePathsByAsset:    changePathsByAsset,
-				feeChangePathByAsset:  feeChangePathByAsset,
-				inputPathsByScript:    marketAccount.DerivationPathByScript,
-				feeInputPathsByScript: feeAccount.DerivationPathByScript,
-				milliSatPerByte:       int(req.MillisatPerByte),
+			txHex, txid, err := o.signer.SendToMany(domain.SendToManyOpts{
+				Unspents:              marketUnspents,
+				FeeUnspents:           feeUnspents,
+				Outputs:               outputs,
+				OutputsBlindingKeys:   outputsBlindingKeys,
+				ChangePathsByAsset:    changePathsByAsset,
+				FeeChangePathByAsset:  feeChangePathByAsset,
+				InputPathsByScript:    marketAccount.DerivationPathByScript,
+				FeeInputPathsByScript: feeAccount.DerivationPathByScript,
+				MilliSatPerByte:       int(req.MillisatPerByte),
 			})
 			if err != nil {
 				return nil, err
 			}
+			signedTxID = txid
 
 			if req.Push {
 				if _, err := o.explorerSvc.BroadcastTransaction(txHex); err != nil {
 					return nil, err
 				}
+				txID = txid
 			}
 
 			rawTx, _ = hex.DecodeString(txHex)
@@ -825,6 +1658,39 @@ func (o *operatorService) WithdrawMarketFunds(
 		return nil, err
 	}
 
+	if err := o.withdrawalPolicy.record(
+		ctx,
+		signedTxID,
+		market.AccountIndex,
+		outs,
+		req.MillisatPerByte,
+		req.Push,
+	); err != nil {
+		return nil, err
+	}
+
+	// keep track of the withdrawal only if the tx has been broadcasted
+	if len(txID) > 0 {
+		if _, err := o.withdrawalRepository.AddWithdrawals(
+			ctx,
+			[]domain.Withdrawal{
+				{
+					TxID:            txID,
+					AccountIndex:    market.AccountIndex,
+					BaseAsset:       req.BaseAsset,
+					BaseAmount:      uint64(req.BalanceToWithdraw.BaseAmount),
+					QuoteAsset:      req.QuoteAsset,
+					QuoteAmount:     uint64(req.BalanceToWithdraw.QuoteAmount),
+					MillisatPerByte: req.MillisatPerByte,
+					Address:         req.Address,
+					Timestamp:       uint64(time.Now().Unix()),
+				},
+			},
+		); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, obs := range addressesToObserve {
 		o.crawlerSvc.AddObservable(obs)
 	}
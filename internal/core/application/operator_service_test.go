@@ -2,18 +2,22 @@ package application
 
 import (
 	"errors"
-	"strings"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/tdex-network/tdex-daemon/internal/infrastructure/storage/db/inmemory"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
 	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
 	"github.com/vulpemventures/go-elements/network"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -45,9 +49,17 @@ func TestListMarket(t *testing.T) {
 }
 
 func TestDepositMarket(t *testing.T) {
-	operatorService, ctx, close := newTestOperator(marketRepoIsEmpty, tradeRepoIsEmpty, vaultRepoIsEmpty)
+	operatorService, _, walletService, ctx, close, _ := newMockServices(
+		marketRepoIsEmpty,
+		tradeRepoIsEmpty,
+		vaultRepoIsEmpty,
+		true,
+		false,
+	)
 
-	config.Set(config.MnemonicKey, strings.Join(newTradeWallet().mnemonic, " "))
+	if err := walletService.UnlockWallet(ctx, newTradeWallet().password); err != nil {
+		t.Fatal(err)
+	}
 
 	t.Run("DepositMarket with new market", func(t *testing.T) {
 		address, err := operatorService.DepositMarket(ctx, "", "")
@@ -704,11 +716,46 @@ func TestUpdateMarketStrategy(t *testing.T) {
 		assert.Equal(t, domain.StrategyTypeBalanced, strategy)
 	})
 
-	t.Run("should return an error if the new strategy is not supported", func(t *testing.T) {
+	t.Run("should update the strategy to UNBALANCED", func(t *testing.T) {
 		err, failErr := updateMarketStrategy(domain.StrategyTypeUnbalanced, validMarket, letsCloseTheMarketBefore)
 		if failErr != nil {
 			t.Error(failErr)
 		}
+		strategy, failErr := getMarketStrategy()
+		if failErr != nil {
+			t.Error(failErr)
+		}
+		assert.Equal(t, nil, err)
+		assert.Equal(t, domain.StrategyTypeUnbalanced, strategy)
+	})
+
+	t.Run("should return an error if the base asset weight is not valid", func(t *testing.T) {
+		err := operatorService.CloseMarket(ctx, validMarket.BaseAsset, validMarket.QuoteAsset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := operatorService.OpenMarket(ctx, validMarket.BaseAsset, validMarket.QuoteAsset); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		err = operatorService.UpdateMarketStrategy(
+			ctx,
+			MarketStrategy{
+				Market:          validMarket,
+				Strategy:        domain.StrategyTypeUnbalanced,
+				BaseAssetWeight: 100,
+			},
+		)
+		assert.True(t, errors.Is(err, domain.ErrInvalidBaseAssetWeight))
+	})
+
+	t.Run("should return an error if the new strategy is not supported", func(t *testing.T) {
+		err, failErr := updateMarketStrategy(domain.StrategyType(3), validMarket, letsCloseTheMarketBefore)
+		if failErr != nil {
+			t.Error(failErr)
+		}
 		assert.NotEqual(t, nil, err)
 	})
 
@@ -772,3 +819,398 @@ func TestUpdateMarketStrategy(t *testing.T) {
 		assert.NotEqual(t, nil, err)
 	})
 }
+
+func TestExport(t *testing.T) {
+	operatorService, _, _, ctx, close, dbManager := newMockServices(
+		!marketRepoIsEmpty,
+		tradeRepoIsEmpty,
+		vaultRepoIsEmpty,
+		unspentRepoIsEmpty,
+		false,
+	)
+	defer close()
+
+	depositRepository := inmemory.NewDepositRepositoryImpl(dbManager)
+	if _, err := depositRepository.AddDeposits(ctx, []domain.Deposit{
+		{AccountIndex: 5, TxID: "txid1", VOut: 0, Asset: baseAsset, Value: 100, Timestamp: 10},
+		{AccountIndex: 5, TxID: "txid2", VOut: 1, Asset: baseAsset, Value: 200, Timestamp: 20},
+		{AccountIndex: 5, TxID: "txid4", VOut: 0, Asset: baseAsset, Value: 300, Timestamp: 40},
+		// unconfirmed deposits are not exported
+		{AccountIndex: 5, TxID: "txid5", VOut: 0, Asset: baseAsset, Value: 400},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	withdrawalRepository := inmemory.NewWithdrawalRepositoryImpl(dbManager)
+	if _, err := withdrawalRepository.AddWithdrawals(ctx, []domain.Withdrawal{
+		{
+			TxID:        "txid3",
+			BaseAsset:   baseAsset,
+			BaseAmount:  50,
+			QuoteAsset:  marketUnspents[1].AssetHash,
+			QuoteAmount: 70,
+			Timestamp:   30,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		req           ExportReq
+		expectedKinds []string
+	}{
+		{
+			"export all",
+			ExportReq{},
+			[]string{
+				ExportKindDeposit,
+				ExportKindDeposit,
+				ExportKindWithdrawal,
+				ExportKindWithdrawal,
+				ExportKindDeposit,
+			},
+		},
+		{
+			"export time range",
+			ExportReq{FromTime: 15, ToTime: 25},
+			[]string{ExportKindDeposit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kinds := make([]string, 0)
+			err := operatorService.Export(ctx, tt.req, func(r ExportRecord) error {
+				kinds = append(kinds, r.Kind)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedKinds, kinds)
+		})
+	}
+
+	err := operatorService.Export(
+		ctx,
+		ExportReq{FromTime: 20, ToTime: 10},
+		func(r ExportRecord) error { return nil },
+	)
+	assert.Equal(t, ErrInvalidTimeRange, err)
+}
+
+func TestExportMultiplePages(t *testing.T) {
+	operatorService, _, _, ctx, close, dbManager := newMockServices(
+		!marketRepoIsEmpty,
+		tradeRepoIsEmpty,
+		vaultRepoIsEmpty,
+		unspentRepoIsEmpty,
+		false,
+	)
+	defer close()
+
+	// deposits and withdrawals alternate in time and span multiple pages
+	numOfRecords := 2*exportPageSize + 10
+	deposits := make([]domain.Deposit, 0, numOfRecords)
+	withdrawals := make([]domain.Withdrawal, 0, numOfRecords)
+	for i := 0; i < numOfRecords; i++ {
+		deposits = append(deposits, domain.Deposit{
+			AccountIndex: 5,
+			TxID:         fmt.Sprintf("deposit%d", i),
+			Asset:        baseAsset,
+			Value:        100,
+			Timestamp:    uint64(2*i + 1),
+		})
+		withdrawals = append(withdrawals, domain.Withdrawal{
+			TxID:       fmt.Sprintf("withdrawal%d", i),
+			BaseAsset:  baseAsset,
+			BaseAmount: 50,
+			Timestamp:  uint64(2*i + 2),
+		})
+	}
+	if _, err := inmemory.NewDepositRepositoryImpl(dbManager).
+		AddDeposits(ctx, deposits); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inmemory.NewWithdrawalRepositoryImpl(dbManager).
+		AddWithdrawals(ctx, withdrawals); err != nil {
+		t.Fatal(err)
+	}
+
+	records := make([]ExportRecord, 0)
+	err := operatorService.Export(ctx, ExportReq{}, func(r ExportRecord) error {
+		records = append(records, r)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 2*numOfRecords)
+	for i, r := range records {
+		assert.Equal(t, uint64(i+1), r.Timestamp)
+	}
+}
+
+func TestTradeToExportRecord(t *testing.T) {
+	quoteAsset := marketUnspents[1].AssetHash
+	swapRequest := &pbswap.SwapRequest{
+		Id:      "swapid",
+		AssetP:  baseAsset,
+		AmountP: 100000000,
+		AssetR:  quoteAsset,
+		AmountR: 650000000000,
+	}
+	msg, _ := proto.Marshal(swapRequest)
+
+	trade := domain.NewTrade()
+	trade.Status = domain.CompletedStatus
+	trade.MarketQuoteAsset = quoteAsset
+	trade.MarketFee = 25
+	trade.MarketFeeAsset = baseAsset
+	trade.TxID = "txid"
+	trade.SwapRequest = domain.Swap{ID: swapRequest.GetId(), Message: msg}
+	trade.Timestamp = domain.Timestamp{Request: 1, Accept: 2}
+
+	record := tradeToExportRecord(trade, 5)
+
+	assert.Equal(t, ExportKindTrade, record.Kind)
+	assert.Equal(t, uint64(2), record.Timestamp)
+	assert.Equal(t, baseAsset, record.AssetIn)
+	assert.Equal(t, uint64(100000000), record.AmountIn)
+	assert.Equal(t, quoteAsset, record.AssetOut)
+	assert.Equal(t, uint64(650000000000), record.AmountOut)
+	assert.Equal(t, "6500", record.Price.String())
+	assert.Equal(t, uint64(250000), record.FeeAmount)
+	assert.Equal(t, "txid", record.TxID)
+}
+
+func TestGetMarketReport(t *testing.T) {
+	operatorService, _, _, ctx, close, dbManager := newMockServices(
+		!marketRepoIsEmpty,
+		tradeRepoIsEmpty,
+		!vaultRepoIsEmpty,
+		!unspentRepoIsEmpty,
+		false,
+	)
+	defer close()
+
+	quoteAsset := marketUnspents[1].AssetHash
+	market := Market{BaseAsset: baseAsset, QuoteAsset: quoteAsset}
+
+	swapRequest := &pbswap.SwapRequest{
+		Id:      "swapid",
+		AssetP:  baseAsset,
+		AmountP: 1000000,
+		AssetR:  quoteAsset,
+		AmountR: 6400000000,
+	}
+	msg, _ := proto.Marshal(swapRequest)
+	tradeRepository := inmemory.NewTradeRepositoryImpl(dbManager)
+	if err := tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			trade.Status = domain.CompletedStatus
+			trade.MarketQuoteAsset = quoteAsset
+			trade.MarketFee = 25
+			trade.MarketFeeAsset = baseAsset
+			trade.SwapRequest = domain.Swap{ID: swapRequest.GetId(), Message: msg}
+			trade.Timestamp = domain.Timestamp{Request: 99, Accept: 100}
+			return trade, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	depositRepository := inmemory.NewDepositRepositoryImpl(dbManager)
+	if _, err := depositRepository.AddDeposits(ctx, []domain.Deposit{
+		{
+			AccountIndex: domain.MarketAccountStart,
+			TxID:         "txid1",
+			Asset:        baseAsset,
+			Value:        10000000,
+			Timestamp:    50,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	withdrawalRepository := inmemory.NewWithdrawalRepositoryImpl(dbManager)
+	if _, err := withdrawalRepository.AddWithdrawals(ctx, []domain.Withdrawal{
+		{
+			TxID:         "txid2",
+			AccountIndex: domain.MarketAccountStart,
+			BaseAsset:    baseAsset,
+			QuoteAsset:   quoteAsset,
+			QuoteAmount:  1000000000,
+			Timestamp:    200,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := operatorService.GetMarketReport(ctx, MarketReportReq{
+		Market:   market,
+		FromTime: 60,
+		ToTime:   150,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Balance{100000000, 650000000000}, report.CurrentBalance)
+	assert.Equal(t, Balance{100000000, 651000000000}, report.EndBalance)
+	assert.Equal(t, Balance{99000000, 657400000000}, report.StartBalance)
+	assert.Equal(t, 1, report.TradesCount)
+	assert.Equal(t, Balance{1000000, 6400000000}, report.Volume)
+	assert.Equal(t, Balance{2500, 0}, report.CollectedFees)
+	assert.Equal(t, "6510", report.EndPrice.QuotePrice.String())
+	assert.Equal(t, "6500", report.CurrentPrice.QuotePrice.String())
+	assert.Equal(t, int64(200000000), report.TradedValue)
+	assert.Equal(t, int64(200000000), report.InventoryValue)
+	assert.Equal(
+		t,
+		report.HoldValue-report.TradedValue+report.CollectedFeesValue,
+		report.ImpermanentLoss,
+	)
+
+	_, err = operatorService.GetMarketReport(ctx, MarketReportReq{
+		Market:   market,
+		FromTime: 150,
+		ToTime:   60,
+	})
+	assert.Equal(t, ErrInvalidTimeRange, err)
+
+	_, err = operatorService.GetMarketReport(ctx, MarketReportReq{
+		Market: Market{BaseAsset: baseAsset, QuoteAsset: "unknown"},
+	})
+	assert.Error(t, err)
+}
+
+func TestTradeToMarketFlowMultiAsset(t *testing.T) {
+	market := &domain.Market{BaseAsset: baseAsset, QuoteAsset: "quoteAsset2"}
+	trade := &domain.Trade{
+		MarketQuoteAsset: "quoteAsset1",
+		MarketFee:        100,
+		MarketFeeAsset:   "quoteAsset1",
+		LegsP:            []pkgswap.Leg{{Asset: "quoteAsset1", Amount: 100}},
+		LegsR:            []pkgswap.Leg{{Asset: "quoteAsset2", Amount: 6400000000}},
+		MarketTrades: []domain.MarketTrade{
+			{QuoteAsset: "quoteAsset1", QuoteAmount: 100, BaseAmount: 1000000},
+			{
+				QuoteAsset:  "quoteAsset2",
+				QuoteAmount: 6400000000,
+				BaseAmount:  1000000,
+				IsQuoteSent: true,
+				Fee:         25,
+				FeeAsset:    baseAsset,
+			},
+		},
+		Timestamp: domain.Timestamp{Request: 99, Accept: 100},
+	}
+
+	// the flow is that of the part of the trade settled by the given market
+	flow := tradeToMarketFlow(trade, market)
+	assert.Equal(t, Balance{1000000, -6400000000}, flow.change)
+	assert.Equal(t, Balance{1000000, 6400000000}, flow.volume)
+	assert.Equal(t, Balance{2500, 0}, flow.fee)
+}
+
+func TestMarketTradeToExportRecord(t *testing.T) {
+	trade := &domain.Trade{
+		MarketQuoteAsset: "quoteAsset1",
+		TxID:             "txid",
+		MarketTrades: []domain.MarketTrade{
+			{
+				QuoteAsset:  "quoteAsset1",
+				QuoteAmount: 100,
+				BaseAmount:  1000000,
+				Fee:         100,
+				FeeAsset:    "quoteAsset1",
+			},
+			{
+				QuoteAsset:  "quoteAsset2",
+				QuoteAmount: 6400000000,
+				BaseAmount:  1000000,
+				IsQuoteSent: true,
+				Fee:         25,
+				FeeAsset:    baseAsset,
+			},
+		},
+		Timestamp: domain.Timestamp{Request: 99, Accept: 100},
+	}
+
+	// every market has a record of the part of the trade it settled
+	record := marketTradeToExportRecord(trade, trade.MarketTrades[0], 5)
+	assert.Equal(t, ExportKindTrade, record.Kind)
+	assert.Equal(t, 5, record.AccountIndex)
+	assert.Equal(t, "quoteAsset1", record.QuoteAsset)
+	assert.Equal(t, "quoteAsset1", record.AssetIn)
+	assert.Equal(t, uint64(100), record.AmountIn)
+	assert.Equal(t, baseAsset, record.AssetOut)
+	assert.Equal(t, uint64(1000000), record.AmountOut)
+	assert.Equal(t, "0.0001", record.Price.String())
+	assert.Equal(t, "quoteAsset1", record.FeeAsset)
+	assert.Equal(t, uint64(1), record.FeeAmount)
+	assert.Equal(t, "txid", record.TxID)
+
+	record = marketTradeToExportRecord(trade, trade.MarketTrades[1], 6)
+	assert.Equal(t, 6, record.AccountIndex)
+	assert.Equal(t, "quoteAsset2", record.QuoteAsset)
+	assert.Equal(t, baseAsset, record.AssetIn)
+	assert.Equal(t, uint64(1000000), record.AmountIn)
+	assert.Equal(t, "quoteAsset2", record.AssetOut)
+	assert.Equal(t, uint64(6400000000), record.AmountOut)
+	assert.Equal(t, "6400", record.Price.String())
+	assert.Equal(t, baseAsset, record.FeeAsset)
+	assert.Equal(t, uint64(2500), record.FeeAmount)
+}
+
+func TestWithdrawalAddresses(t *testing.T) {
+	operatorService, ctx, close := newTestOperator(
+		marketRepoIsEmpty,
+		tradeRepoIsEmpty,
+		vaultRepoIsEmpty,
+	)
+	defer close()
+
+	addr := newTestAddress(t)
+	_, err := operatorService.AddWithdrawalAddress(ctx, AddWithdrawalAddressReq{
+		Address:    addr,
+		Passphrase: "wrongPass",
+	})
+	assert.Equal(t, domain.ErrInvalidPassphrase, err)
+
+	_, err = operatorService.AddWithdrawalAddress(ctx, AddWithdrawalAddressReq{
+		Address:    "not an address",
+		Passphrase: "Sup3rS3cr3tP4ssw0rd!",
+	})
+	assert.Equal(t, domain.ErrInvalidWithdrawalAddress, err)
+
+	withdrawalAddress, err := operatorService.AddWithdrawalAddress(
+		ctx,
+		AddWithdrawalAddressReq{
+			Address:    addr,
+			Label:      "cold storage",
+			Passphrase: "Sup3rS3cr3tP4ssw0rd!",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, addr, withdrawalAddress.Address)
+	assert.Equal(t, "cold storage", withdrawalAddress.Label)
+	assert.Equal(
+		t,
+		withdrawalAddress.CreatedAt+uint64(config.GetInt(config.WithdrawalAddressCoolDownKey)),
+		withdrawalAddress.ActiveFrom,
+	)
+
+	addresses, err := operatorService.ListWithdrawalAddresses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(whitelistedAddresses)+1, len(addresses))
+
+	err = operatorService.RemoveWithdrawalAddress(ctx, addr)
+	assert.NoError(t, err)
+	err = operatorService.RemoveWithdrawalAddress(ctx, addr)
+	assert.Equal(t, domain.ErrWithdrawalAddressNotFound, err)
+}
@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/config"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+)
+
+// quoteTerms are the terms of a quote, encoded in its ID along with their
+// signature so that the daemon doesn't need to store the quotes it issues
+type quoteTerms struct {
+	QuoteAsset string `json:"quote_asset"`
+	TradeType  int    `json:"trade_type"`
+	// Amount is the amount of base asset to buy or sell
+	Amount uint64 `json:"amount"`
+	// PreviewAmount is the amount of quote asset to send or receive
+	PreviewAmount uint64 `json:"preview_amount"`
+	ExpiryTime    uint64 `json:"expiry_time"`
+	// Nonce makes the ID of every quote unique
+	Nonce string `json:"nonce"`
+}
+
+// matches returns whether the given trade proposal respects the terms of the
+// quote
+func (q *quoteTerms) matches(
+	market Market,
+	tradeType int,
+	swapRequest *pb.SwapRequest,
+) bool {
+	amount, previewAmount := swapRequest.GetAmountR(), swapRequest.GetAmountP()
+	if tradeType == TradeSell {
+		amount, previewAmount = swapRequest.GetAmountP(), swapRequest.GetAmountR()
+	}
+	return q.QuoteAsset == market.QuoteAsset &&
+		q.TradeType == tradeType &&
+		q.Amount == amount &&
+		q.PreviewAmount == previewAmount
+}
+
+// quoteBook issues signed quotes and keeps track of those already used until
+// they expire. The signing key is random and not persisted, therefore quotes
+// don't survive a restart of the daemon.
+type quoteBook struct {
+	key        []byte
+	usedQuotes map[string]uint64
+	lock       *sync.Mutex
+}
+
+func newQuoteBook() *quoteBook {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &quoteBook{
+		key:        key,
+		usedQuotes: map[string]uint64{},
+		lock:       &sync.Mutex{},
+	}
+}
+
+// newQuoteID returns the ID of a quote with the given terms, made of the
+// serialized terms and their signature
+func (b *quoteBook) newQuoteID(terms quoteTerms) (string, error) {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	terms.Nonce = hex.EncodeToString(nonce)
+
+	payload, err := json.Marshal(terms)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(b.sign(payload)), nil
+}
+
+// redeemQuote verifies the given quote ID and returns its terms if the given
+// trade proposal matches them. A quote can be redeemed only once and before
+// its expiry time, and it's marked as used only if the proposal matches it,
+// so that a wrong proposal doesn't burn it.
+func (b *quoteBook) redeemQuote(
+	quoteID string,
+	market Market,
+	tradeType int,
+	swapRequest *pb.SwapRequest,
+) (*quoteTerms, error) {
+	parts := strings.Split(quoteID, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidQuote
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidQuote
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidQuote
+	}
+	if !hmac.Equal(signature, b.sign(payload)) {
+		return nil, ErrInvalidQuote
+	}
+
+	terms := &quoteTerms{}
+	if err := json.Unmarshal(payload, terms); err != nil {
+		return nil, ErrInvalidQuote
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := uint64(time.Now().Unix())
+	for id, expiryTime := range b.usedQuotes {
+		if now >= expiryTime {
+			delete(b.usedQuotes, id)
+		}
+	}
+	if now >= terms.ExpiryTime {
+		return nil, ErrQuoteExpired
+	}
+	if _, ok := b.usedQuotes[quoteID]; ok {
+		return nil, ErrQuoteAlreadyUsed
+	}
+	if !terms.matches(market, tradeType, swapRequest) {
+		return nil, ErrQuoteMismatch
+	}
+	b.usedQuotes[quoteID] = terms.ExpiryTime
+
+	return terms, nil
+}
+
+func (b *quoteBook) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// GetMarketQuote is the domain controller for the Quote RPC. It works like
+// GetMarketPrice, but the returned preview is honoured by TradeProposeWithQuote
+// until the quote expires.
+func (t *tradeService) GetMarketQuote(
+	ctx context.Context,
+	market Market,
+	tradeType int,
+	amount uint64,
+) (*Quote, error) {
+	price, err := t.GetMarketPrice(ctx, market, tradeType, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	expiryTime := uint64(time.Now().Unix()) +
+		uint64(config.GetInt(config.QuoteExpiryTimeKey))
+	quoteID, err := t.quotes.newQuoteID(quoteTerms{
+		QuoteAsset:    market.QuoteAsset,
+		TradeType:     tradeType,
+		Amount:        amount,
+		PreviewAmount: price.Amount,
+		ExpiryTime:    expiryTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Quote{
+		PriceWithFee: *price,
+		ID:           quoteID,
+		ExpiryTime:   expiryTime,
+	}, nil
+}
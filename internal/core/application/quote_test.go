@@ -0,0 +1,90 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+)
+
+func TestQuoteBook(t *testing.T) {
+	book := newQuoteBook()
+	market := Market{
+		BaseAsset:  "5ac9f65c0efcc4775e0baec4ec03abdde22473cd3cf33c0419ca290e0751b225",
+		QuoteAsset: "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3",
+	}
+	terms := quoteTerms{
+		QuoteAsset:    market.QuoteAsset,
+		TradeType:     TradeBuy,
+		Amount:        1000,
+		PreviewAmount: 6500000,
+		ExpiryTime:    uint64(time.Now().Unix()) + 10,
+	}
+
+	quoteID, err := book.newQuoteID(terms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherQuoteID, err := book.newQuoteID(terms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, quoteID, otherQuoteID)
+
+	swapRequest := &pb.SwapRequest{AmountP: 6500000, AmountR: 1000}
+
+	// a proposal not matching the quote doesn't burn it
+	_, err = book.redeemQuote(quoteID, market, TradeBuy, &pb.SwapRequest{
+		AmountP: 6499999,
+		AmountR: 1000,
+	})
+	assert.Equal(t, ErrQuoteMismatch, err)
+	_, err = book.redeemQuote(quoteID, market, TradeSell, &pb.SwapRequest{
+		AmountP: 1000,
+		AmountR: 6500000,
+	})
+	assert.Equal(t, ErrQuoteMismatch, err)
+
+	redeemedTerms, err := book.redeemQuote(quoteID, market, TradeBuy, swapRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, terms.PreviewAmount, redeemedTerms.PreviewAmount)
+
+	// a quote can be redeemed only once
+	_, err = book.redeemQuote(quoteID, market, TradeBuy, swapRequest)
+	assert.Equal(t, ErrQuoteAlreadyUsed, err)
+}
+
+func TestFailingRedeemQuote(t *testing.T) {
+	book := newQuoteBook()
+	expiredQuoteID, err := book.newQuoteID(quoteTerms{
+		ExpiryTime: uint64(time.Now().Unix()) - 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// quotes issued by another daemon are not valid
+	foreignQuoteID, err := newQuoteBook().newQuoteID(quoteTerms{
+		ExpiryTime: uint64(time.Now().Unix()) + 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		quoteID string
+		err     error
+	}{
+		{"", ErrInvalidQuote},
+		{"not.a.quote", ErrInvalidQuote},
+		{foreignQuoteID, ErrInvalidQuote},
+		{expiredQuoteID, ErrQuoteExpired},
+	}
+
+	for _, tt := range tests {
+		_, err := book.redeemQuote(tt.quoteID, Market{}, TradeBuy, &pb.SwapRequest{})
+		assert.Equal(t, tt.err, err)
+	}
+}
@@ -7,9 +7,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/btcsuite/btcutil"
 	"github.com/shopspring/decimal"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/keystore"
 	"github.com/tdex-network/tdex-daemon/internal/infrastructure/storage/db/inmemory"
 	"github.com/tdex-network/tdex-daemon/pkg/crawler"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
@@ -93,6 +93,11 @@ func newMockServices(
 		}
 	}
 
+	depositRepo := inmemory.NewDepositRepositoryImpl(dbManager)
+	withdrawalRepo := inmemory.NewWithdrawalRepositoryImpl(dbManager)
+	withdrawalAddressRepo := newTestWithdrawalAddressRepo(ctx, dbManager)
+	paymentRepo := inmemory.NewPaymentRepositoryImpl(dbManager)
+
 	// create services associated with mocked repo
 	explorerSvc := explorer.NewService(RegtestExplorerAPI)
 	crawlerSvc := crawler.NewService(crawler.Opts{
@@ -102,11 +107,16 @@ func newMockServices(
 		IntervalInMilliseconds: 100,
 	})
 
+	// the keystore is shared by all services, like in the daemon
+	ks := keystore.NewKeystore(0)
 	walletSvc := newWalletService(
 		vaultRepo,
 		unspentRepo,
+		withdrawalAddressRepo,
+		paymentRepo,
 		crawlerSvc,
 		explorerSvc,
+		ks,
 	)
 
 	if !vaultRepositoryIsEmpty {
@@ -115,15 +125,15 @@ func newMockServices(
 		}
 
 		err := vaultRepo.UpdateVault(ctx, nil, "", func(v *domain.Vault) (*domain.Vault, error) {
-			_, _, _, err := v.DeriveNextExternalAddressForAccount(domain.FeeAccount)
+			_, _, _, err := v.DeriveNextExternalAddressForAccount(ks, domain.FeeAccount)
 			if err != nil {
 				return nil, err
 			}
-			_, _, _, err = v.DeriveNextExternalAddressForAccount(domain.MarketAccountStart)
+			_, _, _, err = v.DeriveNextExternalAddressForAccount(ks, domain.MarketAccountStart)
 			if err != nil {
 				return nil, err
 			}
-			_, _, _, err = v.DeriveNextExternalAddressForAccount(domain.MarketAccountStart + 1)
+			_, _, _, err = v.DeriveNextExternalAddressForAccount(ks, domain.MarketAccountStart + 1)
 			if err != nil {
 				return nil, err
 			}
@@ -140,9 +150,12 @@ func newMockServices(
 		unspentRepo,
 		marketRepo,
 		vaultRepo,
+		tradeRepo,
+		depositRepo,
 		crawlerSvc,
 		explorerSvc,
 		dbManager,
+		nil,
 	)
 	// observe the blockchain
 	blockchainListener.ObserveBlockchain()
@@ -155,6 +168,7 @@ func newMockServices(
 		unspentRepo,
 		explorerSvc,
 		crawlerSvc,
+		ks,
 	)
 
 	operatorSvc := NewOperatorService(
@@ -162,8 +176,13 @@ func newMockServices(
 		vaultRepo,
 		tradeRepo,
 		unspentRepo,
+		depositRepo,
+		withdrawalRepo,
+		withdrawalAddressRepo,
+		paymentRepo,
 		explorerSvc,
 		crawlerSvc,
+		ks,
 	)
 
 	close := func() {
@@ -213,6 +232,8 @@ func newTestWallet(w *mockedWallet) (*walletService, context.Context, func()) {
 	marketRepo := inmemory.NewMarketRepositoryImpl(dbManager)
 	vaultRepo := newMockedVaultRepositoryImpl(*w)
 	unspentRepo := inmemory.NewUnspentRepositoryImpl(dbManager)
+	tradeRepo := inmemory.NewTradeRepositoryImpl(dbManager)
+	depositRepo := inmemory.NewDepositRepositoryImpl(dbManager)
 	explorerSvc := explorer.NewService(RegtestExplorerAPI)
 	crawlerSvc := crawler.NewService(crawler.Opts{
 		ExplorerSvc:            explorerSvc,
@@ -225,22 +246,28 @@ func newTestWallet(w *mockedWallet) (*walletService, context.Context, func()) {
 		unspentRepo,
 		marketRepo,
 		vaultRepo,
+		tradeRepo,
+		depositRepo,
 		crawlerSvc,
 		explorerSvc,
 		dbManager,
+		nil,
 	)
 	// observe the blockchain
 	blockchainListener.ObserveBlockchain()
 
+	ctx := context.Background()
+
 	walletSvc := newWalletService(
 		vaultRepo,
 		unspentRepo,
+		newTestWithdrawalAddressRepo(ctx, dbManager),
+		inmemory.NewPaymentRepositoryImpl(dbManager),
 		crawlerSvc,
 		explorerSvc,
+		keystore.NewKeystore(0),
 	)
 
-	ctx := context.Background()
-
 	closeFn := func() {
 		blockchainListener.StopObserveBlockchain()
 	}
@@ -248,6 +275,25 @@ func newTestWallet(w *mockedWallet) (*walletService, context.Context, func()) {
 	return walletSvc, ctx, closeFn
 }
 
+// newTestWithdrawalAddressRepo returns a withdrawal address repository
+// whitelisting, without cool-down, the addresses paid by tests
+func newTestWithdrawalAddressRepo(
+	ctx context.Context,
+	dbManager *inmemory.DbManager,
+) domain.WithdrawalAddressRepository {
+	repo := inmemory.NewWithdrawalAddressRepositoryImpl(dbManager)
+	for _, addr := range whitelistedAddresses {
+		withdrawalAddress, err := domain.NewWithdrawalAddress(addr, "", 0)
+		if err != nil {
+			panic(err)
+		}
+		if err := repo.AddWithdrawalAddress(ctx, *withdrawalAddress); err != nil {
+			panic(err)
+		}
+	}
+	return repo
+}
+
 func fillMarketRepo(
 	ctx context.Context,
 	marketRepo *domain.MarketRepository,
@@ -573,7 +619,6 @@ func newMockedVaultRepositoryImpl(w mockedWallet) domain.VaultRepository {
 	return &mockedVaultRepository{
 		vault: &domain.Vault{
 			EncryptedMnemonic:      w.encryptedMnemonic,
-			PassphraseHash:         btcutil.Hash160([]byte(w.password)),
 			Accounts:               map[int]*domain.Account{},
 			AccountAndKeyByAddress: map[string]domain.AccountAndKey{},
 		},
@@ -594,6 +639,10 @@ func (r *mockedVaultRepository) GetOrCreateVault(ctx context.Context, mnemonic [
 	return r.vault, nil
 }
 
+func (r *mockedVaultRepository) GetOrCreateSealedVault(ctx context.Context) (*domain.Vault, error) {
+	return r.vault, nil
+}
+
 func (r *mockedVaultRepository) UpdateVault(
 	ctx context.Context,
 	mnemonic []string,
@@ -670,6 +719,13 @@ var (
 		encryptedMnemonic: "46OIUILJEmvmdb/BbaTOEjMM743D5TnfqLBhl9c+E/PSG+7miMCpP3maRNttCP3RF/jdJnbzG6KkAbcKGXJROpF9tSGV5oizjp07lRG85fQH8OSJajn515sclXlKjX2aaB76b3Vt3a94pIzeZrQ2g5c8voupYnL0TDAjLd1Iltl5ApKLuPf5WfEJtvZ5Klb4rF+cLlvIjPtdqFHIwjotB8fR0LGr9yw1hfduDOWe+DPyNCkgbtKBKe0qWjBnnng88eMdlD8bsanuEkoiDlyHDnIvZ+JwgYOOUw==",
 	}
 
+	whitelistedAddresses = []string{
+		"el1qq22f83p6asdy7jsp4tuke0d9emvxhcenqee5umsn88fsn8gggzlrx0md4hp38rnwcnu9lusmzhmktlt3h5q0gecfpfvx6uac2",
+		"el1qqf7z4k7tmarjcymzqtpy00chfl0qn0rx9f42ldw34l2teckh9csqumsc6s2k8nzpn8v5xyzd6pwxez0nlvt36338yzjrxptnk",
+		"CTEkZW2f7iixzWLkkoFeh85twpK7XYetyFmPqpQjCGdcEYUV1ZjyxqP6zc3qpBKEbdg6tjweJTC5yWrh",
+		"AzpnKwnveEJtDJNQVaTjcPNAJYDwWSYRMYaN2Y8crVFRBSZ4H2xM98WXy6seCR3mqCQFTRnJkfChFJpM",
+	}
+
 	feeUnspents = []domain.Unspent{
 		{
 			TxID:            "ee0d98a091e688959fdea2a2f0e9363bfee3d6d666cabe1294e6c9366804e127",
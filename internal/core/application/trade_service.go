@@ -4,21 +4,20 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
 	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
-	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
 	"github.com/tdex-network/tdex-daemon/pkg/crawler"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
 	mm "github.com/tdex-network/tdex-daemon/pkg/marketmaking"
 	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
-	"github.com/tdex-network/tdex-daemon/pkg/transactionutil"
-	"github.com/tdex-network/tdex-daemon/pkg/wallet"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
 	"github.com/vulpemventures/go-elements/address"
-	"github.com/vulpemventures/go-elements/pset"
+	"google.golang.org/protobuf/proto"
 )
 
 type TradeService interface {
@@ -29,17 +28,36 @@ type TradeService interface {
 		tradeType int,
 		amount uint64,
 	) (*PriceWithFee, error)
+	GetMarketQuote(
+		ctx context.Context,
+		market Market,
+		tradeType int,
+		amount uint64,
+	) (*Quote, error)
 	TradePropose(
 		ctx context.Context,
 		market Market,
 		tradeType int,
 		swapRequest *pb.SwapRequest,
 	) (*pb.SwapAccept, *pb.SwapFail, uint64, error)
+	TradeProposeWithQuote(
+		ctx context.Context,
+		market Market,
+		tradeType int,
+		swapRequest *pb.SwapRequest,
+		quoteID string,
+	) (*pb.SwapAccept, *pb.SwapFail, uint64, error)
+	TradeProposeMultiAsset(
+		ctx context.Context,
+		swapRequest *pb.SwapRequest,
+		legsP, legsR []pkgswap.Leg,
+	) (*pb.SwapAccept, *pb.SwapFail, uint64, error)
 	TradeComplete(
 		ctx context.Context,
 		swapComplete *pb.SwapComplete,
 		swapFail *pb.SwapFail,
 	) (string, *pb.SwapFail, error)
+	GetSwapStatus(ctx context.Context, swapID string) (*SwapStatus, error)
 	GetMarketBalance(
 		ctx context.Context,
 		market Market,
@@ -53,6 +71,8 @@ type tradeService struct {
 	unspentRepository domain.UnspentRepository
 	explorerSvc       explorer.Service
 	crawlerSvc        crawler.Service
+	signer            domain.Signer
+	quotes            *quoteBook
 }
 
 func NewTradeService(
@@ -62,6 +82,7 @@ func NewTradeService(
 	unspentRepository domain.UnspentRepository,
 	explorerSvc explorer.Service,
 	crawlerSvc crawler.Service,
+	signer domain.Signer,
 ) TradeService {
 	return newTradeService(
 		marketRepository,
@@ -70,6 +91,7 @@ func NewTradeService(
 		unspentRepository,
 		explorerSvc,
 		crawlerSvc,
+		signer,
 	)
 }
 
@@ -80,15 +102,32 @@ func newTradeService(
 	unspentRepository domain.UnspentRepository,
 	explorerSvc explorer.Service,
 	crawlerSvc crawler.Service,
+	signer domain.Signer,
 ) *tradeService {
-	return &tradeService{
+	t := &tradeService{
 		marketRepository:  marketRepository,
 		tradeRepository:   tradeRepository,
 		vaultRepository:   vaultRepository,
 		unspentRepository: unspentRepository,
 		explorerSvc:       explorerSvc,
 		crawlerSvc:        crawlerSvc,
+		signer:            signer,
+		quotes:            newQuoteBook(),
+	}
+	// the transactions of the trades completed but not yet settled when the
+	// daemon was last stopped must be observed again until they get confirmed
+	if trades, err := t.tradeRepository.GetAllTrades(
+		context.Background(),
+	); err == nil {
+		for _, trade := range trades {
+			if trade.IsCompleted() && !trade.IsSettled() && trade.TxID != "" {
+				t.crawlerSvc.AddObservable(&crawler.TransactionObservable{
+					TxID: trade.TxID,
+				})
+			}
+		}
 	}
+	return t
 }
 
 // Markets is the domain controller for the Markets RPC
@@ -182,12 +221,44 @@ func (t *tradeService) TradePropose(
 	market Market,
 	tradeType int,
 	swapRequest *pb.SwapRequest,
+) (*pb.SwapAccept, *pb.SwapFail, uint64, error) {
+	return t.tradePropose(ctx, market, tradeType, swapRequest, "")
+}
+
+// TradeProposeWithQuote works like TradePropose, but the proposal is accepted
+// only if it matches exactly the given quote, instead of being within the
+// price slippage of the current preview.
+func (t *tradeService) TradeProposeWithQuote(
+	ctx context.Context,
+	market Market,
+	tradeType int,
+	swapRequest *pb.SwapRequest,
+	quoteID string,
+) (*pb.SwapAccept, *pb.SwapFail, uint64, error) {
+	return t.tradePropose(ctx, market, tradeType, swapRequest, quoteID)
+}
+
+func (t *tradeService) tradePropose(
+	ctx context.Context,
+	market Market,
+	tradeType int,
+	swapRequest *pb.SwapRequest,
+	quoteID string,
 ) (
 	swapAccept *pb.SwapAccept,
 	swapFail *pb.SwapFail,
 	swapExpiryTime uint64,
 	err error,
 ) {
+	// a trader retrying a proposal gets the same replies, without deriving
+	// new addresses nor locking more unspents
+	if trade := t.getTradeBySwapRequest(ctx, swapRequest); trade != nil {
+		tradeLogger(ctx, trade.ID).Debug("replying to duplicated swap request")
+		swapAccept, swapFail, swapExpiryTime =
+			replyToDuplicatedSwapRequest(trade, swapRequest, nil, nil)
+		return
+	}
+
 	// check the asset strings
 	_err := validateAssetString(market.BaseAsset)
 	if _err != nil {
@@ -254,7 +325,16 @@ func (t *tradeService) TradePropose(
 		return
 	}
 
-	var mnemonic []string
+	// a proposal referencing a quote must match it exactly, no matter of the
+	// current preview
+	var quote *quoteTerms
+	var quoteErr error
+	if len(quoteID) > 0 {
+		quote, quoteErr = t.quotes.redeemQuote(
+			quoteID, market, tradeType, swapRequest,
+		)
+	}
+
 	var tradeID uuid.UUID
 	var selectedUnspents []explorer.Utxo
 	var outputBlindingKeysByScript map[string][]byte
@@ -271,22 +351,18 @@ func (t *tradeService) TradePropose(
 		nil,
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
-			mnemonic, err = v.GetMnemonicSafe()
-			if err != nil {
-				return nil, err
-			}
 			outputAddress, outputScript, outputBlindKey, err :=
-				v.DeriveNextExternalAddressForAccount(marketAccountIndex)
+				v.DeriveNextExternalAddressForAccount(t.signer, marketAccountIndex)
 			if err != nil {
 				return nil, err
 			}
 			changeAddress, changeScript, changeBlindKey, err :=
-				v.DeriveNextInternalAddressForAccount(marketAccountIndex)
+				v.DeriveNextInternalAddressForAccount(t.signer, marketAccountIndex)
 			if err != nil {
 				return nil, err
 			}
 			feeChangeAddress, feeChangeScript, feeChangeBlindKey, err :=
-				v.DeriveNextInternalAddressForAccount(domain.FeeAccount)
+				v.DeriveNextInternalAddressForAccount(t.signer, domain.FeeAccount)
 			if err != nil {
 				return nil, err
 			}
@@ -320,12 +396,25 @@ func (t *tradeService) TradePropose(
 			if err != nil {
 				return nil, err
 			}
+			tradeID = trade.ID
 			if !ok {
 				swapFail = trade.SwapFailMessage()
 				return trade, nil
 			}
 
-			if !isValidTradePrice(swapRequest, tradeType, previewAmount) {
+			if quoteErr != nil {
+				trade.Fail(
+					swapRequest.GetId(),
+					domain.ProposalRejectedStatus,
+					pkgswap.ErrCodeInvalidSwapRequest,
+					quoteErr.Error(),
+				)
+				swapFail = trade.SwapFailMessage()
+				return trade, nil
+			}
+
+			if quote == nil &&
+				!isValidTradePrice(swapRequest, tradeType, previewAmount) {
 				trade.Fail(
 					swapRequest.GetId(),
 					domain.ProposalRejectedStatus,
@@ -334,30 +423,36 @@ func (t *tradeService) TradePropose(
 				)
 				return trade, nil
 			}
-			tradeID = trade.ID
 
-			acceptSwapResult, err := acceptSwap(acceptSwapOpts{
-				mnemonic:                   mnemonic,
-				swapRequest:                swapRequest,
-				marketUnspents:             marketUtxos,
-				feeUnspents:                feeUtxos,
-				marketBlindingKeysByScript: marketBlindingKeysByScript,
-				feeBlindingKeysByScript:    feeBlindingKeysByScript,
-				outputBlindingKeysByScript: outputBlindingKeysByScript,
-				marketDerivationPaths:      marketDerivationPaths,
-				feeDerivationPaths:         feeDerivationPaths,
-				outputDerivationPath:       outputDerivationPath,
-				changeDerivationPath:       changeDerivationPath,
-				feeChangeDerivationPath:    feeChangeDerivationPath,
+			acceptSwapResult, err := t.signer.AcceptSwap(domain.AcceptSwapOpts{
+				SwapRequest: swapRequest,
+				MarketSwaps: []domain.MarketSwapOpts{
+					{
+						Unspents:             marketUtxos,
+						InputAsset:           swapRequest.GetAssetR(),
+						InputAmount:          swapRequest.GetAmountR(),
+						OutputAsset:          swapRequest.GetAssetP(),
+						OutputAmount:         swapRequest.GetAmountP(),
+						OutputDerivationPath: outputDerivationPath,
+						ChangeDerivationPath: changeDerivationPath,
+					},
+				},
+				FeeUnspents:                feeUtxos,
+				MarketBlindingKeysByScript: marketBlindingKeysByScript,
+				FeeBlindingKeysByScript:    feeBlindingKeysByScript,
+				OutputBlindingKeysByScript: outputBlindingKeysByScript,
+				MarketDerivationPaths:      marketDerivationPaths,
+				FeeDerivationPaths:         feeDerivationPaths,
+				FeeChangeDerivationPath:    feeChangeDerivationPath,
 			})
 			if err != nil {
 				return nil, err
 			}
 
 			ok, err = trade.Accept(
-				acceptSwapResult.psetBase64,
-				acceptSwapResult.inputBlindingKeys,
-				acceptSwapResult.outputBlindingKeys,
+				acceptSwapResult.PsetBase64,
+				acceptSwapResult.InputBlindingKeys,
+				acceptSwapResult.OutputBlindingKeys,
 			)
 			if err != nil {
 				return nil, err
@@ -367,7 +462,7 @@ func (t *tradeService) TradePropose(
 			} else {
 				swapAccept = trade.SwapAcceptMessage()
 				swapExpiryTime = trade.SwapExpiryTime()
-				selectedUnspents = acceptSwapResult.selectedUnspents
+				selectedUnspents = acceptSwapResult.SelectedUnspents
 			}
 
 			trade.MarketFee = mkt.Fee
@@ -375,8 +470,12 @@ func (t *tradeService) TradePropose(
 
 			return trade, nil
 		}); err != nil {
+		if errors.Is(err, domain.ErrDuplicatedSwapRequest) {
+			return t.replyToConcurrentSwapRequest(ctx, swapRequest, nil, nil)
+		}
 		return nil, nil, 0, err
 	}
+	logTradeProposal(ctx, tradeID, market.QuoteAsset, swapAccept, swapFail)
 
 	selectedUnspentKeys := getUnspentKeys(selectedUnspents)
 	if err := t.unspentRepository.LockUnspents(
@@ -424,7 +523,7 @@ func (t *tradeService) tradeComplete(ctx context.Context, swapComplete *pb.SwapC
 		&tradeID,
 		func(trade *domain.Trade) (*domain.Trade, error) {
 			psetBase64 := swapComplete.GetTransaction()
-			res, err := trade.Complete(psetBase64, txID)
+			res, err := trade.Complete(psetBase64)
 			if err != nil {
 				return nil, err
 			}
@@ -434,6 +533,9 @@ func (t *tradeService) tradeComplete(ctx context.Context, swapComplete *pb.SwapC
 			}
 
 			if _, err := t.explorerSvc.BroadcastTransaction(res.TxHex); err != nil {
+				tradeLogger(ctx, trade.ID).WithError(err).Warn(
+					"unable to broadcast trade transaction",
+				)
 				return nil, err
 			}
 
@@ -441,6 +543,19 @@ func (t *tradeService) tradeComplete(ctx context.Context, swapComplete *pb.SwapC
 			return trade, nil
 		},
 	)
+	if err != nil {
+		return
+	}
+
+	if swapFail != nil {
+		tradeLogger(ctx, tradeID).
+			WithField("reason", swapFail.GetFailureMessage()).
+			Info("trade completion rejected")
+	}
+	if txID != "" {
+		tradeLogger(ctx, tradeID).WithField("txid", txID).Info("trade completed")
+		t.crawlerSvc.AddObservable(&crawler.TransactionObservable{TxID: txID})
+	}
 	return
 }
 
@@ -468,6 +583,10 @@ func (t *tradeService) tradeFail(ctx context.Context, swapFail *pb.SwapFail) (*p
 	if err != nil {
 		return nil, err
 	}
+
+	tradeLogger(ctx, tradeID).
+		WithField("reason", swapFail.GetFailureMessage()).
+		Info("trade set failed by counter-party")
 	return swapFail, nil
 }
 
@@ -519,144 +638,107 @@ func (t *tradeService) getUnspentsBlindingsAndDerivationPathsForAccount(
 	return unspents, utxos, blindingKeysByScript, derivationPaths, nil
 }
 
-type acceptSwapOpts struct {
-	mnemonic                   []string
-	swapRequest                *pb.SwapRequest
-	marketUnspents             []explorer.Utxo
-	feeUnspents                []explorer.Utxo
-	marketBlindingKeysByScript map[string][]byte
-	feeBlindingKeysByScript    map[string][]byte
-	outputBlindingKeysByScript map[string][]byte
-	marketDerivationPaths      map[string]string
-	feeDerivationPaths         map[string]string
-	outputDerivationPath       string
-	changeDerivationPath       string
-	feeChangeDerivationPath    string
+// getTradeBySwapRequest returns the trade originated by a swap request with
+// the same id of the given one, if any
+func (t *tradeService) getTradeBySwapRequest(
+	ctx context.Context,
+	swapRequest *pb.SwapRequest,
+) *domain.Trade {
+	if len(swapRequest.GetId()) <= 0 {
+		return nil
+	}
+	trade, _ := t.tradeRepository.GetTradeBySwapRequestID(ctx, swapRequest.GetId())
+	return trade
 }
 
-type acceptSwapResult struct {
-	psetBase64         string
-	selectedUnspents   []explorer.Utxo
-	inputBlindingKeys  map[string][]byte
-	outputBlindingKeys map[string][]byte
+// replyToConcurrentSwapRequest returns the replies for a swap request whose
+// trade can't be stored because another one with the same id has been stored
+// in the meantime, as if it had been received after that
+func (t *tradeService) replyToConcurrentSwapRequest(
+	ctx context.Context,
+	swapRequest *pb.SwapRequest,
+	legsP, legsR []pkgswap.Leg,
+) (*pb.SwapAccept, *pb.SwapFail, uint64, error) {
+	trade := t.getTradeBySwapRequest(ctx, swapRequest)
+	if trade == nil {
+		return nil, nil, 0, domain.ErrDuplicatedSwapRequest
+	}
+	tradeLogger(ctx, trade.ID).Debug("replying to concurrent swap request")
+	swapAccept, swapFail, swapExpiryTime :=
+		replyToDuplicatedSwapRequest(trade, swapRequest, legsP, legsR)
+	return swapAccept, swapFail, swapExpiryTime, nil
 }
 
-func acceptSwap(opts acceptSwapOpts) (res acceptSwapResult, err error) {
-	w, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
-		SigningMnemonic: opts.mnemonic,
-	})
-	if err != nil {
-		return
-	}
-	network := config.GetNetwork()
-	// fill swap request transaction with daemon's inputs and outputs
-	psetBase64, selectedUnspentsForSwap, err := w.UpdateSwapTx(wallet.UpdateSwapTxOpts{
-		PsetBase64:           opts.swapRequest.GetTransaction(),
-		Unspents:             opts.marketUnspents,
-		InputAmount:          opts.swapRequest.GetAmountR(),
-		InputAsset:           opts.swapRequest.GetAssetR(),
-		OutputAmount:         opts.swapRequest.GetAmountP(),
-		OutputAsset:          opts.swapRequest.GetAssetP(),
-		OutputDerivationPath: opts.outputDerivationPath,
-		ChangeDerivationPath: opts.changeDerivationPath,
-		Network:              network,
-	})
-	if err != nil {
-		return
-	}
+// replyToDuplicatedSwapRequest returns the replies for a swap request whose
+// id has been already used for the given trade. If the request is the same
+// that originated the trade, its SwapAccept, or SwapFail, message is returned
+// again as long as the trade is not expired. Otherwise, a SwapFail message is
+// returned without affecting the trade.
+func replyToDuplicatedSwapRequest(
+	trade *domain.Trade,
+	swapRequest *pb.SwapRequest,
+	legsP, legsR []pkgswap.Leg,
+) (*pb.SwapAccept, *pb.SwapFail, uint64) {
+	if !trade.IsSameSwapRequest(swapRequest, legsP, legsR) {
+		return nil, newSwapFail(
+			swapRequest.GetId(),
+			pkgswap.ErrCodeInvalidSwapRequest,
+			"swap request id already used",
+		), 0
+	}
+	if trade.IsRejected() {
+		return nil, trade.SwapFailMessage(), 0
+	}
+	if trade.IsAccepted() && !trade.IsExpired() {
+		return trade.SwapAcceptMessage(), nil, trade.SwapExpiryTime()
+	}
+	return nil, newSwapFail(
+		swapRequest.GetId(),
+		pkgswap.ErrCodeRejectedSwapRequest,
+		"swap request already processed",
+	), 0
+}
 
-	// top-up fees using fee account. Note that the fee output is added after
-	// blinding the transaction because it's explicit and must not be blinded
-	psetWithFeesResult, err := w.UpdateTx(wallet.UpdateTxOpts{
-		PsetBase64:        psetBase64,
-		Unspents:          opts.feeUnspents,
-		MilliSatsPerBytes: domain.MinMilliSatPerByte,
-		Network:           network,
-		ChangePathsByAsset: map[string]string{
-			network.AssetID: opts.feeChangeDerivationPath,
-		},
-		WantPrivateBlindKeys: true,
-		WantChangeForFees:    true,
+func newSwapFail(
+	messageID string,
+	errCode pkgswap.ErrCode,
+	errMsg string,
+) *pb.SwapFail {
+	_, swapFailMsg, _ := pkgswap.Fail(pkgswap.FailOpts{
+		MessageID:  messageID,
+		ErrCode:    errCode,
+		ErrMessage: errMsg,
 	})
-	if err != nil {
-		return
-	}
-
-	// concat the selected unspents for paying fees with those for completing the
-	// swap in order to get the full list of selected inputs
-	selectedUnspents := append(selectedUnspentsForSwap, psetWithFeesResult.SelectedUnspents...)
-
-	// get blinding private keys for selected inputs
-	unspentsBlindingKeys := mergeBlindingKeys(opts.marketBlindingKeysByScript, opts.feeBlindingKeysByScript)
-	selectedInBlindingKeys := getSelectedBlindingKeys(unspentsBlindingKeys, selectedUnspents)
-	// ... and merge with those contained into the swapRequest (trader keys)
-	inputBlindingKeys := mergeBlindingKeys(opts.swapRequest.GetInputBlindingKey(), selectedInBlindingKeys)
-
-	// same for output  public blinding keys
-	outputBlindingKeys := mergeBlindingKeys(
-		opts.outputBlindingKeysByScript,
-		psetWithFeesResult.ChangeOutputsBlindingKeys,
-		opts.swapRequest.GetOutputBlindingKey(),
-	)
+	swapFail := &pb.SwapFail{}
+	proto.Unmarshal(swapFailMsg, swapFail)
+	return swapFail
+}
 
-	// blind the transaction
-	blindedPset, err := w.BlindSwapTransaction(wallet.BlindSwapTransactionOpts{
-		PsetBase64:         psetWithFeesResult.PsetBase64,
-		InputBlindingKeys:  inputBlindingKeys,
-		OutputBlindingKeys: outputBlindingKeys,
-	})
-	if err != nil {
-		return
-	}
+// tradeLogger returns the logger of the swap-related log lines of the given
+// trade, that carry its ID along with the one of the request being served
+func tradeLogger(ctx context.Context, tradeID uuid.UUID) *log.Entry {
+	return log.WithContext(ctx).WithField("trade_id", tradeID.String())
+}
 
-	// add the explicit fee output to the tx
-	blindedPlusFees, err := w.UpdateTx(wallet.UpdateTxOpts{
-		PsetBase64: blindedPset,
-		Outputs:    transactionutil.NewFeeOutput(psetWithFeesResult.FeeAmount),
-		Network:    network,
-	})
-	if err != nil {
+// logTradeProposal logs whether the proposal of the given trade has been
+// accepted or rejected
+func logTradeProposal(
+	ctx context.Context,
+	tradeID uuid.UUID,
+	market string,
+	swapAccept *pb.SwapAccept,
+	swapFail *pb.SwapFail,
+) {
+	logger := tradeLogger(ctx, tradeID).WithField("market", market)
+	if swapAccept != nil {
+		logger.Info("trade proposal accepted")
 		return
 	}
-
-	// get the indexes of the inputs of the tx to sign
-	inputsToSign := getInputsIndexes(psetWithFeesResult.PsetBase64, selectedUnspents)
-	// get the derivation paths of the selected inputs
-	unspentsDerivationPaths := mergeDerivationPaths(opts.marketDerivationPaths, opts.feeDerivationPaths)
-	derivationPaths := getSelectedDerivationPaths(unspentsDerivationPaths, selectedUnspents)
-
-	signedPsetBase64 := blindedPlusFees.PsetBase64
-	for i, inIndex := range inputsToSign {
-		signedPsetBase64, err = w.SignInput(wallet.SignInputOpts{
-			PsetBase64:     signedPsetBase64,
-			InIndex:        inIndex,
-			DerivationPath: derivationPaths[i],
-		})
-	}
-
-	res = acceptSwapResult{
-		psetBase64:         signedPsetBase64,
-		selectedUnspents:   selectedUnspents,
-		inputBlindingKeys:  inputBlindingKeys,
-		outputBlindingKeys: outputBlindingKeys,
-	}
-
-	return
-}
-
-func getInputsIndexes(psetBase64 string, unspents []explorer.Utxo) []uint32 {
-	indexes := make([]uint32, 0, len(unspents))
-
-	ptx, _ := pset.NewPsetFromBase64(psetBase64)
-	for _, u := range unspents {
-		for i, in := range ptx.UnsignedTx.Inputs {
-			if u.Hash() == bufferutil.TxIDFromBytes(in.Hash) && u.Index() == in.Index {
-				indexes = append(indexes, uint32(i))
-				break
-			}
-		}
+	reason := swapFail.GetFailureMessage()
+	if swapFail == nil {
+		reason = "bad pricing"
 	}
-	return indexes
+	logger.WithField("reason", reason).Info("trade proposal rejected")
 }
 
 func getUnspentKeys(unspents []explorer.Utxo) []domain.UnspentKey {
@@ -690,24 +772,6 @@ func mergeDerivationPaths(maps ...map[string]string) map[string]string {
 	return merge
 }
 
-func getSelectedDerivationPaths(derivationPaths map[string]string, unspents []explorer.Utxo) []string {
-	selectedPaths := make([]string, 0)
-	for _, unspent := range unspents {
-		script := hex.EncodeToString(unspent.Script())
-		selectedPaths = append(selectedPaths, derivationPaths[script])
-	}
-	return selectedPaths
-}
-
-func getSelectedBlindingKeys(blindingKeys map[string][]byte, unspents []explorer.Utxo) map[string][]byte {
-	selectedKeys := map[string][]byte{}
-	for _, unspent := range unspents {
-		script := hex.EncodeToString(unspent.Script())
-		selectedKeys[script] = blindingKeys[script]
-	}
-	return selectedKeys
-}
-
 // getPriceAndPreviewForMarket returns the current price of a market, along
 // with a amount preview for a BUY or SELL trade.
 // Depending on the strategy set for the market, an input amount might be
@@ -829,6 +893,7 @@ func previewFromFormula(
 	balances := getBalanceByAsset(unspents)
 	baseBalanceAvailable := balances[market.BaseAsset]
 	quoteBalanceAvailable := balances[market.QuoteAsset]
+	baseWeight, quoteWeight := market.ReservesWeights()
 	formula := market.Strategy.Formula()
 
 	if tradeType == TradeBuy {
@@ -836,6 +901,8 @@ func previewFromFormula(
 			&mm.FormulaOpts{
 				BalanceIn:           quoteBalanceAvailable,
 				BalanceOut:          baseBalanceAvailable,
+				WeightIn:            quoteWeight,
+				WeightOut:           baseWeight,
 				Fee:                 uint64(market.Fee),
 				ChargeFeeOnTheWayIn: market.FeeAsset == market.BaseAsset,
 			},
@@ -846,6 +913,8 @@ func previewFromFormula(
 			&mm.FormulaOpts{
 				BalanceIn:           baseBalanceAvailable,
 				BalanceOut:          quoteBalanceAvailable,
+				WeightIn:            baseWeight,
+				WeightOut:           quoteWeight,
 				Fee:                 uint64(market.Fee),
 				ChargeFeeOnTheWayIn: market.FeeAsset == market.QuoteAsset,
 			},
@@ -859,6 +928,8 @@ func previewFromFormula(
 	basePrice, err := formula.SpotPrice(&mm.FormulaOpts{
 		BalanceIn:  quoteBalanceAvailable,
 		BalanceOut: baseBalanceAvailable,
+		WeightIn:   quoteWeight,
+		WeightOut:  baseWeight,
 	})
 	if err != nil {
 		return
@@ -866,6 +937,8 @@ func previewFromFormula(
 	quotePrice, err := formula.SpotPrice(&mm.FormulaOpts{
 		BalanceIn:  baseBalanceAvailable,
 		BalanceOut: quoteBalanceAvailable,
+		WeightIn:   baseWeight,
+		WeightOut:  quoteWeight,
 	})
 	if err != nil {
 		return
@@ -892,6 +965,65 @@ func isValidTradePrice(swapRequest *pb.SwapRequest, tradeType int, previewAmount
 	return amountToCheck.GreaterThanOrEqual(lowerBound) && amountToCheck.LessThanOrEqual(upperBound)
 }
 
+// GetSwapStatus is the domain controller for the SwapStatus RPC. The swap is
+// identified by either the id of its request or that of its accept message.
+func (t *tradeService) GetSwapStatus(
+	ctx context.Context,
+	swapID string,
+) (*SwapStatus, error) {
+	if len(swapID) <= 0 {
+		return nil, ErrSwapNotFound
+	}
+
+	// a failure of the repository must not be reported as a swap not found,
+	// that would make a trader give up a swap that may still be completed
+	trade, err := t.tradeRepository.GetTradeBySwapRequestID(ctx, swapID)
+	if err != nil && !errors.Is(err, domain.ErrTradeNotFound) {
+		return nil, err
+	}
+	if trade == nil {
+		trade, err = t.tradeRepository.GetTradeBySwapAcceptID(ctx, swapID)
+		if err != nil && !errors.Is(err, domain.ErrTradeNotFound) {
+			return nil, err
+		}
+	}
+	if trade == nil || trade.IsEmpty() {
+		return nil, ErrSwapNotFound
+	}
+
+	return tradeToSwapStatus(trade)
+}
+
+func tradeToSwapStatus(trade *domain.Trade) (*SwapStatus, error) {
+	swapStatus := &SwapStatus{
+		TxID:       trade.TxID,
+		ExpiryTime: trade.SwapExpiryTime(),
+	}
+
+	switch {
+	case trade.IsSettled():
+		swapStatus.Status = SwapStatusConfirmed
+		swapStatus.BlockTime = trade.SwapCompleteTime()
+	case trade.IsCompleted():
+		swapStatus.Status = SwapStatusBroadcast
+	case trade.Status.Failed:
+		swapStatus.Status = SwapStatusFailed
+	case trade.IsExpired():
+		swapStatus.Status = SwapStatusExpired
+	default:
+		swapStatus.Status = SwapStatusPending
+	}
+
+	if trade.Status.Failed {
+		swapFail := &pb.SwapFail{}
+		if err := proto.Unmarshal(trade.SwapFail.Message, swapFail); err != nil {
+			return nil, fmt.Errorf("unable to decode swap fail message: %w", err)
+		}
+		swapStatus.FailureMessage = swapFail.GetFailureMessage()
+	}
+	return swapStatus, nil
+}
+
 func (t *tradeService) GetMarketBalance(
 	ctx context.Context,
 	market Market,
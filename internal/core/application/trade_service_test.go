@@ -1,13 +1,20 @@
 package application
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/storage/db/inmemory"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
 	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
 	"github.com/vulpemventures/go-elements/network"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestGetPriceAndPreviewForMarket(t *testing.T) {
@@ -177,9 +184,145 @@ func TestTradePropose(t *testing.T) {
 			t.Error(err)
 		}
 		assert.Equal(t, true, isFinalizableTransaction(swapComplete.GetTransaction()))
+
+		// retrying the same proposal returns the same accept
+		duplicatedAccept, swapFail, _, err := traderSvc.TradePropose(ctx, market, TradeSell, swapRequest)
+		if err != nil {
+			t.Error(err)
+		}
+		assert.Nil(t, swapFail)
+		assert.Equal(t, swapAccept.GetId(), duplicatedAccept.GetId())
+
+		// reusing the id for a different proposal is not allowed
+		swapRequest.AmountR++
+		duplicatedAccept, swapFail, _, err = traderSvc.TradePropose(ctx, market, TradeSell, swapRequest)
+		if err != nil {
+			t.Error(err)
+		}
+		assert.Nil(t, duplicatedAccept)
+		assert.NotNil(t, swapFail)
 	})
 }
 
+func TestReplyToDuplicatedSwapRequest(t *testing.T) {
+	swapRequest := &pb.SwapRequest{
+		Id:          "request",
+		AssetP:      "asset_p",
+		AmountP:     1000,
+		AssetR:      "asset_r",
+		AmountR:     10,
+		Transaction: "pset",
+	}
+	swapRequestMsg, _ := proto.Marshal(swapRequest)
+	swapAcceptMsg, _ := proto.Marshal(&pb.SwapAccept{Id: "accept"})
+	_, swapFailMsg, _ := pkgswap.Fail(pkgswap.FailOpts{MessageID: "request"})
+	now := uint64(time.Now().Unix())
+
+	newTrade := func(status domain.Status, expiry uint64) *domain.Trade {
+		trade := domain.NewTrade()
+		trade.Status = status
+		trade.SwapRequest = domain.Swap{ID: "request", Message: swapRequestMsg}
+		trade.Timestamp.Expiry = expiry
+		if status == domain.ProposalRejectedStatus {
+			trade.SwapFail = domain.Swap{ID: "fail", Message: swapFailMsg}
+		} else {
+			trade.SwapAccept = domain.Swap{ID: "accept", Message: swapAcceptMsg}
+		}
+		return trade
+	}
+
+	differentSwapRequest := proto.Clone(swapRequest).(*pb.SwapRequest)
+	differentSwapRequest.AmountR++
+
+	tests := []struct {
+		name              string
+		trade             *domain.Trade
+		swapRequest       *pb.SwapRequest
+		expectedAcceptID  string
+		expectedFailMsgID string
+	}{
+		{
+			name:             "accepted",
+			trade:            newTrade(domain.AcceptedStatus, now+60),
+			swapRequest:      swapRequest,
+			expectedAcceptID: "accept",
+		},
+		{
+			name:              "rejected",
+			trade:             newTrade(domain.ProposalRejectedStatus, now+60),
+			swapRequest:       swapRequest,
+			expectedFailMsgID: "request",
+		},
+		{
+			name:              "expired",
+			trade:             newTrade(domain.AcceptedStatus, now-1),
+			swapRequest:       swapRequest,
+			expectedFailMsgID: "request",
+		},
+		{
+			name:              "different content",
+			trade:             newTrade(domain.AcceptedStatus, now+60),
+			swapRequest:       differentSwapRequest,
+			expectedFailMsgID: "request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swapAccept, swapFail, _ := replyToDuplicatedSwapRequest(
+				tt.trade, tt.swapRequest, nil, nil,
+			)
+			assert.Equal(t, tt.expectedAcceptID, swapAccept.GetId())
+			assert.Equal(t, tt.expectedFailMsgID, swapFail.GetMessageId())
+		})
+	}
+}
+
+func TestReplyToConcurrentSwapRequest(t *testing.T) {
+	swapRequest := &pb.SwapRequest{
+		Id:          "request",
+		AssetP:      "asset_p",
+		AmountP:     1000,
+		AssetR:      "asset_r",
+		AmountR:     10,
+		Transaction: "pset",
+	}
+	swapRequestMsg, _ := proto.Marshal(swapRequest)
+	swapAcceptMsg, _ := proto.Marshal(&pb.SwapAccept{Id: "accept"})
+	ctx := context.Background()
+
+	tradeRepository := inmemory.NewTradeRepositoryImpl(newTestDb())
+	if err := tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			trade.Status = domain.AcceptedStatus
+			trade.SwapRequest = domain.Swap{ID: "request", Message: swapRequestMsg}
+			trade.SwapAccept = domain.Swap{ID: "accept", Message: swapAcceptMsg}
+			trade.Timestamp.Expiry = uint64(time.Now().Unix()) + 60
+			return trade, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+	traderSvc := newTradeService(nil, tradeRepository, nil, nil, nil, nil, nil)
+
+	// the proposal that lost the race gets the replies of the stored one
+	swapAccept, swapFail, _, err := traderSvc.replyToConcurrentSwapRequest(
+		ctx, swapRequest, nil, nil,
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, swapFail)
+	assert.Equal(t, "accept", swapAccept.GetId())
+
+	unknownSwapRequest := proto.Clone(swapRequest).(*pb.SwapRequest)
+	unknownSwapRequest.Id = "unknown"
+	_, _, _, err = traderSvc.replyToConcurrentSwapRequest(
+		ctx, unknownSwapRequest, nil, nil,
+	)
+	assert.Equal(t, domain.ErrDuplicatedSwapRequest, err)
+}
+
 // func TestTradeFailedToComplete(t *testing.T) {
 // 	traderSvc, ctx, close := newTestTrader()
 // 	defer close()
@@ -191,3 +334,251 @@ func TestTradePropose(t *testing.T) {
 // 	assert.Equal(t, true, len(txID) <= 0)
 // 	assert.NotNil(t, swapFail)
 // }
+
+func TestTradeToSwapStatus(t *testing.T) {
+	swapFailMsg, _ := proto.Marshal(&pb.SwapFail{FailureMessage: "bad pricing"})
+	now := uint64(time.Now().Unix())
+
+	newTrade := func(status domain.Status, expiry uint64) *domain.Trade {
+		trade := domain.NewTrade()
+		trade.Status = status
+		trade.Timestamp.Expiry = expiry
+		if status.Failed {
+			trade.SwapFail = domain.Swap{ID: "fail", Message: swapFailMsg}
+		}
+		if status.Code == domain.CompletedStatus.Code {
+			trade.TxID = "txid"
+			trade.Timestamp.Complete = now
+		}
+		return trade
+	}
+	settledTrade := newTrade(domain.CompletedStatus, now-1)
+	settledTrade.Settled = true
+
+	tests := []struct {
+		name                   string
+		trade                  *domain.Trade
+		expectedStatus         string
+		expectedTxID           string
+		expectedBlockTime      uint64
+		expectedFailureMessage string
+	}{
+		{
+			name:           "pending",
+			trade:          newTrade(domain.AcceptedStatus, now+60),
+			expectedStatus: SwapStatusPending,
+		},
+		{
+			name:           "expired",
+			trade:          newTrade(domain.AcceptedStatus, now-1),
+			expectedStatus: SwapStatusExpired,
+		},
+		{
+			name:                   "rejected",
+			trade:                  newTrade(domain.ProposalRejectedStatus, now+60),
+			expectedStatus:         SwapStatusFailed,
+			expectedFailureMessage: "bad pricing",
+		},
+		{
+			name:                   "failed to complete",
+			trade:                  newTrade(domain.FailedToCompleteStatus, now-1),
+			expectedStatus:         SwapStatusFailed,
+			expectedFailureMessage: "bad pricing",
+		},
+		{
+			name:           "broadcast",
+			trade:          newTrade(domain.CompletedStatus, now-1),
+			expectedStatus: SwapStatusBroadcast,
+			expectedTxID:   "txid",
+		},
+		{
+			name:              "confirmed",
+			trade:             settledTrade,
+			expectedStatus:    SwapStatusConfirmed,
+			expectedTxID:      "txid",
+			expectedBlockTime: now,
+		},
+		{
+			name:                   "failed after complete",
+			trade:                  newTrade(domain.FailedAfterCompleteStatus, now-1),
+			expectedStatus:         SwapStatusFailed,
+			expectedTxID:           "txid",
+			expectedFailureMessage: "bad pricing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swapStatus, err := tradeToSwapStatus(tt.trade)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expectedStatus, swapStatus.Status)
+			assert.Equal(t, tt.expectedTxID, swapStatus.TxID)
+			assert.Equal(t, tt.expectedBlockTime, swapStatus.BlockTime)
+			assert.Equal(t, tt.expectedFailureMessage, swapStatus.FailureMessage)
+			assert.Equal(t, tt.trade.Timestamp.Expiry, swapStatus.ExpiryTime)
+		})
+	}
+}
+
+func TestGetSwapStatus(t *testing.T) {
+	trade := domain.NewTrade()
+	trade.Status = domain.AcceptedStatus
+	trade.SwapRequest = domain.Swap{ID: "request"}
+	trade.SwapAccept = domain.Swap{ID: "accept"}
+	trade.Timestamp.Expiry = uint64(time.Now().Unix()) + 60
+	dbErr := errors.New("db failure")
+
+	tests := []struct {
+		name          string
+		repository    *mockedSwapStatusTradeRepository
+		swapID        string
+		expectedError error
+	}{
+		{
+			name:       "by swap request id",
+			repository: &mockedSwapStatusTradeRepository{trade: trade},
+			swapID:     "request",
+		},
+		{
+			name:       "by swap accept id",
+			repository: &mockedSwapStatusTradeRepository{trade: trade},
+			swapID:     "accept",
+		},
+		{
+			name:          "not found",
+			repository:    &mockedSwapStatusTradeRepository{trade: trade},
+			swapID:        "unknown",
+			expectedError: ErrSwapNotFound,
+		},
+		{
+			name:          "repository failure",
+			repository:    &mockedSwapStatusTradeRepository{err: dbErr},
+			swapID:        "request",
+			expectedError: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &tradeService{tradeRepository: tt.repository}
+			swapStatus, err := svc.GetSwapStatus(context.Background(), tt.swapID)
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, SwapStatusPending, swapStatus.Status)
+		})
+	}
+
+	// a failure message that can't be decoded is not ignored
+	trade.Status = domain.FailedToCompleteStatus
+	trade.SwapFail = domain.Swap{ID: "fail", Message: []byte{0xff}}
+	_, err := tradeToSwapStatus(trade)
+	assert.Error(t, err)
+}
+
+// mockedSwapStatusTradeRepository returns the given trade if it matches the
+// swap request or accept id, or the given error
+type mockedSwapStatusTradeRepository struct {
+	domain.TradeRepository
+	trade *domain.Trade
+	err   error
+}
+
+func (r *mockedSwapStatusTradeRepository) GetTradeBySwapRequestID(
+	_ context.Context,
+	swapRequestID string,
+) (*domain.Trade, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.trade.SwapRequest.ID != swapRequestID {
+		return nil, domain.ErrTradeNotFound
+	}
+	return r.trade, nil
+}
+
+func (r *mockedSwapStatusTradeRepository) GetTradeBySwapAcceptID(
+	_ context.Context,
+	swapAcceptID string,
+) (*domain.Trade, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.trade.SwapAccept.ID != swapAcceptID {
+		return nil, domain.ErrTradeNotFound
+	}
+	return r.trade, nil
+}
+
+func TestBalanceMultiAssetTrade(t *testing.T) {
+	tests := []struct {
+		name               string
+		trades             []*multiAssetMarketTrade
+		baseAmountR        uint64
+		isValidPrice       bool
+		expectedBaseAmount []uint64
+	}{
+		{
+			name: "difference goes to the market receiving base asset",
+			trades: []*multiAssetMarketTrade{
+				{isLegSent: true, baseAmount: 6000},
+				{isLegSent: true, baseAmount: 4100},
+				{isLegSent: false, baseAmount: 9000},
+			},
+			baseAmountR:        1000,
+			isValidPrice:       true,
+			expectedBaseAmount: []uint64{6000, 4100, 9100},
+		},
+		{
+			name: "difference is subtracted from the markets paying base asset",
+			trades: []*multiAssetMarketTrade{
+				{isLegSent: true, baseAmount: 50},
+				{isLegSent: true, baseAmount: 10000},
+			},
+			baseAmountR:        9800,
+			isValidPrice:       true,
+			expectedBaseAmount: []uint64{1, 9799},
+		},
+		{
+			name: "missing value is added to the first market paying base asset",
+			trades: []*multiAssetMarketTrade{
+				{isLegSent: true, baseAmount: 5000},
+				{isLegSent: true, baseAmount: 4900},
+			},
+			baseAmountR:        10000,
+			isValidPrice:       true,
+			expectedBaseAmount: []uint64{5100, 4900},
+		},
+		{
+			name: "bad pricing",
+			trades: []*multiAssetMarketTrade{
+				{isLegSent: true, baseAmount: 5000},
+				{isLegSent: false, baseAmount: 10000},
+			},
+			isValidPrice: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isValidPrice := isValidMultiAssetTradePrice(tt.trades, tt.baseAmountR)
+			assert.Equal(t, tt.isValidPrice, isValidPrice)
+			if !isValidPrice {
+				return
+			}
+
+			assert.Equal(t, true, balanceMultiAssetTrade(tt.trades, tt.baseAmountR))
+			for i, trade := range tt.trades {
+				assert.Equal(t, int(tt.expectedBaseAmount[i]), int(trade.baseAmount))
+			}
+			valueP, valueR := getMultiAssetTradeValues(tt.trades, tt.baseAmountR)
+			assert.Equal(t, valueP, valueR)
+		})
+	}
+}
@@ -59,9 +59,49 @@ type PriceWithFee struct {
 	Amount uint64
 }
 
+// Statuses of a swap as seen by the trader
+const (
+	// SwapStatusPending is the status of a swap proposal accepted by the
+	// daemon and waiting to be completed by the trader
+	SwapStatusPending = "pending"
+	// SwapStatusBroadcast is the status of a completed swap whose transaction
+	// has been broadcasted but not yet confirmed
+	SwapStatusBroadcast = "broadcast"
+	// SwapStatusConfirmed is the status of a swap whose transaction has been
+	// included in a block
+	SwapStatusConfirmed = "confirmed"
+	// SwapStatusFailed is the status of a swap either rejected by the daemon or
+	// whose transaction won't ever be included in a block
+	SwapStatusFailed = "failed"
+	// SwapStatusExpired is the status of an accepted swap not completed in time
+	SwapStatusExpired = "expired"
+)
+
+// SwapStatus is the status of a swap, along with the txid and the block time
+// of its transaction if completed, and the reason of the failure if any
+type SwapStatus struct {
+	Status         string
+	TxID           string
+	BlockTime      uint64
+	ExpiryTime     uint64
+	FailureMessage string
+}
+
+// Quote is a price preview that the daemon honours, if the trade proposal
+// referencing its ID is made before ExpiryTime
+type Quote struct {
+	PriceWithFee
+	ID         string
+	ExpiryTime uint64
+}
+
 type MarketStrategy struct {
 	Market
 	Strategy domain.StrategyType
+	// BaseAssetWeight is the target share, in percentage, of the reserves
+	// value held in base asset for the unbalanced strategy. If zero, the
+	// current target of the market is kept.
+	BaseAssetWeight uint64
 }
 
 type Balance struct {
@@ -86,3 +126,112 @@ type ReportMarketFee struct {
 	CollectedFees              []Fee
 	TotalCollectedFeesPerAsset map[string]int64
 }
+
+// ExportReq defines the time range (unix seconds, both ends included) of the
+// records to export. A zero ToTime means up to now.
+type ExportReq struct {
+	FromTime uint64
+	ToTime   uint64
+}
+
+// ExportRecord is a single entry of the accounting export. It describes
+// either a completed trade, a deposit or a withdrawal from the point of view
+// of the provider: AssetIn/AmountIn are the funds received, while
+// AssetOut/AmountOut those sent.
+type ExportRecord struct {
+	Kind         string
+	ID           string
+	Timestamp    uint64
+	AccountIndex int
+	BaseAsset    string
+	QuoteAsset   string
+	AssetIn      string
+	AmountIn     uint64
+	AssetOut     string
+	AmountOut    uint64
+	Price        decimal.Decimal
+	FeeAsset     string
+	FeeAmount    uint64
+	TxID         string
+	RequestTime  uint64
+	AcceptTime   uint64
+	CompleteTime uint64
+}
+
+// MarketReportReq defines the market and the time range (unix seconds, both
+// ends included) of a market report. A zero ToTime means up to now.
+type MarketReportReq struct {
+	Market
+	FromTime uint64
+	ToTime   uint64
+}
+
+// MarketReport summarizes how a market performed in a time range.
+// Reserves before the end of the range are reconstructed backwards from the
+// current balance of the market account by reverting trades, deposits and
+// withdrawals. Values are amounts of base asset, calculated with the price at
+// the end of the range.
+type MarketReport struct {
+	Market
+	FromTime           uint64
+	ToTime             uint64
+	StartBalance       Balance
+	EndBalance         Balance
+	CurrentBalance     Balance
+	StartPrice         Price
+	EndPrice           Price
+	CurrentPrice       Price
+	TradesCount        int
+	Volume             Balance
+	CollectedFees      Balance
+	CollectedFeesValue int64
+	// HoldValue is the value of the starting reserves if they had been just
+	// held, without trading
+	HoldValue int64
+	// TradedValue is the value of the starting reserves updated with the
+	// trades of the period, fees included
+	TradedValue int64
+	// ImpermanentLoss is the value lost with respect to holding the starting
+	// reserves because of the change in price, fees excluded
+	ImpermanentLoss int64
+	// InventoryValue is the value of the current reserves at current price
+	InventoryValue int64
+}
+
+// AddWithdrawalAddressReq defines the confidential address to whitelist as
+// destination of withdrawals, along with the wallet passphrase that
+// authorizes it.
+type AddWithdrawalAddressReq struct {
+	Address    string
+	Label      string
+	Passphrase string
+}
+
+// WithdrawalAddress is an address whitelisted as destination of withdrawals.
+// It can be paid only from ActiveFrom (unix seconds) on.
+type WithdrawalAddress struct {
+	Address    string
+	Label      string
+	CreatedAt  uint64
+	ActiveFrom uint64
+}
+
+// Payment is the audit record of a transaction signed by the daemon to send
+// funds away from a market or the wallet account. Broadcasted tells whether
+// the daemon also pushed it to the network.
+type Payment struct {
+	TxID            string
+	AccountIndex    int
+	Outputs         []TxOut
+	MillisatPerByte int64
+	Broadcasted     bool
+	Timestamp       uint64
+}
+
+// WalletStatus describes the state of the wallet: whether it's initialized,
+// still syncing after being restored from a mnemonic and unlocked
+type WalletStatus struct {
+	Initialized bool
+	Syncing     bool
+	Unlocked    bool
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
 	"github.com/tdex-network/tdex-daemon/pkg/crawler"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
-	"github.com/tdex-network/tdex-daemon/pkg/transactionutil"
 	"github.com/tdex-network/tdex-daemon/pkg/wallet"
 	"github.com/vulpemventures/go-elements/address"
 	"github.com/vulpemventures/go-elements/transaction"
@@ -54,6 +53,7 @@ type WalletService interface {
 		ctx context.Context,
 		req SendToManyRequest,
 	) ([]byte, error)
+	WalletStatus(ctx context.Context) WalletStatus
 }
 
 type walletService struct {
@@ -61,6 +61,8 @@ type walletService struct {
 	unspentRepository domain.UnspentRepository
 	crawlerService    crawler.Service
 	explorerService   explorer.Service
+	keystore          domain.Keystore
+	withdrawalPolicy  withdrawalPolicy
 	walletInitialized bool
 	walletIsSyncing   bool
 }
@@ -68,28 +70,42 @@ type walletService struct {
 func NewWalletService(
 	vaultRepository domain.VaultRepository,
 	unspentRepository domain.UnspentRepository,
+	withdrawalAddressRepository domain.WithdrawalAddressRepository,
+	paymentRepository domain.PaymentRepository,
 	crawlerService crawler.Service,
 	explorerService explorer.Service,
+	keystore domain.Keystore,
 ) WalletService {
 	return newWalletService(
 		vaultRepository,
 		unspentRepository,
+		withdrawalAddressRepository,
+		paymentRepository,
 		crawlerService,
 		explorerService,
+		keystore,
 	)
 }
 
 func newWalletService(
 	vaultRepository domain.VaultRepository,
 	unspentRepository domain.UnspentRepository,
+	withdrawalAddressRepository domain.WithdrawalAddressRepository,
+	paymentRepository domain.PaymentRepository,
 	crawlerService crawler.Service,
 	explorerService explorer.Service,
+	keystore domain.Keystore,
 ) *walletService {
 	w := &walletService{
 		vaultRepository:   vaultRepository,
 		unspentRepository: unspentRepository,
 		crawlerService:    crawlerService,
 		explorerService:   explorerService,
+		keystore:          keystore,
+		withdrawalPolicy: withdrawalPolicy{
+			withdrawalAddressRepository: withdrawalAddressRepository,
+			paymentRepository:           paymentRepository,
+		},
 	}
 	// to understand if the service has an already initialized wallet we check
 	// if the inner vaultRepo is able to return a Vault without passing mnemonic
@@ -115,6 +131,11 @@ func newWalletService(
 }
 
 func (w *walletService) GenSeed(ctx context.Context) ([]string, error) {
+	// the mnemonic of a sealed keystore must never get into the daemon
+	if _, ok := w.keystore.(domain.SealedKeystore); ok {
+		return nil, domain.ErrSealedKeystore
+	}
+
 	mnemonic, err := wallet.NewMnemonic(wallet.NewMnemonicOpts{EntropySize: 256})
 	if err != nil {
 		return nil, err
@@ -122,6 +143,11 @@ func (w *walletService) GenSeed(ctx context.Context) ([]string, error) {
 	return mnemonic, nil
 }
 
+// InitWallet restores the accounts of the wallet of the given mnemonic, whose
+// Vault is encrypted with the given passphrase. If the keystore is sealed,
+// like a remote signer, it already holds the mnemonic encrypted on its own:
+// the mnemonic must not be given and the keystore is unlocked with the
+// passphrase to restore the accounts.
 func (w *walletService) InitWallet(
 	ctx context.Context,
 	mnemonic []string,
@@ -136,46 +162,114 @@ func (w *walletService) InitWallet(
 		return nil
 	}
 
-	w.walletIsSyncing = true
+	sealed, isSealed := w.keystore.(domain.SealedKeystore)
+	if isSealed && len(mnemonic) > 0 {
+		return domain.ErrSealedKeystore
+	}
 
-	err := w.vaultRepository.UpdateVault(
-		ctx,
-		mnemonic,
-		passphrase,
-		func(v *domain.Vault) (*domain.Vault, error) {
-			log.Debug("start syncing wallet")
-			ww, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
-				SigningMnemonic: mnemonic,
-			})
-			if err != nil {
-				return nil, err
-			}
+	w.walletIsSyncing = true
 
-			feeLastDerivedIndex := getLatestDerivationIndexForAccount(ww, domain.FeeAccount, w.explorerService)
-			walletLastDerivedIndex := getLatestDerivationIndexForAccount(ww, domain.WalletAccount, w.explorerService)
-			marketsLastDerivedIndex := getLatestDerivationIndexForMarkets(ww, w.explorerService)
+	var err error
+	if isSealed {
+		err = w.initSealedVault(ctx, sealed, passphrase)
+	} else {
+		err = w.vaultRepository.UpdateVault(
+			ctx,
+			mnemonic,
+			passphrase,
+			func(v *domain.Vault) (*domain.Vault, error) {
+				// the keystore holds the keys only for the time needed to restore
+				// the addresses of the accounts, the wallet must be unlocked
+				// afterwards
+				if err := w.keystore.Unlock(mnemonic); err != nil {
+					return nil, err
+				}
+				defer v.Lock(w.keystore)
 
-			if err := initVaultAccount(v, domain.FeeAccount, feeLastDerivedIndex, w.crawlerService); err != nil {
-				return nil, err
-			}
-			// we dont't want to let the crawler watch for WalletAccount addresses
-			if err := initVaultAccount(v, domain.WalletAccount, walletLastDerivedIndex, nil); err != nil {
-				return nil, err
-			}
-			for i, m := range marketsLastDerivedIndex {
-				if err := initVaultAccount(v, domain.MarketAccountStart+i, m, w.crawlerService); err != nil {
+				if err := w.restoreVault(v); err != nil {
 					return nil, err
 				}
+				return v, nil
+			},
+		)
+	}
+
+	w.walletIsSyncing = false
+	log.Debug("ended syncing wallet")
+	return err
+}
+
+// initSealedVault unlocks the sealed keystore with the passphrase for the time
+// needed to restore the addresses of the accounts in a new sealed Vault. The
+// Vault is created only if the passphrase is valid.
+func (w *walletService) initSealedVault(
+	ctx context.Context,
+	sealed domain.SealedKeystore,
+	passphrase string,
+) error {
+	if err := sealed.UnlockWithPassphrase(passphrase); err != nil {
+		return err
+	}
+	defer sealed.Lock()
+
+	if _, err := w.vaultRepository.GetOrCreateSealedVault(ctx); err != nil {
+		return err
+	}
+	return w.vaultRepository.UpdateVault(
+		ctx,
+		nil,
+		"",
+		func(v *domain.Vault) (*domain.Vault, error) {
+			if err := w.restoreVault(v); err != nil {
+				return nil, err
 			}
-			v.Lock()
-			w.walletInitialized = true
 			return v, nil
 		},
 	)
+}
 
-	w.walletIsSyncing = false
-	log.Debug("ended syncing wallet")
-	return err
+// restoreVault derives with the unlocked keystore the addresses of the fee,
+// wallet and market accounts up to the last one used, and adds them to the
+// given Vault
+func (w *walletService) restoreVault(v *domain.Vault) error {
+	log.Debug("start syncing wallet")
+	feeLastDerivedIndex, err := getLatestDerivationIndexForAccount(w.keystore, domain.FeeAccount, w.explorerService)
+	if err != nil {
+		return err
+	}
+	walletLastDerivedIndex, err := getLatestDerivationIndexForAccount(w.keystore, domain.WalletAccount, w.explorerService)
+	if err != nil {
+		return err
+	}
+	marketsLastDerivedIndex, err := getLatestDerivationIndexForMarkets(w.keystore, w.explorerService)
+	if err != nil {
+		return err
+	}
+
+	if err := initVaultAccount(v, w.keystore, domain.FeeAccount, feeLastDerivedIndex, w.crawlerService); err != nil {
+		return err
+	}
+	// we dont't want to let the crawler watch for WalletAccount addresses
+	if err := initVaultAccount(v, w.keystore, domain.WalletAccount, walletLastDerivedIndex, nil); err != nil {
+		return err
+	}
+	for i, m := range marketsLastDerivedIndex {
+		if err := initVaultAccount(v, w.keystore, domain.MarketAccountStart+i, m, w.crawlerService); err != nil {
+			return err
+		}
+	}
+	w.walletInitialized = true
+	return nil
+}
+
+// WalletStatus returns whether the wallet is initialized, still syncing after
+// being restored and unlocked
+func (w *walletService) WalletStatus(ctx context.Context) WalletStatus {
+	return WalletStatus{
+		Initialized: w.walletInitialized,
+		Syncing:     w.walletIsSyncing,
+		Unlocked:    !w.keystore.IsLocked(),
+	}
 }
 
 func (w *walletService) UnlockWallet(
@@ -194,7 +288,7 @@ func (w *walletService) UnlockWallet(
 		nil,
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
-			if err := v.Unlock(passphrase); err != nil {
+			if err := v.Unlock(w.keystore, passphrase); err != nil {
 				return nil, err
 			}
 			return v, nil
@@ -219,7 +313,7 @@ func (w *walletService) ChangePassword(
 		nil,
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
-			err := v.ChangePassphrase(currentPassphrase, newPassphrase)
+			err := v.ChangePassphrase(w.keystore, currentPassphrase, newPassphrase)
 			if err != nil {
 				return nil, err
 			}
@@ -244,6 +338,7 @@ func (w *walletService) GenerateAddressAndBlindingKey(
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
 			adr, _, bk, err1 := v.DeriveNextExternalAddressForAccount(
+				w.keystore,
 				domain.WalletAccount,
 			)
 			if err1 != nil {
@@ -313,6 +408,13 @@ func (w *walletService) SendToMany(
 		return nil, err
 	}
 
+	paymentsLock.Lock()
+	defer paymentsLock.Unlock()
+
+	if err := w.withdrawalPolicy.check(ctx, req.Outputs); err != nil {
+		return nil, err
+	}
+
 	walletUnspents, err := w.getAllUnspentsForAccount(ctx, domain.WalletAccount, true)
 	if err != nil {
 		return nil, err
@@ -332,6 +434,7 @@ func (w *walletService) SendToMany(
 	}
 
 	var rawTx []byte
+	var txID string
 	var addressToObserve *crawler.AddressObservable
 
 	err = w.vaultRepository.UpdateVault(
@@ -339,10 +442,6 @@ func (w *walletService) SendToMany(
 		nil,
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
-			mnemonic, err := v.GetMnemonicSafe()
-			if err != nil {
-				return nil, err
-			}
 			walletAccount, err := v.AccountByIndex(domain.WalletAccount)
 			if err != nil {
 				return nil, err
@@ -356,6 +455,7 @@ func (w *walletService) SendToMany(
 			feeChangePathByAsset := map[string]string{}
 			for _, asset := range getAssetsOfOutputs(outputs) {
 				_, script, _, err := v.DeriveNextInternalAddressForAccount(
+					w.keystore,
 					domain.WalletAccount,
 				)
 				if err != nil {
@@ -364,7 +464,7 @@ func (w *walletService) SendToMany(
 				derivationPath, _ := walletAccount.DerivationPathByScript[script]
 				changePathsByAsset[asset] = derivationPath
 			}
-			feeAddress, script, feeBlindkey, err := v.DeriveNextInternalAddressForAccount(domain.FeeAccount)
+			feeAddress, script, feeBlindkey, err := v.DeriveNextInternalAddressForAccount(w.keystore, domain.FeeAccount)
 			if err != nil {
 				return nil, err
 			}
@@ -375,21 +475,21 @@ func (w *walletService) SendToMany(
 				BlindingKey:  feeBlindkey,
 			}
 
-			txHex, _, err := sendToMany(sendToManyOpts{
-				mnemonic:              mnemonic,
-				unspents:              walletUnspents,
-				feeUnspents:           feeUnspents,
-				outputs:               outputs,
-				outputsBlindingKeys:   outputsBlindingKeys,
-				changePathsByAsset:    changePathsByAsset,
-				feeChangePathByAsset:  feeChangePathByAsset,
-				inputPathsByScript:    walletAccount.DerivationPathByScript,
-				feeInputPathsByScript: feeAccount.DerivationPathByScript,
-				milliSatPerByte:       int(req.MillisatPerByte),
+			txHex, txid, err := w.keystore.SendToMany(domain.SendToManyOpts{
+				Unspents:              walletUnspents,
+				FeeUnspents:           feeUnspents,
+				Outputs:               outputs,
+				OutputsBlindingKeys:   outputsBlindingKeys,
+				ChangePathsByAsset:    changePathsByAsset,
+				FeeChangePathByAsset:  feeChangePathByAsset,
+				InputPathsByScript:    walletAccount.DerivationPathByScript,
+				FeeInputPathsByScript: feeAccount.DerivationPathByScript,
+				MilliSatPerByte:       int(req.MillisatPerByte),
 			})
 			if err != nil {
 				return nil, err
 			}
+			txID = txid
 
 			if req.Push {
 				if _, err := w.explorerService.BroadcastTransaction(txHex); err != nil {
@@ -410,6 +510,17 @@ func (w *walletService) SendToMany(
 		return nil, err
 	}
 
+	if err := w.withdrawalPolicy.record(
+		ctx,
+		txID,
+		domain.WalletAccount,
+		req.Outputs,
+		req.MillisatPerByte,
+		req.Push,
+	); err != nil {
+		return nil, err
+	}
+
 	// of course, do not forget of starting watching new address of fee account
 	w.crawlerService.AddObservable(addressToObserve)
 
@@ -534,113 +645,6 @@ func containsAsset(assets []string, asset string) bool {
 	return false
 }
 
-type sendToManyOpts struct {
-	mnemonic              []string
-	unspents              []explorer.Utxo
-	feeUnspents           []explorer.Utxo
-	outputs               []*transaction.TxOutput
-	outputsBlindingKeys   [][]byte
-	changePathsByAsset    map[string]string
-	feeChangePathByAsset  map[string]string
-	inputPathsByScript    map[string]string
-	feeInputPathsByScript map[string]string
-	milliSatPerByte       int
-}
-
-func sendToMany(opts sendToManyOpts) (string, string, error) {
-	w, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
-		SigningMnemonic: opts.mnemonic,
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	// default to MinMilliSatPerByte if needed
-	milliSatPerByte := opts.milliSatPerByte
-	if milliSatPerByte < domain.MinMilliSatPerByte {
-		milliSatPerByte = domain.MinMilliSatPerByte
-	}
-
-	// create the transaction
-	newPset, err := w.CreateTx()
-	if err != nil {
-		return "", "", err
-	}
-
-	// add inputs and outputs
-	updateResult, err := w.UpdateTx(wallet.UpdateTxOpts{
-		PsetBase64:         newPset,
-		Unspents:           opts.unspents,
-		Outputs:            opts.outputs,
-		ChangePathsByAsset: opts.changePathsByAsset,
-		MilliSatsPerBytes:  milliSatPerByte,
-		Network:            config.GetNetwork(),
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	// update the list of output blinding keys with those of the eventual changes
-	outputsBlindingKeys := opts.outputsBlindingKeys
-	for _, v := range updateResult.ChangeOutputsBlindingKeys {
-		outputsBlindingKeys = append(outputsBlindingKeys, v)
-	}
-
-	// add inputs for paying network fees
-	updateResult, err = w.UpdateTx(wallet.UpdateTxOpts{
-		PsetBase64:         updateResult.PsetBase64,
-		Unspents:           opts.feeUnspents,
-		ChangePathsByAsset: opts.feeChangePathByAsset,
-		MilliSatsPerBytes:  milliSatPerByte,
-		Network:            config.GetNetwork(),
-		WantChangeForFees:  true,
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	// again, add changes' blinding keys to the list of those of the outputs
-	for _, v := range updateResult.ChangeOutputsBlindingKeys {
-		outputsBlindingKeys = append(outputsBlindingKeys, v)
-	}
-
-	// blind the transaction
-	blindedPset, err := w.BlindTransaction(wallet.BlindTransactionOpts{
-		PsetBase64:         updateResult.PsetBase64,
-		OutputBlindingKeys: outputsBlindingKeys,
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	// add the explicit fee amount
-	blindedPlusFees, err := w.UpdateTx(wallet.UpdateTxOpts{
-		PsetBase64: blindedPset,
-		Outputs:    transactionutil.NewFeeOutput(updateResult.FeeAmount),
-		Network:    config.GetNetwork(),
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	// sign the inputs
-	inputPathsByScript := mergeDerivationPaths(opts.inputPathsByScript, opts.feeInputPathsByScript)
-	signedPset, err := w.SignTransaction(wallet.SignTransactionOpts{
-		PsetBase64:        blindedPlusFees.PsetBase64,
-		DerivationPathMap: inputPathsByScript,
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	// finalize, extract and return the transaction
-	return wallet.FinalizeAndExtractTransaction(
-		wallet.FinalizeAndExtractTransactionOpts{
-			PsetBase64: signedPset,
-		},
-	)
-}
-
 func getDerivationPathsForUnspents(
 	account *domain.Account,
 	unspents []explorer.Utxo,
@@ -660,17 +664,19 @@ type accountLastDerivedIndex struct {
 	internal int
 }
 
-func getLatestDerivationIndexForAccount(w *wallet.Wallet, accountIndex int, explorerSvc explorer.Service) *accountLastDerivedIndex {
+func getLatestDerivationIndexForAccount(signer domain.Signer, accountIndex int, explorerSvc explorer.Service) (*accountLastDerivedIndex, error) {
 	lastDerivedIndex := &accountLastDerivedIndex{}
 	for chainIndex := 0; chainIndex <= 1; chainIndex++ {
 		firstUnfundedAddress := -1
 		unfundedAddressesCounter := 0
 		i := 0
 		for unfundedAddressesCounter < 20 {
-			ctAddress, _, _ := w.DeriveConfidentialAddress(wallet.DeriveConfidentialAddressOpts{
-				DerivationPath: fmt.Sprintf("%d'/%d/%d", accountIndex, chainIndex, i),
-				Network:        config.GetNetwork(),
-			})
+			ctAddress, _, _, err := signer.DeriveConfidentialAddress(
+				fmt.Sprintf("%d'/%d/%d", accountIndex, chainIndex, i),
+			)
+			if err != nil {
+				return nil, err
+			}
 
 			if !isAddressFunded(ctAddress, explorerSvc) {
 				if firstUnfundedAddress < 0 {
@@ -694,35 +700,38 @@ func getLatestDerivationIndexForAccount(w *wallet.Wallet, accountIndex int, expl
 
 	if lastDerivedIndex.external < 0 && lastDerivedIndex.internal < 0 {
 		log.Debugf("account %d empty", accountIndex)
-		return nil
+		return nil, nil
 	}
 	log.Debugf("account %d last derived external address %d", accountIndex, lastDerivedIndex.external)
-	return lastDerivedIndex
+	return lastDerivedIndex, nil
 }
 
-func getLatestDerivationIndexForMarkets(w *wallet.Wallet, explorerSvc explorer.Service) []*accountLastDerivedIndex {
+func getLatestDerivationIndexForMarkets(signer domain.Signer, explorerSvc explorer.Service) ([]*accountLastDerivedIndex, error) {
 	marketsLastIndex := make([]*accountLastDerivedIndex, 0)
 	i := 0
 	for {
 		marketIndex := domain.MarketAccountStart + i
-		lastDerivedIndex := getLatestDerivationIndexForAccount(w, marketIndex, explorerSvc)
+		lastDerivedIndex, err := getLatestDerivationIndexForAccount(signer, marketIndex, explorerSvc)
+		if err != nil {
+			return nil, err
+		}
 		if lastDerivedIndex == nil {
 			break
 		}
 		marketsLastIndex = append(marketsLastIndex, lastDerivedIndex)
 		i++
 	}
-	return marketsLastIndex
+	return marketsLastIndex, nil
 }
 
-func initVaultAccount(v *domain.Vault, accountIndex int, lastDerivedIndex *accountLastDerivedIndex, crawlerSvc crawler.Service) error {
+func initVaultAccount(v *domain.Vault, signer domain.Signer, accountIndex int, lastDerivedIndex *accountLastDerivedIndex, crawlerSvc crawler.Service) error {
 	if lastDerivedIndex == nil {
 		v.InitAccount(accountIndex)
 		return nil
 	}
 
 	for i := 0; i <= lastDerivedIndex.external; i++ {
-		addr, _, blindingKey, err := v.DeriveNextExternalAddressForAccount(accountIndex)
+		addr, _, blindingKey, err := v.DeriveNextExternalAddressForAccount(signer, accountIndex)
 		if err != nil {
 			return err
 		}
@@ -735,7 +744,7 @@ func initVaultAccount(v *domain.Vault, accountIndex int, lastDerivedIndex *accou
 		}
 	}
 	for i := 0; i <= lastDerivedIndex.internal; i++ {
-		addr, _, blindingKey, err := v.DeriveNextInternalAddressForAccount(accountIndex)
+		addr, _, blindingKey, err := v.DeriveNextInternalAddressForAccount(signer, accountIndex)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -140,11 +141,21 @@ func TestWalletUnlock(t *testing.T) {
 
 	address, blindingKey, err := walletSvc.GenerateAddressAndBlindingKey(ctx)
 	assert.Equal(t, domain.ErrMustBeUnlocked, err)
+	assert.Equal(
+		t,
+		WalletStatus{Initialized: true},
+		walletSvc.WalletStatus(ctx),
+	)
 
 	err = walletSvc.UnlockWallet(ctx, dryLockedWallet.password)
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(
+		t,
+		WalletStatus{Initialized: true, Unlocked: true},
+		walletSvc.WalletStatus(ctx),
+	)
 
 	address, blindingKey, err = walletSvc.GenerateAddressAndBlindingKey(ctx)
 	if err != nil {
@@ -163,10 +174,13 @@ func TestWalletChangePass(t *testing.T) {
 	walletSvc, ctx, close := newTestWallet(dryLockedWallet)
 	defer close()
 
-	err := walletSvc.ChangePassword(ctx, "wrongPass", "newPass")
+	err := walletSvc.ChangePassword(ctx, "wrongPass", "N3wSup3rS3cr3tP4ss!")
 	assert.Equal(t, domain.ErrInvalidPassphrase, err)
 
 	err = walletSvc.ChangePassword(ctx, dryLockedWallet.password, "newPass")
+	assert.True(t, errors.Is(err, domain.ErrWeakPassphrase))
+
+	err = walletSvc.ChangePassword(ctx, dryLockedWallet.password, "N3wSup3rS3cr3tP4ss!")
 	assert.NoError(t, err)
 
 	err = walletSvc.UnlockWallet(ctx, dryLockedWallet.password)
@@ -251,7 +265,7 @@ func TestSendToMany(t *testing.T) {
 		nil,
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
-			v.DeriveNextExternalAddressForAccount(domain.FeeAccount)
+			v.DeriveNextExternalAddressForAccount(walletSvc.keystore, domain.FeeAccount)
 			return v, nil
 		},
 	)
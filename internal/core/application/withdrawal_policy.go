@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+// paymentsLock serializes the outgoing payments of the operator and wallet
+// services, so that concurrent ones can't exceed the daily limits
+var paymentsLock = &sync.Mutex{}
+
+// withdrawalPolicy makes sure that the funds sent away from the daemon go
+// only to whitelisted addresses and within the daily limits, and keeps an
+// audit record of every outgoing payment
+type withdrawalPolicy struct {
+	withdrawalAddressRepository domain.WithdrawalAddressRepository
+	paymentRepository           domain.PaymentRepository
+}
+
+// check returns an error if any of the outputs pays an address not
+// whitelisted or still cooling down, or if, along with the payments of the
+// last 24 hours, they make the amount sent of some asset exceed its limit
+func (p withdrawalPolicy) check(ctx context.Context, outs []TxOut) error {
+	amountByAsset := map[string]uint64{}
+	for _, out := range outs {
+		script, _, err := parseConfidentialAddress(out.Address)
+		if err != nil {
+			return err
+		}
+		withdrawalAddress, err := p.withdrawalAddressRepository.
+			GetWithdrawalAddressByScript(ctx, hex.EncodeToString(script))
+		if err != nil {
+			return err
+		}
+		if withdrawalAddress == nil {
+			return fmt.Errorf(
+				"%w: %s", ErrWithdrawalAddressNotWhitelisted, out.Address,
+			)
+		}
+		if !withdrawalAddress.IsActive() {
+			return fmt.Errorf(
+				"%w: %s until %s",
+				ErrWithdrawalAddressCoolingDown,
+				out.Address,
+				time.Unix(int64(withdrawalAddress.ActiveFrom), 0).UTC(),
+			)
+		}
+		amountByAsset[out.Asset] += uint64(out.Value)
+	}
+
+	limits := config.GetWithdrawalDailyLimits()
+	if len(limits) <= 0 {
+		return nil
+	}
+
+	since := uint64(time.Now().Add(-24 * time.Hour).Unix())
+	payments, err := p.paymentRepository.GetPaymentsSince(ctx, since)
+	if err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		for asset, amount := range payment.AmountByAsset() {
+			if _, ok := amountByAsset[asset]; ok {
+				amountByAsset[asset] += amount
+			}
+		}
+	}
+
+	for asset, amount := range amountByAsset {
+		if limit, ok := limits[asset]; ok && amount > limit {
+			return fmt.Errorf(
+				"%w: %d of %d sats of asset %s in 24 hours",
+				ErrWithdrawalDailyLimitExceeded, amount, limit, asset,
+			)
+		}
+	}
+	return nil
+}
+
+// record adds the audit record of a payment
+func (p withdrawalPolicy) record(
+	ctx context.Context,
+	txID string,
+	accountIndex int,
+	outs []TxOut,
+	millisatPerByte int64,
+	broadcasted bool,
+) error {
+	outputs := make([]domain.PaymentOutput, 0, len(outs))
+	for _, out := range outs {
+		outputs = append(outputs, domain.PaymentOutput{
+			Asset:   out.Asset,
+			Amount:  uint64(out.Value),
+			Address: out.Address,
+		})
+	}
+	return p.paymentRepository.AddPayment(ctx, domain.Payment{
+		TxID:            txID,
+		AccountIndex:    accountIndex,
+		Outputs:         outputs,
+		MillisatPerByte: millisatPerByte,
+		Broadcasted:     broadcasted,
+		Timestamp:       uint64(time.Now().Unix()),
+	})
+}
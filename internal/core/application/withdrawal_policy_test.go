@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/storage/db/inmemory"
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	"github.com/vulpemventures/go-elements/network"
+)
+
+func TestWithdrawalPolicy(t *testing.T) {
+	ctx := context.Background()
+	dbManager := newTestDb()
+	withdrawalAddressRepo := newTestWithdrawalAddressRepo(ctx, dbManager)
+	policy := withdrawalPolicy{
+		withdrawalAddressRepository: withdrawalAddressRepo,
+		paymentRepository:           inmemory.NewPaymentRepositoryImpl(dbManager),
+	}
+
+	coolingDownAddress := newTestAddress(t)
+	withdrawalAddress, err := domain.NewWithdrawalAddress(
+		coolingDownAddress, "", time.Hour,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := withdrawalAddressRepo.AddWithdrawalAddress(
+		ctx, *withdrawalAddress,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	asset := network.Regtest.AssetID
+	config.Set(config.WithdrawalDailyLimitsKey, fmt.Sprintf("%s:1500", asset))
+	defer config.Set(config.WithdrawalDailyLimitsKey, "")
+
+	outs := []TxOut{{Asset: asset, Value: 1000, Address: whitelistedAddresses[0]}}
+	assert.NoError(t, policy.check(ctx, outs))
+	if err := policy.record(ctx, "txid", domain.WalletAccount, outs, 100, true); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		outs []TxOut
+		err  error
+	}{
+		{
+			outs: []TxOut{{Asset: asset, Value: 500, Address: whitelistedAddresses[1]}},
+			err:  nil,
+		},
+		{
+			outs: []TxOut{{Asset: asset, Value: 600, Address: whitelistedAddresses[1]}},
+			err:  ErrWithdrawalDailyLimitExceeded,
+		},
+		{
+			outs: []TxOut{{Asset: asset, Value: 1, Address: coolingDownAddress}},
+			err:  ErrWithdrawalAddressCoolingDown,
+		},
+		{
+			outs: []TxOut{{Asset: asset, Value: 1, Address: newTestAddress(t)}},
+			err:  ErrWithdrawalAddressNotWhitelisted,
+		},
+	}
+
+	for _, tt := range tests {
+		err := policy.check(ctx, tt.outs)
+		if tt.err == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.True(t, errors.Is(err, tt.err))
+		}
+	}
+}
+
+func newTestAddress(t *testing.T) string {
+	w, err := trade.NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.Address()
+}
@@ -16,4 +16,9 @@ const (
 	StrategyTypePluggable  StrategyType = 0
 	StrategyTypeBalanced   StrategyType = 1
 	StrategyTypeUnbalanced StrategyType = 2
+
+	// target weights, in percentage, of the base asset reserve
+	defaultBaseAssetWeight = 50
+	minBaseAssetWeight     = 1
+	maxBaseAssetWeight     = 99
 )
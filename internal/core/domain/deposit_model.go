@@ -0,0 +1,23 @@
+package domain
+
+// Deposit defines the Deposit entity data structure for holding the funds
+// received by the fee account or by a market account from an external source
+type Deposit struct {
+	AccountIndex int
+	TxID         string
+	VOut         uint32
+	Asset        string
+	Value        uint64
+	// Timestamp is the time of the block including the tx of the deposit, zero
+	// while the tx is unconfirmed
+	Timestamp uint64
+}
+
+// Key returns the unique identifier of the deposit, ie. the outpoint of the
+// received unspent
+func (d *Deposit) Key() UnspentKey {
+	return UnspentKey{
+		TxID: d.TxID,
+		VOut: d.VOut,
+	}
+}
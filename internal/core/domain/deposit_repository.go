@@ -0,0 +1,27 @@
+package domain
+
+import "context"
+
+// DepositRepository defines the abstraction for Deposit
+type DepositRepository interface {
+	// Adds the given deposits to the storage. Already existing deposits are
+	// skipped and the number of the actually added ones is returned
+	AddDeposits(ctx context.Context, deposits []Deposit) (int, error)
+	// Retrieves all the deposits stored, sorted by timestamp
+	GetAllDeposits(ctx context.Context) ([]Deposit, error)
+	// Retrieves a page of at most limit deposits, skipping the first offset
+	// ones, confirmed in the given time range, both ends included, sorted by
+	// timestamp
+	GetDepositsByTime(
+		ctx context.Context,
+		fromTime, toTime uint64,
+		offset, limit int,
+	) ([]Deposit, error)
+	// Sets the time of the block including the tx of the given deposits.
+	// Keys not referring to any deposit are ignored
+	ConfirmDeposits(
+		ctx context.Context,
+		keys []UnspentKey,
+		blockTime uint64,
+	) error
+}
@@ -13,8 +13,21 @@ var (
 	ErrMustBeUnlocked = errors.New("wallet must be unlocked to perform this operation")
 	// ErrInvalidPassphrase ...
 	ErrInvalidPassphrase = errors.New("passphrase is not valid")
+	// ErrWeakPassphrase is thrown when the passphrase set for the wallet does
+	// not satisfy the passphrase policy
+	ErrWeakPassphrase = errors.New("passphrase is too weak")
 	// ErrVaultAlreadyInitialized ...
 	ErrVaultAlreadyInitialized = errors.New("vault is already initialized")
+	// ErrSealedKeystore is thrown when trying to unlock with a mnemonic a
+	// keystore that holds it encrypted on its own
+	ErrSealedKeystore = errors.New(
+		"keystore holds the mnemonic on its own and can't be given one",
+	)
+	// ErrKeystoreNotSealed is thrown when trying to unlock a sealed Vault with
+	// a keystore that doesn't hold the mnemonic on its own
+	ErrKeystoreNotSealed = errors.New(
+		"vault is sealed but keystore doesn't hold the mnemonic",
+	)
 	// ErrNullMnemonicOrPassphrase ...
 	ErrNullMnemonicOrPassphrase = errors.New("mnemonic and/or passphrase must not be null")
 	//ErrNotFunded is thrown when a market requires being funded for a change
@@ -23,6 +36,8 @@ var (
 	ErrMarketIsClosed = errors.New("market is closed")
 	//ErrMarketMustBeClose is thrown when a market requires being NOT tradable for a change
 	ErrMarketMustBeClose = errors.New("market must be closed")
+	//ErrInvalidBaseAssetWeight is thrown when the target weight of the base asset for an unbalanced market is not a valid percentage.
+	ErrInvalidBaseAssetWeight = errors.New("base asset weight must be in range [1, 99]")
 	//ErrPriceExists is thrown when a price for that given timestamp already exists
 	ErrPriceExists = errors.New("price has been inserted already")
 	//ErrNotPriced is thrown when the price is still 0 (ie. not initialized)
@@ -47,10 +62,39 @@ var (
 	ErrMustBeCompleted = errors.New(
 		"trade must be in completed state to add txid",
 	)
+	// ErrMustNotBeSettled is thrown when trying to fail a trade whose
+	// transaction has been already included in the blockchain
+	ErrMustNotBeSettled = errors.New(
+		"trade must not be settled to be set failed",
+	)
+	// ErrMissingMarketTrades is thrown when proposing a multi-asset trade
+	// without any of the markets it touches
+	ErrMissingMarketTrades = errors.New(
+		"multi-asset trade must touch at least one market",
+	)
+	// ErrDuplicatedSwapRequest is thrown when storing a trade originated by a
+	// swap request whose id is already used by another trade
+	ErrDuplicatedSwapRequest = errors.New(
+		"swap request id already used by another trade",
+	)
 	// ErrExpirationDateNotReached ...
 	ErrExpirationDateNotReached = errors.New(
 		"trade did not reached expiration date yet and cannot be set expired",
 	)
 	// ErrMarketNotExist ...
 	ErrMarketNotExist = errors.New("market does not exists")
+	// ErrInvalidWithdrawalAddress is thrown when whitelisting an address that
+	// is not a valid confidential address for the current network
+	ErrInvalidWithdrawalAddress = errors.New(
+		"withdrawal address must be a valid confidential address",
+	)
+	// ErrWithdrawalAddressAlreadyWhitelisted ...
+	ErrWithdrawalAddressAlreadyWhitelisted = errors.New(
+		"withdrawal address is already whitelisted",
+	)
+	// ErrWithdrawalAddressNotFound ...
+	ErrWithdrawalAddressNotFound = errors.New("withdrawal address not found")
+	// ErrTradeNotFound is returned by the trade repository if no trade matches
+	// the given swap id or txid
+	ErrTradeNotFound = errors.New("trade not found")
 )
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	"github.com/vulpemventures/go-elements/transaction"
+)
+
+// Signer makes use of the keys of the daemon's HD wallet to derive addresses
+// and to blind and sign transactions, as long as the Vault is unlocked. The
+// keys may be held by the daemon itself or by a remote signer process, that's
+// why the transactions are crafted by the Signer given the unspents and
+// derivation paths to use rather than passed to it already built.
+// Every method returns ErrMustBeUnlocked if the Signer is locked.
+type Signer interface {
+	IsLocked() bool
+	// DeriveConfidentialAddress returns the confidential address, the output
+	// script and the private blinding key for the given derivation path
+	DeriveConfidentialAddress(derivationPath string) (
+		address string,
+		script []byte,
+		blindingKey []byte,
+		err error,
+	)
+	// AcceptSwap adds the inputs and outputs of the daemon's markets to the
+	// transaction of a swap request, then blinds and signs it
+	AcceptSwap(opts AcceptSwapOpts) (*AcceptSwapResult, error)
+	// SendToMany crafts, blinds and signs a transaction paying the given
+	// outputs, and returns it in hex format along with its hash
+	SendToMany(opts SendToManyOpts) (txHex string, txID string, err error)
+}
+
+// Keystore holds in memory the keys of the daemon's HD wallet while the Vault
+// is unlocked. Only the wallet service locks and unlocks it, while the other
+// services make use of its signing capability.
+type Keystore interface {
+	Signer
+	// Unlock derives the master keys from the mnemonic and holds them in
+	// memory until Lock is called
+	Unlock(mnemonic []string) error
+	// Lock wipes the keys from memory
+	Lock()
+}
+
+// SealedKeystore is a Keystore that, like a remote signer, stores the
+// mnemonic of the daemon's HD wallet encrypted on its own, so that the daemon
+// never handles it. The Vault of a SealedKeystore doesn't hold the encrypted
+// mnemonic, and is unlocked by passing the passphrase to the keystore. Its
+// Unlock method always returns ErrSealedKeystore.
+type SealedKeystore interface {
+	Keystore
+	// UnlockWithPassphrase decrypts the mnemonic with the passphrase and holds
+	// the master keys derived from it until Lock is called
+	UnlockWithPassphrase(passphrase string) error
+	// ChangePassphrase encrypts the mnemonic with the new passphrase, if the
+	// current one is valid. The keystore must be locked
+	ChangePassphrase(currentPassphrase, newPassphrase string) error
+	// IsValidPassphrase returns whether the mnemonic can be decrypted with the
+	// given passphrase
+	IsValidPassphrase(passphrase string) bool
+}
+
+// AcceptSwapOpts is the struct given to Signer.AcceptSwap method
+type AcceptSwapOpts struct {
+	SwapRequest *pb.SwapRequest
+	// LegsP and LegsR are set only for multi-asset swaps
+	LegsP                      []pkgswap.Leg
+	LegsR                      []pkgswap.Leg
+	MarketSwaps                []MarketSwapOpts
+	FeeUnspents                []explorer.Utxo
+	MarketBlindingKeysByScript map[string][]byte
+	FeeBlindingKeysByScript    map[string][]byte
+	OutputBlindingKeysByScript map[string][]byte
+	MarketDerivationPaths      map[string]string
+	FeeDerivationPaths         map[string]string
+	FeeChangeDerivationPath    string
+}
+
+// MarketSwapOpts defines the inputs and outputs that a market account adds to
+// a swap transaction: the market pays InputAmount of InputAsset and receives
+// OutputAmount of OutputAsset. A trade involves a single market, unless it's
+// a multi-asset one.
+type MarketSwapOpts struct {
+	Unspents             []explorer.Utxo
+	InputAsset           string
+	InputAmount          uint64
+	OutputAsset          string
+	OutputAmount         uint64
+	OutputDerivationPath string
+	ChangeDerivationPath string
+}
+
+// AcceptSwapResult is the type returned by Signer.AcceptSwap method
+type AcceptSwapResult struct {
+	PsetBase64         string
+	SelectedUnspents   []explorer.Utxo
+	InputBlindingKeys  map[string][]byte
+	OutputBlindingKeys map[string][]byte
+}
+
+// SendToManyOpts is the struct given to Signer.SendToMany method
+type SendToManyOpts struct {
+	Unspents              []explorer.Utxo
+	FeeUnspents           []explorer.Utxo
+	Outputs               []*transaction.TxOutput
+	OutputsBlindingKeys   [][]byte
+	ChangePathsByAsset    map[string]string
+	FeeChangePathByAsset  map[string]string
+	InputPathsByScript    map[string]string
+	FeeInputPathsByScript map[string]string
+	MilliSatPerByte       int
+}
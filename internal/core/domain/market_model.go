@@ -21,6 +21,9 @@ type Market struct {
 	Tradable bool
 	// Market Making strategy
 	Strategy mm.MakingStrategy
+	// BaseAssetWeight is the target share, in percentage, of the value of the
+	// reserves held in base asset. Used only by the unbalanced strategy.
+	BaseAssetWeight uint64
 	//Pluggable Price of the asset pair.
 	Price Prices
 }
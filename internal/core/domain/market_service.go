@@ -237,3 +237,37 @@ func (m *Market) MakeStrategyBalanced() error {
 
 	return nil
 }
+
+// MakeStrategyUnbalanced makes the current market using an unbalanced AMM
+// formula whose reserves are weighted with the given target ratio, expressed
+// as the percentage of the reserves value to be held in base asset.
+func (m *Market) MakeStrategyUnbalanced(baseAssetWeight uint64) error {
+	if m.IsTradable() {
+		// We need the market be switched off before making this change
+		return ErrMarketMustBeClose
+	}
+	if baseAssetWeight < minBaseAssetWeight || baseAssetWeight > maxBaseAssetWeight {
+		return ErrInvalidBaseAssetWeight
+	}
+
+	m.Strategy = mm.NewStrategyFromFormula(formula.UnbalancedReserves{})
+	m.BaseAssetWeight = baseAssetWeight
+
+	return nil
+}
+
+// IsStrategyUnbalanced returns true if the market uses the unbalanced AMM
+// formula.
+func (m *Market) IsStrategyUnbalanced() bool {
+	return m.Strategy.Type == formula.UnbalancedReservesType
+}
+
+// ReservesWeights returns the weights of the base and quote reserves to be
+// used by the market making formula. They are 50/50 for every strategy but
+// the unbalanced one.
+func (m *Market) ReservesWeights() (baseWeight, quoteWeight uint64) {
+	if !m.IsStrategyUnbalanced() || m.BaseAssetWeight == 0 {
+		return defaultBaseAssetWeight, 100 - defaultBaseAssetWeight
+	}
+	return m.BaseAssetWeight, 100 - m.BaseAssetWeight
+}
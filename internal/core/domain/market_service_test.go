@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeStrategyUnbalanced(t *testing.T) {
+	m, err := NewMarket(MarketAccountStart)
+	require.NoError(t, err)
+
+	baseWeight, quoteWeight := m.ReservesWeights()
+	assert.Equal(t, uint64(50), baseWeight)
+	assert.Equal(t, uint64(50), quoteWeight)
+
+	err = m.MakeStrategyUnbalanced(70)
+	require.NoError(t, err)
+	assert.True(t, m.IsStrategyUnbalanced())
+	assert.False(t, m.IsStrategyPluggable())
+
+	baseWeight, quoteWeight = m.ReservesWeights()
+	assert.Equal(t, uint64(70), baseWeight)
+	assert.Equal(t, uint64(30), quoteWeight)
+
+	// the target ratio is ignored once the market switches strategy
+	err = m.MakeStrategyBalanced()
+	require.NoError(t, err)
+	baseWeight, quoteWeight = m.ReservesWeights()
+	assert.Equal(t, uint64(50), baseWeight)
+	assert.Equal(t, uint64(50), quoteWeight)
+}
+
+func TestFailingMakeStrategyUnbalanced(t *testing.T) {
+	tests := []struct {
+		name            string
+		tradable        bool
+		baseAssetWeight uint64
+		wantError       error
+	}{
+		{"with zero weight", false, 0, ErrInvalidBaseAssetWeight},
+		{"with weight exceeding the max", false, 100, ErrInvalidBaseAssetWeight},
+		{"with open market", true, 70, ErrMarketMustBeClose},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMarket(MarketAccountStart)
+			require.NoError(t, err)
+			m.Tradable = tt.tradable
+
+			err = m.MakeStrategyUnbalanced(tt.baseAssetWeight)
+			assert.Equal(t, tt.wantError, err)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package domain
+
+// Payment defines the Payment entity data structure for auditing the funds
+// sent away from the daemon, either withdrawn from a market or sent from the
+// wallet account. Every signed transaction is recorded, even if not
+// broadcasted by the daemon, since the operator could do it on its own
+type Payment struct {
+	TxID            string
+	AccountIndex    int
+	Outputs         []PaymentOutput
+	MillisatPerByte int64
+	Broadcasted     bool
+	Timestamp       uint64
+}
+
+// PaymentOutput is an amount of some asset paid to an address
+type PaymentOutput struct {
+	Asset   string
+	Amount  uint64
+	Address string
+}
+
+// AmountByAsset returns the total amount paid for every asset
+func (p *Payment) AmountByAsset() map[string]uint64 {
+	amounts := map[string]uint64{}
+	for _, out := range p.Outputs {
+		amounts[out.Asset] += out.Amount
+	}
+	return amounts
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "context"
+
+// PaymentRepository defines the abstraction for Payment
+type PaymentRepository interface {
+	// Adds the given payment to the storage, skipped if already existing
+	AddPayment(ctx context.Context, payment Payment) error
+	// Retrieves all the payments stored, sorted by timestamp
+	GetAllPayments(ctx context.Context) ([]Payment, error)
+	// Retrieves the payments made since the given unix timestamp, sorted by
+	// timestamp
+	GetPaymentsSince(ctx context.Context, timestamp uint64) ([]Payment, error)
+}
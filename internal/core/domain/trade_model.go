@@ -2,6 +2,7 @@ package domain
 
 import (
 	"github.com/google/uuid"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/operator"
 )
 
@@ -37,6 +38,13 @@ var (
 	CompletedStatus = Status{
 		Code: pb.SwapStatus_COMPLETE,
 	}
+	// FailedAfterCompleteStatus represents the status of a trade that has been
+	// completed but whose transaction has been double-spent or dropped from the
+	// mempool, so that it will never be published on the blockchain
+	FailedAfterCompleteStatus = Status{
+		Code:   pb.SwapStatus_COMPLETE,
+		Failed: true,
+	}
 )
 
 type Timestamp struct {
@@ -67,6 +75,7 @@ type Trade struct {
 	PsetBase64       string
 	TxID             string
 	TxHex            string
+	Settled          bool
 	Price            float32
 	MarketFee        int64
 	MarketFeeAsset   string
@@ -75,6 +84,27 @@ type Trade struct {
 	SwapAccept       Swap
 	SwapComplete     Swap
 	SwapFail         Swap
+	// LegsP and LegsR are set only for multi-asset trades and hold all the
+	// assets and amounts respectively sent and received by the trader
+	LegsP []pkgswap.Leg
+	LegsR []pkgswap.Leg
+	// MarketTrades is set only for multi-asset trades and holds the part of
+	// the trade settled by each of the markets it touches, the first being
+	// that of MarketQuoteAsset
+	MarketTrades []MarketTrade
+}
+
+// MarketTrade is the part of a multi-asset trade settled by one market: the
+// market receives QuoteAmount of its quote asset and pays BaseAmount of base
+// asset or, if IsQuoteSent, vice versa. Fee and FeeAsset are those of the
+// market at the time of the trade.
+type MarketTrade struct {
+	QuoteAsset  string
+	QuoteAmount uint64
+	BaseAmount  uint64
+	IsQuoteSent bool
+	Fee         int64
+	FeeAsset    string
 }
 
 // NewTrade returns an empty trade
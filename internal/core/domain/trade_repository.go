@@ -11,13 +11,36 @@ type TradeRepository interface {
 	GetAllTrades(ctx context.Context) ([]*Trade, error)
 	GetAllTradesByMarket(ctx context.Context, marketQuoteAsset string) ([]*Trade, error)
 	GetTradeBySwapAcceptID(ctx context.Context, swapAcceptID string) (*Trade, error)
+	GetTradeBySwapRequestID(ctx context.Context, swapRequestID string) (*Trade, error)
+	GetTradeByTxID(ctx context.Context, txID string) (*Trade, error)
 	UpdateTrade(
 		ctx context.Context,
 		tradeID *uuid.UUID,
 		updateFn func(t *Trade) (*Trade, error),
 	) error
+	// GetAcceptedTrades returns the trades accepted and neither completed nor
+	// failed yet, including the expired ones
+	GetAcceptedTrades(ctx context.Context) ([]*Trade, error)
+	// CountTradesByMarketAndStatus returns the number of trades of every
+	// market quote asset and status
+	CountTradesByMarketAndStatus(ctx context.Context) ([]TradeCount, error)
 	GetCompletedTradesByMarket(
 		ctx context.Context,
 		marketQuoteAsset string,
 	) ([]*Trade, error)
+	// GetTradesByCompleteTime returns a page of at most limit trades, skipping
+	// the first offset ones, whose swap has been completed in the given time
+	// range, both ends included, sorted by complete time
+	GetTradesByCompleteTime(
+		ctx context.Context,
+		fromTime, toTime uint64,
+		offset, limit int,
+	) ([]*Trade, error)
+}
+
+// TradeCount is the number of trades of a market in a certain status
+type TradeCount struct {
+	MarketQuoteAsset string
+	Status           Status
+	Count            int
 }
@@ -40,6 +40,77 @@ func (t *Trade) Propose(swapRequest *pb.SwapRequest, marketQuoteAsset string, tr
 	return true, nil
 }
 
+// ProposeMultiAsset works like Propose for a trade proposal with many assets
+// sent and/or received by the trader. The first legs of each list must be
+// those of the given swap request. The trade is recorded under the market of
+// the first of the given market trades, but it belongs to all of them.
+func (t *Trade) ProposeMultiAsset(
+	swapRequest *pb.SwapRequest,
+	legsP, legsR []pkgswap.Leg,
+	marketTrades []MarketTrade,
+	traderPubkey []byte,
+) (bool, error) {
+	if !t.IsEmpty() {
+		return false, ErrMustBeEmpty
+	}
+	if len(marketTrades) <= 0 {
+		return false, ErrMissingMarketTrades
+	}
+
+	t.TraderPubkey = traderPubkey
+	t.MarketQuoteAsset = marketTrades[0].QuoteAsset
+	t.MarketTrades = marketTrades
+	t.SwapRequest.ID = swapRequest.GetId()
+	t.Timestamp.Request = uint64(time.Now().Unix())
+	t.Timestamp.Expiry = t.Timestamp.Request + uint64(config.GetInt(config.
+		TradeExpiryTimeKey))
+	t.PsetBase64 = swapRequest.GetTransaction()
+
+	msg, err := pkgswap.ParseMultiAssetSwapRequest(swapRequest, legsP, legsR)
+	if err != nil {
+		t.Fail(
+			swapRequest.GetId(),
+			ProposalRejectedStatus,
+			pkgswap.ErrCodeInvalidSwapRequest,
+			err.Error(),
+		)
+		return false, nil
+	}
+
+	t.Status = ProposalStatus
+	t.SwapRequest.Message = msg
+	t.LegsP = legsP
+	t.LegsR = legsR
+	return true, nil
+}
+
+// IsMultiAsset returns whether the trade has more than one asset sent or
+// received by the trader
+func (t *Trade) IsMultiAsset() bool {
+	return len(t.LegsP) > 0 || len(t.LegsR) > 0
+}
+
+// IsInMarket returns whether the trade has been settled, at least in part, by
+// the market with the given quote asset
+func (t *Trade) IsInMarket(marketQuoteAsset string) bool {
+	if t.MarketQuoteAsset == marketQuoteAsset {
+		return true
+	}
+	_, ok := t.MarketTrade(marketQuoteAsset)
+	return ok
+}
+
+// MarketTrade returns the part of a multi-asset trade settled by the market
+// with the given quote asset, if any
+func (t *Trade) MarketTrade(marketQuoteAsset string) (MarketTrade, bool) {
+	for _, mt := range t.MarketTrades {
+		if mt.QuoteAsset == marketQuoteAsset {
+			return mt, true
+		}
+	}
+	return MarketTrade{}, false
+}
+
 // Accept attempts to accept a trade proposal. The trade must be in Proposal
 // status to be accepted, otherwise an error is thrown
 func (t *Trade) Accept(
@@ -56,6 +127,8 @@ func (t *Trade) Accept(
 		PsetBase64:         psetBase64,
 		InputBlindingKeys:  inputBlindingKeys,
 		OutputBlindingKeys: outputBlindingKeys,
+		LegsP:              t.LegsP,
+		LegsR:              t.LegsR,
 	})
 	if err != nil {
 		t.Fail(
@@ -83,9 +156,10 @@ type CompleteResult struct {
 }
 
 // Complete sets the status of the trade to Complete by adding the txID
-// of the tx in the blockchain. The trade must be in Accepted or
-// FailedToComplete status for being completed, otherwise an error is thrown
-func (t *Trade) Complete(psetBase64 string, txID string) (*CompleteResult, error) {
+// of the finalized tx to be published in the blockchain. The trade must be in
+// Accepted or FailedToComplete status for being completed, otherwise an error
+// is thrown
+func (t *Trade) Complete(psetBase64 string) (*CompleteResult, error) {
 	if t.IsCompleted() {
 		return &CompleteResult{OK: true, TxHex: t.TxHex, TxID: t.TxID}, nil
 	}
@@ -99,6 +173,8 @@ func (t *Trade) Complete(psetBase64 string, txID string) (*CompleteResult, error
 		InputBlindingKeys:  t.SwapAcceptMessage().GetInputBlindingKey(),
 		OutputBlindingKeys: t.SwapAcceptMessage().GetOutputBlindingKey(),
 		SwapRequest:        t.SwapRequestMessage(),
+		LegsP:              t.LegsP,
+		LegsR:              t.LegsR,
 	}); err != nil {
 		t.Fail(
 			t.SwapAccept.ID,
@@ -141,7 +217,7 @@ func (t *Trade) Complete(psetBase64 string, txID string) (*CompleteResult, error
 	t.SwapComplete.ID = swapCompleteID
 	t.SwapComplete.Message = swapCompleteMsg
 	t.PsetBase64 = psetBase64
-	t.TxID = txID
+	t.TxID = txHash
 	t.TxHex = txHex
 	return &CompleteResult{OK: true, TxHex: txHex, TxID: txHash}, nil
 }
@@ -170,6 +246,38 @@ func (t *Trade) AddBlocktime(blocktime uint64) error {
 	return nil
 }
 
+// Settle marks a completed trade as settled, ie. its transaction has been
+// included in a block with the given blocktime. If the trade is not in
+// Complete status, an error is thrown
+func (t *Trade) Settle(blocktime uint64) error {
+	if err := t.AddBlocktime(blocktime); err != nil {
+		return err
+	}
+
+	t.Settled = true
+	return nil
+}
+
+// FailAfterComplete sets the status of a completed but not yet settled trade
+// to FailedAfterComplete, meaning that its transaction won't ever be
+// published on the blockchain
+func (t *Trade) FailAfterComplete(errMsg string) error {
+	if !t.IsCompleted() {
+		return ErrMustBeCompleted
+	}
+	if t.IsSettled() {
+		return ErrMustNotBeSettled
+	}
+
+	t.Fail(
+		t.SwapComplete.ID,
+		FailedAfterCompleteStatus,
+		pkgswap.ErrCodeFailedToComplete,
+		errMsg,
+	)
+	return nil
+}
+
 // IsEmpty returns whether the Trade is empty
 func (t *Trade) IsEmpty() bool {
 	return t.Status == EmptyStatus
@@ -190,6 +298,18 @@ func (t *Trade) IsCompleted() bool {
 	return t.Status == CompletedStatus
 }
 
+// IsSettled returns whether the trade is completed and its transaction has
+// been included in the blockchain
+func (t *Trade) IsSettled() bool {
+	return t.IsCompleted() && t.Settled
+}
+
+// IsFailedAfterComplete returns whether the trade is in FailedAfterComplete
+// status
+func (t *Trade) IsFailedAfterComplete() bool {
+	return t.Status == FailedAfterCompleteStatus
+}
+
 // IsRejected returns whether the trade is in ProposalRejected status
 func (t *Trade) IsRejected() bool {
 	return t.Status == ProposalRejectedStatus
@@ -209,6 +329,37 @@ func (t *Trade) ContainsSwap(swapID string) bool {
 		ID || swapID == t.SwapComplete.ID
 }
 
+// IsSameSwapRequest returns whether the given swap request, along with the
+// legs of a multi-asset swap, is the one that originated the trade. Swap
+// requests rejected because malformed are matched by id only, since their
+// content is not stored.
+func (t *Trade) IsSameSwapRequest(
+	swapRequest *pb.SwapRequest,
+	legsP, legsR []pkgswap.Leg,
+) bool {
+	if swapRequest.GetId() != t.SwapRequest.ID {
+		return false
+	}
+	if len(t.SwapRequest.Message) <= 0 {
+		return t.IsRejected()
+	}
+	return proto.Equal(swapRequest, t.SwapRequestMessage()) &&
+		equalLegs(legsP, t.LegsP) &&
+		equalLegs(legsR, t.LegsR)
+}
+
+func equalLegs(a, b []pkgswap.Leg) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // SwapRequestMessage returns the swap request made for the trade
 func (t *Trade) SwapRequestMessage() *pb.SwapRequest {
 	if t.IsEmpty() {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
 )
 
@@ -17,6 +18,52 @@ func TestTradePropose(t *testing.T) {
 	assert.Equal(t, true, ok)
 }
 
+func TestTradeProposeMultiAsset(t *testing.T) {
+	swapRequest, marketQuoteAsset, traderPubkey := mockProposeArgs()
+	legsP, legsR := pkgswap.RequestLegs(swapRequest)
+
+	marketTrades := []MarketTrade{
+		{QuoteAsset: marketQuoteAsset, QuoteAmount: legsP[0].Amount},
+		{QuoteAsset: "otherQuoteAsset", IsQuoteSent: true},
+	}
+
+	trade := NewTrade()
+	ok, err := trade.ProposeMultiAsset(
+		swapRequest, legsP, legsR, marketTrades, traderPubkey,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, trade.IsMultiAsset())
+	assert.Equal(t, marketQuoteAsset, trade.MarketQuoteAsset)
+	assert.Equal(t, true, trade.IsInMarket(marketQuoteAsset))
+	assert.Equal(t, true, trade.IsInMarket("otherQuoteAsset"))
+	assert.Equal(t, false, trade.IsInMarket("unknownQuoteAsset"))
+	mt, ok := trade.MarketTrade("otherQuoteAsset")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, marketTrades[1], mt)
+
+	ok, err = trade.Accept(mockAcceptArgs())
+	assert.NoError(t, err)
+	assert.Equal(t, true, ok)
+
+	// the trade is rejected if the transaction has no output for some leg
+	legsR = append(legsR, pkgswap.Leg{
+		Asset:  "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3",
+		Amount: 1000,
+	})
+	trade = NewTrade()
+	ok, err = trade.ProposeMultiAsset(
+		swapRequest, legsP, legsR, marketTrades, traderPubkey,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, ProposalRejectedStatus, trade.Status)
+
+	trade = NewTrade()
+	_, err = trade.ProposeMultiAsset(swapRequest, legsP, legsR, nil, traderPubkey)
+	assert.Equal(t, ErrMissingMarketTrades, err)
+}
+
 func TestTradeAccept(t *testing.T) {
 	trade := NewTrade()
 	_, err := trade.Propose(mockProposeArgs())
@@ -34,11 +81,63 @@ func TestTradeComplete(t *testing.T) {
 	assert.NoError(t, err)
 	_, err = trade.Accept(mockAcceptArgs())
 	assert.NoError(t, err)
-	res, err := trade.Complete(mockCompleteArgs())
+	psetBase64, txID := mockCompleteArgs()
+	res, err := trade.Complete(psetBase64)
 	assert.NoError(t, err)
 	assert.Equal(t, true, res.OK)
+	assert.Equal(t, txID, trade.TxID)
 	err = trade.AddBlocktime(uint64(time.Now().Unix()))
 	assert.NoError(t, err)
+	assert.Equal(t, false, trade.IsSettled())
+	err = trade.Settle(uint64(time.Now().Unix()))
+	assert.NoError(t, err)
+	assert.Equal(t, true, trade.IsSettled())
+}
+
+func TestTradeSettleNotCompleted(t *testing.T) {
+	trade := NewTrade()
+	_, err := trade.Propose(mockProposeArgs())
+	assert.NoError(t, err)
+	err = trade.Settle(uint64(time.Now().Unix()))
+	assert.Equal(t, ErrMustBeCompleted, err)
+	assert.Equal(t, false, trade.IsSettled())
+}
+
+func TestTradeFailAfterComplete(t *testing.T) {
+	trade := NewTrade()
+	_, err := trade.Propose(mockProposeArgs())
+	assert.NoError(t, err)
+	err = trade.FailAfterComplete("double-spent")
+	assert.Equal(t, ErrMustBeCompleted, err)
+
+	_, err = trade.Accept(mockAcceptArgs())
+	assert.NoError(t, err)
+	psetBase64, _ := mockCompleteArgs()
+	_, err = trade.Complete(psetBase64)
+	assert.NoError(t, err)
+
+	err = trade.FailAfterComplete("double-spent")
+	assert.NoError(t, err)
+	assert.Equal(t, true, trade.IsFailedAfterComplete())
+	assert.Equal(t, false, trade.IsCompleted())
+	assert.NotEmpty(t, trade.SwapFail.Message)
+}
+
+func TestTradeFailAfterSettle(t *testing.T) {
+	trade := NewTrade()
+	_, err := trade.Propose(mockProposeArgs())
+	assert.NoError(t, err)
+	_, err = trade.Accept(mockAcceptArgs())
+	assert.NoError(t, err)
+	psetBase64, _ := mockCompleteArgs()
+	_, err = trade.Complete(psetBase64)
+	assert.NoError(t, err)
+	err = trade.Settle(uint64(time.Now().Unix()))
+	assert.NoError(t, err)
+
+	err = trade.FailAfterComplete("double-spent")
+	assert.Equal(t, ErrMustNotBeSettled, err)
+	assert.Equal(t, true, trade.IsSettled())
 }
 
 func mockProposeArgs() (swapRequest *pb.SwapRequest, marketQuoteAsset string, traderPubkey []byte) {
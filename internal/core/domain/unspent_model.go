@@ -26,6 +26,8 @@ type Unspent struct {
 	Locked          bool
 	LockedBy        *uuid.UUID
 	Confirmed       bool
+	BlockHeight     uint64
+	BlockHash       string
 }
 
 type BalanceInfo struct {
@@ -52,10 +54,30 @@ func (u *Unspent) Spend() {
 	u.Spent = true
 }
 
+func (u *Unspent) UnSpend() {
+	u.Spent = false
+}
+
 func (u *Unspent) Confirm() {
 	u.Confirmed = true
 }
 
+// ConfirmInBlock marks the unspent as confirmed in the block with the given
+// height and hash
+func (u *Unspent) ConfirmInBlock(blockHeight uint64, blockHash string) {
+	u.Confirmed = true
+	u.BlockHeight = blockHeight
+	u.BlockHash = blockHash
+}
+
+// Unconfirm marks the unspent as unconfirmed again, for example when the
+// block including it is no longer part of the best chain
+func (u *Unspent) Unconfirm() {
+	u.Confirmed = false
+	u.BlockHeight = 0
+	u.BlockHash = ""
+}
+
 func (u *Unspent) IsSpent() bool {
 	return u.Spent
 }
@@ -88,5 +110,7 @@ func (u *Unspent) ToUtxo() explorer.Utxo {
 		u.RangeProof,
 		u.SurjectionProof,
 		u.Confirmed,
+		u.BlockHeight,
+		u.BlockHash,
 	)
 }
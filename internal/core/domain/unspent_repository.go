@@ -36,10 +36,30 @@ type UnspentRepository interface {
 		ctx context.Context,
 		unspentKeys []UnspentKey,
 	) error
+	// UnspendUnspents marks the given unspents as unspent and unlocked again,
+	// for example when the tx spending them has been double-spent
+	UnspendUnspents(
+		ctx context.Context,
+		unspentKeys []UnspentKey,
+	) error
 	ConfirmUnspents(
 		ctx context.Context,
 		unspentKeys []UnspentKey,
 	) error
+	// ConfirmUnspentsInBlock marks the given unspents as confirmed in the block
+	// with the given height and hash
+	ConfirmUnspentsInBlock(
+		ctx context.Context,
+		unspentKeys []UnspentKey,
+		blockHeight uint64,
+		blockHash string,
+	) error
+	// UnconfirmUnspents marks the given unspents as unconfirmed again, for
+	// example when their block has been reorganized out of the best chain
+	UnconfirmUnspents(
+		ctx context.Context,
+		unspentKeys []UnspentKey,
+	) error
 	LockUnspents(
 		ctx context.Context,
 		unspentKeys []UnspentKey,
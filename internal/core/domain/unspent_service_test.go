@@ -21,3 +21,17 @@ func TestLockUnlockUnSpents(t *testing.T) {
 	u.Lock(&tradeID)
 	assert.Equal(t, true, u.IsLocked())
 }
+
+func TestConfirmUnconfirmUnspent(t *testing.T) {
+	u := Unspent{}
+
+	u.ConfirmInBlock(10, "blockhash")
+	assert.Equal(t, true, u.IsConfirmed())
+	assert.Equal(t, uint64(10), u.BlockHeight)
+	assert.Equal(t, "blockhash", u.BlockHash)
+
+	u.Unconfirm()
+	assert.Equal(t, false, u.IsConfirmed())
+	assert.Equal(t, uint64(0), u.BlockHeight)
+	assert.Equal(t, "", u.BlockHash)
+}
@@ -3,7 +3,6 @@ package domain
 import (
 	"strings"
 
-	"github.com/btcsuite/btcutil"
 	"github.com/tdex-network/tdex-daemon/pkg/wallet"
 )
 
@@ -14,8 +13,10 @@ type AccountAndKey struct {
 
 type Vault struct {
 	//Mnemonic               []string
-	EncryptedMnemonic      string
-	PassphraseHash         []byte
+	EncryptedMnemonic string
+	// Sealed is true if the mnemonic is held encrypted by a SealedKeystore
+	// rather than by the Vault
+	Sealed                 bool
 	Accounts               map[int]*Account
 	AccountAndKeyByAddress map[string]AccountAndKey
 }
@@ -36,10 +37,10 @@ type AddressInfo struct {
 	DerivationPath string
 }
 
-// NewVault encrypts the provided mnemonic with the passhrase and returns a new
-// Vault initialized with the encrypted mnemonic and the hash of the passphrase.
-// The Vault is locked by default since it is initialized without the mnemonic
-// in plain text
+// NewVault encrypts the provided mnemonic with the passhrase, that must
+// satisfy the passphrase policy, and returns a new Vault initialized with the
+// encrypted mnemonic. The Vault is locked by default since it is initialized
+// without the mnemonic in plain text
 func NewVault(mnemonic []string, passphrase string) (*Vault, error) {
 	if len(mnemonic) <= 0 || len(passphrase) <= 0 {
 		return nil, ErrNullMnemonicOrPassphrase
@@ -49,23 +50,35 @@ func NewVault(mnemonic []string, passphrase string) (*Vault, error) {
 	}); err != nil {
 		return nil, err
 	}
+	if err := validatePassphrase(passphrase); err != nil {
+		return nil, err
+	}
 
-	encryptedMnemonic, err := wallet.Encrypt(wallet.EncryptOpts{
-		PlainText:  strings.Join(mnemonic, " "),
-		Passphrase: passphrase,
-	})
+	encryptedMnemonic, err := encryptMnemonic(
+		strings.Join(mnemonic, " "),
+		passphrase,
+	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Vault{
 		EncryptedMnemonic:      encryptedMnemonic,
-		PassphraseHash:         btcutil.Hash160([]byte(passphrase)),
 		Accounts:               map[int]*Account{},
 		AccountAndKeyByAddress: map[string]AccountAndKey{},
 	}, nil
 }
 
+// NewSealedVault returns a new Vault whose mnemonic is encrypted and held by a
+// SealedKeystore, so that it doesn't hold any secret on its own
+func NewSealedVault() *Vault {
+	return &Vault{
+		Sealed:                 true,
+		Accounts:               map[int]*Account{},
+		AccountAndKeyByAddress: map[string]AccountAndKey{},
+	}
+}
+
 // NewAccount returns an empty Account instance
 func NewAccount(positiveAccountIndex int) (*Account, error) {
 	if err := validateAccountIndex(positiveAccountIndex); err != nil {
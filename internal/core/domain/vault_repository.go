@@ -10,6 +10,9 @@ type VaultRepository interface {
 		mnemonic []string,
 		passphrase string,
 	) (*Vault, error)
+	// GetOrCreateSealedVault returns the current Vault or, if not yet
+	// initialized, creates a new one whose mnemonic is held by a SealedKeystore
+	GetOrCreateSealedVault(ctx context.Context) (*Vault, error)
 	UpdateVault(
 		ctx context.Context,
 		mnemonic []string,
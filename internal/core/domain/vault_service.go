@@ -1,14 +1,14 @@
 package domain
 
 import (
-	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
+	"unicode"
 
-	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/pkg/wallet"
@@ -20,31 +20,33 @@ func (v *Vault) IsZero() bool {
 	return reflect.DeepEqual(*v, Vault{})
 }
 
-// GetMnemonicSafe is getter for Vault's mnemonic in plain text
-func (v *Vault) GetMnemonicSafe() ([]string, error) {
-	if v.isLocked() {
-		return nil, ErrMustBeUnlocked
-	}
-
-	return config.GetMnemonic(), nil
-}
-
-// Lock locks the Vault by wiping its mnemonic field
-func (v *Vault) Lock() error {
-	if v.isLocked() {
+// Lock locks the Vault by wiping the keys held by the keystore
+func (v *Vault) Lock(keystore Keystore) error {
+	if v.isLocked(keystore) {
 		return nil
 	}
-	// flush mnemonic in plain text
-	config.Set(config.MnemonicKey, "")
+	keystore.Lock()
 	return nil
 }
 
-// Unlock attempts to decrypt the mnemonic with the provided passphrase
-func (v *Vault) Unlock(passphrase string) error {
-	if !v.isLocked() {
+// Unlock attempts to decrypt the mnemonic with the provided passphrase and
+// lets the keystore hold the keys derived from it. If the mnemonic is
+// encrypted in the legacy format or with KDF params other than the configured
+// ones, it's encrypted again with them. If the Vault is sealed, the keystore
+// decrypts the mnemonic on its own instead
+func (v *Vault) Unlock(keystore Keystore, passphrase string) error {
+	if !v.isLocked(keystore) {
 		return nil
 	}
 
+	if v.Sealed {
+		sealed, err := sealedKeystore(keystore)
+		if err != nil {
+			return err
+		}
+		return sealed.UnlockWithPassphrase(passphrase)
+	}
+
 	mnemonic, err := wallet.Decrypt(wallet.DecryptOpts{
 		CypherText: v.EncryptedMnemonic,
 		Passphrase: passphrase,
@@ -53,17 +55,36 @@ func (v *Vault) Unlock(passphrase string) error {
 		return err
 	}
 
-	config.Set(config.MnemonicKey, mnemonic)
-	return nil
+	if wallet.NeedsMigration(v.EncryptedMnemonic, kdfParams()) {
+		encryptedMnemonic, err := encryptMnemonic(mnemonic, passphrase)
+		if err != nil {
+			return err
+		}
+		v.EncryptedMnemonic = encryptedMnemonic
+	}
+
+	return keystore.Unlock(strings.Split(mnemonic, " "))
 }
 
-// ChangePassphrase attempts to unlock the
-func (v *Vault) ChangePassphrase(currentPassphrase, newPassphrase string) error {
-	if !v.isLocked() {
+// ChangePassphrase attempts to decrypt the mnemonic with the current
+// passphrase and, if successful, encrypts it with the new one, that must
+// satisfy the passphrase policy. If the Vault is sealed, the keystore does it
+// on its own
+func (v *Vault) ChangePassphrase(
+	signer Signer,
+	currentPassphrase,
+	newPassphrase string,
+) error {
+	if !v.isLocked(signer) {
 		return ErrMustBeLocked
 	}
-	if !v.isValidPassphrase(currentPassphrase) {
-		return ErrInvalidPassphrase
+
+	if v.Sealed {
+		sealed, err := sealedKeystore(signer)
+		if err != nil {
+			return err
+		}
+		return sealed.ChangePassphrase(currentPassphrase, newPassphrase)
 	}
 
 	mnemonic, err := wallet.Decrypt(wallet.DecryptOpts{
@@ -71,22 +92,43 @@ func (v *Vault) ChangePassphrase(currentPassphrase, newPassphrase string) error
 		Passphrase: currentPassphrase,
 	})
 	if err != nil {
+		if err == wallet.ErrInvalidPassphrase || err == wallet.ErrNullPassphrase {
+			return ErrInvalidPassphrase
+		}
 		return err
 	}
 
-	encryptedMnemonic, err := wallet.Encrypt(wallet.EncryptOpts{
-		PlainText:  mnemonic,
-		Passphrase: newPassphrase,
-	})
+	if err := validatePassphrase(newPassphrase); err != nil {
+		return err
+	}
+
+	encryptedMnemonic, err := encryptMnemonic(mnemonic, newPassphrase)
 	if err != nil {
 		return err
 	}
 
 	v.EncryptedMnemonic = encryptedMnemonic
-	v.PassphraseHash = btcutil.Hash160([]byte(newPassphrase))
 	return nil
 }
 
+// IsValidPassphrase returns whether the mnemonic can be decrypted with the
+// given passphrase. It doesn't require the Vault to be unlocked
+func (v *Vault) IsValidPassphrase(signer Signer, passphrase string) bool {
+	if v.Sealed {
+		sealed, err := sealedKeystore(signer)
+		if err != nil {
+			return false
+		}
+		return sealed.IsValidPassphrase(passphrase)
+	}
+
+	_, err := wallet.Decrypt(wallet.DecryptOpts{
+		CypherText: v.EncryptedMnemonic,
+		Passphrase: passphrase,
+	})
+	return err == nil
+}
+
 // InitAccount creates a new account in the current Vault if not existing
 func (v *Vault) InitAccount(accountIndex int) {
 	if _, ok := v.Accounts[accountIndex]; !ok {
@@ -96,22 +138,22 @@ func (v *Vault) InitAccount(accountIndex int) {
 }
 
 // DeriveNextExternalAddressForAccount returns the next unused address, the corresponding output script, the blinding key.
-func (v *Vault) DeriveNextExternalAddressForAccount(accountIndex int) (address string, script string, blindingPrivateKey []byte, err error) {
-	if v.isLocked() {
+func (v *Vault) DeriveNextExternalAddressForAccount(signer Signer, accountIndex int) (address string, script string, blindingPrivateKey []byte, err error) {
+	if v.isLocked(signer) {
 		return "", "", nil, ErrMustBeUnlocked
 	}
 
-	return v.deriveNextAddressForAccount(accountIndex, ExternalChain)
+	return v.deriveNextAddressForAccount(signer, accountIndex, ExternalChain)
 }
 
 // DeriveNextInternalAddressForAccount returns the next unused change address for the
 // provided account and the corresponding output script
-func (v *Vault) DeriveNextInternalAddressForAccount(accountIndex int) (address string, script string, blindingPrivateKey []byte, err error) {
-	if v.isLocked() {
+func (v *Vault) DeriveNextInternalAddressForAccount(signer Signer, accountIndex int) (address string, script string, blindingPrivateKey []byte, err error) {
+	if v.isLocked(signer) {
 		return "", "", nil, ErrMustBeUnlocked
 	}
 
-	return v.deriveNextAddressForAccount(accountIndex, InternalChain)
+	return v.deriveNextAddressForAccount(signer, accountIndex, InternalChain)
 }
 
 // AccountByIndex returns the account with the given index
@@ -147,12 +189,9 @@ func (v *Vault) AllDerivedAddressesInfo() []AddressInfo {
 
 // AllDerivedAddressesAndBlindingKeysForAccount returns all the external and
 // internal addresses derived for the provided account along with the
-// respective private blinding keys
+// respective private blinding keys. Like AllDerivedAddressesInfo, this method
+// does not require the Vault to be unlocked.
 func (v *Vault) AllDerivedAddressesAndBlindingKeysForAccount(accountIndex int) ([]string, [][]byte, error) {
-	if v.isLocked() {
-		return nil, nil, ErrMustBeUnlocked
-	}
-
 	return v.allDerivedAddressesAndBlindingKeysForAccount(accountIndex)
 }
 
@@ -162,46 +201,39 @@ func (v *Vault) AllDerivedExternalAddressesForAccount(accountIndex int) (
 	[]string,
 	error,
 ) {
-	if v.isLocked() {
-		return nil, ErrMustBeUnlocked
-	}
-
 	return v.allDerivedExternalAddressesForAccount(accountIndex)
 }
 
 // isInitialized returnes whether the Vault has been inizitialized by checking
-// if the mnemonic has been encrypted, its plain text version has been wiped
-// and a passphrase (hash) has been set
+// if the mnemonic has been encrypted, either by the Vault or by the keystore
 func (v *Vault) isInitialized() bool {
-	return len(v.EncryptedMnemonic) > 0
+	return v.Sealed || len(v.EncryptedMnemonic) > 0
 }
 
 // isLocked returns whether the Vault is initialized and locked
-func (v *Vault) isLocked() bool {
-	return !v.isInitialized() || len(config.GetMnemonic()) == 0
-}
-
-func (v *Vault) isValidPassphrase(passphrase string) bool {
-	return bytes.Equal(v.PassphraseHash, btcutil.Hash160([]byte(passphrase)))
+func (v *Vault) isLocked(signer Signer) bool {
+	return !v.isInitialized() || signer.IsLocked()
 }
 
-func (v *Vault) isPassphraseSet() bool {
-	return len(v.PassphraseHash) > 0
-}
-
-func (v *Vault) deriveNextAddressForAccount(accountIndex, chainIndex int) (string, string, []byte, error) {
-	w, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
-		SigningMnemonic: config.GetMnemonic(),
-	})
-	if err != nil {
-		return "", "", nil, err
+// sealedKeystore returns the given signer as a SealedKeystore, or
+// ErrKeystoreNotSealed if it doesn't hold the mnemonic on its own
+func sealedKeystore(signer Signer) (SealedKeystore, error) {
+	sealed, ok := signer.(SealedKeystore)
+	if !ok {
+		return nil, ErrKeystoreNotSealed
 	}
+	return sealed, nil
+}
 
+func (v *Vault) deriveNextAddressForAccount(
+	signer Signer,
+	accountIndex, chainIndex int,
+) (addr string, script string, blindingKey []byte, err error) {
 	account, ok := v.Accounts[accountIndex]
 	if !ok {
 		account, err = NewAccount(accountIndex)
 		if err != nil {
-			return "", "", nil, err
+			return
 		}
 		v.Accounts[accountIndex] = account
 	}
@@ -214,19 +246,15 @@ func (v *Vault) deriveNextAddressForAccount(accountIndex, chainIndex int) (strin
 		"%d'/%d/%d",
 		account.AccountIndex, chainIndex, addressIndex,
 	)
-	addr, script, err := w.DeriveConfidentialAddress(wallet.DeriveConfidentialAddressOpts{
-		DerivationPath: derivationPath,
-		Network:        config.GetNetwork(),
-	})
+
+	addr, outputScript, blindingKey, err := signer.DeriveConfidentialAddress(
+		derivationPath,
+	)
 	if err != nil {
 		return "", "", nil, err
 	}
 
-	blindingKey, _, _ := w.DeriveBlindingKeyPair(wallet.DeriveBlindingKeyPairOpts{
-		Script: script,
-	})
-
-	account.addDerivationPath(hex.EncodeToString(script), derivationPath)
+	account.addDerivationPath(hex.EncodeToString(outputScript), derivationPath)
 	if chainIndex == InternalChain {
 		account.nextInternalIndex()
 	} else {
@@ -234,10 +262,10 @@ func (v *Vault) deriveNextAddressForAccount(accountIndex, chainIndex int) (strin
 	}
 	v.AccountAndKeyByAddress[addr] = AccountAndKey{
 		AccountIndex: account.AccountIndex,
-		BlindingKey:  blindingKey.Serialize(),
+		BlindingKey:  blindingKey,
 	}
 
-	return addr, hex.EncodeToString(script), blindingKey.Serialize(), err
+	return addr, hex.EncodeToString(outputScript), blindingKey, nil
 }
 
 func (v *Vault) allDerivedAddressesInfo() []AddressInfo {
@@ -269,40 +297,21 @@ func (v *Vault) allDerivedAddressesAndBlindingKeysForAccount(accountIndex int) (
 		return nil, nil, err
 	}
 
-	w, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
-		SigningMnemonic: config.GetMnemonic(),
-	})
-	if err != nil {
-		return nil, nil, err
-	}
-
-	addresses := make([]string, 0, account.LastExternalIndex+account.LastInternalIndex)
-	externalAddresses := deriveAddressesInRange(
-		w,
-		accountIndex,
+	externalAddresses, externalKeys := v.derivedAddressesInRange(
+		account,
 		ExternalChain,
 		0,
 		account.LastExternalIndex-1,
 	)
-	internalAddresses := deriveAddressesInRange(
-		w,
-		accountIndex,
+	internalAddresses, internalKeys := v.derivedAddressesInRange(
+		account,
 		InternalChain,
 		0,
 		account.LastInternalIndex-1,
 	)
-	addresses = append(addresses, externalAddresses...)
-	addresses = append(addresses, internalAddresses...)
-
-	blindingKeys := make([][]byte, 0, len(addresses))
-	for _, addr := range addresses {
-		script, _ := address.ToOutputScript(addr, *config.GetNetwork())
-		key, _, _ := w.DeriveBlindingKeyPair(wallet.DeriveBlindingKeyPairOpts{
-			Script: script,
-		})
-		blindingKeys = append(blindingKeys, key.Serialize())
-	}
 
+	addresses := append(externalAddresses, internalAddresses...)
+	blindingKeys := append(externalKeys, internalKeys...)
 	return addresses, blindingKeys, nil
 }
 
@@ -315,16 +324,8 @@ func (v *Vault) allDerivedExternalAddressesForAccount(accountIndex int) (
 		return nil, err
 	}
 
-	w, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
-		SigningMnemonic: config.GetMnemonic(),
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	externalAddresses := deriveAddressesInRange(
-		w,
-		accountIndex,
+	externalAddresses, _ := v.derivedAddressesInRange(
+		account,
 		ExternalChain,
 		0,
 		account.LastExternalIndex-1,
@@ -333,6 +334,101 @@ func (v *Vault) allDerivedExternalAddressesForAccount(accountIndex int) (
 	return externalAddresses, nil
 }
 
+// derivedAddressesInRange returns the addresses of the given account and
+// chain in the given range of derivation indexes, along with their private
+// blinding keys. Since these are stored in the Vault when addresses are
+// derived, the mnemonic is not required.
+func (v *Vault) derivedAddressesInRange(
+	account *Account,
+	chainIndex,
+	firstAddressIndex,
+	lastAddressIndex int,
+) ([]string, [][]byte) {
+	addressesByPath := map[string]string{}
+	for addr, info := range v.AccountAndKeyByAddress {
+		if info.AccountIndex != account.AccountIndex {
+			continue
+		}
+		script, _ := address.ToOutputScript(addr, *config.GetNetwork())
+		if path, ok := account.DerivationPathByScript[hex.EncodeToString(script)]; ok {
+			addressesByPath[path] = addr
+		}
+	}
+
+	addresses := make([]string, 0)
+	blindingKeys := make([][]byte, 0)
+	for i := firstAddressIndex; i <= lastAddressIndex; i++ {
+		derivationPath := fmt.Sprintf("%d'/%d/%d", account.AccountIndex, chainIndex, i)
+		addr, ok := addressesByPath[derivationPath]
+		if !ok {
+			continue
+		}
+		addresses = append(addresses, addr)
+		blindingKeys = append(blindingKeys, v.AccountAndKeyByAddress[addr].BlindingKey)
+	}
+	return addresses, blindingKeys
+}
+
+// encryptMnemonic encrypts the mnemonic with a key derived from the
+// passphrase with the configured KDF params
+func encryptMnemonic(mnemonic, passphrase string) (string, error) {
+	kdf := kdfParams()
+	return wallet.Encrypt(wallet.EncryptOpts{
+		PlainText:  mnemonic,
+		Passphrase: passphrase,
+		KDF:        &kdf,
+	})
+}
+
+func kdfParams() wallet.KDFParams {
+	if config.GetString(config.KDFKey) == wallet.KDFArgon2id {
+		return wallet.KDFParams{
+			Type:    wallet.KDFArgon2id,
+			Time:    uint32(config.GetInt(config.Argon2TimeKey)),
+			Memory:  uint32(config.GetInt(config.Argon2MemoryKey)),
+			Threads: uint8(config.GetInt(config.Argon2ThreadsKey)),
+		}
+	}
+	return wallet.KDFParams{
+		Type: wallet.KDFScrypt,
+		N:    config.GetInt(config.ScryptNKey),
+		R:    wallet.DefaultScryptParams.R,
+		P:    wallet.DefaultScryptParams.P,
+	}
+}
+
+// validatePassphrase returns ErrWeakPassphrase if the passphrase is shorter
+// than the configured min length or mixes less than the configured min number
+// of character classes among lowercase, uppercase, digits and symbols
+func validatePassphrase(passphrase string) error {
+	minLength := config.GetInt(config.PassphraseMinLengthKey)
+	minClasses := config.GetInt(config.PassphraseMinCharClassesKey)
+
+	var lower, upper, digit, symbol int
+	for _, r := range passphrase {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	if len([]rune(passphrase)) < minLength ||
+		lower+upper+digit+symbol < minClasses {
+		return fmt.Errorf(
+			"%w: must be at least %d characters long and mix at least %d among "+
+				"lowercase, uppercase, digits and symbols",
+			ErrWeakPassphrase, minLength, minClasses,
+		)
+	}
+	return nil
+}
+
 func validateAccountIndex(accIndex int) error {
 	if accIndex < 0 {
 		return errors.New("account index must be a positive integer number")
@@ -369,22 +465,3 @@ func (a *Account) addDerivationPath(outputScript, derivationPath string) {
 		a.DerivationPathByScript[outputScript] = derivationPath
 	}
 }
-
-func deriveAddressesInRange(
-	w *wallet.Wallet,
-	accountIndex,
-	chainIndex,
-	firstAddressIndex,
-	lastAddressIndex int,
-) []string {
-	addresses := make([]string, 0)
-	for i := firstAddressIndex; i <= lastAddressIndex; i++ {
-		derivationPath := fmt.Sprintf("%d'/%d/%d", accountIndex, chainIndex, i)
-		addr, _, _ := w.DeriveConfidentialAddress(wallet.DeriveConfidentialAddressOpts{
-			DerivationPath: derivationPath,
-			Network:        config.GetNetwork(),
-		})
-		addresses = append(addresses, addr)
-	}
-	return addresses
-}
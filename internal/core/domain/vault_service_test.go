@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/pkg/wallet"
+)
+
+var (
+	mnemonic = strings.Split(
+		"leave dice fine decrease dune ribbon ocean earn lunar account silver"+
+			" admit cheap fringe disorder trade because trade steak clock grace video jacket equal",
+		" ",
+	)
+	// mnemonic encrypted with passphrase "pass" in the legacy format
+	legacyEncryptedMnemonic = "dVoBFte1oeRkPl8Vf8DzBP3PRnzPA3fxtyvDHXFGYAS9MP8V2Sc9nHcQW4PrMkQNnf2uGrDg81dFgBrwqv1n3frXxRBKhp83fSsTm4xqj8+jdwTI3nouFmi1W/O4UqpHdQ62EYoabJQtKpptWO11TFJzw8WF02pfS6git8YjLR4xrnfp2LkOEjSU9CI82ZasF46WZFKcpeUJTAsxU/03ONpAdwwEsC96f1KAvh8tqaO0yLDOcmPf8a5B82jefgncCRrt32kCpbpIE4YiCFrqqdUHXKH+"
+	passphrase              = "Sup3rS3cr3tP4ssw0rd!"
+)
+
+func init() {
+	// keep key derivation cheap for tests
+	config.Set(config.ScryptNKey, 1024)
+}
+
+func TestValidatePassphrase(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		isValid    bool
+	}{
+		{"Sup3rS3cr3tP4ssw0rd!", true},
+		{"correct horse battery staple 42", true},
+		{"Sh0rt!", false},
+		{"onlylowercaseletters", false},
+		{"lowercaseand1234567", false},
+	}
+
+	for _, tt := range tests {
+		err := validatePassphrase(tt.passphrase)
+		if tt.isValid {
+			assert.NoError(t, err)
+		} else {
+			assert.True(t, errors.Is(err, ErrWeakPassphrase))
+		}
+	}
+}
+
+func TestNewVaultWeakPassphrase(t *testing.T) {
+	_, err := NewVault(mnemonic, "pass")
+	assert.True(t, errors.Is(err, ErrWeakPassphrase))
+}
+
+func TestVaultChangePassphrase(t *testing.T) {
+	v, err := NewVault(mnemonic, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := &mockedKeystore{}
+
+	err = v.ChangePassphrase(ks, "wrongPass", "N3wSup3rS3cr3tP4ss!")
+	assert.Equal(t, ErrInvalidPassphrase, err)
+
+	err = v.ChangePassphrase(ks, passphrase, "newPass")
+	assert.True(t, errors.Is(err, ErrWeakPassphrase))
+
+	err = v.ChangePassphrase(ks, passphrase, "N3wSup3rS3cr3tP4ss!")
+	assert.NoError(t, err)
+
+	assert.Equal(t, wallet.ErrInvalidPassphrase, v.Unlock(ks, passphrase))
+	assert.NoError(t, v.Unlock(ks, "N3wSup3rS3cr3tP4ss!"))
+	assert.Equal(t, mnemonic, ks.mnemonic)
+}
+
+func TestVaultUnlockMigratesEncryptedMnemonic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+
+	v := &Vault{
+		EncryptedMnemonic:      legacyEncryptedMnemonic,
+		Accounts:               map[int]*Account{},
+		AccountAndKeyByAddress: map[string]AccountAndKey{},
+	}
+	ks := &mockedKeystore{}
+
+	assert.Equal(t, wallet.ErrInvalidPassphrase, v.Unlock(ks, "wrongPass"))
+	assert.Equal(t, legacyEncryptedMnemonic, v.EncryptedMnemonic)
+
+	if err := v.Unlock(ks, "pass"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, mnemonic, ks.mnemonic)
+	assert.NotEqual(t, legacyEncryptedMnemonic, v.EncryptedMnemonic)
+	assert.Equal(t, false, wallet.NeedsMigration(v.EncryptedMnemonic, kdfParams()))
+
+	ks.Lock()
+	assert.NoError(t, v.Unlock(ks, "pass"))
+}
+
+func TestSealedVault(t *testing.T) {
+	v := NewSealedVault()
+	ks := &mockedSealedKeystore{passphrase: passphrase}
+
+	assert.Equal(t, true, v.isInitialized())
+	assert.Equal(t, ErrKeystoreNotSealed, v.Unlock(&mockedKeystore{}, passphrase))
+	assert.Equal(t, ErrInvalidPassphrase, v.Unlock(ks, "wrongPass"))
+	assert.Equal(t, true, v.IsValidPassphrase(ks, passphrase))
+
+	if err := v.Unlock(ks, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, ks.IsLocked())
+	assert.Equal(
+		t, ErrMustBeLocked, v.ChangePassphrase(ks, passphrase, "N3wSup3rS3cr3tP4ss!"),
+	)
+
+	ks.Lock()
+	assert.NoError(t, v.ChangePassphrase(ks, passphrase, "N3wSup3rS3cr3tP4ss!"))
+	assert.Equal(t, "N3wSup3rS3cr3tP4ss!", ks.passphrase)
+	assert.Equal(t, "", v.EncryptedMnemonic)
+}
+
+type mockedKeystore struct {
+	Signer
+	mnemonic []string
+}
+
+func (m *mockedKeystore) IsLocked() bool {
+	return m.mnemonic == nil
+}
+
+func (m *mockedKeystore) Unlock(mnemonic []string) error {
+	m.mnemonic = mnemonic
+	return nil
+}
+
+func (m *mockedKeystore) Lock() {
+	m.mnemonic = nil
+}
+
+type mockedSealedKeystore struct {
+	Signer
+	passphrase string
+	unlocked   bool
+}
+
+func (m *mockedSealedKeystore) IsLocked() bool {
+	return !m.unlocked
+}
+
+func (m *mockedSealedKeystore) Unlock(mnemonic []string) error {
+	return ErrSealedKeystore
+}
+
+func (m *mockedSealedKeystore) Lock() {
+	m.unlocked = false
+}
+
+func (m *mockedSealedKeystore) UnlockWithPassphrase(passphrase string) error {
+	if passphrase != m.passphrase {
+		return ErrInvalidPassphrase
+	}
+	m.unlocked = true
+	return nil
+}
+
+func (m *mockedSealedKeystore) ChangePassphrase(
+	currentPassphrase, newPassphrase string,
+) error {
+	if currentPassphrase != m.passphrase {
+		return ErrInvalidPassphrase
+	}
+	m.passphrase = newPassphrase
+	return nil
+}
+
+func (m *mockedSealedKeystore) IsValidPassphrase(passphrase string) bool {
+	return passphrase == m.passphrase
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/vulpemventures/go-elements/address"
+)
+
+// WithdrawalAddress defines the entity data structure for an address
+// whitelisted by the operator as destination of the funds sent away from the
+// daemon. It can be paid only once its cool-down period is over
+type WithdrawalAddress struct {
+	// Script is the hex encoded output script of the address, the unique
+	// identifier of the entry regardless of the blinding key
+	Script     string
+	Address    string
+	Label      string
+	CreatedAt  uint64
+	ActiveFrom uint64
+}
+
+// NewWithdrawalAddress returns a new WithdrawalAddress for the given
+// confidential address that can be paid after the given cool-down
+func NewWithdrawalAddress(
+	addr, label string,
+	coolDown time.Duration,
+) (*WithdrawalAddress, error) {
+	script, err := address.ToOutputScript(addr, *config.GetNetwork())
+	if err != nil {
+		return nil, ErrInvalidWithdrawalAddress
+	}
+	if _, err := address.FromConfidential(addr); err != nil {
+		return nil, ErrInvalidWithdrawalAddress
+	}
+
+	now := time.Now()
+	return &WithdrawalAddress{
+		Script:     hex.EncodeToString(script),
+		Address:    addr,
+		Label:      label,
+		CreatedAt:  uint64(now.Unix()),
+		ActiveFrom: uint64(now.Add(coolDown).Unix()),
+	}, nil
+}
+
+// IsActive returns whether the cool-down period of the address is over
+func (w *WithdrawalAddress) IsActive() bool {
+	return uint64(time.Now().Unix()) >= w.ActiveFrom
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// WithdrawalAddressRepository defines the abstraction for WithdrawalAddress
+type WithdrawalAddressRepository interface {
+	// Adds the given address to the whitelist, returns
+	// ErrWithdrawalAddressAlreadyWhitelisted if already existing
+	AddWithdrawalAddress(ctx context.Context, withdrawalAddress WithdrawalAddress) error
+	// Removes the address with the given script from the whitelist, returns
+	// ErrWithdrawalAddressNotFound if not existing
+	RemoveWithdrawalAddress(ctx context.Context, script string) error
+	// Retrieves the address with the given script, nil if not whitelisted
+	GetWithdrawalAddressByScript(
+		ctx context.Context,
+		script string,
+	) (*WithdrawalAddress, error)
+	// Retrieves all the whitelisted addresses, sorted by creation time
+	GetAllWithdrawalAddresses(ctx context.Context) ([]WithdrawalAddress, error)
+}
@@ -0,0 +1,15 @@
+package domain
+
+// Withdrawal defines the Withdrawal entity data structure for holding the
+// funds moved away from a market account by the operator
+type Withdrawal struct {
+	TxID            string
+	AccountIndex    int
+	BaseAsset       string
+	BaseAmount      uint64
+	QuoteAsset      string
+	QuoteAmount     uint64
+	MillisatPerByte int64
+	Address         string
+	Timestamp       uint64
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// WithdrawalRepository defines the abstraction for Withdrawal
+type WithdrawalRepository interface {
+	// Adds the given withdrawals to the storage. Already existing withdrawals
+	// are skipped and the number of the actually added ones is returned
+	AddWithdrawals(ctx context.Context, withdrawals []Withdrawal) (int, error)
+	// Retrieves all the withdrawals stored, sorted by timestamp
+	GetAllWithdrawals(ctx context.Context) ([]Withdrawal, error)
+	// Retrieves a page of at most limit withdrawals, skipping the first offset
+	// ones, made in the given time range, both ends included, sorted by
+	// timestamp
+	GetWithdrawalsByTime(
+		ctx context.Context,
+		fromTime, toTime uint64,
+		offset, limit int,
+	) ([]Withdrawal, error)
+}
@@ -0,0 +1,39 @@
+package ports
+
+// AuditLog interface defines the methods of the append-only log recording
+// the calls made on the operator interface. Every entry is chained to the
+// previous one through its hash so that any modification, insertion or
+// removal of past entries can be detected.
+type AuditLog interface {
+	Append(entry AuditEntry) (*AuditEntry, error)
+	Entries(fromTime, toTime uint64) ([]AuditEntry, error)
+	Verify() (*AuditLogVerification, error)
+	Close() error
+}
+
+// AuditEntry is a record of the audit log. Index, PrevHash and Hash are set
+// when the entry is appended.
+type AuditEntry struct {
+	Index     uint64 `json:"index"`
+	Timestamp uint64 `json:"timestamp"`
+	Method    string `json:"method"`
+	// Caller identifies who made the call, either by macaroon or by address
+	Caller string `json:"caller"`
+	// Params is the JSON encoded request with secrets redacted
+	Params   string `json:"params"`
+	Result   string `json:"result"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// AuditLogVerification is the result of the verification of the audit log.
+// LastHash can be stored elsewhere to detect a truncation of the log later.
+type AuditLogVerification struct {
+	Valid        bool   `json:"valid"`
+	EntriesCount uint64 `json:"entries_count"`
+	LastHash     string `json:"last_hash"`
+	// FirstInvalidIndex and Reason are set only if the log is not valid
+	FirstInvalidIndex uint64 `json:"first_invalid_index,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
@@ -0,0 +1,188 @@
+package auditlog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+)
+
+// maxEntrySize is the max size of a line of the log file. Requests bigger
+// than this are not expected on the operator interface.
+const maxEntrySize = 4 * 1024 * 1024
+
+// auditLog is an append-only log stored as a JSON lines file. Every entry
+// commits to the hash of the previous one, so that the whole history is
+// bound to the hash of the last entry.
+type auditLog struct {
+	path      string
+	file      *os.File
+	nextIndex uint64
+	lastHash  string
+	lock      *sync.RWMutex
+}
+
+// NewAuditLog opens, or creates if not existing, the audit log at the given
+// path. New entries are chained to the last one found in the file.
+func NewAuditLog(path string) (ports.AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &auditLog{
+		path: path,
+		file: file,
+		lock: &sync.RWMutex{},
+	}
+	if err := a.forEachEntry(func(entry *ports.AuditEntry, err error) bool {
+		if err == nil {
+			a.nextIndex = entry.Index + 1
+			a.lastHash = entry.Hash
+		}
+		return true
+	}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// Close closes the file of the audit log
+func (a *auditLog) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.file.Close()
+}
+
+func (a *auditLog) Append(entry ports.AuditEntry) (*ports.AuditEntry, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if entry.Timestamp == 0 {
+		entry.Timestamp = uint64(time.Now().Unix())
+	}
+	entry.Index = a.nextIndex
+	entry.PrevHash = a.lastHash
+	entry.Hash = hashEntry(entry)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	if err := a.file.Sync(); err != nil {
+		return nil, err
+	}
+
+	a.nextIndex++
+	a.lastHash = entry.Hash
+	return &entry, nil
+}
+
+func (a *auditLog) Entries(fromTime, toTime uint64) ([]ports.AuditEntry, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	entries := make([]ports.AuditEntry, 0)
+	var malformed error
+	if err := a.forEachEntry(func(entry *ports.AuditEntry, err error) bool {
+		if err != nil {
+			malformed = err
+			return false
+		}
+		if entry.Timestamp >= fromTime &&
+			(toTime == 0 || entry.Timestamp <= toTime) {
+			entries = append(entries, *entry)
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	if malformed != nil {
+		return nil, malformed
+	}
+	return entries, nil
+}
+
+func (a *auditLog) Verify() (*ports.AuditLogVerification, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	result := &ports.AuditLogVerification{Valid: true}
+	invalidate := func(reason string) bool {
+		result.Valid = false
+		result.FirstInvalidIndex = result.EntriesCount
+		result.Reason = reason
+		return false
+	}
+
+	if err := a.forEachEntry(func(entry *ports.AuditEntry, err error) bool {
+		if err != nil {
+			return invalidate(err.Error())
+		}
+		if entry.Index != result.EntriesCount {
+			return invalidate(fmt.Sprintf(
+				"expected index %d, got %d", result.EntriesCount, entry.Index,
+			))
+		}
+		if entry.PrevHash != result.LastHash {
+			return invalidate("previous hash does not match")
+		}
+		if entry.Hash != hashEntry(*entry) {
+			return invalidate("hash does not match")
+		}
+		result.EntriesCount++
+		result.LastHash = entry.Hash
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// forEachEntry calls the given function for every line of the log file, with
+// the parsed entry or an error if malformed, until it returns false
+func (a *auditLog) forEachEntry(
+	f func(entry *ports.AuditEntry, err error) bool,
+) error {
+	file, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		entry := &ports.AuditEntry{}
+		var err error
+		if jsonErr := json.Unmarshal(scanner.Bytes(), entry); jsonErr != nil {
+			err = fmt.Errorf("malformed entry at line %d: %s", line, jsonErr)
+		}
+		if !f(entry, err) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// hashEntry returns the hex encoded sha256 hash of the JSON encoding of the
+// given entry, hash excluded
+func hashEntry(entry ports.AuditEntry) string {
+	entry.Hash = ""
+	buf, _ := json.Marshal(entry)
+	hash := sha256.Sum256(buf)
+	return hex.EncodeToString(hash[:])
+}
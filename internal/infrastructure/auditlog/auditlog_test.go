@@ -0,0 +1,131 @@
+package auditlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+)
+
+func newTestAuditLog(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "auditlog")
+	require.NoError(t, err)
+	return filepath.Join(dir, "audit.log"), func() { os.RemoveAll(dir) }
+}
+
+func appendEntries(t *testing.T, a ports.AuditLog, timestamps ...uint64) {
+	for _, ts := range timestamps {
+		_, err := a.Append(ports.AuditEntry{
+			Timestamp: ts,
+			Method:    "/Operator/OpenMarket",
+			Caller:    "peer:127.0.0.1:50000",
+			Params:    `{"market":{}}`,
+			Result:    "OK",
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	path, cleanup := newTestAuditLog(t)
+	defer cleanup()
+
+	a, err := NewAuditLog(path)
+	require.NoError(t, err)
+	appendEntries(t, a, 100, 200)
+	require.NoError(t, a.Close())
+
+	// entries appended after reopening are chained to the existing ones
+	a, err = NewAuditLog(path)
+	require.NoError(t, err)
+	defer a.Close()
+	appendEntries(t, a, 300)
+
+	entries, err := a.Entries(0, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for i, e := range entries {
+		assert.Equal(t, uint64(i), e.Index)
+		if i > 0 {
+			assert.Equal(t, entries[i-1].Hash, e.PrevHash)
+		}
+	}
+
+	result, err := a.Verify()
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, uint64(3), result.EntriesCount)
+	assert.Equal(t, entries[2].Hash, result.LastHash)
+
+	entries, err = a.Entries(150, 250)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(200), entries[0].Timestamp)
+}
+
+func TestAuditLogTampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		index  uint64
+	}{
+		{
+			name: "modified entry",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], "OpenMarket", "CloseMarket", 1)
+				return lines
+			},
+			index: 1,
+		},
+		{
+			name: "removed entry",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			index: 1,
+		},
+		{
+			name: "malformed entry",
+			tamper: func(lines []string) []string {
+				lines[2] = lines[2][:10]
+				return lines
+			},
+			index: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup := newTestAuditLog(t)
+			defer cleanup()
+
+			a, err := NewAuditLog(path)
+			require.NoError(t, err)
+			appendEntries(t, a, 100, 200, 300)
+			require.NoError(t, a.Close())
+
+			buf, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+			lines = tt.tamper(lines)
+			require.NoError(t, ioutil.WriteFile(
+				path, []byte(strings.Join(lines, "\n")+"\n"), 0600,
+			))
+
+			a, err = NewAuditLog(path)
+			require.NoError(t, err)
+			defer a.Close()
+
+			result, err := a.Verify()
+			require.NoError(t, err)
+			assert.False(t, result.Valid)
+			assert.Equal(t, tt.index, result.FirstInvalidIndex)
+			assert.NotEmpty(t, result.Reason)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package keystore
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/wallet"
+)
+
+// keystore holds the signing and blinding master keys of the daemon's wallet
+// in a single buffer that, when supported by the OS, is locked into RAM so
+// that it never gets swapped to disk. The buffer is zeroed when the keystore
+// gets locked, either explicitly or after being unused for autoLockTimeout.
+type keystore struct {
+	buffer           []byte
+	signingKeyLength int
+	autoLockTimeout  time.Duration
+	autoLockTimer    *time.Timer
+	// autoLockTimerID identifies the last started timer, so that one already
+	// fired while being reset doesn't lock the keystore
+	autoLockTimerID uint64
+	lock            *sync.RWMutex
+	timerLock       *sync.Mutex
+}
+
+// NewKeystore returns a new locked keystore. If autoLockTimeout is greater
+// than zero, the keystore locks itself once not used for such time.
+func NewKeystore(autoLockTimeout time.Duration) domain.Keystore {
+	return &keystore{
+		autoLockTimeout: autoLockTimeout,
+		lock:            &sync.RWMutex{},
+		timerLock:       &sync.Mutex{},
+	}
+}
+
+func (k *keystore) IsLocked() bool {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.buffer == nil
+}
+
+func (k *keystore) Unlock(mnemonic []string) error {
+	w, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
+		SigningMnemonic: mnemonic,
+	})
+	if err != nil {
+		return err
+	}
+	signingMasterKey, err := w.SigningMasterKey()
+	if err != nil {
+		return err
+	}
+	blindingMasterKey, err := w.BlindingMasterKey()
+	if err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.wipe()
+	k.buffer = make([]byte, len(signingMasterKey)+len(blindingMasterKey))
+	if err := mlock(k.buffer); err != nil {
+		log.WithError(err).Warn("unable to lock keystore memory")
+	}
+	k.signingKeyLength = copy(k.buffer, signingMasterKey)
+	copy(k.buffer[k.signingKeyLength:], blindingMasterKey)
+	zero(signingMasterKey)
+	zero(blindingMasterKey)
+
+	k.resetAutoLockTimer()
+	return nil
+}
+
+func (k *keystore) Lock() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.wipe()
+}
+
+func (k *keystore) DeriveConfidentialAddress(derivationPath string) (
+	addr string,
+	script []byte,
+	blindingKey []byte,
+	err error,
+) {
+	err = k.withWallet(func(w *wallet.Wallet) error {
+		var err error
+		addr, script, err = w.DeriveConfidentialAddress(
+			wallet.DeriveConfidentialAddressOpts{
+				DerivationPath: derivationPath,
+				Network:        config.GetNetwork(),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		key, _, err := w.DeriveBlindingKeyPair(wallet.DeriveBlindingKeyPairOpts{
+			Script: script,
+		})
+		if err != nil {
+			return err
+		}
+		blindingKey = key.Serialize()
+		return nil
+	})
+	return
+}
+
+func (k *keystore) AcceptSwap(
+	opts domain.AcceptSwapOpts,
+) (res *domain.AcceptSwapResult, err error) {
+	err = k.withWallet(func(w *wallet.Wallet) error {
+		var err error
+		res, err = acceptSwap(w, opts)
+		return err
+	})
+	return
+}
+
+func (k *keystore) SendToMany(
+	opts domain.SendToManyOpts,
+) (txHex string, txID string, err error) {
+	err = k.withWallet(func(w *wallet.Wallet) error {
+		var err error
+		txHex, txID, err = sendToMany(w, opts)
+		return err
+	})
+	return
+}
+
+// withWallet calls fn with a wallet made of the keys held in memory, or
+// returns ErrMustBeUnlocked if the keystore is locked. The wallet must not be
+// used after fn returns.
+func (k *keystore) withWallet(fn func(w *wallet.Wallet) error) error {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	if k.buffer == nil {
+		return domain.ErrMustBeUnlocked
+	}
+	k.resetAutoLockTimer()
+
+	w, err := wallet.NewWalletFromMasterKeys(wallet.NewWalletFromMasterKeysOpts{
+		SigningMasterKey:  k.buffer[:k.signingKeyLength],
+		BlindingMasterKey: k.buffer[k.signingKeyLength:],
+	})
+	if err != nil {
+		return err
+	}
+	return fn(w)
+}
+
+// wipe zeroes and releases the buffer. It must be called with the write lock
+// held.
+func (k *keystore) wipe() {
+	k.timerLock.Lock()
+	if k.autoLockTimer != nil {
+		k.autoLockTimer.Stop()
+		k.autoLockTimer = nil
+	}
+	k.autoLockTimerID++
+	k.timerLock.Unlock()
+
+	if k.buffer == nil {
+		return
+	}
+	zero(k.buffer)
+	munlock(k.buffer)
+	k.buffer = nil
+	k.signingKeyLength = 0
+}
+
+func (k *keystore) resetAutoLockTimer() {
+	if k.autoLockTimeout <= 0 {
+		return
+	}
+
+	k.timerLock.Lock()
+	defer k.timerLock.Unlock()
+
+	if k.autoLockTimer != nil {
+		k.autoLockTimer.Stop()
+	}
+	k.autoLockTimerID++
+	timerID := k.autoLockTimerID
+	k.autoLockTimer = time.AfterFunc(k.autoLockTimeout, func() {
+		k.lock.Lock()
+		defer k.lock.Unlock()
+
+		k.timerLock.Lock()
+		isLastTimer := timerID == k.autoLockTimerID
+		k.timerLock.Unlock()
+
+		if isLastTimer && k.buffer != nil {
+			k.wipe()
+			log.Info("keystore locked after being unused for ", k.autoLockTimeout)
+		}
+	})
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,73 @@
+package keystore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/wallet"
+)
+
+var mnemonic = strings.Split(
+	"leave dice fine decrease dune ribbon ocean earn lunar account silver"+
+		" admit cheap fringe disorder trade because trade steak clock grace video jacket equal",
+	" ",
+)
+
+func TestKeystore(t *testing.T) {
+	ks := NewKeystore(0)
+	assert.Equal(t, true, ks.IsLocked())
+
+	err := ks.(*keystore).withWallet(func(w *wallet.Wallet) error { return nil })
+	assert.Equal(t, domain.ErrMustBeUnlocked, err)
+
+	if err := ks.Unlock(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, ks.IsLocked())
+
+	expected, err := wallet.NewWalletFromMnemonic(wallet.NewWalletFromMnemonicOpts{
+		SigningMnemonic: mnemonic,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedKey, _ := expected.SigningMasterKey()
+
+	err = ks.(*keystore).withWallet(func(w *wallet.Wallet) error {
+		key, err := w.SigningMasterKey()
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, expectedKey, key)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	ks.Lock()
+	assert.Equal(t, true, ks.IsLocked())
+}
+
+func TestKeystoreAutoLock(t *testing.T) {
+	ks := NewKeystore(100 * time.Millisecond)
+	if err := ks.Unlock(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, ks.(*keystore).withWallet(func(w *wallet.Wallet) error { return nil }))
+	// using the keystore resets the timer
+	time.Sleep(70 * time.Millisecond)
+	assert.Equal(t, false, ks.IsLocked())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, true, ks.IsLocked())
+}
+
+func TestFailingKeystoreUnlock(t *testing.T) {
+	ks := NewKeystore(0)
+	assert.Error(t, ks.Unlock([]string{"invalid", "mnemonic"}))
+	assert.Equal(t, true, ks.IsLocked())
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package keystore
+
+// mlock is a no-op on systems where locking memory is not supported
+func mlock(b []byte) error {
+	return nil
+}
+
+func munlock(b []byte) {}
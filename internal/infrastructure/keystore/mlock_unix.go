@@ -0,0 +1,14 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package keystore
+
+import "golang.org/x/sys/unix"
+
+func mlock(b []byte) error {
+	return unix.Mlock(b)
+}
+
+func munlock(b []byte) {
+	unix.Munlock(b)
+}
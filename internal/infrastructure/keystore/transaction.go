@@ -0,0 +1,263 @@
+package keystore
+
+import (
+	"encoding/hex"
+
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/tdex-network/tdex-daemon/pkg/transactionutil"
+	"github.com/tdex-network/tdex-daemon/pkg/wallet"
+	"github.com/vulpemventures/go-elements/pset"
+)
+
+func acceptSwap(
+	w *wallet.Wallet,
+	opts domain.AcceptSwapOpts,
+) (res *domain.AcceptSwapResult, err error) {
+	network := config.GetNetwork()
+	// fill swap request transaction with daemon's inputs and outputs, market
+	// by market
+	psetBase64 := opts.SwapRequest.GetTransaction()
+	selectedUnspentsForSwap := make([]explorer.Utxo, 0)
+	for _, marketSwap := range opts.MarketSwaps {
+		var selectedUnspents []explorer.Utxo
+		psetBase64, selectedUnspents, err = w.UpdateSwapTx(wallet.UpdateSwapTxOpts{
+			PsetBase64:           psetBase64,
+			Unspents:             marketSwap.Unspents,
+			InputAmount:          marketSwap.InputAmount,
+			InputAsset:           marketSwap.InputAsset,
+			OutputAmount:         marketSwap.OutputAmount,
+			OutputAsset:          marketSwap.OutputAsset,
+			OutputDerivationPath: marketSwap.OutputDerivationPath,
+			ChangeDerivationPath: marketSwap.ChangeDerivationPath,
+			Network:              network,
+		})
+		if err != nil {
+			return
+		}
+		selectedUnspentsForSwap = append(selectedUnspentsForSwap, selectedUnspents...)
+	}
+
+	// top-up fees using fee account. Note that the fee output is added after
+	// blinding the transaction because it's explicit and must not be blinded
+	psetWithFeesResult, err := w.UpdateTx(wallet.UpdateTxOpts{
+		PsetBase64:        psetBase64,
+		Unspents:          opts.FeeUnspents,
+		MilliSatsPerBytes: domain.MinMilliSatPerByte,
+		Network:           network,
+		ChangePathsByAsset: map[string]string{
+			network.AssetID: opts.FeeChangeDerivationPath,
+		},
+		WantPrivateBlindKeys: true,
+		WantChangeForFees:    true,
+	})
+	if err != nil {
+		return
+	}
+
+	// concat the selected unspents for paying fees with those for completing the
+	// swap in order to get the full list of selected inputs
+	selectedUnspents := append(selectedUnspentsForSwap, psetWithFeesResult.SelectedUnspents...)
+
+	// get blinding private keys for selected inputs
+	unspentsBlindingKeys := mergeBlindingKeys(opts.MarketBlindingKeysByScript, opts.FeeBlindingKeysByScript)
+	selectedInBlindingKeys := getSelectedBlindingKeys(unspentsBlindingKeys, selectedUnspents)
+	// ... and merge with those contained into the swapRequest (trader keys)
+	inputBlindingKeys := mergeBlindingKeys(opts.SwapRequest.GetInputBlindingKey(), selectedInBlindingKeys)
+
+	// same for output  public blinding keys
+	outputBlindingKeys := mergeBlindingKeys(
+		opts.OutputBlindingKeysByScript,
+		psetWithFeesResult.ChangeOutputsBlindingKeys,
+		opts.SwapRequest.GetOutputBlindingKey(),
+	)
+
+	// blind the transaction
+	blindedPset, err := w.BlindSwapTransaction(wallet.BlindSwapTransactionOpts{
+		PsetBase64:         psetWithFeesResult.PsetBase64,
+		InputBlindingKeys:  inputBlindingKeys,
+		OutputBlindingKeys: outputBlindingKeys,
+	})
+	if err != nil {
+		return
+	}
+
+	// add the explicit fee output to the tx
+	blindedPlusFees, err := w.UpdateTx(wallet.UpdateTxOpts{
+		PsetBase64: blindedPset,
+		Outputs:    transactionutil.NewFeeOutput(psetWithFeesResult.FeeAmount),
+		Network:    network,
+	})
+	if err != nil {
+		return
+	}
+
+	// get the indexes of the inputs of the tx to sign
+	inputsToSign := getInputsIndexes(psetWithFeesResult.PsetBase64, selectedUnspents)
+	// get the derivation paths of the selected inputs
+	unspentsDerivationPaths := mergeDerivationPaths(opts.MarketDerivationPaths, opts.FeeDerivationPaths)
+	derivationPaths := getSelectedDerivationPaths(unspentsDerivationPaths, selectedUnspents)
+
+	signedPsetBase64 := blindedPlusFees.PsetBase64
+	for i, inIndex := range inputsToSign {
+		signedPsetBase64, err = w.SignInput(wallet.SignInputOpts{
+			PsetBase64:     signedPsetBase64,
+			InIndex:        inIndex,
+			DerivationPath: derivationPaths[i],
+		})
+	}
+
+	res = &domain.AcceptSwapResult{
+		PsetBase64:         signedPsetBase64,
+		SelectedUnspents:   selectedUnspents,
+		InputBlindingKeys:  inputBlindingKeys,
+		OutputBlindingKeys: outputBlindingKeys,
+	}
+
+	return
+}
+
+func getInputsIndexes(psetBase64 string, unspents []explorer.Utxo) []uint32 {
+	indexes := make([]uint32, 0, len(unspents))
+
+	ptx, _ := pset.NewPsetFromBase64(psetBase64)
+	for _, u := range unspents {
+		for i, in := range ptx.UnsignedTx.Inputs {
+			if u.Hash() == bufferutil.TxIDFromBytes(in.Hash) && u.Index() == in.Index {
+				indexes = append(indexes, uint32(i))
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+func sendToMany(
+	w *wallet.Wallet,
+	opts domain.SendToManyOpts,
+) (string, string, error) {
+	// default to MinMilliSatPerByte if needed
+	milliSatPerByte := opts.MilliSatPerByte
+	if milliSatPerByte < domain.MinMilliSatPerByte {
+		milliSatPerByte = domain.MinMilliSatPerByte
+	}
+
+	// create the transaction
+	newPset, err := w.CreateTx()
+	if err != nil {
+		return "", "", err
+	}
+
+	// add inputs and outputs
+	updateResult, err := w.UpdateTx(wallet.UpdateTxOpts{
+		PsetBase64:         newPset,
+		Unspents:           opts.Unspents,
+		Outputs:            opts.Outputs,
+		ChangePathsByAsset: opts.ChangePathsByAsset,
+		MilliSatsPerBytes:  milliSatPerByte,
+		Network:            config.GetNetwork(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// update the list of output blinding keys with those of the eventual changes
+	outputsBlindingKeys := opts.OutputsBlindingKeys
+	for _, v := range updateResult.ChangeOutputsBlindingKeys {
+		outputsBlindingKeys = append(outputsBlindingKeys, v)
+	}
+
+	// add inputs for paying network fees
+	updateResult, err = w.UpdateTx(wallet.UpdateTxOpts{
+		PsetBase64:         updateResult.PsetBase64,
+		Unspents:           opts.FeeUnspents,
+		ChangePathsByAsset: opts.FeeChangePathByAsset,
+		MilliSatsPerBytes:  milliSatPerByte,
+		Network:            config.GetNetwork(),
+		WantChangeForFees:  true,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// again, add changes' blinding keys to the list of those of the outputs
+	for _, v := range updateResult.ChangeOutputsBlindingKeys {
+		outputsBlindingKeys = append(outputsBlindingKeys, v)
+	}
+
+	// blind the transaction
+	blindedPset, err := w.BlindTransaction(wallet.BlindTransactionOpts{
+		PsetBase64:         updateResult.PsetBase64,
+		OutputBlindingKeys: outputsBlindingKeys,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// add the explicit fee amount
+	blindedPlusFees, err := w.UpdateTx(wallet.UpdateTxOpts{
+		PsetBase64: blindedPset,
+		Outputs:    transactionutil.NewFeeOutput(updateResult.FeeAmount),
+		Network:    config.GetNetwork(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// sign the inputs
+	inputPathsByScript := mergeDerivationPaths(opts.InputPathsByScript, opts.FeeInputPathsByScript)
+	signedPset, err := w.SignTransaction(wallet.SignTransactionOpts{
+		PsetBase64:        blindedPlusFees.PsetBase64,
+		DerivationPathMap: inputPathsByScript,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// finalize, extract and return the transaction
+	return wallet.FinalizeAndExtractTransaction(
+		wallet.FinalizeAndExtractTransactionOpts{
+			PsetBase64: signedPset,
+		},
+	)
+}
+
+func mergeBlindingKeys(maps ...map[string][]byte) map[string][]byte {
+	merge := make(map[string][]byte, 0)
+	for _, m := range maps {
+		for k, v := range m {
+			merge[k] = v
+		}
+	}
+	return merge
+}
+
+func mergeDerivationPaths(maps ...map[string]string) map[string]string {
+	merge := make(map[string]string, 0)
+	for _, m := range maps {
+		for k, v := range m {
+			merge[k] = v
+		}
+	}
+	return merge
+}
+
+func getSelectedDerivationPaths(derivationPaths map[string]string, unspents []explorer.Utxo) []string {
+	selectedPaths := make([]string, 0)
+	for _, unspent := range unspents {
+		script := hex.EncodeToString(unspent.Script())
+		selectedPaths = append(selectedPaths, derivationPaths[script])
+	}
+	return selectedPaths
+}
+
+func getSelectedBlindingKeys(blindingKeys map[string][]byte, unspents []explorer.Utxo) map[string][]byte {
+	selectedKeys := map[string][]byte{}
+	for _, unspent := range unspents {
+		script := hex.EncodeToString(unspent.Script())
+		selectedKeys[script] = blindingKeys[script]
+	}
+	return selectedKeys
+}
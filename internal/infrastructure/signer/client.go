@@ -0,0 +1,182 @@
+package signer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// callTimeout is the deadline of every call to the remote signer
+const callTimeout = 30 * time.Second
+
+// remoteKeystore is a domain.SealedKeystore whose mnemonic and keys are held
+// by a tdex-signer process, so that the daemon never has them in memory and
+// can't blind and sign any transaction not allowed by the signer's policy.
+type remoteKeystore struct {
+	client rpcext.SignerClient
+}
+
+// NewRemoteKeystore returns a keystore that delegates every operation to the
+// tdex-signer listening at the given address, over a connection mutually
+// authenticated with the given TLS config
+func NewRemoteKeystore(
+	addr string,
+	tlsConfig *tls.Config,
+) (domain.SealedKeystore, error) {
+	conn, err := grpc.Dial(
+		addr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newRemoteKeystore(conn), nil
+}
+
+func newRemoteKeystore(cc grpc.ClientConnInterface) domain.SealedKeystore {
+	return &remoteKeystore{rpcext.NewSignerClient(cc)}
+}
+
+func (r *remoteKeystore) IsLocked() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	reply, err := r.client.IsLocked(ctx, &rpcext.IsLockedRequest{})
+	if err != nil {
+		log.WithError(err).Warn("unable to reach remote signer")
+		return true
+	}
+	return reply.Locked
+}
+
+// Unlock always fails since the mnemonic is held by the signer and must never
+// be sent to it by the daemon
+func (r *remoteKeystore) Unlock(mnemonic []string) error {
+	return domain.ErrSealedKeystore
+}
+
+func (r *remoteKeystore) UnlockWithPassphrase(passphrase string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	_, err := r.client.Unlock(ctx, &rpcext.UnlockRequest{Passphrase: passphrase})
+	return fromStatusError(err)
+}
+
+func (r *remoteKeystore) ChangePassphrase(
+	currentPassphrase,
+	newPassphrase string,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	_, err := r.client.ChangePassphrase(ctx, &rpcext.ChangePassphraseRequest{
+		CurrentPassphrase: currentPassphrase,
+		NewPassphrase:     newPassphrase,
+	})
+	return fromStatusError(err)
+}
+
+func (r *remoteKeystore) IsValidPassphrase(passphrase string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	reply, err := r.client.IsValidPassphrase(
+		ctx, &rpcext.IsValidPassphraseRequest{Passphrase: passphrase},
+	)
+	if err != nil {
+		log.WithError(err).Warn("unable to reach remote signer")
+		return false
+	}
+	return reply.Valid
+}
+
+func (r *remoteKeystore) Lock() {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	if _, err := r.client.Lock(ctx, &rpcext.LockRequest{}); err != nil {
+		log.WithError(err).Warn("unable to lock remote signer")
+	}
+}
+
+func (r *remoteKeystore) DeriveConfidentialAddress(derivationPath string) (
+	string,
+	[]byte,
+	[]byte,
+	error,
+) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	reply, err := r.client.DeriveConfidentialAddress(
+		ctx,
+		&rpcext.DeriveConfidentialAddressRequest{DerivationPath: derivationPath},
+	)
+	if err != nil {
+		return "", nil, nil, fromStatusError(err)
+	}
+	return reply.Address, reply.Script, reply.BlindingKey, nil
+}
+
+func (r *remoteKeystore) AcceptSwap(
+	opts domain.AcceptSwapOpts,
+) (*domain.AcceptSwapResult, error) {
+	req, err := toAcceptSwapRequest(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	reply, err := r.client.AcceptSwap(ctx, req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return &domain.AcceptSwapResult{
+		PsetBase64:         reply.PsetBase64,
+		SelectedUnspents:   fromRPCUtxos(reply.SelectedUnspents),
+		InputBlindingKeys:  reply.InputBlindingKeys,
+		OutputBlindingKeys: reply.OutputBlindingKeys,
+	}, nil
+}
+
+func (r *remoteKeystore) SendToMany(
+	opts domain.SendToManyOpts,
+) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	reply, err := r.client.SendToMany(ctx, toSendToManyRequest(opts))
+	if err != nil {
+		return "", "", fromStatusError(err)
+	}
+	return reply.TxHex, reply.TxID, nil
+}
+
+// fromStatusError turns the error returned by the signer into
+// domain.ErrMustBeUnlocked or domain.ErrMustBeLocked if it's not in the
+// expected state, or into a plain error carrying the message of the status
+// otherwise
+func fromStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	if st.Code() == codes.FailedPrecondition {
+		if st.Message() == domain.ErrMustBeLocked.Error() {
+			return domain.ErrMustBeLocked
+		}
+		return domain.ErrMustBeUnlocked
+	}
+	return errors.New(st.Message())
+}
@@ -0,0 +1,196 @@
+package signer
+
+import (
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	"github.com/vulpemventures/go-elements/transaction"
+	"google.golang.org/protobuf/proto"
+)
+
+func toRPCUtxos(utxos []explorer.Utxo) []rpcext.Utxo {
+	list := make([]rpcext.Utxo, 0, len(utxos))
+	for _, u := range utxos {
+		list = append(list, rpcext.Utxo{
+			TxID:            u.Hash(),
+			VOut:            u.Index(),
+			Value:           u.Value(),
+			Asset:           u.Asset(),
+			ValueCommitment: u.ValueCommitment(),
+			AssetCommitment: u.AssetCommitment(),
+			Script:          u.Script(),
+			Nonce:           u.Nonce(),
+			RangeProof:      u.RangeProof(),
+			SurjectionProof: u.SurjectionProof(),
+		})
+	}
+	return list
+}
+
+func fromRPCUtxos(utxos []rpcext.Utxo) []explorer.Utxo {
+	list := make([]explorer.Utxo, 0, len(utxos))
+	for _, u := range utxos {
+		list = append(list, explorer.NewWitnessUtxo(
+			u.TxID, u.VOut,
+			u.Value, u.Asset,
+			u.ValueCommitment, u.AssetCommitment,
+			u.Script, u.Nonce, u.RangeProof, u.SurjectionProof,
+			false, 0, "",
+		))
+	}
+	return list
+}
+
+func toRPCLegs(legs []pkgswap.Leg) []rpcext.Leg {
+	if legs == nil {
+		return nil
+	}
+	list := make([]rpcext.Leg, 0, len(legs))
+	for _, l := range legs {
+		list = append(list, rpcext.Leg{Asset: l.Asset, Amount: l.Amount})
+	}
+	return list
+}
+
+func fromRPCLegs(legs []rpcext.Leg) []pkgswap.Leg {
+	if legs == nil {
+		return nil
+	}
+	list := make([]pkgswap.Leg, 0, len(legs))
+	for _, l := range legs {
+		list = append(list, pkgswap.Leg{Asset: l.Asset, Amount: l.Amount})
+	}
+	return list
+}
+
+func toRPCTxOutputs(outputs []*transaction.TxOutput) []rpcext.TxOutput {
+	list := make([]rpcext.TxOutput, 0, len(outputs))
+	for _, out := range outputs {
+		list = append(list, rpcext.TxOutput{
+			Asset:           out.Asset,
+			Value:           out.Value,
+			Nonce:           out.Nonce,
+			Script:          out.Script,
+			RangeProof:      out.RangeProof,
+			SurjectionProof: out.SurjectionProof,
+		})
+	}
+	return list
+}
+
+func fromRPCTxOutputs(outputs []rpcext.TxOutput) []*transaction.TxOutput {
+	list := make([]*transaction.TxOutput, 0, len(outputs))
+	for _, out := range outputs {
+		list = append(list, &transaction.TxOutput{
+			Asset:           out.Asset,
+			Value:           out.Value,
+			Nonce:           out.Nonce,
+			Script:          out.Script,
+			RangeProof:      out.RangeProof,
+			SurjectionProof: out.SurjectionProof,
+		})
+	}
+	return list
+}
+
+func toAcceptSwapRequest(
+	opts domain.AcceptSwapOpts,
+) (*rpcext.AcceptSwapRequest, error) {
+	swapRequest, err := proto.Marshal(opts.SwapRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	marketSwaps := make([]rpcext.MarketSwap, 0, len(opts.MarketSwaps))
+	for _, s := range opts.MarketSwaps {
+		marketSwaps = append(marketSwaps, rpcext.MarketSwap{
+			Unspents:             toRPCUtxos(s.Unspents),
+			InputAsset:           s.InputAsset,
+			InputAmount:          s.InputAmount,
+			OutputAsset:          s.OutputAsset,
+			OutputAmount:         s.OutputAmount,
+			OutputDerivationPath: s.OutputDerivationPath,
+			ChangeDerivationPath: s.ChangeDerivationPath,
+		})
+	}
+
+	return &rpcext.AcceptSwapRequest{
+		SwapRequest:                swapRequest,
+		LegsP:                      toRPCLegs(opts.LegsP),
+		LegsR:                      toRPCLegs(opts.LegsR),
+		MarketSwaps:                marketSwaps,
+		FeeUnspents:                toRPCUtxos(opts.FeeUnspents),
+		MarketBlindingKeysByScript: opts.MarketBlindingKeysByScript,
+		FeeBlindingKeysByScript:    opts.FeeBlindingKeysByScript,
+		OutputBlindingKeysByScript: opts.OutputBlindingKeysByScript,
+		MarketDerivationPaths:      opts.MarketDerivationPaths,
+		FeeDerivationPaths:         opts.FeeDerivationPaths,
+		FeeChangeDerivationPath:    opts.FeeChangeDerivationPath,
+	}, nil
+}
+
+func fromAcceptSwapRequest(
+	req *rpcext.AcceptSwapRequest,
+) (domain.AcceptSwapOpts, error) {
+	swapRequest := &pb.SwapRequest{}
+	if err := proto.Unmarshal(req.SwapRequest, swapRequest); err != nil {
+		return domain.AcceptSwapOpts{}, err
+	}
+
+	marketSwaps := make([]domain.MarketSwapOpts, 0, len(req.MarketSwaps))
+	for _, s := range req.MarketSwaps {
+		marketSwaps = append(marketSwaps, domain.MarketSwapOpts{
+			Unspents:             fromRPCUtxos(s.Unspents),
+			InputAsset:           s.InputAsset,
+			InputAmount:          s.InputAmount,
+			OutputAsset:          s.OutputAsset,
+			OutputAmount:         s.OutputAmount,
+			OutputDerivationPath: s.OutputDerivationPath,
+			ChangeDerivationPath: s.ChangeDerivationPath,
+		})
+	}
+
+	return domain.AcceptSwapOpts{
+		SwapRequest:                swapRequest,
+		LegsP:                      fromRPCLegs(req.LegsP),
+		LegsR:                      fromRPCLegs(req.LegsR),
+		MarketSwaps:                marketSwaps,
+		FeeUnspents:                fromRPCUtxos(req.FeeUnspents),
+		MarketBlindingKeysByScript: req.MarketBlindingKeysByScript,
+		FeeBlindingKeysByScript:    req.FeeBlindingKeysByScript,
+		OutputBlindingKeysByScript: req.OutputBlindingKeysByScript,
+		MarketDerivationPaths:      req.MarketDerivationPaths,
+		FeeDerivationPaths:         req.FeeDerivationPaths,
+		FeeChangeDerivationPath:    req.FeeChangeDerivationPath,
+	}, nil
+}
+
+func toSendToManyRequest(opts domain.SendToManyOpts) *rpcext.SendToManyRequest {
+	return &rpcext.SendToManyRequest{
+		Unspents:              toRPCUtxos(opts.Unspents),
+		FeeUnspents:           toRPCUtxos(opts.FeeUnspents),
+		Outputs:               toRPCTxOutputs(opts.Outputs),
+		OutputsBlindingKeys:   opts.OutputsBlindingKeys,
+		ChangePathsByAsset:    opts.ChangePathsByAsset,
+		FeeChangePathByAsset:  opts.FeeChangePathByAsset,
+		InputPathsByScript:    opts.InputPathsByScript,
+		FeeInputPathsByScript: opts.FeeInputPathsByScript,
+		MilliSatPerByte:       opts.MilliSatPerByte,
+	}
+}
+
+func fromSendToManyRequest(req *rpcext.SendToManyRequest) domain.SendToManyOpts {
+	return domain.SendToManyOpts{
+		Unspents:              fromRPCUtxos(req.Unspents),
+		FeeUnspents:           fromRPCUtxos(req.FeeUnspents),
+		Outputs:               fromRPCTxOutputs(req.Outputs),
+		OutputsBlindingKeys:   req.OutputsBlindingKeys,
+		ChangePathsByAsset:    req.ChangePathsByAsset,
+		FeeChangePathByAsset:  req.FeeChangePathByAsset,
+		InputPathsByScript:    req.InputPathsByScript,
+		FeeInputPathsByScript: req.FeeInputPathsByScript,
+		MilliSatPerByte:       req.MilliSatPerByte,
+	}
+}
@@ -0,0 +1,53 @@
+package signer
+
+import (
+	"context"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1024 * 1024
+
+// NewInProcessKeystore serves the Signer service for the given keystore,
+// vault and policy over an in-memory connection, and returns a remote
+// keystore connected to it along with the function to stop the server. It
+// lets tests exercise the daemon in remote signer mode without spawning a
+// tdex-signer. Since the connection never leaves the process, it's not
+// authenticated.
+func NewInProcessKeystore(
+	keystore domain.Keystore,
+	vault *FileVault,
+	policy *Policy,
+) (domain.SealedKeystore, func(), error) {
+	lis := bufconn.Listen(bufSize)
+	grpcServer := grpc.NewServer()
+	rpcext.RegisterSignerServer(grpcServer, NewServer(keystore, vault, policy))
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.WithError(err).Warn("in-process signer stopped")
+		}
+	}()
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		grpcServer.Stop()
+		return nil, nil, err
+	}
+
+	stop := func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+	return newRemoteKeystore(conn), stop, nil
+}
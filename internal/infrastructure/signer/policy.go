@@ -0,0 +1,235 @@
+package signer
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
+	"github.com/vulpemventures/go-elements/address"
+	"github.com/vulpemventures/go-elements/pset"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrInvalidSwapRequest is returned if the swap request to accept is
+	// malformed
+	ErrInvalidSwapRequest = errors.New("invalid swap request")
+	// ErrSwapTermsMismatch is returned if the amounts paid and received by the
+	// markets don't match those of the swap request
+	ErrSwapTermsMismatch = errors.New(
+		"market swaps do not match the terms of the swap request",
+	)
+	// ErrInvalidMarketSwap is returned if a market swap doesn't exchange the
+	// base asset with a quote asset
+	ErrInvalidMarketSwap = errors.New(
+		"market swap must exchange the base asset with a quote asset",
+	)
+	// ErrPriceOutOfBounds is returned if the price of a market swap is not
+	// within the bounds configured for its market
+	ErrPriceOutOfBounds = errors.New(
+		"price of market swap out of the signer's bounds",
+	)
+	// ErrSwapSpendsOwnUnspents is returned if the transaction of the swap
+	// request already spends some unspents of the signer's wallet
+	ErrSwapSpendsOwnUnspents = errors.New(
+		"swap request transaction spends unspents of the signer's wallet",
+	)
+	// ErrWithdrawalNotWhitelisted is returned if a withdrawal pays an address
+	// not in the whitelist
+	ErrWithdrawalNotWhitelisted = errors.New(
+		"withdrawal to address not in whitelist",
+	)
+)
+
+// Policy defines which transactions the signer is allowed to blind and sign.
+// These are only swaps that respect the terms of the accepted swap request
+// at a price within the bounds of their markets, and withdrawals whose
+// outputs pay whitelisted addresses.
+type Policy struct {
+	whitelistedScripts map[string]bool
+	baseAsset          string
+	priceBounds        map[string]config.PriceBounds
+}
+
+// NewPolicy returns a new Policy that allows withdrawals only to the given
+// addresses, and swaps only of the markets with the given price bounds, by
+// quote asset. Empty addresses are ignored, so that no withdrawal is allowed
+// if none is given.
+func NewPolicy(
+	withdrawalWhitelist []string,
+	priceBounds map[string]config.PriceBounds,
+) (*Policy, error) {
+	whitelistedScripts := make(map[string]bool)
+	for _, addr := range withdrawalWhitelist {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		script, err := address.ToOutputScript(addr, *config.GetNetwork())
+		if err != nil {
+			return nil, fmt.Errorf("invalid whitelisted address '%s': %w", addr, err)
+		}
+		whitelistedScripts[hex.EncodeToString(script)] = true
+	}
+	return &Policy{
+		whitelistedScripts: whitelistedScripts,
+		baseAsset:          config.GetString(config.BaseAssetKey),
+		priceBounds:        priceBounds,
+	}, nil
+}
+
+// CheckSwap makes sure that, once added to the transaction of the swap
+// request, the inputs and outputs of the markets let the daemon receive
+// exactly what the trader sends and pay exactly what it receives, at a price
+// within the bounds of every market, and that the trader is not trying to
+// spend any unspent of the signer's wallet.
+func (p *Policy) CheckSwap(opts domain.AcceptSwapOpts) error {
+	legsP, legsR, err := parseSwapRequest(opts)
+	if err != nil {
+		return err
+	}
+
+	for _, marketSwap := range opts.MarketSwaps {
+		if err := p.checkPrice(marketSwap); err != nil {
+			return err
+		}
+	}
+
+	balance := make(map[string]int64)
+	for _, leg := range legsP {
+		balance[leg.Asset] += int64(leg.Amount)
+	}
+	for _, leg := range legsR {
+		balance[leg.Asset] -= int64(leg.Amount)
+	}
+	for _, marketSwap := range opts.MarketSwaps {
+		balance[marketSwap.OutputAsset] -= int64(marketSwap.OutputAmount)
+		balance[marketSwap.InputAsset] += int64(marketSwap.InputAmount)
+	}
+	for _, amount := range balance {
+		if amount != 0 {
+			return ErrSwapTermsMismatch
+		}
+	}
+
+	ownUnspents := make(map[string]bool)
+	for _, marketSwap := range opts.MarketSwaps {
+		for _, u := range marketSwap.Unspents {
+			ownUnspents[fmt.Sprintf("%s:%d", u.Hash(), u.Index())] = true
+		}
+	}
+	for _, u := range opts.FeeUnspents {
+		ownUnspents[fmt.Sprintf("%s:%d", u.Hash(), u.Index())] = true
+	}
+	ptx, err := pset.NewPsetFromBase64(opts.SwapRequest.GetTransaction())
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSwapRequest, err)
+	}
+	for _, in := range ptx.UnsignedTx.Inputs {
+		key := fmt.Sprintf("%s:%d", bufferutil.TxIDFromBytes(in.Hash), in.Index)
+		if ownUnspents[key] {
+			return ErrSwapSpendsOwnUnspents
+		}
+	}
+	return nil
+}
+
+// CheckAcceptedSwap makes sure that the transaction crafted by the signer is a
+// valid acceptance of the swap request, before returning it.
+func (p *Policy) CheckAcceptedSwap(
+	opts domain.AcceptSwapOpts,
+	result *domain.AcceptSwapResult,
+) error {
+	msg, err := proto.Marshal(opts.SwapRequest)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSwapRequest, err)
+	}
+
+	if _, _, err := pkgswap.Accept(pkgswap.AcceptOpts{
+		Message:            msg,
+		PsetBase64:         result.PsetBase64,
+		InputBlindingKeys:  result.InputBlindingKeys,
+		OutputBlindingKeys: result.OutputBlindingKeys,
+		LegsP:              opts.LegsP,
+		LegsR:              opts.LegsR,
+	}); err != nil {
+		return fmt.Errorf("%w: %s", ErrSwapTermsMismatch, err)
+	}
+	return nil
+}
+
+// CheckWithdrawal makes sure that every output of a withdrawal pays a
+// whitelisted address. Change outputs are not subject to the check since
+// they are derived by the signer itself.
+func (p *Policy) CheckWithdrawal(opts domain.SendToManyOpts) error {
+	for _, out := range opts.Outputs {
+		script := hex.EncodeToString(out.Script)
+		if !p.whitelistedScripts[script] {
+			return fmt.Errorf("%w: output script %s", ErrWithdrawalNotWhitelisted, script)
+		}
+	}
+	return nil
+}
+
+// checkPrice makes sure that the market swap exchanges the base asset with a
+// quote asset at a price, the amount of quote asset per unit of base asset,
+// within the bounds of the market. Since the amounts include the fee of the
+// market, the bounds must account for it.
+func (p *Policy) checkPrice(marketSwap domain.MarketSwapOpts) error {
+	var quoteAsset string
+	var baseAmount, quoteAmount uint64
+	switch p.baseAsset {
+	case marketSwap.InputAsset:
+		quoteAsset = marketSwap.OutputAsset
+		baseAmount, quoteAmount = marketSwap.InputAmount, marketSwap.OutputAmount
+	case marketSwap.OutputAsset:
+		quoteAsset = marketSwap.InputAsset
+		baseAmount, quoteAmount = marketSwap.OutputAmount, marketSwap.InputAmount
+	default:
+		return ErrInvalidMarketSwap
+	}
+	if quoteAsset == p.baseAsset || baseAmount == 0 || quoteAmount == 0 {
+		return ErrInvalidMarketSwap
+	}
+
+	bounds, ok := p.priceBounds[quoteAsset]
+	if !ok {
+		return fmt.Errorf(
+			"%w: no bounds for market %s", ErrPriceOutOfBounds, quoteAsset,
+		)
+	}
+	price := decimal.New(int64(quoteAmount), 0).Div(
+		decimal.New(int64(baseAmount), 0),
+	)
+	if price.LessThan(bounds.Min) || price.GreaterThan(bounds.Max) {
+		return fmt.Errorf(
+			"%w: price %s of market %s", ErrPriceOutOfBounds, price, quoteAsset,
+		)
+	}
+	return nil
+}
+
+func parseSwapRequest(opts domain.AcceptSwapOpts) (legsP, legsR []pkgswap.Leg, err error) {
+	if opts.SwapRequest == nil {
+		err = fmt.Errorf("%w: missing swap request", ErrInvalidSwapRequest)
+		return
+	}
+
+	if len(opts.LegsP) <= 0 && len(opts.LegsR) <= 0 {
+		legsP, legsR = pkgswap.RequestLegs(opts.SwapRequest)
+		_, err = pkgswap.ParseSwapRequest(opts.SwapRequest)
+	} else {
+		legsP, legsR = opts.LegsP, opts.LegsR
+		_, err = pkgswap.ParseMultiAssetSwapRequest(opts.SwapRequest, legsP, legsR)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrInvalidSwapRequest, err)
+	}
+	return
+}
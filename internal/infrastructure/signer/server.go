@@ -0,0 +1,155 @@
+package signer
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type server struct {
+	keystore domain.Keystore
+	vault    *FileVault
+	policy   *Policy
+}
+
+// NewServer returns the handler of the Signer service that holds the keys of
+// the wallet in the given keystore, once decrypted from the given vault, and
+// blinds and signs only the transactions allowed by the given policy
+func NewServer(
+	keystore domain.Keystore,
+	vault *FileVault,
+	policy *Policy,
+) rpcext.SignerServer {
+	return &server{keystore, vault, policy}
+}
+
+func (s *server) IsLocked(
+	ctx context.Context,
+	req *rpcext.IsLockedRequest,
+) (*rpcext.IsLockedReply, error) {
+	return &rpcext.IsLockedReply{Locked: s.keystore.IsLocked()}, nil
+}
+
+func (s *server) Unlock(
+	ctx context.Context,
+	req *rpcext.UnlockRequest,
+) (*rpcext.UnlockReply, error) {
+	if err := s.vault.Unlock(s.keystore, req.Passphrase); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Info("signer unlocked")
+	return &rpcext.UnlockReply{}, nil
+}
+
+func (s *server) ChangePassphrase(
+	ctx context.Context,
+	req *rpcext.ChangePassphraseRequest,
+) (*rpcext.ChangePassphraseReply, error) {
+	if err := s.vault.ChangePassphrase(
+		s.keystore, req.CurrentPassphrase, req.NewPassphrase,
+	); err != nil {
+		if errors.Is(err, domain.ErrMustBeLocked) {
+			return nil, toStatusError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Info("signer passphrase changed")
+	return &rpcext.ChangePassphraseReply{}, nil
+}
+
+func (s *server) IsValidPassphrase(
+	ctx context.Context,
+	req *rpcext.IsValidPassphraseRequest,
+) (*rpcext.IsValidPassphraseReply, error) {
+	return &rpcext.IsValidPassphraseReply{
+		Valid: s.vault.IsValidPassphrase(s.keystore, req.Passphrase),
+	}, nil
+}
+
+func (s *server) Lock(
+	ctx context.Context,
+	req *rpcext.LockRequest,
+) (*rpcext.LockReply, error) {
+	s.keystore.Lock()
+	log.Info("signer locked")
+	return &rpcext.LockReply{}, nil
+}
+
+func (s *server) DeriveConfidentialAddress(
+	ctx context.Context,
+	req *rpcext.DeriveConfidentialAddressRequest,
+) (*rpcext.DeriveConfidentialAddressReply, error) {
+	addr, script, blindingKey, err :=
+		s.keystore.DeriveConfidentialAddress(req.DerivationPath)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &rpcext.DeriveConfidentialAddressReply{
+		Address:     addr,
+		Script:      script,
+		BlindingKey: blindingKey,
+	}, nil
+}
+
+func (s *server) AcceptSwap(
+	ctx context.Context,
+	req *rpcext.AcceptSwapRequest,
+) (*rpcext.AcceptSwapReply, error) {
+	opts, err := fromAcceptSwapRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.policy.CheckSwap(opts); err != nil {
+		log.WithError(err).Warn("signer policy rejected swap")
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	result, err := s.keystore.AcceptSwap(opts)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	if err := s.policy.CheckAcceptedSwap(opts, result); err != nil {
+		log.WithError(err).Warn("signer policy rejected swap")
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return &rpcext.AcceptSwapReply{
+		PsetBase64:         result.PsetBase64,
+		SelectedUnspents:   toRPCUtxos(result.SelectedUnspents),
+		InputBlindingKeys:  result.InputBlindingKeys,
+		OutputBlindingKeys: result.OutputBlindingKeys,
+	}, nil
+}
+
+func (s *server) SendToMany(
+	ctx context.Context,
+	req *rpcext.SendToManyRequest,
+) (*rpcext.SendToManyReply, error) {
+	opts := fromSendToManyRequest(req)
+
+	if err := s.policy.CheckWithdrawal(opts); err != nil {
+		log.WithError(err).Warn("signer policy rejected withdrawal")
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	txHex, txID, err := s.keystore.SendToMany(opts)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &rpcext.SendToManyReply{TxHex: txHex, TxID: txID}, nil
+}
+
+func toStatusError(err error) error {
+	if errors.Is(err, domain.ErrMustBeUnlocked) ||
+		errors.Is(err, domain.ErrMustBeLocked) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,536 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/keystore"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	"github.com/vulpemventures/go-elements/address"
+	"github.com/vulpemventures/go-elements/network"
+	"github.com/vulpemventures/go-elements/transaction"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	mnemonic = strings.Split(
+		"leave dice fine decrease dune ribbon ocean earn lunar account silver"+
+			" admit cheap fringe disorder trade because trade steak clock grace video jacket equal",
+		" ",
+	)
+	passphrase = "Sup3rS3cr3tP4ssw0rd!"
+	baseAsset  = network.Regtest.AssetID
+	quoteAsset = strings.Repeat("ab", 32)
+	// the market of the test swap trades 1 base asset for 1.25 quote asset
+	priceBounds = map[string]config.PriceBounds{
+		quoteAsset: {
+			Min: decimal.NewFromFloat(1.2),
+			Max: decimal.NewFromFloat(1.3),
+		},
+	}
+)
+
+func init() {
+	// keep key derivation cheap for tests
+	config.Set(config.ScryptNKey, 1024)
+}
+
+func TestRemoteKeystore(t *testing.T) {
+	local := keystore.NewKeystore(0)
+	vault := newTestVault(t)
+	if err := vault.Init(mnemonic, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	policy, _ := NewPolicy(nil, nil)
+	remote, stop, err := NewInProcessKeystore(local, vault, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	assert.Equal(t, true, remote.IsLocked())
+	_, _, _, err = remote.DeriveConfidentialAddress("0'/0/0")
+	assert.Equal(t, domain.ErrMustBeUnlocked, err)
+
+	// the mnemonic is never sent to the signer
+	assert.Equal(t, domain.ErrSealedKeystore, remote.Unlock(mnemonic))
+	assert.Error(t, remote.UnlockWithPassphrase("wrong passphrase"))
+	assert.Equal(t, false, remote.IsValidPassphrase("wrong passphrase"))
+	assert.Equal(t, true, remote.IsValidPassphrase(passphrase))
+	if err := remote.UnlockWithPassphrase(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, remote.IsLocked())
+	assert.Equal(t, false, local.IsLocked())
+
+	addr, script, blindingKey, err := remote.DeriveConfidentialAddress("0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedAddr, expectedScript, expectedBlindingKey, _ :=
+		local.DeriveConfidentialAddress("0'/0/0")
+	assert.Equal(t, expectedAddr, addr)
+	assert.Equal(t, expectedScript, script)
+	assert.Equal(t, expectedBlindingKey, blindingKey)
+
+	newPassphrase := "N3wS3cr3tP4ssw0rd!"
+	assert.Equal(
+		t,
+		domain.ErrMustBeLocked,
+		remote.ChangePassphrase(passphrase, newPassphrase),
+	)
+
+	remote.Lock()
+	assert.Equal(t, true, remote.IsLocked())
+	assert.Equal(t, true, local.IsLocked())
+
+	if err := remote.ChangePassphrase(passphrase, newPassphrase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, remote.UnlockWithPassphrase(passphrase))
+	assert.NoError(t, remote.UnlockWithPassphrase(newPassphrase))
+}
+
+func TestFileVault(t *testing.T) {
+	vault := newTestVault(t)
+	local := keystore.NewKeystore(0)
+
+	assert.Equal(t, false, vault.IsInitialized())
+	assert.Equal(t, ErrVaultNotInitialized, vault.Unlock(local, passphrase))
+	assert.True(t, errors.Is(
+		vault.Init(mnemonic, "weak"), domain.ErrWeakPassphrase,
+	))
+
+	if err := vault.Init(mnemonic, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, vault.IsInitialized())
+	assert.Equal(
+		t, domain.ErrVaultAlreadyInitialized, vault.Init(mnemonic, passphrase),
+	)
+
+	info, err := os.Stat(vault.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, _ := ioutil.ReadFile(vault.path)
+	assert.NotContains(t, string(data), mnemonic[0]+" "+mnemonic[1])
+
+	if err := vault.Unlock(local, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, local.IsLocked())
+}
+
+func TestRemoteKeystoreMutualTLS(t *testing.T) {
+	certs := newTestCerts(t)
+	serverTLS, err := NewServerTLSConfig(
+		certs.serverCert, certs.serverKey, certs.ca,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := keystore.NewKeystore(0)
+	policy, _ := NewPolicy(nil, nil)
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverTLS)))
+	rpcext.RegisterSignerServer(
+		grpcServer, NewServer(local, newTestVault(t), policy),
+	)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	clientTLS, err := NewClientTLSConfig(
+		certs.clientCert, certs.clientKey, certs.ca,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := NewRemoteKeystore(lis.Addr().String(), clientTLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, false, remote.IsValidPassphrase(passphrase))
+	assert.Equal(
+		t,
+		ErrVaultNotInitialized.Error(),
+		remote.UnlockWithPassphrase(passphrase).Error(),
+	)
+
+	// a client without a certificate signed by the CA can't call the signer
+	clientTLS.Certificates = nil
+	remote, err = NewRemoteKeystore(lis.Addr().String(), clientTLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.UnlockWithPassphrase(passphrase)
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrVaultNotInitialized.Error(), err.Error())
+}
+
+func TestRemoteAcceptSwap(t *testing.T) {
+	remote, opts := newTestAcceptSwap(t)
+
+	result, err := remote.AcceptSwap(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, result.PsetBase64)
+	assert.Len(t, result.SelectedUnspents, 2)
+
+	// the market pays more than the trader asks for
+	opts.MarketSwaps[0].InputAmount++
+	_, err = remote.AcceptSwap(opts)
+	assert.EqualError(t, err, ErrSwapTermsMismatch.Error())
+	opts.MarketSwaps[0].InputAmount--
+
+	// the trader tries to spend an unspent of the daemon
+	opts.FeeUnspents = append(
+		opts.FeeUnspents, newTestUtxo(10, 10000, quoteAsset, nil),
+	)
+	_, err = remote.AcceptSwap(opts)
+	assert.EqualError(t, err, ErrSwapSpendsOwnUnspents.Error())
+}
+
+func TestRemoteSendToMany(t *testing.T) {
+	local := keystore.NewKeystore(0)
+	if err := local.Unlock(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	whitelisted := newTestAddress(t)
+	policy, err := NewPolicy([]string{"", whitelisted}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, stop, err := NewInProcessKeystore(local, newTestVault(t), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	_, walletScript, _, _ := local.DeriveConfidentialAddress("2'/0/0")
+	_, feeScript, _, _ := local.DeriveConfidentialAddress("0'/0/0")
+	opts := domain.SendToManyOpts{
+		Unspents: []explorer.Utxo{
+			newTestUtxo(0, 100000000, baseAsset, walletScript),
+		},
+		FeeUnspents: []explorer.Utxo{
+			newTestUtxo(1, 100000, baseAsset, feeScript),
+		},
+		ChangePathsByAsset:    map[string]string{baseAsset: "2'/1/0"},
+		FeeChangePathByAsset:  map[string]string{baseAsset: "0'/1/0"},
+		InputPathsByScript:    map[string]string{hex.EncodeToString(walletScript): "2'/0/0"},
+		FeeInputPathsByScript: map[string]string{hex.EncodeToString(feeScript): "0'/0/0"},
+	}
+
+	notWhitelisted := newTestAddress(t)
+	opts.Outputs, opts.OutputsBlindingKeys = newTestOutput(t, notWhitelisted)
+	_, _, err = remote.SendToMany(opts)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrWithdrawalNotWhitelisted.Error())
+
+	opts.Outputs, opts.OutputsBlindingKeys = newTestOutput(t, whitelisted)
+	txHex, txID, err := remote.SendToMany(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, txHex)
+	assert.NotEmpty(t, txID)
+}
+
+func TestFailingNewPolicy(t *testing.T) {
+	_, err := NewPolicy([]string{"not an address"}, nil)
+	assert.Error(t, err)
+}
+
+func TestPolicyCheckSwap(t *testing.T) {
+	_, opts := newTestAcceptSwap(t)
+	policy, _ := NewPolicy(nil, priceBounds)
+	assert.NoError(t, policy.CheckSwap(opts))
+
+	// the market has no price bounds
+	noBoundsPolicy, _ := NewPolicy(nil, nil)
+	assert.True(t, errors.Is(noBoundsPolicy.CheckSwap(opts), ErrPriceOutOfBounds))
+
+	// the trader and the market swap the same amounts, but the market pays
+	// too much base asset for the quote asset it receives
+	opts = newTestAcceptSwapWithAmounts(t, 5000, 4500)
+	assert.True(t, errors.Is(policy.CheckSwap(opts), ErrPriceOutOfBounds))
+
+	_, opts = newTestAcceptSwap(t)
+	opts.MarketSwaps[0].InputAsset = quoteAsset
+	assert.Equal(t, ErrInvalidMarketSwap, policy.CheckSwap(opts))
+	opts.MarketSwaps[0].InputAsset = baseAsset
+
+	opts.MarketSwaps[0].OutputAmount--
+	assert.Equal(t, ErrSwapTermsMismatch, policy.CheckSwap(opts))
+	opts.MarketSwaps[0].OutputAmount++
+
+	opts.LegsP = []pkgswap.Leg{{Asset: quoteAsset, Amount: 1}}
+	opts.LegsR = []pkgswap.Leg{{Asset: baseAsset, Amount: 1}}
+	assert.True(t, errors.Is(policy.CheckSwap(opts), ErrInvalidSwapRequest))
+
+	opts.LegsP, opts.LegsR = nil, nil
+	opts.SwapRequest = nil
+	assert.True(t, errors.Is(policy.CheckSwap(opts), ErrInvalidSwapRequest))
+}
+
+// newTestAcceptSwap returns an unlocked remote keystore and the opts for
+// accepting a swap request where the trader sells 5000 of quote asset for 4000
+// of base asset
+func newTestAcceptSwap(t *testing.T) (domain.Keystore, domain.AcceptSwapOpts) {
+	local := keystore.NewKeystore(0)
+	if err := local.Unlock(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	policy, _ := NewPolicy(nil, priceBounds)
+	remote, stop, err := NewInProcessKeystore(local, newTestVault(t), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+
+	return remote, newTestAcceptSwapWithAmounts(t, 5000, 4000)
+}
+
+// newTestAcceptSwapWithAmounts returns the opts for accepting a swap request
+// where the trader sells the given amount of quote asset for the given
+// amount of base asset
+func newTestAcceptSwapWithAmounts(
+	t *testing.T,
+	quoteAmount, baseAmount uint64,
+) domain.AcceptSwapOpts {
+	local := keystore.NewKeystore(0)
+	if err := local.Unlock(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+
+	traderWallet, err := trade.NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, traderScript := traderWallet.Script()
+	traderUnspents := []explorer.Utxo{
+		newTestUtxo(10, 10000, quoteAsset, traderScript),
+	}
+	psetBase64, err := trade.NewSwapTx(
+		traderUnspents,
+		traderWallet.BlindingKey(),
+		quoteAsset,
+		quoteAmount,
+		baseAsset,
+		baseAmount,
+		traderScript,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blindingKeys := map[string][]byte{
+		hex.EncodeToString(traderScript): traderWallet.BlindingKey(),
+	}
+	msg, err := pkgswap.Request(pkgswap.RequestOpts{
+		AssetToBeSent:      quoteAsset,
+		AmountToBeSent:     quoteAmount,
+		AssetToReceive:     baseAsset,
+		AmountToReceive:    baseAmount,
+		PsetBase64:         psetBase64,
+		InputBlindingKeys:  blindingKeys,
+		OutputBlindingKeys: blindingKeys,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapRequest := &pb.SwapRequest{}
+	if err := proto.Unmarshal(msg, swapRequest); err != nil {
+		t.Fatal(err)
+	}
+
+	_, marketScript, marketBlindingKey, _ := local.DeriveConfidentialAddress("5'/0/0")
+	_, outputScript, outputBlindingKey, _ := local.DeriveConfidentialAddress("5'/0/1")
+	_, changeScript, changeBlindingKey, _ := local.DeriveConfidentialAddress("5'/1/0")
+	_, feeScript, feeBlindingKey, _ := local.DeriveConfidentialAddress("0'/0/0")
+
+	return domain.AcceptSwapOpts{
+		SwapRequest: swapRequest,
+		MarketSwaps: []domain.MarketSwapOpts{
+			{
+				Unspents: []explorer.Utxo{
+					newTestUtxo(0, 100000, baseAsset, marketScript),
+				},
+				InputAsset:           baseAsset,
+				InputAmount:          baseAmount,
+				OutputAsset:          quoteAsset,
+				OutputAmount:         quoteAmount,
+				OutputDerivationPath: "5'/0/1",
+				ChangeDerivationPath: "5'/1/0",
+			},
+		},
+		FeeUnspents: []explorer.Utxo{
+			newTestUtxo(1, 100000, baseAsset, feeScript),
+		},
+		MarketBlindingKeysByScript: map[string][]byte{
+			hex.EncodeToString(marketScript): marketBlindingKey,
+		},
+		FeeBlindingKeysByScript: map[string][]byte{
+			hex.EncodeToString(feeScript): feeBlindingKey,
+		},
+		OutputBlindingKeysByScript: map[string][]byte{
+			hex.EncodeToString(outputScript): outputBlindingKey,
+			hex.EncodeToString(changeScript): changeBlindingKey,
+		},
+		MarketDerivationPaths: map[string]string{
+			hex.EncodeToString(marketScript): "5'/0/0",
+		},
+		FeeDerivationPaths: map[string]string{
+			hex.EncodeToString(feeScript): "0'/0/0",
+		},
+		FeeChangeDerivationPath: "0'/1/0",
+	}
+}
+
+// newTestVault returns a not initialized FileVault stored in a temporary dir
+func newTestVault(t *testing.T) *FileVault {
+	dir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return NewFileVault(filepath.Join(dir, VaultFileName))
+}
+
+type testCerts struct {
+	ca         string
+	serverCert string
+	serverKey  string
+	clientCert string
+	clientKey  string
+}
+
+// newTestCerts creates in a temporary dir a CA and the server and client
+// certificates signed by it
+func newTestCerts(t *testing.T) testCerts {
+	dir, err := ioutil.TempDir("", "signer_tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tdex test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(
+		rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	certs := testCerts{ca: filepath.Join(dir, "ca.pem")}
+	writeTestPEM(t, certs.ca, "CERTIFICATE", caDER)
+
+	newCert := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(
+			rand.Reader, template, caCert, &key.PublicKey, caKey,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		certPath := filepath.Join(dir, name+".pem")
+		keyPath := filepath.Join(dir, name+".key")
+		writeTestPEM(t, certPath, "CERTIFICATE", der)
+		writeTestPEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+		return certPath, keyPath
+	}
+	certs.serverCert, certs.serverKey = newCert("server", 2, x509.ExtKeyUsageServerAuth)
+	certs.clientCert, certs.clientKey = newCert("client", 3, x509.ExtKeyUsageClientAuth)
+	return certs
+}
+
+func writeTestPEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestUtxo(
+	index uint32,
+	value uint64,
+	asset string,
+	script []byte,
+) explorer.Utxo {
+	hash := strings.Repeat("0", 62) + hex.EncodeToString([]byte{byte(index + 1)})
+	return explorer.NewUnconfidentialWitnessUtxo(hash, index, value, asset, script)
+}
+
+func newTestAddress(t *testing.T) string {
+	w, err := trade.NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.Address()
+}
+
+func newTestOutput(t *testing.T, addr string) ([]*transaction.TxOutput, [][]byte) {
+	script, err := address.ToOutputScript(addr, network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctAddr, err := address.FromConfidential(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset, _ := bufferutil.AssetHashToBytes(baseAsset)
+	value, _ := bufferutil.ValueToBytes(1000)
+	return []*transaction.TxOutput{transaction.NewTxOutput(asset, value, script)},
+		[][]byte{ctAddr.BlindingKey}
+}
@@ -0,0 +1,64 @@
+package signer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// NewServerTLSConfig returns the TLS config of the signer listener. It serves
+// the given certificate and accepts only clients presenting a certificate
+// signed by the given CA, so that only tdexd can unlock the signer and ask
+// for signatures.
+func NewServerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, certPool, err := loadTLSFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    certPool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// NewClientTLSConfig returns the TLS config of the connection to the signer.
+// It presents the given certificate and accepts only a signer certificate
+// signed by the given CA.
+func NewClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, certPool, err := loadTLSFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      certPool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func loadTLSFiles(
+	certFile, keyFile, caFile string,
+) (tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf(
+			"unable to load signer TLS certificate: %w", err,
+		)
+	}
+	caCert, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf(
+			"unable to read signer TLS CA certificate: %w", err,
+		)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return tls.Certificate{}, nil, fmt.Errorf(
+			"invalid signer TLS CA certificate %s", caFile,
+		)
+	}
+	return cert, certPool, nil
+}
@@ -0,0 +1,146 @@
+package signer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+// VaultFileName is the name of the file, in the data dir of tdex-signer,
+// that holds the encrypted mnemonic of the wallet
+const VaultFileName = "signer_vault.json"
+
+// ErrVaultNotInitialized is returned if the signer is unlocked before its
+// vault is initialized with the mnemonic of the wallet
+var ErrVaultNotInitialized = errors.New(
+	"signer vault not initialized, run 'tdex-signer init' first",
+)
+
+type vaultFile struct {
+	EncryptedMnemonic string `json:"encrypted_mnemonic"`
+}
+
+// FileVault holds the mnemonic of the wallet encrypted with the passphrase in
+// a file of the signer's data dir, so that neither the mnemonic nor its
+// encrypted form ever leave the signer process. The mnemonic is encrypted and
+// decrypted exactly like the one held by the Vault of the daemon.
+type FileVault struct {
+	path string
+	lock *sync.Mutex
+}
+
+// NewFileVault returns a FileVault stored at the given path
+func NewFileVault(path string) *FileVault {
+	return &FileVault{path, &sync.Mutex{}}
+}
+
+// IsInitialized returns whether the vault file exists
+func (f *FileVault) IsInitialized() bool {
+	_, err := os.Stat(f.path)
+	return err == nil
+}
+
+// Init encrypts the mnemonic with the passphrase, that must satisfy the
+// passphrase policy, and creates the vault file
+func (f *FileVault) Init(mnemonic []string, passphrase string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.IsInitialized() {
+		return domain.ErrVaultAlreadyInitialized
+	}
+	vault, err := domain.NewVault(mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	return f.write(vault)
+}
+
+// Unlock decrypts the mnemonic with the passphrase and lets the keystore hold
+// the keys derived from it. The vault file is rewritten if the mnemonic gets
+// encrypted again with the configured KDF params
+func (f *FileVault) Unlock(keystore domain.Keystore, passphrase string) error {
+	return f.update(func(vault *domain.Vault) error {
+		return vault.Unlock(keystore, passphrase)
+	})
+}
+
+// ChangePassphrase encrypts the mnemonic with the new passphrase if the
+// current one is valid. The keystore must be locked
+func (f *FileVault) ChangePassphrase(
+	keystore domain.Keystore,
+	currentPassphrase,
+	newPassphrase string,
+) error {
+	return f.update(func(vault *domain.Vault) error {
+		return vault.ChangePassphrase(keystore, currentPassphrase, newPassphrase)
+	})
+}
+
+// IsValidPassphrase returns whether the mnemonic can be decrypted with the
+// given passphrase
+func (f *FileVault) IsValidPassphrase(
+	keystore domain.Keystore,
+	passphrase string,
+) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	vault, err := f.read()
+	if err != nil {
+		return false
+	}
+	return vault.IsValidPassphrase(keystore, passphrase)
+}
+
+func (f *FileVault) update(handler func(vault *domain.Vault) error) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	vault, err := f.read()
+	if err != nil {
+		return err
+	}
+	encryptedMnemonic := vault.EncryptedMnemonic
+
+	if err := handler(vault); err != nil {
+		return err
+	}
+	if vault.EncryptedMnemonic == encryptedMnemonic {
+		return nil
+	}
+	return f.write(vault)
+}
+
+func (f *FileVault) read() (*domain.Vault, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrVaultNotInitialized
+		}
+		return nil, err
+	}
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return &domain.Vault{EncryptedMnemonic: file.EncryptedMnemonic}, nil
+}
+
+// write replaces the vault file with a temporary one, so that the encrypted
+// mnemonic is never lost if writing fails
+func (f *FileVault) write(vault *domain.Vault) error {
+	data, err := json.Marshal(vaultFile{vault.EncryptedMnemonic})
+	if err != nil {
+		return err
+	}
+	tmpPath := f.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, f.path)
+}
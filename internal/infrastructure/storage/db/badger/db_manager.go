@@ -27,6 +27,15 @@ func NewDbManager(baseDbDir string, logger badger.Logger) (*DbManager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("opening main db: %w", err)
 	}
+	if err := buildTimeIndexes(mainDb); err != nil {
+		return nil, fmt.Errorf("indexing main db by time: %w", err)
+	}
+	if err := buildSwapRequestIndex(mainDb); err != nil {
+		return nil, fmt.Errorf("indexing trades by swap request: %w", err)
+	}
+	if err := buildTradeStatusIndex(mainDb); err != nil {
+		return nil, fmt.Errorf("indexing trades by status: %w", err)
+	}
 
 	priceDb, err := createDb(filepath.Join(baseDbDir, "prices"), logger)
 	if err != nil {
@@ -154,6 +163,25 @@ func (d DbManager) runTransaction(
 
 // isTransactionConflict returns wheter the error occured when commiting a
 // transacton is a conflict
+// Status returns an error if any of the stores is closed or can't be read
+func (d DbManager) Status() error {
+	stores := map[string]*badgerhold.Store{
+		"main":    d.Store,
+		"prices":  d.PriceStore,
+		"unspent": d.UnspentStore,
+	}
+	for name, store := range stores {
+		db := store.Badger()
+		if db.IsClosed() {
+			return fmt.Errorf("%s store is closed", name)
+		}
+		if err := db.View(func(txn *badger.Txn) error { return nil }); err != nil {
+			return fmt.Errorf("%s store: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func (d DbManager) isTransactionConflict(err error) bool {
 	return err == badger.ErrConflict
 }
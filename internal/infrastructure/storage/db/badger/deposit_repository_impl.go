@@ -0,0 +1,159 @@
+package dbbadger
+
+import (
+	"context"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+type depositRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewDepositRepositoryImpl initialize a badger implementation of the
+// domain.DepositRepository
+func NewDepositRepositoryImpl(db *DbManager) domain.DepositRepository {
+	return depositRepositoryImpl{
+		db: db,
+	}
+}
+
+func (d depositRepositoryImpl) AddDeposits(
+	ctx context.Context,
+	deposits []domain.Deposit,
+) (int, error) {
+	count := 0
+	for _, deposit := range deposits {
+		done, err := d.insertDeposit(ctx, deposit)
+		if err != nil {
+			return count, err
+		}
+		if done {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (d depositRepositoryImpl) GetAllDeposits(
+	ctx context.Context,
+) ([]domain.Deposit, error) {
+	query := (&badgerhold.Query{}).SortBy("Timestamp")
+	return d.findDeposits(ctx, query)
+}
+
+func (d depositRepositoryImpl) findDeposits(
+	ctx context.Context,
+	query *badgerhold.Query,
+) ([]domain.Deposit, error) {
+	var deposits []domain.Deposit
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = d.db.Store.TxFind(tx, &deposits, query)
+	} else {
+		err = d.db.Store.Find(&deposits, query)
+	}
+
+	return deposits, err
+}
+
+func (d depositRepositoryImpl) GetDepositsByTime(
+	ctx context.Context,
+	fromTime, toTime uint64,
+	offset, limit int,
+) ([]domain.Deposit, error) {
+	deposits := make([]domain.Deposit, 0)
+	err := view(ctx, d.db.Store, func(tx *badger.Txn) error {
+		return iterateTimeIndex(
+			tx, depositTimeIndexName, fromTime, toTime, offset, limit,
+			func(encodedKey []byte) error {
+				var key domain.UnspentKey
+				if err := JSONDecode(encodedKey, &key); err != nil {
+					return err
+				}
+				var deposit domain.Deposit
+				if err := d.db.Store.TxGet(tx, key, &deposit); err != nil {
+					return err
+				}
+				deposits = append(deposits, deposit)
+				return nil
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deposits, nil
+}
+
+func (d depositRepositoryImpl) ConfirmDeposits(
+	ctx context.Context,
+	keys []domain.UnspentKey,
+	blockTime uint64,
+) error {
+	for _, key := range keys {
+		if err := update(ctx, d.db.Store, func(tx *badger.Txn) error {
+			return d.confirmDeposit(tx, key, blockTime)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d depositRepositoryImpl) confirmDeposit(
+	tx *badger.Txn,
+	key domain.UnspentKey,
+	blockTime uint64,
+) error {
+	var deposit domain.Deposit
+	if err := d.db.Store.TxGet(tx, key, &deposit); err != nil {
+		if err == badgerhold.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if deposit.Timestamp == blockTime {
+		return nil
+	}
+
+	if deposit.Timestamp > 0 {
+		if err := removeFromTimeIndex(
+			tx, depositTimeIndexName, deposit.Timestamp, key,
+		); err != nil {
+			return err
+		}
+	}
+	deposit.Timestamp = blockTime
+	if err := d.db.Store.TxUpdate(tx, key, deposit); err != nil {
+		return err
+	}
+	return addToTimeIndex(tx, depositTimeIndexName, blockTime, key)
+}
+
+func (d depositRepositoryImpl) insertDeposit(
+	ctx context.Context,
+	deposit domain.Deposit,
+) (bool, error) {
+	inserted := false
+	err := update(ctx, d.db.Store, func(tx *badger.Txn) error {
+		if err := d.db.Store.TxInsert(tx, deposit.Key(), &deposit); err != nil {
+			if err == badgerhold.ErrKeyExists {
+				return nil
+			}
+			return err
+		}
+		inserted = true
+
+		if deposit.Timestamp == 0 {
+			return nil
+		}
+		return addToTimeIndex(
+			tx, depositTimeIndexName, deposit.Timestamp, deposit.Key(),
+		)
+	})
+	return inserted, err
+}
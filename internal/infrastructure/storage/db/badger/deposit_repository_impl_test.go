@@ -0,0 +1,102 @@
+package dbbadger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestAddAndGetAllDeposits(t *testing.T) {
+	before()
+	defer after()
+
+	deposits := []domain.Deposit{
+		{AccountIndex: 5, TxID: "txid1", VOut: 0, Asset: "ah5", Value: 100, Timestamp: 20},
+		{AccountIndex: 5, TxID: "txid1", VOut: 1, Asset: "qh5", Value: 200, Timestamp: 10},
+	}
+
+	count, err := depositRepository.AddDeposits(ctx, deposits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, count)
+
+	// adding the same deposits again must not create duplicates
+	count, err = depositRepository.AddDeposits(ctx, deposits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, count)
+
+	allDeposits, err := depositRepository.GetAllDeposits(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(allDeposits))
+	assert.Equal(t, uint64(10), allDeposits[0].Timestamp)
+}
+
+func TestGetDepositsByTimeAndConfirm(t *testing.T) {
+	before()
+	defer after()
+
+	deposits := []domain.Deposit{
+		{AccountIndex: 5, TxID: "txid1", VOut: 0, Asset: "ah5", Value: 100, Timestamp: 30},
+		{AccountIndex: 5, TxID: "txid2", VOut: 0, Asset: "ah5", Value: 200, Timestamp: 10},
+		{AccountIndex: 5, TxID: "txid3", VOut: 0, Asset: "ah5", Value: 300, Timestamp: 20},
+		// unconfirmed
+		{AccountIndex: 5, TxID: "txid4", VOut: 0, Asset: "ah5", Value: 400},
+	}
+	if _, err := depositRepository.AddDeposits(ctx, deposits); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := depositRepository.GetDepositsByTime(ctx, 0, 100, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(page))
+	assert.Equal(t, "txid2", page[0].TxID)
+	assert.Equal(t, "txid3", page[1].TxID)
+
+	page, err = depositRepository.GetDepositsByTime(ctx, 0, 100, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(page))
+	assert.Equal(t, "txid1", page[0].TxID)
+
+	page, err = depositRepository.GetDepositsByTime(ctx, 15, 25, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(page))
+	assert.Equal(t, "txid3", page[0].TxID)
+
+	// confirming the unconfirmed deposit and moving another one to a new block
+	// must index them with the new block times
+	err = depositRepository.ConfirmDeposits(
+		ctx, []domain.UnspentKey{{TxID: "txid4", VOut: 0}}, 40,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = depositRepository.ConfirmDeposits(
+		ctx, []domain.UnspentKey{{TxID: "txid2", VOut: 0}, {TxID: "unknown"}}, 50,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err = depositRepository.GetDepositsByTime(ctx, 0, 100, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txids := make([]string, 0, len(page))
+	for _, d := range page {
+		txids = append(txids, d.TxID)
+	}
+	assert.Equal(t, []string{"txid3", "txid1", "txid4", "txid2"}, txids)
+	assert.Equal(t, uint64(50), page[3].Timestamp)
+}
@@ -367,6 +367,8 @@ func restoreStrategy(market *domain.Market) {
 		switch market.Strategy.Type {
 		case formula.BalancedReservesType:
 			market.Strategy = mm.NewStrategyFromFormula(formula.BalancedReserves{})
+		case formula.UnbalancedReservesType:
+			market.Strategy = mm.NewStrategyFromFormula(formula.UnbalancedReserves{})
 		}
 	}
 }
@@ -0,0 +1,69 @@
+package dbbadger
+
+import (
+	"context"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+type paymentRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewPaymentRepositoryImpl initialize a badger implementation of the
+// domain.PaymentRepository
+func NewPaymentRepositoryImpl(db *DbManager) domain.PaymentRepository {
+	return paymentRepositoryImpl{
+		db: db,
+	}
+}
+
+func (p paymentRepositoryImpl) AddPayment(
+	ctx context.Context,
+	payment domain.Payment,
+) error {
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = p.db.Store.TxInsert(tx, payment.TxID, &payment)
+	} else {
+		err = p.db.Store.Insert(payment.TxID, &payment)
+	}
+	if err == badgerhold.ErrKeyExists {
+		return nil
+	}
+	return err
+}
+
+func (p paymentRepositoryImpl) GetAllPayments(
+	ctx context.Context,
+) ([]domain.Payment, error) {
+	query := (&badgerhold.Query{}).SortBy("Timestamp")
+	return p.findPayments(ctx, query)
+}
+
+func (p paymentRepositoryImpl) GetPaymentsSince(
+	ctx context.Context,
+	timestamp uint64,
+) ([]domain.Payment, error) {
+	query := badgerhold.Where("Timestamp").Ge(timestamp).SortBy("Timestamp")
+	return p.findPayments(ctx, query)
+}
+
+func (p paymentRepositoryImpl) findPayments(
+	ctx context.Context,
+	query *badgerhold.Query,
+) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = p.db.Store.TxFind(tx, &payments, query)
+	} else {
+		err = p.db.Store.Find(&payments, query)
+	}
+
+	return payments, err
+}
@@ -0,0 +1,53 @@
+package dbbadger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestAddAndGetPayments(t *testing.T) {
+	before()
+	defer after()
+
+	payments := []domain.Payment{
+		{
+			TxID:         "txid1",
+			AccountIndex: 5,
+			Outputs:      []domain.PaymentOutput{{Asset: "ah5", Amount: 100, Address: "addr1"}},
+			Broadcasted:  true,
+			Timestamp:    20,
+		},
+		{
+			TxID:         "txid2",
+			AccountIndex: 1,
+			Outputs:      []domain.PaymentOutput{{Asset: "ah5", Amount: 200, Address: "addr2"}},
+			Timestamp:    10,
+		},
+	}
+	for _, p := range payments {
+		if err := paymentRepository.AddPayment(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// adding the same payment again must not create duplicates
+	if err := paymentRepository.AddPayment(ctx, payments[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	allPayments, err := paymentRepository.GetAllPayments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(allPayments))
+	assert.Equal(t, "txid2", allPayments[0].TxID)
+	assert.Equal(t, payments[0], allPayments[1])
+
+	recentPayments, err := paymentRepository.GetPaymentsSince(ctx, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(recentPayments))
+	assert.Equal(t, "txid1", recentPayments[0].TxID)
+}
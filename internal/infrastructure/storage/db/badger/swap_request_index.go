@@ -0,0 +1,114 @@
+package dbbadger
+
+import (
+	"github.com/dgraph-io/badger/v2"
+	"github.com/google/uuid"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+// Trades are indexed also by the id of the swap request that originated them
+// to make sure that a swap request id is never used by more than one trade.
+// The index entry is read and written in the same transaction that stores the
+// trade, therefore two concurrent proposals with the same swap request id
+// can't be both committed.
+const (
+	swapRequestIndexPrefix       = "swap_request_idx_"
+	swapRequestIndexesVersionKey = "swap_request_idx_version"
+	swapRequestIndexesVersion    = "1"
+)
+
+func swapRequestIndexKey(swapRequestID string) []byte {
+	return []byte(swapRequestIndexPrefix + swapRequestID)
+}
+
+// indexSwapRequest binds the given swap request id to the given trade. It
+// returns domain.ErrDuplicatedSwapRequest if the id is already bound to
+// another trade.
+func indexSwapRequest(
+	tx *badger.Txn,
+	swapRequestID string,
+	tradeID uuid.UUID,
+) error {
+	key := swapRequestIndexKey(swapRequestID)
+	item, err := tx.Get(key)
+	if err != nil {
+		if err != badger.ErrKeyNotFound {
+			return err
+		}
+		return tx.Set(key, []byte(tradeID.String()))
+	}
+
+	indexedTradeID, err := item.ValueCopy(nil)
+	if err != nil {
+		return err
+	}
+	if string(indexedTradeID) != tradeID.String() {
+		return domain.ErrDuplicatedSwapRequest
+	}
+	return nil
+}
+
+// buildSwapRequestIndex indexes by swap request id the trades stored before
+// the index was introduced. It runs only once for every store.
+func buildSwapRequestIndex(store *badgerhold.Store) error {
+	db := store.Badger()
+	alreadyBuilt := false
+	if err := db.View(func(tx *badger.Txn) error {
+		_, err := tx.Get([]byte(swapRequestIndexesVersionKey))
+		if err == nil {
+			alreadyBuilt = true
+			return nil
+		}
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	if alreadyBuilt {
+		return nil
+	}
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	if err := db.View(func(tx *badger.Txn) error {
+		prefix := []byte(TradeBadgerholdKeyPrefix + `"`)
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			var trade domain.Trade
+			if err := JSONDecode(data, &trade); err != nil {
+				continue
+			}
+			if trade.SwapRequest.ID == "" {
+				continue
+			}
+			if err := wb.Set(
+				swapRequestIndexKey(trade.SwapRequest.ID),
+				[]byte(trade.ID.String()),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := wb.Set(
+		[]byte(swapRequestIndexesVersionKey), []byte(swapRequestIndexesVersion),
+	); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
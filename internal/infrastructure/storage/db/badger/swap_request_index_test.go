@@ -0,0 +1,44 @@
+package dbbadger
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestBuildSwapRequestIndex(t *testing.T) {
+	os.Mkdir(testDbDir, os.ModePerm)
+	defer os.RemoveAll(testDbDir)
+
+	db, err := NewDbManager(testDbDir, nil)
+	require.NoError(t, err)
+
+	// a trade stored before the index was introduced
+	trade := domain.NewTrade()
+	trade.SwapRequest.ID = "swapid"
+	require.NoError(t, db.Store.Insert(trade.ID, trade))
+	require.NoError(t, db.Store.Badger().Update(func(tx *badger.Txn) error {
+		return tx.Delete([]byte(swapRequestIndexesVersionKey))
+	}))
+
+	require.NoError(t, buildSwapRequestIndex(db.Store))
+
+	err = NewTradeRepositoryImpl(db).UpdateTrade(
+		context.Background(),
+		nil,
+		func(t *domain.Trade) (*domain.Trade, error) {
+			t.SwapRequest.ID = "swapid"
+			return t, nil
+		},
+	)
+	assert.Equal(t, domain.ErrDuplicatedSwapRequest, err)
+
+	db.Store.Close()
+	db.PriceStore.Close()
+	db.UnspentStore.Close()
+}
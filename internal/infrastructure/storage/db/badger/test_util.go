@@ -20,6 +20,8 @@ var (
 	unspentRepository domain.UnspentRepository
 	vaultRepository   domain.VaultRepository
 	tradeRepository   domain.TradeRepository
+	depositRepository domain.DepositRepository
+	paymentRepository domain.PaymentRepository
 	dbManager         *DbManager
 	testDbDir         = "testdb"
 )
@@ -61,6 +63,8 @@ func before() {
 	unspentRepository = NewUnspentRepositoryImpl(dbManager)
 	vaultRepository = NewVaultRepositoryImpl(dbManager)
 	tradeRepository = NewTradeRepositoryImpl(dbManager)
+	depositRepository = NewDepositRepositoryImpl(dbManager)
+	paymentRepository = NewPaymentRepositoryImpl(dbManager)
 	ctx = context.WithValue(
 		context.Background(),
 		"tx",
@@ -247,7 +251,6 @@ func insertUnspents(tx *badger.Txn, db *DbManager) error {
 func insertVault(tx *badger.Txn, db *DbManager) error {
 	vault := &domain.Vault{
 		EncryptedMnemonic:      "dVoBFte1oeRkPl8Vf8DzBP3PRnzPA3fxtyvDHXFGYAS9MP8V2Sc9nHcQW4PrMkQNnf2uGrDg81dFgBrwqv1n3frXxRBKhp83fSsTm4xqj8+jdwTI3nouFmi1W/O4UqpHdQ62EYoabJQtKpptWO11TFJzw8WF02pfS6git8YjLR4xrnfp2LkOEjSU9CI82ZasF46WZFKcpeUJTAsxU/03ONpAdwwEsC96f1KAvh8tqaO0yLDOcmPf8a5B82jefgncCRrt32kCpbpIE4YiCFrqqdUHXKH+",
-		PassphraseHash:         []byte("pass"),
 		Accounts:               map[int]*domain.Account{},
 		AccountAndKeyByAddress: map[string]domain.AccountAndKey{},
 	}
@@ -0,0 +1,255 @@
+package dbbadger
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+// Badgerhold sorts the results of a query only after having loaded all of
+// them in memory. Records that must be listed by time, a page at a time, are
+// therefore indexed also with keys made of the name of their type followed by
+// their timestamp, big endian encoded, and by their own key. This way they
+// can be iterated in time order directly with a badger iterator.
+const (
+	timeIndexPrefix         = "time_idx_"
+	timeIndexesVersionKey   = "time_idx_version"
+	timeIndexesVersion      = "1"
+	timestampSize           = 8
+	tradeTimeIndexName      = "Trade"
+	depositTimeIndexName    = "Deposit"
+	withdrawalTimeIndexName = "Withdrawal"
+)
+
+func timeIndexTypePrefix(typeName string) []byte {
+	return []byte(timeIndexPrefix + typeName + ":")
+}
+
+func timeIndexKey(typeName string, timestamp uint64, key []byte) []byte {
+	prefix := timeIndexTypePrefix(typeName)
+	indexKey := make([]byte, len(prefix)+timestampSize+len(key))
+	copy(indexKey, prefix)
+	binary.BigEndian.PutUint64(indexKey[len(prefix):], timestamp)
+	copy(indexKey[len(prefix)+timestampSize:], key)
+	return indexKey
+}
+
+// addToTimeIndex indexes the record of the given type and key with the given
+// timestamp. The JSON encoded key of the record is stored as value of the
+// index entry.
+func addToTimeIndex(
+	tx *badger.Txn,
+	typeName string,
+	timestamp uint64,
+	key interface{},
+) error {
+	encodedKey, err := JSONEncode(key)
+	if err != nil {
+		return err
+	}
+	return tx.Set(timeIndexKey(typeName, timestamp, encodedKey), encodedKey)
+}
+
+// removeFromTimeIndex removes the entry of the record of the given type and
+// key for the given timestamp, if any
+func removeFromTimeIndex(
+	tx *badger.Txn,
+	typeName string,
+	timestamp uint64,
+	key interface{},
+) error {
+	encodedKey, err := JSONEncode(key)
+	if err != nil {
+		return err
+	}
+	return tx.Delete(timeIndexKey(typeName, timestamp, encodedKey))
+}
+
+// iterateTimeIndex passes to the given handler, in time order, the JSON
+// encoded keys of at most limit records of the given type whose timestamp is
+// in the given range, both ends included, skipping the first offset ones.
+// A zero limit means no limit.
+func iterateTimeIndex(
+	tx *badger.Txn,
+	typeName string,
+	fromTime, toTime uint64,
+	offset, limit int,
+	handler func(encodedKey []byte) error,
+) error {
+	prefix := timeIndexTypePrefix(typeName)
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = prefix
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	count := 0
+	for it.Seek(timeIndexKey(typeName, fromTime, nil)); it.ValidForPrefix(prefix); it.Next() {
+		indexKey := it.Item().Key()
+		if len(indexKey) < len(prefix)+timestampSize {
+			continue
+		}
+		timestamp := binary.BigEndian.Uint64(indexKey[len(prefix):])
+		if timestamp > toTime {
+			break
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		encodedKey, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err := handler(encodedKey); err != nil {
+			return err
+		}
+
+		count++
+		if limit > 0 && count >= limit {
+			break
+		}
+	}
+	return nil
+}
+
+// update runs the given handler in the transaction of the context, if any,
+// otherwise in a new read-write one
+func update(
+	ctx context.Context,
+	store *badgerhold.Store,
+	handler func(tx *badger.Txn) error,
+) error {
+	if ctx.Value("tx") != nil {
+		return handler(ctx.Value("tx").(*badger.Txn))
+	}
+	return store.Badger().Update(handler)
+}
+
+// view runs the given handler in the transaction of the context, if any,
+// otherwise in a new read-only one
+func view(
+	ctx context.Context,
+	store *badgerhold.Store,
+	handler func(tx *badger.Txn) error,
+) error {
+	if ctx.Value("tx") != nil {
+		return handler(ctx.Value("tx").(*badger.Txn))
+	}
+	return store.Badger().View(handler)
+}
+
+// timeIndexedType defines how to find the records of a type to index by time:
+// recordsPrefix is the badgerhold prefix of the records, followed by the
+// first char of their JSON encoded key not to match the records of other
+// types with the same prefix. indexEntry returns the timestamp and the key of
+// a decoded record, or false if it must not be indexed.
+type timeIndexedType struct {
+	name          string
+	recordsPrefix []byte
+	indexEntry    func(data []byte) (uint64, interface{}, bool)
+}
+
+var timeIndexedTypes = []timeIndexedType{
+	{
+		name:          tradeTimeIndexName,
+		recordsPrefix: []byte(TradeBadgerholdKeyPrefix + `"`),
+		indexEntry: func(data []byte) (uint64, interface{}, bool) {
+			var trade domain.Trade
+			if err := JSONDecode(data, &trade); err != nil {
+				return 0, nil, false
+			}
+			return trade.Timestamp.Complete, trade.ID, trade.Timestamp.Complete > 0
+		},
+	},
+	{
+		name:          depositTimeIndexName,
+		recordsPrefix: []byte("bh_Deposit{"),
+		indexEntry: func(data []byte) (uint64, interface{}, bool) {
+			var deposit domain.Deposit
+			if err := JSONDecode(data, &deposit); err != nil {
+				return 0, nil, false
+			}
+			return deposit.Timestamp, deposit.Key(), deposit.Timestamp > 0
+		},
+	},
+	{
+		name:          withdrawalTimeIndexName,
+		recordsPrefix: []byte(`bh_Withdrawal"`),
+		indexEntry: func(data []byte) (uint64, interface{}, bool) {
+			var withdrawal domain.Withdrawal
+			if err := JSONDecode(data, &withdrawal); err != nil {
+				return 0, nil, false
+			}
+			return withdrawal.Timestamp, withdrawal.TxID, withdrawal.TxID != ""
+		},
+	},
+}
+
+// buildTimeIndexes indexes by time the records stored before the indexes
+// were introduced. It runs only once for every store.
+func buildTimeIndexes(store *badgerhold.Store) error {
+	db := store.Badger()
+	alreadyBuilt := false
+	if err := db.View(func(tx *badger.Txn) error {
+		_, err := tx.Get([]byte(timeIndexesVersionKey))
+		if err == nil {
+			alreadyBuilt = true
+			return nil
+		}
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	if alreadyBuilt {
+		return nil
+	}
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, t := range timeIndexedTypes {
+		if err := db.View(func(tx *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = t.recordsPrefix
+			it := tx.NewIterator(opts)
+			defer it.Close()
+
+			for it.Rewind(); it.ValidForPrefix(t.recordsPrefix); it.Next() {
+				data, err := it.Item().ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				timestamp, key, ok := t.indexEntry(data)
+				if !ok {
+					continue
+				}
+				encodedKey, err := JSONEncode(key)
+				if err != nil {
+					return err
+				}
+				if err := wb.Set(
+					timeIndexKey(t.name, timestamp, encodedKey), encodedKey,
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := wb.Set(
+		[]byte(timeIndexesVersionKey), []byte(timeIndexesVersion),
+	); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
@@ -0,0 +1,59 @@
+package dbbadger
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestBuildTimeIndexes(t *testing.T) {
+	os.Mkdir(testDbDir, os.ModePerm)
+	defer os.RemoveAll(testDbDir)
+
+	db, err := NewDbManager(testDbDir, nil)
+	require.NoError(t, err)
+
+	// records stored before the time indexes were introduced
+	trade := domain.NewTrade()
+	trade.Timestamp.Complete = 20
+	require.NoError(t, db.Store.Insert(trade.ID, trade))
+	deposit := domain.Deposit{TxID: "txid1", Asset: "ah5", Value: 100, Timestamp: 10}
+	require.NoError(t, db.Store.Insert(deposit.Key(), &deposit))
+	withdrawal := domain.Withdrawal{TxID: "txid2", BaseAsset: "ah5", Timestamp: 30}
+	require.NoError(t, db.Store.Insert(withdrawal.TxID, &withdrawal))
+	// a record of another type sharing the badgerhold prefix of withdrawals
+	address := domain.WithdrawalAddress{Script: "script", Address: "addr"}
+	require.NoError(t, db.Store.Insert(address.Script, &address))
+	require.NoError(t, db.Store.Badger().Update(func(tx *badger.Txn) error {
+		return tx.Delete([]byte(timeIndexesVersionKey))
+	}))
+
+	require.NoError(t, buildTimeIndexes(db.Store))
+
+	trades, err := NewTradeRepositoryImpl(db).
+		GetTradesByCompleteTime(context.Background(), 0, 100, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.Equal(t, trade.ID, trades[0].ID)
+
+	deposits, err := NewDepositRepositoryImpl(db).
+		GetDepositsByTime(context.Background(), 0, 100, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	assert.Equal(t, deposit, deposits[0])
+
+	withdrawals, err := NewWithdrawalRepositoryImpl(db).
+		GetWithdrawalsByTime(context.Background(), 0, 100, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, withdrawals, 1)
+	assert.Equal(t, withdrawal, withdrawals[0])
+
+	db.Store.Close()
+	db.PriceStore.Close()
+	db.UnspentStore.Close()
+}
@@ -2,11 +2,11 @@ package dbbadger
 
 import (
 	"context"
-	"errors"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/operator"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/timshannon/badgerhold/v2"
 )
@@ -45,14 +45,16 @@ func (t tradeRepositoryImpl) GetAllTradesByMarket(
 	ctx context.Context,
 	marketQuoteAsset string,
 ) ([]*domain.Trade, error) {
-	query := badgerhold.Where("MarketQuoteAsset").Eq(marketQuoteAsset)
+	query := badgerhold.Where("MarketQuoteAsset").MatchFunc(
+		isInMarket(marketQuoteAsset),
+	)
 	tr, err := t.findTrades(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	trades := make([]*domain.Trade, 0, len(tr))
-	for _, v := range tr {
-		trades = append(trades, &v)
+	for i := range tr {
+		trades = append(trades, &tr[i])
 	}
 
 	return trades, nil
@@ -70,7 +72,45 @@ func (t tradeRepositoryImpl) GetTradeBySwapAcceptID(
 	}
 
 	if len(trades) <= 0 {
-		return nil, errors.New("trade not found")
+		return nil, domain.ErrTradeNotFound
+	}
+
+	trade := &trades[0]
+	return trade, nil
+}
+
+func (t tradeRepositoryImpl) GetTradeBySwapRequestID(
+	ctx context.Context,
+	swapRequestID string,
+) (*domain.Trade, error) {
+	query := badgerhold.Where("SwapRequest.ID").Eq(swapRequestID)
+
+	trades, err := t.findTrades(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(trades) <= 0 {
+		return nil, domain.ErrTradeNotFound
+	}
+
+	trade := &trades[0]
+	return trade, nil
+}
+
+func (t tradeRepositoryImpl) GetTradeByTxID(
+	ctx context.Context,
+	txID string,
+) (*domain.Trade, error) {
+	query := badgerhold.Where("TxID").Eq(txID)
+
+	trades, err := t.findTrades(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(trades) <= 0 {
+		return nil, domain.ErrTradeNotFound
 	}
 
 	trade := &trades[0]
@@ -92,7 +132,45 @@ func (t tradeRepositoryImpl) UpdateTrade(
 		return err
 	}
 
-	return t.updateTrade(ctx, updatedTrade.ID, *updatedTrade)
+	if err := t.updateTrade(ctx, updatedTrade.ID, *updatedTrade); err != nil {
+		return err
+	}
+
+	log.WithContext(ctx).WithField("trade_id", updatedTrade.ID.String()).
+		Debugf("trade stored with status %s", updatedTrade.Status.Code)
+	return nil
+}
+
+func (t tradeRepositoryImpl) GetAcceptedTrades(
+	ctx context.Context,
+) ([]*domain.Trade, error) {
+	query := badgerhold.
+		Where("Status.Code").Eq(pb.SwapStatus_ACCEPT).
+		And("Status.Failed").Eq(false)
+	tr, err := t.findTrades(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]*domain.Trade, 0, len(tr))
+	for i := range tr {
+		trades = append(trades, &tr[i])
+	}
+
+	return trades, nil
+}
+
+func (t tradeRepositoryImpl) CountTradesByMarketAndStatus(
+	ctx context.Context,
+) ([]domain.TradeCount, error) {
+	var counts []domain.TradeCount
+	if err := view(ctx, t.db.Store, func(tx *badger.Txn) error {
+		var err error
+		counts, err = countTradesByStatus(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return counts, nil
 }
 
 func (t tradeRepositoryImpl) GetCompletedTradesByMarket(
@@ -100,20 +178,50 @@ func (t tradeRepositoryImpl) GetCompletedTradesByMarket(
 	marketQuoteAsset string,
 ) ([]*domain.Trade, error) {
 	query := badgerhold.
-		Where("MarketQuoteAsset").Eq(marketQuoteAsset).
-		And("Status.Code").Eq(pb.SwapStatus_COMPLETE)
+		Where("MarketQuoteAsset").MatchFunc(isInMarket(marketQuoteAsset)).
+		And("Status.Code").Eq(pb.SwapStatus_COMPLETE).
+		And("Status.Failed").Eq(false)
 	tr, err := t.findTrades(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	trades := make([]*domain.Trade, 0, len(tr))
-	for _, v := range tr {
-		trades = append(trades, &v)
+	for i := range tr {
+		trades = append(trades, &tr[i])
 	}
 
 	return trades, nil
 }
 
+func (t tradeRepositoryImpl) GetTradesByCompleteTime(
+	ctx context.Context,
+	fromTime, toTime uint64,
+	offset, limit int,
+) ([]*domain.Trade, error) {
+	trades := make([]*domain.Trade, 0)
+	err := view(ctx, t.db.Store, func(tx *badger.Txn) error {
+		return iterateTimeIndex(
+			tx, tradeTimeIndexName, fromTime, toTime, offset, limit,
+			func(encodedKey []byte) error {
+				var ID uuid.UUID
+				if err := JSONDecode(encodedKey, &ID); err != nil {
+					return err
+				}
+				var trade domain.Trade
+				if err := t.db.Store.TxGet(tx, ID, &trade); err != nil {
+					return err
+				}
+				trades = append(trades, &trade)
+				return nil
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
 func (t tradeRepositoryImpl) getOrCreateTrade(
 	ctx context.Context,
 	ID *uuid.UUID,
@@ -169,30 +277,42 @@ func (t tradeRepositoryImpl) updateTrade(
 	ID uuid.UUID,
 	trade domain.Trade,
 ) error {
-	if ctx.Value("tx") != nil {
-		tx := ctx.Value("tx").(*badger.Txn)
-		return t.db.Store.TxUpdate(tx, ID, trade)
-	}
-	return t.db.Store.Update(ID, trade)
+	return update(ctx, t.db.Store, func(tx *badger.Txn) error {
+		if trade.SwapRequest.ID != "" {
+			if err := indexSwapRequest(tx, trade.SwapRequest.ID, ID); err != nil {
+				return err
+			}
+		}
+		if err := t.db.Store.TxUpdate(tx, ID, trade); err != nil {
+			return err
+		}
+		if err := indexTradeStatus(tx, trade); err != nil {
+			return err
+		}
+		// the complete time of a trade never changes once set, so indexing it
+		// again just overwrites the same entry
+		if trade.Timestamp.Complete == 0 {
+			return nil
+		}
+		return addToTimeIndex(
+			tx, tradeTimeIndexName, trade.Timestamp.Complete, ID,
+		)
+	})
 }
 
 func (t tradeRepositoryImpl) insertTrade(
 	ctx context.Context,
 	trade domain.Trade,
 ) error {
-	var err error
-	if ctx.Value("tx") != nil {
-		tx := ctx.Value("tx").(*badger.Txn)
-		err = t.db.Store.TxInsert(tx, trade.ID, &trade)
-	} else {
-		err = t.db.Store.Insert(trade.ID, &trade)
-	}
-	if err != nil {
-		if err != badgerhold.ErrKeyExists {
-			return err
+	return update(ctx, t.db.Store, func(tx *badger.Txn) error {
+		if err := t.db.Store.TxInsert(tx, trade.ID, &trade); err != nil {
+			if err != badgerhold.ErrKeyExists {
+				return err
+			}
+			return nil
 		}
-	}
-	return nil
+		return indexTradeStatus(tx, trade)
+	})
 }
 
 func (t tradeRepositoryImpl) getAllTrades(ctx context.Context) []*domain.Trade {
@@ -228,3 +348,15 @@ func (t tradeRepositoryImpl) getAllTrades(ctx context.Context) []*domain.Trade {
 	})
 	return trades
 }
+
+// isInMarket matches the trades of the market with the given quote asset,
+// including the multi-asset ones recorded under another market
+func isInMarket(marketQuoteAsset string) badgerhold.MatchFunc {
+	return func(ra *badgerhold.RecordAccess) (bool, error) {
+		trade, ok := ra.Record().(*domain.Trade)
+		if !ok {
+			return false, nil
+		}
+		return trade.IsInMarket(marketQuoteAsset), nil
+	}
+}
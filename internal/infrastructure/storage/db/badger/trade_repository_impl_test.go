@@ -1,6 +1,7 @@
 package dbbadger
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
@@ -52,6 +53,72 @@ func TestGetAllTradesByMarket(t *testing.T) {
 	}
 
 	assert.Equal(t, 2, len(trades))
+
+	// a multi-asset trade belongs to all the markets it touches
+	completedTrades, err := tradeRepository.GetCompletedTradesByMarket(ctx, "mqa2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			trade.MarketQuoteAsset = "mqa1"
+			trade.MarketTrades = []domain.MarketTrade{
+				{QuoteAsset: "mqa1"},
+				{QuoteAsset: "mqa2", IsQuoteSent: true},
+			}
+			trade.Status = domain.CompletedStatus
+			return trade, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	trades, err = tradeRepository.GetAllTradesByMarket(ctx, "mqa2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(trades))
+
+	trades, err = tradeRepository.GetCompletedTradesByMarket(ctx, "mqa2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(completedTrades)+1, len(trades))
+}
+
+func TestGetAcceptedTrades(t *testing.T) {
+	before()
+	defer after()
+
+	trades, err := tradeRepository.GetAcceptedTrades(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(trades))
+
+	trade, err := tradeRepository.GetTradeBySwapAcceptID(ctx, "22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tradeRepository.UpdateTrade(
+		ctx,
+		&trade.ID,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			trade.Status = domain.AcceptedStatus
+			return trade, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	trades, err = tradeRepository.GetAcceptedTrades(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, "22", trades[0].SwapAccept.ID)
 }
 
 func TestGetTradeBySwapAcceptID(t *testing.T) {
@@ -66,6 +133,18 @@ func TestGetTradeBySwapAcceptID(t *testing.T) {
 	assert.Equal(t, "424", trade.TxID)
 }
 
+func TestGetTradeBySwapRequestID(t *testing.T) {
+	before()
+	defer after()
+
+	trade, err := tradeRepository.GetTradeBySwapRequestID(ctx, "12")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "424", trade.TxID)
+}
+
 func TestUpdateTrade(t *testing.T) {
 	before()
 	defer after()
@@ -94,3 +173,85 @@ func TestUpdateTrade(t *testing.T) {
 
 	assert.Equal(t, float32(100), trade.Price)
 }
+
+func TestUpdateTradeDuplicatedSwapRequest(t *testing.T) {
+	before()
+	defer after()
+
+	setSwapRequestID := func(id string, tradeID *uuid.UUID) func(*domain.Trade) (*domain.Trade, error) {
+		return func(trade *domain.Trade) (*domain.Trade, error) {
+			trade.SwapRequest.ID = id
+			*tradeID = trade.ID
+			return trade, nil
+		}
+	}
+
+	var tradeID, otherTradeID uuid.UUID
+	err := tradeRepository.UpdateTrade(ctx, nil, setSwapRequestID("swapid", &tradeID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tradeRepository.UpdateTrade(ctx, nil, setSwapRequestID("swapid", &otherTradeID))
+	assert.Equal(t, domain.ErrDuplicatedSwapRequest, err)
+	err = tradeRepository.UpdateTrade(ctx, &tradeID, setSwapRequestID("swapid", &tradeID))
+	assert.NoError(t, err)
+
+	// concurrent trades with the same swap request id can't be both committed
+	tx := dbManager.NewTransaction()
+	otherTx := dbManager.NewTransaction()
+	err = tradeRepository.UpdateTrade(
+		context.WithValue(context.Background(), "tx", tx),
+		nil,
+		setSwapRequestID("concurrentswapid", &tradeID),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tradeRepository.UpdateTrade(
+		context.WithValue(context.Background(), "tx", otherTx),
+		nil,
+		setSwapRequestID("concurrentswapid", &otherTradeID),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, true, dbManager.isTransactionConflict(otherTx.Commit()))
+}
+
+func TestGetTradesByCompleteTime(t *testing.T) {
+	before()
+	defer after()
+
+	completeTimes := []uint64{30, 10, 20}
+	for _, completeTime := range completeTimes {
+		ct := completeTime
+		err := tradeRepository.UpdateTrade(
+			ctx,
+			nil,
+			func(t *domain.Trade) (*domain.Trade, error) {
+				t.Timestamp.Complete = ct
+				return t, nil
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trades, err := tradeRepository.GetTradesByCompleteTime(ctx, 0, 100, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(trades))
+	for i, trade := range trades {
+		assert.Equal(t, uint64(10*(i+1)), trade.SwapCompleteTime())
+	}
+
+	trades, err = tradeRepository.GetTradesByCompleteTime(ctx, 15, 100, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, uint64(30), trades[0].SwapCompleteTime())
+}
@@ -0,0 +1,181 @@
+package dbbadger
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/google/uuid"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/operator"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+// Trades are indexed also by market and status, with keys made of both
+// followed by the trade id, so that they can be counted by iterating over the
+// keys of the index only, without loading and decoding the trades. Every
+// trade has also a reference to its index entry, so that the entry of the
+// previous status can be replaced without reading the stored trade.
+const (
+	tradeStatusIndexPrefix       = "trade_status_idx:"
+	tradeStatusRefPrefix         = "trade_status_ref:"
+	tradeStatusIndexesVersionKey = "trade_status_idx_version"
+	tradeStatusIndexesVersion    = "1"
+)
+
+func tradeStatusIndexKey(trade domain.Trade) []byte {
+	return []byte(tradeStatusIndexPrefix + strings.Join([]string{
+		trade.MarketQuoteAsset,
+		strconv.Itoa(int(trade.Status.Code)),
+		strconv.FormatBool(trade.Status.Failed),
+		trade.ID.String(),
+	}, ":"))
+}
+
+func tradeStatusRefKey(tradeID uuid.UUID) []byte {
+	return []byte(tradeStatusRefPrefix + tradeID.String())
+}
+
+// indexTradeStatus indexes the given trade by its market and status,
+// replacing the entry of its previous status, if any
+func indexTradeStatus(tx *badger.Txn, trade domain.Trade) error {
+	key := tradeStatusIndexKey(trade)
+	refKey := tradeStatusRefKey(trade.ID)
+
+	item, err := tx.Get(refKey)
+	if err != nil && err != badger.ErrKeyNotFound {
+		return err
+	}
+	if err == nil {
+		prevKey, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(prevKey, key) {
+			return nil
+		}
+		if err := tx.Delete(prevKey); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Set(key, nil); err != nil {
+		return err
+	}
+	return tx.Set(refKey, key)
+}
+
+// countTradesByStatus returns the number of trades of every market and status
+func countTradesByStatus(tx *badger.Txn) ([]domain.TradeCount, error) {
+	prefix := []byte(tradeStatusIndexPrefix)
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = prefix
+	opts.PrefetchValues = false
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	type countKey struct {
+		market string
+		status domain.Status
+	}
+	counts := map[countKey]int{}
+	keys := make([]countKey, 0)
+	for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+		parts := strings.Split(
+			strings.TrimPrefix(string(it.Item().Key()), tradeStatusIndexPrefix),
+			":",
+		)
+		if len(parts) != 4 {
+			continue
+		}
+		code, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		failed, err := strconv.ParseBool(parts[2])
+		if err != nil {
+			continue
+		}
+
+		k := countKey{
+			parts[0], domain.Status{Code: pb.SwapStatus(code), Failed: failed},
+		}
+		if _, ok := counts[k]; !ok {
+			keys = append(keys, k)
+		}
+		counts[k]++
+	}
+
+	tradeCounts := make([]domain.TradeCount, 0, len(keys))
+	for _, k := range keys {
+		tradeCounts = append(tradeCounts, domain.TradeCount{
+			MarketQuoteAsset: k.market,
+			Status:           k.status,
+			Count:            counts[k],
+		})
+	}
+	return tradeCounts, nil
+}
+
+// buildTradeStatusIndex indexes by market and status the trades stored before
+// the index was introduced. It runs only once for every store.
+func buildTradeStatusIndex(store *badgerhold.Store) error {
+	db := store.Badger()
+	alreadyBuilt := false
+	if err := db.View(func(tx *badger.Txn) error {
+		_, err := tx.Get([]byte(tradeStatusIndexesVersionKey))
+		if err == nil {
+			alreadyBuilt = true
+			return nil
+		}
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	if alreadyBuilt {
+		return nil
+	}
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	if err := db.View(func(tx *badger.Txn) error {
+		prefix := []byte(TradeBadgerholdKeyPrefix + `"`)
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			var trade domain.Trade
+			if err := JSONDecode(data, &trade); err != nil {
+				continue
+			}
+			key := tradeStatusIndexKey(trade)
+			if err := wb.Set(key, nil); err != nil {
+				return err
+			}
+			if err := wb.Set(tradeStatusRefKey(trade.ID), key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := wb.Set(
+		[]byte(tradeStatusIndexesVersionKey), []byte(tradeStatusIndexesVersion),
+	); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
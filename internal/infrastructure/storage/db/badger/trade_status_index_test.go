@@ -0,0 +1,71 @@
+package dbbadger
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestTradeStatusIndex(t *testing.T) {
+	os.Mkdir(testDbDir, os.ModePerm)
+	defer os.RemoveAll(testDbDir)
+
+	db, err := NewDbManager(testDbDir, nil)
+	require.NoError(t, err)
+	defer func() {
+		db.Store.Close()
+		db.PriceStore.Close()
+		db.UnspentStore.Close()
+	}()
+
+	// a trade stored before the index was introduced
+	trade := domain.NewTrade()
+	trade.MarketQuoteAsset = "mqa1"
+	trade.Status = domain.CompletedStatus
+	require.NoError(t, db.Store.Insert(trade.ID, trade))
+	require.NoError(t, db.Store.Badger().Update(func(tx *badger.Txn) error {
+		return tx.Delete([]byte(tradeStatusIndexesVersionKey))
+	}))
+
+	require.NoError(t, buildTradeStatusIndex(db.Store))
+
+	ctx := context.Background()
+	repository := NewTradeRepositoryImpl(db)
+	newTrade, err := repository.GetOrCreateTrade(ctx, nil)
+	require.NoError(t, err)
+
+	counts, err := repository.CountTradesByMarketAndStatus(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.TradeCount{
+		{MarketQuoteAsset: "mqa1", Status: domain.CompletedStatus, Count: 1},
+		{MarketQuoteAsset: "", Status: domain.EmptyStatus, Count: 1},
+	}, counts)
+
+	// the entry of the previous status is replaced by that of the new one
+	for _, status := range []domain.Status{
+		domain.ProposalStatus, domain.AcceptedStatus,
+	} {
+		status := status
+		require.NoError(t, repository.UpdateTrade(
+			ctx,
+			&newTrade.ID,
+			func(t *domain.Trade) (*domain.Trade, error) {
+				t.MarketQuoteAsset = "mqa1"
+				t.Status = status
+				return t, nil
+			},
+		))
+	}
+
+	counts, err = repository.CountTradesByMarketAndStatus(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.TradeCount{
+		{MarketQuoteAsset: "mqa1", Status: domain.CompletedStatus, Count: 1},
+		{MarketQuoteAsset: "mqa1", Status: domain.AcceptedStatus, Count: 1},
+	}, counts)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/timshannon/badgerhold/v2"
 )
@@ -147,6 +148,13 @@ func (u unspentRepositoryImpl) SpendUnspents(
 	return u.spendUnspents(ctx, unspentKeys)
 }
 
+func (u unspentRepositoryImpl) UnspendUnspents(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+) error {
+	return u.unspendUnspents(ctx, unspentKeys)
+}
+
 func (u unspentRepositoryImpl) ConfirmUnspents(
 	ctx context.Context,
 	unspentKeys []domain.UnspentKey,
@@ -154,6 +162,22 @@ func (u unspentRepositoryImpl) ConfirmUnspents(
 	return u.confirmUnspents(ctx, unspentKeys)
 }
 
+func (u unspentRepositoryImpl) ConfirmUnspentsInBlock(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+	blockHeight uint64,
+	blockHash string,
+) error {
+	return u.confirmUnspentsInBlock(ctx, unspentKeys, blockHeight, blockHash)
+}
+
+func (u unspentRepositoryImpl) UnconfirmUnspents(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+) error {
+	return u.unconfirmUnspents(ctx, unspentKeys)
+}
+
 func (u unspentRepositoryImpl) LockUnspents(
 	ctx context.Context,
 	unspentKeys []domain.UnspentKey,
@@ -288,6 +312,41 @@ func (u unspentRepositoryImpl) spendUnspent(
 	return u.updateUnspent(ctx, key, *unspent)
 }
 
+func (u unspentRepositoryImpl) unspendUnspents(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+) error {
+	for _, key := range unspentKeys {
+		if err := u.unspendUnspent(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u unspentRepositoryImpl) unspendUnspent(
+	ctx context.Context,
+	key domain.UnspentKey,
+) error {
+	unspent, err := u.getUnspent(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if unspent == nil {
+		return nil
+	}
+
+	if err := u.unlockUnspent(ctx, key); err != nil {
+		return err
+	}
+
+	unspent.UnSpend()
+	unspent.UnLock() // prevent conflict, locks not stored under unspent prefix
+
+	return u.updateUnspent(ctx, key, *unspent)
+}
+
 func (u unspentRepositoryImpl) confirmUnspents(
 	ctx context.Context,
 	unspentKeys []domain.UnspentKey,
@@ -319,6 +378,77 @@ func (u unspentRepositoryImpl) confirmUnspent(
 	return u.updateUnspent(ctx, key, *unspent)
 }
 
+func (u unspentRepositoryImpl) confirmUnspentsInBlock(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+	blockHeight uint64,
+	blockHash string,
+) error {
+	for _, key := range unspentKeys {
+		if err := u.confirmUnspentInBlock(
+			ctx,
+			key,
+			blockHeight,
+			blockHash,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u unspentRepositoryImpl) confirmUnspentInBlock(
+	ctx context.Context,
+	key domain.UnspentKey,
+	blockHeight uint64,
+	blockHash string,
+) error {
+	unspent, err := u.getUnspent(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if unspent == nil {
+		return nil
+	}
+
+	unspent.ConfirmInBlock(blockHeight, blockHash)
+	unspent.UnLock() // prevent conflict, locks not stored under unspent prefix
+
+	return u.updateUnspent(ctx, key, *unspent)
+}
+
+func (u unspentRepositoryImpl) unconfirmUnspents(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+) error {
+	for _, key := range unspentKeys {
+		if err := u.unconfirmUnspent(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u unspentRepositoryImpl) unconfirmUnspent(
+	ctx context.Context,
+	key domain.UnspentKey,
+) error {
+	unspent, err := u.getUnspent(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if unspent == nil {
+		return nil
+	}
+
+	unspent.Unconfirm()
+	unspent.UnLock() // prevent conflict, locks not stored under unspent prefix
+
+	return u.updateUnspent(ctx, key, *unspent)
+}
+
 func (u unspentRepositoryImpl) lockUnspents(
 	ctx context.Context,
 	unspentKeys []domain.UnspentKey,
@@ -329,6 +459,11 @@ func (u unspentRepositoryImpl) lockUnspents(
 			return err
 		}
 	}
+
+	if len(unspentKeys) > 0 {
+		log.WithContext(ctx).WithField("trade_id", tradeID.String()).
+			Debugf("locked %d unspents", len(unspentKeys))
+	}
 	return nil
 }
 
@@ -253,6 +253,49 @@ func TestConfirmUnspents(t *testing.T) {
 	assert.Equal(t, true, unspent.IsConfirmed())
 }
 
+func TestConfirmUnconfirmUnspentsInBlock(t *testing.T) {
+	before()
+	defer after()
+
+	unspentKey := domain.UnspentKey{
+		TxID: "2",
+		VOut: 1,
+	}
+	if err := unspentRepository.ConfirmUnspentsInBlock(
+		ctx,
+		[]domain.UnspentKey{unspentKey},
+		10,
+		"blockhash",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	unspent, err := unspentRepository.GetUnspentForKey(ctx, unspentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, true, unspent.IsConfirmed())
+	assert.Equal(t, uint64(10), unspent.BlockHeight)
+	assert.Equal(t, "blockhash", unspent.BlockHash)
+
+	if err = unspentRepository.UnconfirmUnspents(
+		ctx,
+		[]domain.UnspentKey{unspentKey},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	unspent, err = unspentRepository.GetUnspentForKey(ctx, unspentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, false, unspent.IsConfirmed())
+	assert.Equal(t, uint64(0), unspent.BlockHeight)
+	assert.Equal(t, "", unspent.BlockHash)
+}
+
 func TestGetUnlockedBalance(t *testing.T) {
 	before()
 	defer after()
@@ -33,6 +33,24 @@ func (v vaultRepositoryImpl) GetOrCreateVault(
 	return v.getOrCreateVault(ctx, mnemonic, passphrase)
 }
 
+func (v vaultRepositoryImpl) GetOrCreateSealedVault(
+	ctx context.Context,
+) (*domain.Vault, error) {
+	vault, err := v.getVault(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if vault == nil {
+		vault = domain.NewSealedVault()
+		if err := v.insertVault(ctx, *vault); err != nil {
+			return nil, err
+		}
+	}
+
+	return vault, nil
+}
+
 func (v vaultRepositoryImpl) UpdateVault(
 	ctx context.Context,
 	mnemonic []string,
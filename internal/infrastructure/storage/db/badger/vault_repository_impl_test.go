@@ -1,11 +1,12 @@
 package dbbadger
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/keystore"
 )
 
 func TestAll(t *testing.T) {
@@ -14,9 +15,14 @@ func TestAll(t *testing.T) {
 
 	var addr string
 
-	config.Set(config.MnemonicKey,
+	ks := keystore.NewKeystore(0)
+	if err := ks.Unlock(strings.Split(
 		"leave dice fine decrease dune ribbon ocean earn lunar account silver"+
-			" admit cheap fringe disorder trade because trade steak clock grace video jacket equal")
+			" admit cheap fringe disorder trade because trade steak clock grace video jacket equal",
+		" ",
+	)); err != nil {
+		t.Fatal(err)
+	}
 
 	if err := vaultRepository.UpdateVault(
 		ctx,
@@ -24,6 +30,7 @@ func TestAll(t *testing.T) {
 		"",
 		func(v *domain.Vault) (*domain.Vault, error) {
 			a, _, _, err := v.DeriveNextExternalAddressForAccount(
+				ks,
 				domain.FeeAccount,
 			)
 			if err != nil {
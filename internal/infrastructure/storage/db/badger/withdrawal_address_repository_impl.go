@@ -0,0 +1,101 @@
+package dbbadger
+
+import (
+	"context"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+type withdrawalAddressRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewWithdrawalAddressRepositoryImpl initialize a badger implementation of
+// the domain.WithdrawalAddressRepository
+func NewWithdrawalAddressRepositoryImpl(
+	db *DbManager,
+) domain.WithdrawalAddressRepository {
+	return withdrawalAddressRepositoryImpl{
+		db: db,
+	}
+}
+
+func (w withdrawalAddressRepositoryImpl) AddWithdrawalAddress(
+	ctx context.Context,
+	withdrawalAddress domain.WithdrawalAddress,
+) error {
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = w.db.Store.TxInsert(
+			tx,
+			withdrawalAddress.Script,
+			&withdrawalAddress,
+		)
+	} else {
+		err = w.db.Store.Insert(withdrawalAddress.Script, &withdrawalAddress)
+	}
+	if err == badgerhold.ErrKeyExists {
+		return domain.ErrWithdrawalAddressAlreadyWhitelisted
+	}
+	return err
+}
+
+func (w withdrawalAddressRepositoryImpl) RemoveWithdrawalAddress(
+	ctx context.Context,
+	script string,
+) error {
+	withdrawalAddress, err := w.GetWithdrawalAddressByScript(ctx, script)
+	if err != nil {
+		return err
+	}
+	if withdrawalAddress == nil {
+		return domain.ErrWithdrawalAddressNotFound
+	}
+
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		return w.db.Store.TxDelete(tx, script, domain.WithdrawalAddress{})
+	}
+	return w.db.Store.Delete(script, domain.WithdrawalAddress{})
+}
+
+func (w withdrawalAddressRepositoryImpl) GetWithdrawalAddressByScript(
+	ctx context.Context,
+	script string,
+) (*domain.WithdrawalAddress, error) {
+	var withdrawalAddress domain.WithdrawalAddress
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = w.db.Store.TxGet(tx, script, &withdrawalAddress)
+	} else {
+		err = w.db.Store.Get(script, &withdrawalAddress)
+	}
+	if err != nil {
+		if err == badgerhold.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &withdrawalAddress, nil
+}
+
+func (w withdrawalAddressRepositoryImpl) GetAllWithdrawalAddresses(
+	ctx context.Context,
+) ([]domain.WithdrawalAddress, error) {
+	query := (&badgerhold.Query{}).SortBy("CreatedAt")
+
+	var addresses []domain.WithdrawalAddress
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = w.db.Store.TxFind(tx, &addresses, query)
+	} else {
+		err = w.db.Store.Find(&addresses, query)
+	}
+
+	return addresses, err
+}
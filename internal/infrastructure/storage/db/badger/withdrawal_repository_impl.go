@@ -0,0 +1,111 @@
+package dbbadger
+
+import (
+	"context"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/timshannon/badgerhold/v2"
+)
+
+type withdrawalRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewWithdrawalRepositoryImpl initialize a badger implementation of the
+// domain.WithdrawalRepository
+func NewWithdrawalRepositoryImpl(db *DbManager) domain.WithdrawalRepository {
+	return withdrawalRepositoryImpl{
+		db: db,
+	}
+}
+
+func (w withdrawalRepositoryImpl) AddWithdrawals(
+	ctx context.Context,
+	withdrawals []domain.Withdrawal,
+) (int, error) {
+	count := 0
+	for _, withdrawal := range withdrawals {
+		done, err := w.insertWithdrawal(ctx, withdrawal)
+		if err != nil {
+			return count, err
+		}
+		if done {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (w withdrawalRepositoryImpl) GetAllWithdrawals(
+	ctx context.Context,
+) ([]domain.Withdrawal, error) {
+	query := (&badgerhold.Query{}).SortBy("Timestamp")
+	return w.findWithdrawals(ctx, query)
+}
+
+func (w withdrawalRepositoryImpl) findWithdrawals(
+	ctx context.Context,
+	query *badgerhold.Query,
+) ([]domain.Withdrawal, error) {
+	var withdrawals []domain.Withdrawal
+	var err error
+	if ctx.Value("tx") != nil {
+		tx := ctx.Value("tx").(*badger.Txn)
+		err = w.db.Store.TxFind(tx, &withdrawals, query)
+	} else {
+		err = w.db.Store.Find(&withdrawals, query)
+	}
+
+	return withdrawals, err
+}
+
+func (w withdrawalRepositoryImpl) GetWithdrawalsByTime(
+	ctx context.Context,
+	fromTime, toTime uint64,
+	offset, limit int,
+) ([]domain.Withdrawal, error) {
+	withdrawals := make([]domain.Withdrawal, 0)
+	err := view(ctx, w.db.Store, func(tx *badger.Txn) error {
+		return iterateTimeIndex(
+			tx, withdrawalTimeIndexName, fromTime, toTime, offset, limit,
+			func(encodedKey []byte) error {
+				var txid string
+				if err := JSONDecode(encodedKey, &txid); err != nil {
+					return err
+				}
+				var withdrawal domain.Withdrawal
+				if err := w.db.Store.TxGet(tx, txid, &withdrawal); err != nil {
+					return err
+				}
+				withdrawals = append(withdrawals, withdrawal)
+				return nil
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawals, nil
+}
+
+func (w withdrawalRepositoryImpl) insertWithdrawal(
+	ctx context.Context,
+	withdrawal domain.Withdrawal,
+) (bool, error) {
+	inserted := false
+	err := update(ctx, w.db.Store, func(tx *badger.Txn) error {
+		if err := w.db.Store.TxInsert(tx, withdrawal.TxID, &withdrawal); err != nil {
+			if err == badgerhold.ErrKeyExists {
+				return nil
+			}
+			return err
+		}
+		inserted = true
+
+		return addToTimeIndex(
+			tx, withdrawalTimeIndexName, withdrawal.Timestamp, withdrawal.TxID,
+		)
+	})
+	return inserted, err
+}
@@ -17,11 +17,12 @@ type marketInmemoryStore struct {
 }
 
 type tradeInmemoryStore struct {
-	trades               map[uuid.UUID]domain.Trade
-	tradesBySwapAcceptID map[string]uuid.UUID
-	tradesByTrader       map[string][]uuid.UUID
-	tradesByMarket       map[string][]uuid.UUID
-	locker               *sync.Mutex
+	trades                map[uuid.UUID]domain.Trade
+	tradesBySwapAcceptID  map[string]uuid.UUID
+	tradesBySwapRequestID map[string]uuid.UUID
+	tradesByTrader        map[string][]uuid.UUID
+	tradesByMarket        map[string][]uuid.UUID
+	locker                *sync.Mutex
 }
 
 type unspentInmemoryStore struct {
@@ -29,16 +30,42 @@ type unspentInmemoryStore struct {
 	locker   *sync.RWMutex
 }
 
+type depositInmemoryStore struct {
+	deposits map[domain.UnspentKey]domain.Deposit
+	locker   *sync.RWMutex
+}
+
+type withdrawalInmemoryStore struct {
+	withdrawals map[string]domain.Withdrawal
+	locker      *sync.RWMutex
+}
+
+type withdrawalAddressInmemoryStore struct {
+	addresses map[string]domain.WithdrawalAddress
+	locker    *sync.RWMutex
+}
+
+type paymentInmemoryStore struct {
+	payments map[string]domain.Payment
+	locker   *sync.RWMutex
+}
+
 type vaultInmemoryStore struct {
 	vault  *domain.Vault
 	locker *sync.Mutex
 }
 
 type DbManager struct {
-	marketStore  *marketInmemoryStore
-	tradeStore   *tradeInmemoryStore
-	unspentStore *unspentInmemoryStore
-	vaultStore   *vaultInmemoryStore
+	marketStore     *marketInmemoryStore
+	tradeStore      *tradeInmemoryStore
+	unspentStore    *unspentInmemoryStore
+	vaultStore      *vaultInmemoryStore
+	depositStore    *depositInmemoryStore
+	withdrawalStore *withdrawalInmemoryStore
+	// withdrawal addresses are whitelisted ones, not to be confused with
+	// those of the withdrawals
+	withdrawalAddressStore *withdrawalAddressInmemoryStore
+	paymentStore           *paymentInmemoryStore
 }
 
 type InmemoryTx struct {
@@ -66,11 +93,12 @@ func NewDbManager() *DbManager {
 			locker:          &sync.Mutex{},
 		},
 		tradeStore: &tradeInmemoryStore{
-			trades:               map[uuid.UUID]domain.Trade{},
-			tradesBySwapAcceptID: map[string]uuid.UUID{},
-			tradesByTrader:       map[string][]uuid.UUID{},
-			tradesByMarket:       map[string][]uuid.UUID{},
-			locker:               &sync.Mutex{},
+			trades:                map[uuid.UUID]domain.Trade{},
+			tradesBySwapAcceptID:  map[string]uuid.UUID{},
+			tradesBySwapRequestID: map[string]uuid.UUID{},
+			tradesByTrader:        map[string][]uuid.UUID{},
+			tradesByMarket:        map[string][]uuid.UUID{},
+			locker:                &sync.Mutex{},
 		},
 		unspentStore: &unspentInmemoryStore{
 			unspents: map[domain.UnspentKey]domain.Unspent{},
@@ -80,6 +108,22 @@ func NewDbManager() *DbManager {
 			vault:  &domain.Vault{},
 			locker: &sync.Mutex{},
 		},
+		depositStore: &depositInmemoryStore{
+			deposits: map[domain.UnspentKey]domain.Deposit{},
+			locker:   &sync.RWMutex{},
+		},
+		withdrawalStore: &withdrawalInmemoryStore{
+			withdrawals: map[string]domain.Withdrawal{},
+			locker:      &sync.RWMutex{},
+		},
+		withdrawalAddressStore: &withdrawalAddressInmemoryStore{
+			addresses: map[string]domain.WithdrawalAddress{},
+			locker:    &sync.RWMutex{},
+		},
+		paymentStore: &paymentInmemoryStore{
+			payments: map[string]domain.Payment{},
+			locker:   &sync.RWMutex{},
+		},
 	}
 }
 
@@ -133,3 +177,17 @@ func (db *DbManager) runTransaction(
 
 	return res, nil
 }
+
+// pageBounds returns the bounds of the page of a list of the given length
+// that skips the first offset items and has at most limit items. A zero limit
+// means no limit.
+func pageBounds(length, offset, limit int) (int, int) {
+	if offset > length {
+		offset = length
+	}
+	end := length
+	if limit > 0 && offset+limit < length {
+		end = offset + limit
+	}
+	return offset, end
+}
@@ -0,0 +1,100 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+// DepositRepositoryImpl represents an in memory storage
+type DepositRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewDepositRepositoryImpl returns a new empty DepositRepositoryImpl
+func NewDepositRepositoryImpl(db *DbManager) domain.DepositRepository {
+	return &DepositRepositoryImpl{
+		db: db,
+	}
+}
+
+// AddDeposits adds the given deposits to the storage, skipping those already
+// existing, and returns the number of the added ones
+func (r DepositRepositoryImpl) AddDeposits(
+	_ context.Context,
+	deposits []domain.Deposit,
+) (int, error) {
+	r.db.depositStore.locker.Lock()
+	defer r.db.depositStore.locker.Unlock()
+
+	count := 0
+	for _, d := range deposits {
+		if _, ok := r.db.depositStore.deposits[d.Key()]; ok {
+			continue
+		}
+		r.db.depositStore.deposits[d.Key()] = d
+		count++
+	}
+	return count, nil
+}
+
+// GetAllDeposits returns all the deposits sorted by timestamp
+func (r DepositRepositoryImpl) GetAllDeposits(
+	_ context.Context,
+) ([]domain.Deposit, error) {
+	r.db.depositStore.locker.RLock()
+	defer r.db.depositStore.locker.RUnlock()
+
+	deposits := make([]domain.Deposit, 0, len(r.db.depositStore.deposits))
+	for _, d := range r.db.depositStore.deposits {
+		deposits = append(deposits, d)
+	}
+	sort.SliceStable(deposits, func(i, j int) bool {
+		return deposits[i].Timestamp < deposits[j].Timestamp
+	})
+	return deposits, nil
+}
+
+// GetDepositsByTime returns a page of the deposits confirmed in the given time
+// range, sorted by timestamp
+func (r DepositRepositoryImpl) GetDepositsByTime(
+	_ context.Context,
+	fromTime, toTime uint64,
+	offset, limit int,
+) ([]domain.Deposit, error) {
+	r.db.depositStore.locker.RLock()
+	defer r.db.depositStore.locker.RUnlock()
+
+	deposits := make([]domain.Deposit, 0)
+	for _, d := range r.db.depositStore.deposits {
+		if d.Timestamp > 0 && d.Timestamp >= fromTime && d.Timestamp <= toTime {
+			deposits = append(deposits, d)
+		}
+	}
+	sort.SliceStable(deposits, func(i, j int) bool {
+		return deposits[i].Timestamp < deposits[j].Timestamp
+	})
+	start, end := pageBounds(len(deposits), offset, limit)
+	return deposits[start:end], nil
+}
+
+// ConfirmDeposits sets the block time of the given deposits
+func (r DepositRepositoryImpl) ConfirmDeposits(
+	_ context.Context,
+	keys []domain.UnspentKey,
+	blockTime uint64,
+) error {
+	r.db.depositStore.locker.Lock()
+	defer r.db.depositStore.locker.Unlock()
+
+	for _, key := range keys {
+		d, ok := r.db.depositStore.deposits[key]
+		if !ok {
+			continue
+		}
+		d.Timestamp = blockTime
+		r.db.depositStore.deposits[key] = d
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestAddAndGetAllDeposits(t *testing.T) {
+	db := newMockDb()
+	depositRepository := NewDepositRepositoryImpl(db)
+
+	deposits := []domain.Deposit{
+		{AccountIndex: 5, TxID: "txid1", VOut: 0, Asset: "ah5", Value: 100, Timestamp: 20},
+		{AccountIndex: 5, TxID: "txid1", VOut: 1, Asset: "qh5", Value: 200, Timestamp: 10},
+	}
+
+	count, err := depositRepository.AddDeposits(ctx, deposits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, count)
+
+	// adding the same deposits again must not create duplicates
+	count, err = depositRepository.AddDeposits(ctx, deposits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, count)
+
+	allDeposits, err := depositRepository.GetAllDeposits(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(allDeposits))
+	assert.Equal(t, uint64(10), allDeposits[0].Timestamp)
+}
+
+func TestGetDepositsByTimeAndConfirm(t *testing.T) {
+	db := newMockDb()
+	depositRepository := NewDepositRepositoryImpl(db)
+
+	deposits := []domain.Deposit{
+		{AccountIndex: 5, TxID: "txid1", VOut: 0, Asset: "ah5", Value: 100, Timestamp: 30},
+		{AccountIndex: 5, TxID: "txid2", VOut: 0, Asset: "ah5", Value: 200, Timestamp: 10},
+		{AccountIndex: 5, TxID: "txid3", VOut: 0, Asset: "ah5", Value: 300},
+	}
+	if _, err := depositRepository.AddDeposits(ctx, deposits); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := depositRepository.GetDepositsByTime(ctx, 0, 100, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(page))
+	assert.Equal(t, "txid2", page[0].TxID)
+
+	page, err = depositRepository.GetDepositsByTime(ctx, 0, 100, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(page))
+	assert.Equal(t, "txid1", page[0].TxID)
+
+	err = depositRepository.ConfirmDeposits(
+		ctx, []domain.UnspentKey{{TxID: "txid3", VOut: 0}}, 20,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err = depositRepository.GetDepositsByTime(ctx, 15, 25, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(page))
+	assert.Equal(t, "txid3", page[0].TxID)
+}
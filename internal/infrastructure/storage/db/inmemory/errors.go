@@ -1,6 +1,11 @@
 package inmemory
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
 
 var (
 	// ErrMarketNotExist is thrown when a market is not found
@@ -8,7 +13,9 @@ var (
 	// ErrMarketsNotFound is thrown when there is no market associated to a given quote asset
 	ErrMarketsNotFound = errors.New("no markets found for the given address")
 	// ErrTradesNotFound is thrown when there is no trades associated to a given trade or swap ID
-	ErrTradesNotFound = errors.New("no trades found for the given tradeID/SwapID")
+	ErrTradesNotFound = fmt.Errorf(
+		"%w for the given tradeID/SwapID", domain.ErrTradeNotFound,
+	)
 	// ErrAlreadyLocked is thrown when trying to lock an already locked wallet
 	ErrAlreadyLocked = errors.New("wallet is already locked")
 	// ErrAlreadyUnlocked is thrown when trying to lunock an already unlocked wallet
@@ -0,0 +1,62 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+// PaymentRepositoryImpl represents an in memory storage
+type PaymentRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewPaymentRepositoryImpl returns a new empty PaymentRepositoryImpl
+func NewPaymentRepositoryImpl(db *DbManager) domain.PaymentRepository {
+	return &PaymentRepositoryImpl{
+		db: db,
+	}
+}
+
+// AddPayment adds the given payment to the storage if not already existing
+func (r PaymentRepositoryImpl) AddPayment(
+	_ context.Context,
+	payment domain.Payment,
+) error {
+	r.db.paymentStore.locker.Lock()
+	defer r.db.paymentStore.locker.Unlock()
+
+	if _, ok := r.db.paymentStore.payments[payment.TxID]; !ok {
+		r.db.paymentStore.payments[payment.TxID] = payment
+	}
+	return nil
+}
+
+// GetAllPayments returns all the payments sorted by timestamp
+func (r PaymentRepositoryImpl) GetAllPayments(
+	ctx context.Context,
+) ([]domain.Payment, error) {
+	return r.GetPaymentsSince(ctx, 0)
+}
+
+// GetPaymentsSince returns the payments made since the given timestamp,
+// sorted by timestamp
+func (r PaymentRepositoryImpl) GetPaymentsSince(
+	_ context.Context,
+	timestamp uint64,
+) ([]domain.Payment, error) {
+	r.db.paymentStore.locker.RLock()
+	defer r.db.paymentStore.locker.RUnlock()
+
+	payments := make([]domain.Payment, 0)
+	for _, p := range r.db.paymentStore.payments {
+		if p.Timestamp >= timestamp {
+			payments = append(payments, p)
+		}
+	}
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].Timestamp < payments[j].Timestamp
+	})
+	return payments, nil
+}
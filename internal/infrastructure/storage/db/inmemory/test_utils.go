@@ -182,7 +182,6 @@ func insertUnspents(db *DbManager) error {
 func insertVault(db *DbManager) error {
 	vault := &domain.Vault{
 		EncryptedMnemonic:      "dVoBFte1oeRkPl8Vf8DzBP3PRnzPA3fxtyvDHXFGYAS9MP8V2Sc9nHcQW4PrMkQNnf2uGrDg81dFgBrwqv1n3frXxRBKhp83fSsTm4xqj8+jdwTI3nouFmi1W/O4UqpHdQ62EYoabJQtKpptWO11TFJzw8WF02pfS6git8YjLR4xrnfp2LkOEjSU9CI82ZasF46WZFKcpeUJTAsxU/03ONpAdwwEsC96f1KAvh8tqaO0yLDOcmPf8a5B82jefgncCRrt32kCpbpIE4YiCFrqqdUHXKH+",
-		PassphraseHash:         []byte("pass"),
 		Accounts:               map[int]*domain.Account{},
 		AccountAndKeyByAddress: map[string]domain.AccountAndKey{},
 	}
@@ -261,6 +260,7 @@ func insertTrades(db *DbManager) error {
 		}
 
 		db.tradeStore.tradesBySwapAcceptID[v.SwapAccept.ID] = v.ID
+		db.tradeStore.tradesBySwapRequestID[v.SwapRequest.ID] = v.ID
 	}
 	return nil
 }
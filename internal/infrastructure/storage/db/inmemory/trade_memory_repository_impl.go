@@ -3,6 +3,7 @@ package inmemory
 import (
 	"context"
 	"encoding/hex"
+	"sort"
 
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 
@@ -33,7 +34,7 @@ func (r TradeRepositoryImpl) GetCompletedTradesByMarket(ctx context.Context, mar
 
 	completedTrades := make([]*domain.Trade, 0)
 	for _, trade := range tradesByMarkets {
-		if trade.Status.Code == domain.CompletedStatus.Code {
+		if trade.IsCompleted() {
 			completedTrades = append(completedTrades, trade)
 		}
 	}
@@ -41,6 +42,53 @@ func (r TradeRepositoryImpl) GetCompletedTradesByMarket(ctx context.Context, mar
 	return completedTrades, nil
 }
 
+// GetAcceptedTrades returns the trades accepted and neither completed nor
+// failed yet
+func (r TradeRepositoryImpl) GetAcceptedTrades(_ context.Context) ([]*domain.Trade, error) {
+	r.db.tradeStore.locker.Lock()
+	defer r.db.tradeStore.locker.Unlock()
+
+	trades, err := r.getAllTrades()
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedTrades := make([]*domain.Trade, 0)
+	for _, trade := range trades {
+		if trade.Status == domain.AcceptedStatus {
+			acceptedTrades = append(acceptedTrades, trade)
+		}
+	}
+
+	return acceptedTrades, nil
+}
+
+// CountTradesByMarketAndStatus returns the number of trades of every market
+// and status
+func (r TradeRepositoryImpl) CountTradesByMarketAndStatus(_ context.Context) ([]domain.TradeCount, error) {
+	r.db.tradeStore.locker.Lock()
+	defer r.db.tradeStore.locker.Unlock()
+
+	type countKey struct {
+		market string
+		status domain.Status
+	}
+	counts := map[countKey]int{}
+	for _, trade := range r.db.tradeStore.trades {
+		counts[countKey{trade.MarketQuoteAsset, trade.Status}]++
+	}
+
+	tradeCounts := make([]domain.TradeCount, 0, len(counts))
+	for k, count := range counts {
+		tradeCounts = append(tradeCounts, domain.TradeCount{
+			MarketQuoteAsset: k.market,
+			Status:           k.status,
+			Count:            count,
+		})
+	}
+	return tradeCounts, nil
+}
+
 // GetOrCreateTrade gets a trade with a given swapID that can be either a
 // request, accept, or complete ID. They all identify the same Trade.
 // If not found, a new entry is inserted
@@ -83,6 +131,60 @@ func (r TradeRepositoryImpl) GetTradeBySwapAcceptID(_ context.Context, swapAccep
 	return r.getTradeBySwapAcceptID(swapAcceptID)
 }
 
+// GetTradeBySwapRequestID returns the trade idetified by the swap request
+// message's id
+func (r TradeRepositoryImpl) GetTradeBySwapRequestID(_ context.Context, swapRequestID string) (*domain.Trade, error) {
+	r.db.tradeStore.locker.Lock()
+	defer r.db.tradeStore.locker.Unlock()
+
+	tradeID, ok := r.db.tradeStore.tradesBySwapRequestID[swapRequestID]
+	if !ok {
+		return nil, ErrTradesNotFound
+	}
+	trade := r.db.tradeStore.trades[tradeID]
+	return &trade, nil
+}
+
+// GetTradeByTxID returns the trade identified by the txid of its completed
+// swap transaction
+func (r TradeRepositoryImpl) GetTradeByTxID(_ context.Context, txID string) (*domain.Trade, error) {
+	r.db.tradeStore.locker.Lock()
+	defer r.db.tradeStore.locker.Unlock()
+
+	for _, trade := range r.db.tradeStore.trades {
+		if trade.TxID == txID {
+			t := trade
+			return &t, nil
+		}
+	}
+	return nil, ErrTradesNotFound
+}
+
+// GetTradesByCompleteTime returns a page of the trades completed in the given
+// time range, sorted by complete time
+func (r TradeRepositoryImpl) GetTradesByCompleteTime(
+	_ context.Context,
+	fromTime, toTime uint64,
+	offset, limit int,
+) ([]*domain.Trade, error) {
+	r.db.tradeStore.locker.Lock()
+	defer r.db.tradeStore.locker.Unlock()
+
+	trades := make([]*domain.Trade, 0)
+	for _, trade := range r.db.tradeStore.trades {
+		completeTime := trade.SwapCompleteTime()
+		if completeTime > 0 && completeTime >= fromTime && completeTime <= toTime {
+			t := trade
+			trades = append(trades, &t)
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].SwapCompleteTime() < trades[j].SwapCompleteTime()
+	})
+	start, end := pageBounds(len(trades), offset, limit)
+	return trades[start:end], nil
+}
+
 // UpdateTrade updates data to a trade identified by any of its swap ids (request, accept, complete) passing an update function
 func (r TradeRepositoryImpl) UpdateTrade(
 	ctx context.Context,
@@ -102,13 +204,27 @@ func (r TradeRepositoryImpl) UpdateTrade(
 		return err
 	}
 
+	// a swap request id can't be used by more than one trade
+	swapRequestID := updatedTrade.SwapRequest.ID
+	if id, ok := r.db.tradeStore.tradesBySwapRequestID[swapRequestID]; ok &&
+		id != currentTrade.ID {
+		return domain.ErrDuplicatedSwapRequest
+	}
+
 	if swapAccept := updatedTrade.SwapAcceptMessage(); swapAccept != nil {
 		if _, ok := r.db.tradeStore.tradesBySwapAcceptID[swapAccept.GetId()]; !ok {
 			r.db.tradeStore.tradesBySwapAcceptID[swapAccept.GetId()] = currentTrade.ID
 		}
 	}
 
+	if swapRequestID != "" {
+		r.db.tradeStore.tradesBySwapRequestID[swapRequestID] = currentTrade.ID
+	}
+
 	r.addTradeByMarket(updatedTrade.MarketQuoteAsset, currentTrade.ID)
+	for _, mt := range updatedTrade.MarketTrades {
+		r.addTradeByMarket(mt.QuoteAsset, currentTrade.ID)
+	}
 	r.addTradeByTrader(hex.EncodeToString(updatedTrade.TraderPubkey), currentTrade.ID)
 
 	r.db.tradeStore.trades[updatedTrade.ID] = *updatedTrade
@@ -138,7 +254,8 @@ func (r TradeRepositoryImpl) getOrCreateTrade(tradeID *uuid.UUID) (*domain.Trade
 func (r TradeRepositoryImpl) getAllTrades() ([]*domain.Trade, error) {
 	allTrades := make([]*domain.Trade, 0)
 	for _, trade := range r.db.tradeStore.trades {
-		allTrades = append(allTrades, &trade)
+		t := trade
+		allTrades = append(allTrades, &t)
 	}
 	return allTrades, nil
 }
@@ -52,6 +52,39 @@ func TestGetAllTradesByMarket(t *testing.T) {
 	}
 
 	assert.Equal(t, 3, len(trades))
+
+	// a multi-asset trade belongs to all the markets it touches
+	completedTrades, err := tradeRepository.GetCompletedTradesByMarket(ctx, "mqa2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(trade *domain.Trade) (*domain.Trade, error) {
+			trade.MarketQuoteAsset = "mqa1"
+			trade.MarketTrades = []domain.MarketTrade{
+				{QuoteAsset: "mqa1"},
+				{QuoteAsset: "mqa2", IsQuoteSent: true},
+			}
+			trade.Status = domain.CompletedStatus
+			return trade, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	trades, err = tradeRepository.GetAllTradesByMarket(ctx, "mqa2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4, len(trades))
+
+	trades, err = tradeRepository.GetCompletedTradesByMarket(ctx, "mqa2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(completedTrades)+1, len(trades))
 }
 
 func TestGetTradeBySwapAcceptID(t *testing.T) {
@@ -66,6 +99,18 @@ func TestGetTradeBySwapAcceptID(t *testing.T) {
 	assert.Equal(t, "424", trade.TxID)
 }
 
+func TestGetTradeBySwapRequestID(t *testing.T) {
+	db := newMockDb()
+	tradeRepository := NewTradeRepositoryImpl(db)
+
+	trade, err := tradeRepository.GetTradeBySwapRequestID(ctx, "12")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "424", trade.TxID)
+}
+
 func TestUpdateTrade(t *testing.T) {
 	db := newMockDb()
 	tradeRepository := NewTradeRepositoryImpl(db)
@@ -94,3 +139,24 @@ func TestUpdateTrade(t *testing.T) {
 
 	assert.Equal(t, float32(100), trade.Price)
 }
+
+func TestUpdateTradeDuplicatedSwapRequest(t *testing.T) {
+	db := newMockDb()
+	tradeRepository := NewTradeRepositoryImpl(db)
+
+	err := tradeRepository.UpdateTrade(
+		ctx,
+		nil,
+		func(t *domain.Trade) (*domain.Trade, error) {
+			t.SwapRequest.ID = "12"
+			return t, nil
+		},
+	)
+	assert.Equal(t, domain.ErrDuplicatedSwapRequest, err)
+
+	trade, err := tradeRepository.GetTradeBySwapRequestID(ctx, "12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "424", trade.TxID)
+}
@@ -77,6 +77,25 @@ func (r UnspentRepositoryImpl) SpendUnspents(
 	return nil
 }
 
+// UnspendUnspents marks the given unspents as unspent and unlocked
+func (r UnspentRepositoryImpl) UnspendUnspents(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+) error {
+	r.db.unspentStore.locker.Lock()
+	defer r.db.unspentStore.locker.Unlock()
+
+	for _, key := range unspentKeys {
+		if unspent, ok := r.db.unspentStore.unspents[key]; ok {
+			unspent.UnSpend()
+			unspent.UnLock()
+			r.db.unspentStore.unspents[key] = unspent
+		}
+	}
+
+	return nil
+}
+
 func (r UnspentRepositoryImpl) ConfirmUnspents(
 	ctx context.Context,
 	unspentKeys []domain.UnspentKey,
@@ -97,6 +116,42 @@ func (r UnspentRepositoryImpl) ConfirmUnspents(
 	return nil
 }
 
+func (r UnspentRepositoryImpl) ConfirmUnspentsInBlock(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+	blockHeight uint64,
+	blockHash string,
+) error {
+	r.db.unspentStore.locker.Lock()
+	defer r.db.unspentStore.locker.Unlock()
+
+	for _, key := range unspentKeys {
+		if unspent, ok := r.db.unspentStore.unspents[key]; ok {
+			unspent.ConfirmInBlock(blockHeight, blockHash)
+			r.db.unspentStore.unspents[key] = unspent
+		}
+	}
+
+	return nil
+}
+
+func (r UnspentRepositoryImpl) UnconfirmUnspents(
+	ctx context.Context,
+	unspentKeys []domain.UnspentKey,
+) error {
+	r.db.unspentStore.locker.Lock()
+	defer r.db.unspentStore.locker.Unlock()
+
+	for _, key := range unspentKeys {
+		if unspent, ok := r.db.unspentStore.unspents[key]; ok {
+			unspent.Unconfirm()
+			r.db.unspentStore.unspents[key] = unspent
+		}
+	}
+
+	return nil
+}
+
 // GetAllUnspents returns all the unspents stored
 func (r UnspentRepositoryImpl) GetAllUnspents(_ context.Context) []domain.Unspent {
 	r.db.unspentStore.locker.RLock()
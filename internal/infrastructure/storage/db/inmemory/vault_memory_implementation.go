@@ -34,6 +34,21 @@ func (r VaultRepositoryImpl) GetOrCreateVault(ctx context.Context,
 	return r.getOrCreateVault(mnemonic, passphrase)
 }
 
+// GetOrCreateSealedVault returns the current Vault.
+// If not yet initialized, it creates a new sealed Vault, whose mnemonic is
+// held by the keystore
+func (r VaultRepositoryImpl) GetOrCreateSealedVault(
+	ctx context.Context,
+) (*domain.Vault, error) {
+	r.db.vaultStore.locker.Lock()
+	defer r.db.vaultStore.locker.Unlock()
+
+	if r.db.vaultStore.vault.IsZero() {
+		r.db.vaultStore.vault = domain.NewSealedVault()
+	}
+	return r.db.vaultStore.vault, nil
+}
+
 // UpdateVault updates data to the Vault passing an update function
 func (r VaultRepositoryImpl) UpdateVault(
 	ctx context.Context,
@@ -0,0 +1,94 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+// WithdrawalAddressRepositoryImpl represents an in memory storage
+type WithdrawalAddressRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewWithdrawalAddressRepositoryImpl returns a new empty
+// WithdrawalAddressRepositoryImpl
+func NewWithdrawalAddressRepositoryImpl(
+	db *DbManager,
+) domain.WithdrawalAddressRepository {
+	return &WithdrawalAddressRepositoryImpl{
+		db: db,
+	}
+}
+
+// AddWithdrawalAddress adds the given address to the whitelist
+func (r WithdrawalAddressRepositoryImpl) AddWithdrawalAddress(
+	_ context.Context,
+	withdrawalAddress domain.WithdrawalAddress,
+) error {
+	r.db.withdrawalAddressStore.locker.Lock()
+	defer r.db.withdrawalAddressStore.locker.Unlock()
+
+	addresses := r.db.withdrawalAddressStore.addresses
+	if _, ok := addresses[withdrawalAddress.Script]; ok {
+		return domain.ErrWithdrawalAddressAlreadyWhitelisted
+	}
+	addresses[withdrawalAddress.Script] = withdrawalAddress
+	return nil
+}
+
+// RemoveWithdrawalAddress removes the address with the given script from the
+// whitelist
+func (r WithdrawalAddressRepositoryImpl) RemoveWithdrawalAddress(
+	_ context.Context,
+	script string,
+) error {
+	r.db.withdrawalAddressStore.locker.Lock()
+	defer r.db.withdrawalAddressStore.locker.Unlock()
+
+	addresses := r.db.withdrawalAddressStore.addresses
+	if _, ok := addresses[script]; !ok {
+		return domain.ErrWithdrawalAddressNotFound
+	}
+	delete(addresses, script)
+	return nil
+}
+
+// GetWithdrawalAddressByScript returns the whitelisted address with the given
+// script, if any
+func (r WithdrawalAddressRepositoryImpl) GetWithdrawalAddressByScript(
+	_ context.Context,
+	script string,
+) (*domain.WithdrawalAddress, error) {
+	r.db.withdrawalAddressStore.locker.RLock()
+	defer r.db.withdrawalAddressStore.locker.RUnlock()
+
+	withdrawalAddress, ok := r.db.withdrawalAddressStore.addresses[script]
+	if !ok {
+		return nil, nil
+	}
+	return &withdrawalAddress, nil
+}
+
+// GetAllWithdrawalAddresses returns all the whitelisted addresses sorted by
+// creation time
+func (r WithdrawalAddressRepositoryImpl) GetAllWithdrawalAddresses(
+	_ context.Context,
+) ([]domain.WithdrawalAddress, error) {
+	r.db.withdrawalAddressStore.locker.RLock()
+	defer r.db.withdrawalAddressStore.locker.RUnlock()
+
+	addresses := make(
+		[]domain.WithdrawalAddress,
+		0,
+		len(r.db.withdrawalAddressStore.addresses),
+	)
+	for _, a := range r.db.withdrawalAddressStore.addresses {
+		addresses = append(addresses, a)
+	}
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return addresses[i].CreatedAt < addresses[j].CreatedAt
+	})
+	return addresses, nil
+}
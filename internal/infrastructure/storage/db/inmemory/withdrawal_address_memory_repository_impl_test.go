@@ -0,0 +1,52 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+func TestAddGetRemoveWithdrawalAddresses(t *testing.T) {
+	withdrawalAddressRepository := NewWithdrawalAddressRepositoryImpl(newMockDb())
+	addresses := []domain.WithdrawalAddress{
+		{Script: "script1", Address: "addr1", CreatedAt: 20, ActiveFrom: 30},
+		{Script: "script2", Address: "addr2", CreatedAt: 10, ActiveFrom: 20},
+	}
+	for _, a := range addresses {
+		if err := withdrawalAddressRepository.AddWithdrawalAddress(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := withdrawalAddressRepository.AddWithdrawalAddress(ctx, addresses[0])
+	assert.Equal(t, domain.ErrWithdrawalAddressAlreadyWhitelisted, err)
+
+	withdrawalAddress, err := withdrawalAddressRepository.
+		GetWithdrawalAddressByScript(ctx, "script1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, addresses[0], *withdrawalAddress)
+
+	allAddresses, err := withdrawalAddressRepository.GetAllWithdrawalAddresses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(allAddresses))
+	assert.Equal(t, "script2", allAddresses[0].Script)
+
+	err = withdrawalAddressRepository.RemoveWithdrawalAddress(ctx, "script1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = withdrawalAddressRepository.RemoveWithdrawalAddress(ctx, "script1")
+	assert.Equal(t, domain.ErrWithdrawalAddressNotFound, err)
+
+	withdrawalAddress, err = withdrawalAddressRepository.
+		GetWithdrawalAddressByScript(ctx, "script1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, withdrawalAddress)
+}
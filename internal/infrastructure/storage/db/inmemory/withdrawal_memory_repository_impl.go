@@ -0,0 +1,84 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+// WithdrawalRepositoryImpl represents an in memory storage
+type WithdrawalRepositoryImpl struct {
+	db *DbManager
+}
+
+// NewWithdrawalRepositoryImpl returns a new empty WithdrawalRepositoryImpl
+func NewWithdrawalRepositoryImpl(db *DbManager) domain.WithdrawalRepository {
+	return &WithdrawalRepositoryImpl{
+		db: db,
+	}
+}
+
+// AddWithdrawals adds the given withdrawals to the storage, skipping those
+// already existing, and returns the number of the added ones
+func (r WithdrawalRepositoryImpl) AddWithdrawals(
+	_ context.Context,
+	withdrawals []domain.Withdrawal,
+) (int, error) {
+	r.db.withdrawalStore.locker.Lock()
+	defer r.db.withdrawalStore.locker.Unlock()
+
+	count := 0
+	for _, w := range withdrawals {
+		if _, ok := r.db.withdrawalStore.withdrawals[w.TxID]; ok {
+			continue
+		}
+		r.db.withdrawalStore.withdrawals[w.TxID] = w
+		count++
+	}
+	return count, nil
+}
+
+// GetAllWithdrawals returns all the withdrawals sorted by timestamp
+func (r WithdrawalRepositoryImpl) GetAllWithdrawals(
+	_ context.Context,
+) ([]domain.Withdrawal, error) {
+	r.db.withdrawalStore.locker.RLock()
+	defer r.db.withdrawalStore.locker.RUnlock()
+
+	withdrawals := make(
+		[]domain.Withdrawal,
+		0,
+		len(r.db.withdrawalStore.withdrawals),
+	)
+	for _, w := range r.db.withdrawalStore.withdrawals {
+		withdrawals = append(withdrawals, w)
+	}
+	sort.SliceStable(withdrawals, func(i, j int) bool {
+		return withdrawals[i].Timestamp < withdrawals[j].Timestamp
+	})
+	return withdrawals, nil
+}
+
+// GetWithdrawalsByTime returns a page of the withdrawals made in the given
+// time range, sorted by timestamp
+func (r WithdrawalRepositoryImpl) GetWithdrawalsByTime(
+	_ context.Context,
+	fromTime, toTime uint64,
+	offset, limit int,
+) ([]domain.Withdrawal, error) {
+	r.db.withdrawalStore.locker.RLock()
+	defer r.db.withdrawalStore.locker.RUnlock()
+
+	withdrawals := make([]domain.Withdrawal, 0)
+	for _, w := range r.db.withdrawalStore.withdrawals {
+		if w.Timestamp >= fromTime && w.Timestamp <= toTime {
+			withdrawals = append(withdrawals, w)
+		}
+	}
+	sort.SliceStable(withdrawals, func(i, j int) bool {
+		return withdrawals[i].Timestamp < withdrawals[j].Timestamp
+	})
+	start, end := pageBounds(len(withdrawals), offset, limit)
+	return withdrawals[start:end], nil
+}
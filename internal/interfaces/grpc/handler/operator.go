@@ -9,6 +9,7 @@ import (
 	"github.com/tdex-network/tdex-daemon/internal/core/application"
 	"github.com/tdex-network/tdex-daemon/internal/core/domain"
 	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/operator"
 	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
 	"google.golang.org/grpc/codes"
@@ -19,25 +20,40 @@ const readOnlyTx = true
 
 type operatorHandler struct {
 	pb.UnimplementedOperatorServer
+	rpcext.UnimplementedOperatorExtensionServer
 	operatorSvc application.OperatorService
 	dbManager   ports.DbManager
+	auditLog    ports.AuditLog
 }
 
 // NewOperatorHandler is a constructor function returning an protobuf OperatorServer.
 func NewOperatorHandler(
 	operatorSvc application.OperatorService,
 	dbManager ports.DbManager,
+	auditLog ports.AuditLog,
 ) pb.OperatorServer {
-	return newOperatorHandler(operatorSvc, dbManager)
+	return newOperatorHandler(operatorSvc, dbManager, auditLog)
+}
+
+// NewOperatorExtensionHandler is a constructor function returning an
+// OperatorExtensionServer.
+func NewOperatorExtensionHandler(
+	operatorSvc application.OperatorService,
+	dbManager ports.DbManager,
+	auditLog ports.AuditLog,
+) rpcext.OperatorExtensionServer {
+	return newOperatorHandler(operatorSvc, dbManager, auditLog)
 }
 
 func newOperatorHandler(
 	operatorSvc application.OperatorService,
 	dbManager ports.DbManager,
+	auditLog ports.AuditLog,
 ) *operatorHandler {
 	return &operatorHandler{
 		operatorSvc: operatorSvc,
 		dbManager:   dbManager,
+		auditLog:    auditLog,
 	}
 }
 
@@ -132,6 +148,69 @@ func (o operatorHandler) ReportMarketFee(
 	return o.reportMarketFee(ctx, req)
 }
 
+func (o operatorHandler) Export(
+	req *rpcext.ExportRequest,
+	stream rpcext.OperatorExtension_ExportServer,
+) error {
+	return o.export(req, stream)
+}
+
+func (o operatorHandler) MarketReport(
+	ctx context.Context,
+	req *rpcext.MarketReportRequest,
+) (*rpcext.MarketReportReply, error) {
+	return o.marketReport(ctx, req)
+}
+
+func (o operatorHandler) AddWithdrawalAddress(
+	ctx context.Context,
+	req *rpcext.AddWithdrawalAddressRequest,
+) (*rpcext.AddWithdrawalAddressReply, error) {
+	return o.addWithdrawalAddress(ctx, req)
+}
+
+func (o operatorHandler) RemoveWithdrawalAddress(
+	ctx context.Context,
+	req *rpcext.RemoveWithdrawalAddressRequest,
+) (*rpcext.RemoveWithdrawalAddressReply, error) {
+	return o.removeWithdrawalAddress(ctx, req)
+}
+
+func (o operatorHandler) ListWithdrawalAddresses(
+	ctx context.Context,
+	req *rpcext.ListWithdrawalAddressesRequest,
+) (*rpcext.ListWithdrawalAddressesReply, error) {
+	return o.listWithdrawalAddresses(ctx, req)
+}
+
+func (o operatorHandler) ListPayments(
+	ctx context.Context,
+	req *rpcext.ListPaymentsRequest,
+) (*rpcext.ListPaymentsReply, error) {
+	return o.listPayments(ctx, req)
+}
+
+func (o operatorHandler) ListAuditEntries(
+	ctx context.Context,
+	req *rpcext.ListAuditEntriesRequest,
+) (*rpcext.ListAuditEntriesReply, error) {
+	return o.listAuditEntries(ctx, req)
+}
+
+func (o operatorHandler) VerifyAuditLog(
+	ctx context.Context,
+	req *rpcext.VerifyAuditLogRequest,
+) (*rpcext.VerifyAuditLogReply, error) {
+	return o.verifyAuditLog(ctx, req)
+}
+
+func (o operatorHandler) UpdateMarketStrategyUnbalanced(
+	ctx context.Context,
+	req *rpcext.UpdateMarketStrategyUnbalancedRequest,
+) (*rpcext.UpdateMarketStrategyUnbalancedReply, error) {
+	return o.updateMarketStrategyUnbalanced(ctx, req)
+}
+
 func (o operatorHandler) depositMarket(
 	reqCtx context.Context,
 	req *pb.DepositMarketRequest,
@@ -457,6 +536,9 @@ func (o operatorHandler) withdrawMarket(
 		},
 	)
 	if err != nil {
+		if isWithdrawalPolicyError(err) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -601,6 +683,389 @@ func (o operatorHandler) reportMarketFee(
 	return res.(*pb.ReportMarketFeeReply), nil
 }
 
+func (o operatorHandler) export(
+	req *rpcext.ExportRequest,
+	stream rpcext.OperatorExtension_ExportServer,
+) error {
+	if err := validateTimeRange(req.FromTimeUnix, req.ToTimeUnix); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	exportReq := application.ExportReq{
+		FromTime: req.FromTimeUnix,
+		ToTime:   req.ToTimeUnix,
+	}
+
+	if _, err := o.dbManager.RunTransaction(
+		stream.Context(),
+		readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			err := o.operatorSvc.Export(
+				ctx,
+				exportReq,
+				func(record application.ExportRecord) error {
+					price := ""
+					if !record.Price.IsZero() {
+						price = record.Price.String()
+					}
+					return stream.Send(&rpcext.ExportReply{
+						Record: &rpcext.ExportRecord{
+							Kind:             record.Kind,
+							ID:               record.ID,
+							TimeUnix:         record.Timestamp,
+							AccountIndex:     record.AccountIndex,
+							BaseAsset:        record.BaseAsset,
+							QuoteAsset:       record.QuoteAsset,
+							AssetIn:          record.AssetIn,
+							AmountIn:         record.AmountIn,
+							AssetOut:         record.AssetOut,
+							AmountOut:        record.AmountOut,
+							Price:            price,
+							FeeAsset:         record.FeeAsset,
+							FeeAmount:        record.FeeAmount,
+							TxID:             record.TxID,
+							RequestTimeUnix:  record.RequestTime,
+							AcceptTimeUnix:   record.AcceptTime,
+							CompleteTimeUnix: record.CompleteTime,
+						},
+					})
+				},
+			)
+			return nil, err
+		},
+	); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func (o operatorHandler) marketReport(
+	ctx context.Context,
+	req *rpcext.MarketReportRequest,
+) (*rpcext.MarketReportReply, error) {
+	if err := validateMarket(&pbtypes.Market{
+		BaseAsset:  req.BaseAsset,
+		QuoteAsset: req.QuoteAsset,
+	}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateTimeRange(req.FromTimeUnix, req.ToTimeUnix); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := o.dbManager.RunTransaction(
+		ctx,
+		readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return o.operatorSvc.GetMarketReport(
+				ctx,
+				application.MarketReportReq{
+					Market: application.Market{
+						BaseAsset:  req.BaseAsset,
+						QuoteAsset: req.QuoteAsset,
+					},
+					FromTime: req.FromTimeUnix,
+					ToTime:   req.ToTimeUnix,
+				},
+			)
+		},
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	report := res.(*application.MarketReport)
+	return &rpcext.MarketReportReply{
+		Report: &rpcext.MarketReport{
+			BaseAsset:          report.BaseAsset,
+			QuoteAsset:         report.QuoteAsset,
+			FromTimeUnix:       report.FromTime,
+			ToTimeUnix:         report.ToTime,
+			StartBalance:       balanceToRPC(report.StartBalance),
+			EndBalance:         balanceToRPC(report.EndBalance),
+			CurrentBalance:     balanceToRPC(report.CurrentBalance),
+			StartPrice:         priceToRPC(report.StartPrice),
+			EndPrice:           priceToRPC(report.EndPrice),
+			CurrentPrice:       priceToRPC(report.CurrentPrice),
+			TradesCount:        report.TradesCount,
+			Volume:             balanceToRPC(report.Volume),
+			CollectedFees:      balanceToRPC(report.CollectedFees),
+			CollectedFeesValue: report.CollectedFeesValue,
+			HoldValue:          report.HoldValue,
+			TradedValue:        report.TradedValue,
+			ImpermanentLoss:    report.ImpermanentLoss,
+			InventoryValue:     report.InventoryValue,
+		},
+	}, nil
+}
+
+func (o operatorHandler) addWithdrawalAddress(
+	reqCtx context.Context,
+	req *rpcext.AddWithdrawalAddressRequest,
+) (*rpcext.AddWithdrawalAddressReply, error) {
+	if len(req.Address) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "address is null")
+	}
+	if len(req.Passphrase) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "passphrase is null")
+	}
+
+	res, err := o.dbManager.RunTransaction(
+		reqCtx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return o.operatorSvc.AddWithdrawalAddress(
+				ctx,
+				application.AddWithdrawalAddressReq{
+					Address:    req.Address,
+					Label:      req.Label,
+					Passphrase: req.Passphrase,
+				},
+			)
+		},
+	)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPassphrase) ||
+			errors.Is(err, domain.ErrInvalidWithdrawalAddress) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, domain.ErrWithdrawalAddressAlreadyWhitelisted) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpcext.AddWithdrawalAddressReply{
+		WithdrawalAddress: withdrawalAddressToRPC(
+			*res.(*application.WithdrawalAddress),
+		),
+	}, nil
+}
+
+func (o operatorHandler) removeWithdrawalAddress(
+	reqCtx context.Context,
+	req *rpcext.RemoveWithdrawalAddressRequest,
+) (*rpcext.RemoveWithdrawalAddressReply, error) {
+	if len(req.Address) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "address is null")
+	}
+
+	if _, err := o.dbManager.RunTransaction(
+		reqCtx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			err := o.operatorSvc.RemoveWithdrawalAddress(ctx, req.Address)
+			return nil, err
+		},
+	); err != nil {
+		if errors.Is(err, domain.ErrInvalidWithdrawalAddress) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, domain.ErrWithdrawalAddressNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpcext.RemoveWithdrawalAddressReply{}, nil
+}
+
+func (o operatorHandler) listWithdrawalAddresses(
+	reqCtx context.Context,
+	req *rpcext.ListWithdrawalAddressesRequest,
+) (*rpcext.ListWithdrawalAddressesReply, error) {
+	res, err := o.dbManager.RunTransaction(
+		reqCtx,
+		readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return o.operatorSvc.ListWithdrawalAddresses(ctx)
+		},
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	addresses := res.([]application.WithdrawalAddress)
+	withdrawalAddresses := make([]*rpcext.WithdrawalAddress, 0, len(addresses))
+	for _, a := range addresses {
+		withdrawalAddresses = append(withdrawalAddresses, withdrawalAddressToRPC(a))
+	}
+
+	return &rpcext.ListWithdrawalAddressesReply{
+		WithdrawalAddresses: withdrawalAddresses,
+	}, nil
+}
+
+func (o operatorHandler) listPayments(
+	reqCtx context.Context,
+	req *rpcext.ListPaymentsRequest,
+) (*rpcext.ListPaymentsReply, error) {
+	res, err := o.dbManager.RunTransaction(
+		reqCtx,
+		readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			return o.operatorSvc.ListPayments(ctx)
+		},
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	payments := res.([]application.Payment)
+	pbPayments := make([]*rpcext.Payment, 0, len(payments))
+	for _, p := range payments {
+		outputs := make([]*rpcext.PaymentOutput, 0, len(p.Outputs))
+		for _, out := range p.Outputs {
+			outputs = append(outputs, &rpcext.PaymentOutput{
+				Asset:   out.Asset,
+				Amount:  uint64(out.Value),
+				Address: out.Address,
+			})
+		}
+		pbPayments = append(pbPayments, &rpcext.Payment{
+			TxID:            p.TxID,
+			AccountIndex:    p.AccountIndex,
+			Outputs:         outputs,
+			MillisatPerByte: p.MillisatPerByte,
+			Broadcasted:     p.Broadcasted,
+			TimeUnix:        p.Timestamp,
+		})
+	}
+
+	return &rpcext.ListPaymentsReply{Payments: pbPayments}, nil
+}
+
+func (o operatorHandler) listAuditEntries(
+	ctx context.Context,
+	req *rpcext.ListAuditEntriesRequest,
+) (*rpcext.ListAuditEntriesReply, error) {
+	if err := validateTimeRange(req.FromTimeUnix, req.ToTimeUnix); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	entries, err := o.auditLog.Entries(req.FromTimeUnix, req.ToTimeUnix)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	pbEntries := make([]*rpcext.AuditEntry, 0, len(entries))
+	for _, e := range entries {
+		pbEntries = append(pbEntries, &rpcext.AuditEntry{
+			Index:    e.Index,
+			TimeUnix: e.Timestamp,
+			Method:   e.Method,
+			Caller:   e.Caller,
+			Params:   e.Params,
+			Result:   e.Result,
+			Error:    e.Error,
+			PrevHash: e.PrevHash,
+			Hash:     e.Hash,
+		})
+	}
+
+	return &rpcext.ListAuditEntriesReply{Entries: pbEntries}, nil
+}
+
+func (o operatorHandler) verifyAuditLog(
+	ctx context.Context,
+	req *rpcext.VerifyAuditLogRequest,
+) (*rpcext.VerifyAuditLogReply, error) {
+	result, err := o.auditLog.Verify()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &rpcext.VerifyAuditLogReply{
+		Valid:             result.Valid,
+		EntriesCount:      result.EntriesCount,
+		LastHash:          result.LastHash,
+		FirstInvalidIndex: result.FirstInvalidIndex,
+		Reason:            result.Reason,
+	}, nil
+}
+
+func (o operatorHandler) updateMarketStrategyUnbalanced(
+	reqCtx context.Context,
+	req *rpcext.UpdateMarketStrategyUnbalancedRequest,
+) (*rpcext.UpdateMarketStrategyUnbalancedReply, error) {
+	market := &pbtypes.Market{
+		BaseAsset:  req.BaseAsset,
+		QuoteAsset: req.QuoteAsset,
+	}
+	if err := validateMarket(market); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.BaseAssetWeight == 0 {
+		return nil, status.Error(
+			codes.InvalidArgument, domain.ErrInvalidBaseAssetWeight.Error(),
+		)
+	}
+
+	ms := application.MarketStrategy{
+		Market: application.Market{
+			BaseAsset:  req.BaseAsset,
+			QuoteAsset: req.QuoteAsset,
+		},
+		Strategy:        domain.StrategyTypeUnbalanced,
+		BaseAssetWeight: req.BaseAssetWeight,
+	}
+
+	res, err := o.dbManager.RunTransaction(
+		reqCtx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			if err := o.operatorSvc.UpdateMarketStrategy(ctx, ms); err != nil {
+				return nil, err
+			}
+
+			return &rpcext.UpdateMarketStrategyUnbalancedReply{}, nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidBaseAssetWeight) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return res.(*rpcext.UpdateMarketStrategyUnbalancedReply), nil
+}
+
+func withdrawalAddressToRPC(
+	address application.WithdrawalAddress,
+) *rpcext.WithdrawalAddress {
+	return &rpcext.WithdrawalAddress{
+		Address:        address.Address,
+		Label:          address.Label,
+		CreatedAtUnix:  address.CreatedAt,
+		ActiveFromUnix: address.ActiveFrom,
+	}
+}
+
+// isWithdrawalPolicyError returns whether the given error is the rejection of
+// a payment by the withdrawal policy, ie. a destination not whitelisted or
+// a daily limit exceeded
+func isWithdrawalPolicyError(err error) bool {
+	return errors.Is(err, application.ErrWithdrawalAddressNotWhitelisted) ||
+		errors.Is(err, application.ErrWithdrawalAddressCoolingDown) ||
+		errors.Is(err, application.ErrWithdrawalDailyLimitExceeded)
+}
+
+func balanceToRPC(balance application.Balance) *rpcext.Balance {
+	return &rpcext.Balance{
+		BaseAmount:  balance.BaseAmount,
+		QuoteAmount: balance.QuoteAmount,
+	}
+}
+
+func priceToRPC(price application.Price) *rpcext.Price {
+	return &rpcext.Price{
+		BasePrice:  price.BasePrice.String(),
+		QuotePrice: price.QuotePrice.String(),
+	}
+}
+
 func validateMarketWithFee(marketWithFee *pbtypes.MarketWithFee) error {
 	if marketWithFee == nil {
 		return errors.New("market with fee is null")
@@ -654,6 +1119,13 @@ func validatePrice(price *pbtypes.Price) error {
 	return nil
 }
 
+func validateTimeRange(from, to uint64) error {
+	if to > 0 && from > to {
+		return errors.New("start time must not be after end time")
+	}
+	return nil
+}
+
 func validateStrategyType(sType pb.StrategyType) error {
 	if domain.StrategyType(sType) < domain.StrategyTypePluggable ||
 		domain.StrategyType(sType) > domain.StrategyTypeUnbalanced {
@@ -5,20 +5,27 @@ import (
 	"errors"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/config"
 	"github.com/tdex-network/tdex-daemon/internal/core/application"
 	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	pkgswap "github.com/tdex-network/tdex-daemon/pkg/swap"
 	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/trade"
 	"github.com/tdex-network/tdex-protobuf/generated/go/types"
 	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const ErrCannotServeRequest = "cannot serve request, please retry"
 
 type traderHandler struct {
 	pb.UnimplementedTradeServer
+	rpcext.UnimplementedTradeExtensionServer
 	traderSvc application.TradeService
 	dbManager ports.DbManager
 }
@@ -30,6 +37,15 @@ func NewTraderHandler(
 	return newTraderHandler(traderSvc, dbManager)
 }
 
+// NewTraderExtensionHandler is a constructor function returning a
+// TradeExtensionServer.
+func NewTraderExtensionHandler(
+	traderSvc application.TradeService,
+	dbManager ports.DbManager,
+) rpcext.TradeExtensionServer {
+	return newTraderHandler(traderSvc, dbManager)
+}
+
 func newTraderHandler(
 	traderSvc application.TradeService,
 	dbManager ports.DbManager,
@@ -75,6 +91,27 @@ func (t traderHandler) TradeComplete(
 	return t.tradeComplete(req, stream)
 }
 
+func (t traderHandler) Quote(
+	ctx context.Context,
+	req *rpcext.QuoteRequest,
+) (*rpcext.QuoteReply, error) {
+	return t.quote(ctx, req)
+}
+
+func (t traderHandler) TradeProposeMultiAsset(
+	ctx context.Context,
+	req *rpcext.TradeProposeMultiAssetRequest,
+) (*rpcext.TradeProposeMultiAssetReply, error) {
+	return t.tradeProposeMultiAsset(ctx, req)
+}
+
+func (t traderHandler) SwapStatus(
+	ctx context.Context,
+	req *rpcext.SwapStatusRequest,
+) (*rpcext.SwapStatusReply, error) {
+	return t.swapStatus(ctx, req)
+}
+
 func (t traderHandler) markets(
 	reqCtx context.Context,
 	req *pb.MarketsRequest,
@@ -110,6 +147,15 @@ func (t traderHandler) markets(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	// let traders know the provider accepts multi-asset swaps
+	if config.GetBool(config.EnableMultiAssetSwapsKey) {
+		if err := grpc.SetHeader(
+			reqCtx, metadata.Pairs(rpcext.MultiAssetSwapsHeader, "true"),
+		); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	return res.(*pb.MarketsReply), nil
 }
 
@@ -243,16 +289,24 @@ func (t traderHandler) tradePropose(
 		BaseAsset:  mkt.GetBaseAsset(),
 		QuoteAsset: mkt.GetQuoteAsset(),
 	}
+	// the trader can reference a quote previously returned by the Quote RPC
+	var quoteID string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if values := md.Get(rpcext.QuoteIDHeader); len(values) > 0 {
+			quoteID = values[0]
+		}
+	}
 
 	res, err := t.dbManager.RunTransaction(
 		stream.Context(),
 		!readOnlyTx,
 		func(ctx context.Context) (interface{}, error) {
-			swapAccept, swapFail, swapExpiryTime, err := t.traderSvc.TradePropose(
+			swapAccept, swapFail, swapExpiryTime, err := t.traderSvc.TradeProposeWithQuote(
 				ctx,
 				market,
 				int(tradeType),
 				swapRequest,
+				quoteID,
 			)
 			if err != nil {
 				return nil, err
@@ -266,7 +320,8 @@ func (t traderHandler) tradePropose(
 		},
 	)
 	if err != nil {
-		log.Debug("trying to process trade proposal: ", err)
+		log.WithContext(stream.Context()).WithError(err).
+			Debug("trying to process trade proposal")
 		return status.Error(codes.Internal, ErrCannotServeRequest)
 	}
 
@@ -300,7 +355,8 @@ func (t traderHandler) tradeComplete(
 		},
 	)
 	if err != nil {
-		log.Debug("trying to complete trade: ", err)
+		log.WithContext(stream.Context()).WithError(err).
+			Debug("trying to complete trade")
 		return status.Error(codes.Internal, ErrCannotServeRequest)
 	}
 
@@ -310,6 +366,157 @@ func (t traderHandler) tradeComplete(
 	return nil
 }
 
+func (t traderHandler) quote(
+	reqCtx context.Context,
+	req *rpcext.QuoteRequest,
+) (*rpcext.QuoteReply, error) {
+	market := &pbtypes.Market{
+		BaseAsset:  req.BaseAsset,
+		QuoteAsset: req.QuoteAsset,
+	}
+	if err := validateMarket(market); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	tradeType := pbtypes.TradeType(req.Type)
+	if err := validateTradeType(tradeType); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateAmount(req.Amount); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := t.dbManager.RunTransaction(
+		reqCtx,
+		readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			quote, err := t.traderSvc.GetMarketQuote(
+				ctx,
+				application.Market{
+					BaseAsset:  market.GetBaseAsset(),
+					QuoteAsset: market.GetQuoteAsset(),
+				},
+				int(tradeType),
+				req.Amount,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			return &rpcext.QuoteReply{
+				QuoteID:        quote.ID,
+				Amount:         quote.Amount,
+				BasePrice:      quote.BasePrice.String(),
+				QuotePrice:     quote.QuotePrice.String(),
+				FeeAsset:       quote.FeeAsset,
+				FeeBasisPoint:  quote.BasisPoint,
+				ExpiryTimeUnix: quote.ExpiryTime,
+			}, nil
+		},
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return res.(*rpcext.QuoteReply), nil
+}
+
+func (t traderHandler) tradeProposeMultiAsset(
+	reqCtx context.Context,
+	req *rpcext.TradeProposeMultiAssetRequest,
+) (*rpcext.TradeProposeMultiAssetReply, error) {
+	if !config.GetBool(config.EnableMultiAssetSwapsKey) {
+		return nil, status.Error(
+			codes.Unimplemented, application.ErrMultiAssetSwapsDisabled.Error(),
+		)
+	}
+
+	swapRequest := &pbswap.SwapRequest{}
+	if err := proto.Unmarshal(req.SwapRequest, swapRequest); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateSwapRequest(swapRequest); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	legsP, err := parseLegs(req.LegsP)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	legsR, err := parseLegs(req.LegsR)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := t.dbManager.RunTransaction(
+		reqCtx,
+		!readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			swapAccept, swapFail, swapExpiryTime, err :=
+				t.traderSvc.TradeProposeMultiAsset(ctx, swapRequest, legsP, legsR)
+			if err != nil {
+				return nil, err
+			}
+
+			reply := &rpcext.TradeProposeMultiAssetReply{
+				ExpiryTimeUnix: swapExpiryTime,
+			}
+			if swapAccept != nil {
+				if reply.SwapAccept, err = proto.Marshal(swapAccept); err != nil {
+					return nil, err
+				}
+			}
+			if swapFail != nil {
+				if reply.SwapFail, err = proto.Marshal(swapFail); err != nil {
+					return nil, err
+				}
+			}
+			return reply, nil
+		},
+	)
+	if err != nil {
+		log.WithContext(reqCtx).WithError(err).
+			Debug("trying to process multi-asset trade proposal")
+		return nil, status.Error(codes.Internal, ErrCannotServeRequest)
+	}
+
+	return res.(*rpcext.TradeProposeMultiAssetReply), nil
+}
+
+func (t traderHandler) swapStatus(
+	reqCtx context.Context,
+	req *rpcext.SwapStatusRequest,
+) (*rpcext.SwapStatusReply, error) {
+	if len(req.SwapID) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "swap id is null")
+	}
+
+	res, err := t.dbManager.RunTransaction(
+		reqCtx,
+		readOnlyTx,
+		func(ctx context.Context) (interface{}, error) {
+			swapStatus, err := t.traderSvc.GetSwapStatus(ctx, req.SwapID)
+			if err != nil {
+				return nil, err
+			}
+
+			return &rpcext.SwapStatusReply{
+				Status:         swapStatus.Status,
+				TxID:           swapStatus.TxID,
+				BlockTimeUnix:  swapStatus.BlockTime,
+				ExpiryTimeUnix: swapStatus.ExpiryTime,
+				FailureMessage: swapStatus.FailureMessage,
+			}, nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, application.ErrSwapNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return res.(*rpcext.SwapStatusReply), nil
+}
+
 func validateTradeType(tType pbtypes.TradeType) error {
 	if int(tType) < application.TradeBuy || int(tType) > application.TradeSell {
 		return errors.New("trade type is unknown")
@@ -339,3 +546,17 @@ func validateSwapRequest(swapRequest *pbswap.SwapRequest) error {
 	}
 	return nil
 }
+
+func parseLegs(legs []*rpcext.Leg) ([]pkgswap.Leg, error) {
+	if len(legs) <= 0 {
+		return nil, pkgswap.ErrNullLegs
+	}
+	parsed := make([]pkgswap.Leg, 0, len(legs))
+	for _, leg := range legs {
+		if leg == nil || len(leg.Asset) != 64 || leg.Amount <= 0 {
+			return nil, pkgswap.ErrInvalidLeg
+		}
+		parsed = append(parsed, pkgswap.Leg{Asset: leg.Asset, Amount: leg.Amount})
+	}
+	return parsed, nil
+}
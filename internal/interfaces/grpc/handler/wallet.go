@@ -99,10 +99,9 @@ func (w walletHandler) initWallet(
 	req *pb.InitWalletRequest,
 	stream pb.Wallet_InitWalletServer,
 ) error {
+	// the mnemonic is missing in remote signer mode, since it's held by the
+	// signer, therefore it's validated by the wallet service
 	mnemonic := req.GetSeedMnemonic()
-	if err := validateMnemonic(mnemonic); err != nil {
-		return status.Error(codes.InvalidArgument, err.Error())
-	}
 	password := req.GetWalletPassword()
 	if err := validatePassword(password); err != nil {
 		return status.Error(codes.InvalidArgument, err.Error())
@@ -294,19 +293,15 @@ func (w walletHandler) sendToMany(
 		},
 	)
 	if err != nil {
+		if isWithdrawalPolicyError(err) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return res.(*pb.SendToManyReply), nil
 }
 
-func validateMnemonic(mnemonic []string) error {
-	if len(mnemonic) <= 0 {
-		return errors.New("mnemonic is null")
-	}
-	return nil
-}
-
 func validatePassword(password []byte) error {
 	if len(password) <= 0 {
 		return errors.New("password is null")
@@ -0,0 +1,168 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	macaroonMetadataKey = "macaroon"
+	redactedValue       = "[REDACTED]"
+	// healthServicePrefix is the prefix of the methods of the gRPC health
+	// service, not recorded since periodically called by probes
+	healthServicePrefix = "/grpc.health.v1.Health/"
+)
+
+// sensitiveParams are the substrings that identify the request fields whose
+// values must never end up in the audit log
+var sensitiveParams = []string{
+	"password", "passphrase", "mnemonic", "seed", "secret", "private",
+}
+
+// Auditor records every call made on the operator interface into the audit
+// log, once completed
+type Auditor struct {
+	auditLog ports.AuditLog
+}
+
+// NewAuditor returns a new Auditor appending entries to the given log
+func NewAuditor(auditLog ports.AuditLog) *Auditor {
+	return &Auditor{auditLog}
+}
+
+func (a *Auditor) unaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	res, err := handler(ctx, req)
+	if !strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+		a.record(ctx, info.FullMethod, req, err)
+	}
+	return res, err
+}
+
+func (a *Auditor) streamInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+		return handler(srv, stream)
+	}
+
+	wrapped := &auditedServerStream{ServerStream: stream}
+	err := handler(srv, wrapped)
+	a.record(stream.Context(), info.FullMethod, wrapped.req, err)
+	return err
+}
+
+func (a *Auditor) record(
+	ctx context.Context,
+	method string,
+	req interface{},
+	err error,
+) {
+	st := status.Convert(err)
+	entry := ports.AuditEntry{
+		Method: method,
+		Caller: callerIdentity(ctx),
+		Params: sanitizeParams(req),
+		Result: st.Code().String(),
+	}
+	if err != nil {
+		entry.Error = st.Message()
+	}
+
+	if _, err := a.auditLog.Append(entry); err != nil {
+		log.WithError(err).Errorf("unable to record call to %s in audit log", method)
+	}
+}
+
+// auditedServerStream keeps a reference to the first message received, ie.
+// the request of a server-side streaming call
+type auditedServerStream struct {
+	grpc.ServerStream
+	req interface{}
+}
+
+func (s *auditedServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+// callerIdentity identifies the caller by the fingerprint of its macaroon,
+// if any, or by its address otherwise
+func callerIdentity(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if macaroons := md.Get(macaroonMetadataKey); len(macaroons) > 0 {
+			hash := sha256.Sum256([]byte(macaroons[0]))
+			return "macaroon:" + hex.EncodeToString(hash[:8])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return "peer:" + p.Addr.String()
+	}
+	return "unknown"
+}
+
+// sanitizeParams returns the JSON encoding of the given request with the
+// values of sensitive fields redacted
+func sanitizeParams(req interface{}) string {
+	if req == nil {
+		return ""
+	}
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	var params interface{}
+	if err := json.Unmarshal(buf, &params); err != nil {
+		return ""
+	}
+	buf, _ = json.Marshal(redact(params))
+	return string(buf)
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if isSensitiveParam(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redact(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val)
+		}
+	}
+	return value
+}
+
+func isSensitiveParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveParams {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/ports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestSanitizeParams(t *testing.T) {
+	req := map[string]interface{}{
+		"wallet_password": []byte("password"),
+		"seed_mnemonic":   []string{"leave", "dice"},
+		"outputs": []map[string]interface{}{
+			{"address": "el1qq", "passphrase": "secret"},
+		},
+		"market": map[string]string{"base_asset": "5ac9f6"},
+	}
+
+	params := sanitizeParams(req)
+	assert.NotContains(t, params, "leave")
+	assert.NotContains(t, params, "secret")
+	assert.Contains(t, params, `"wallet_password":"[REDACTED]"`)
+	assert.Contains(t, params, `"passphrase":"[REDACTED]"`)
+	assert.Contains(t, params, `"base_asset":"5ac9f6"`)
+	assert.Contains(t, params, `"address":"el1qq"`)
+	assert.Equal(t, "", sanitizeParams(nil))
+}
+
+func TestCallerIdentity(t *testing.T) {
+	ctx := peerContext(t, "10.0.0.1:9945")
+	assert.Equal(t, "peer:10.0.0.1:9945", callerIdentity(ctx))
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("macaroon", "0201"))
+	caller := callerIdentity(ctx)
+	assert.Contains(t, caller, "macaroon:")
+	assert.NotContains(t, caller, "0201")
+
+	assert.Equal(t, "unknown", callerIdentity(context.Background()))
+}
+
+func TestAuditorUnaryInterceptor(t *testing.T) {
+	auditLog := &mockedAuditLog{}
+	auditor := NewAuditor(auditLog)
+	info := &grpc.UnaryServerInfo{FullMethod: "/Operator/CloseMarket"}
+
+	_, err := auditor.unaryInterceptor(
+		peerContext(t, "10.0.0.1:9945"),
+		map[string]string{"base_asset": "5ac9f6"},
+		info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.InvalidArgument, "market not found")
+		},
+	)
+	assert.Error(t, err)
+
+	_, err = auditor.unaryInterceptor(
+		context.Background(),
+		nil,
+		&grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.Len(t, auditLog.entries, 1)
+	entry := auditLog.entries[0]
+	assert.Equal(t, "/Operator/CloseMarket", entry.Method)
+	assert.Equal(t, "peer:10.0.0.1:9945", entry.Caller)
+	assert.Equal(t, `{"base_asset":"5ac9f6"}`, entry.Params)
+	assert.Equal(t, codes.InvalidArgument.String(), entry.Result)
+	assert.Equal(t, "market not found", entry.Error)
+}
+
+type mockedAuditLog struct {
+	ports.AuditLog
+	entries []ports.AuditEntry
+}
+
+func (m *mockedAuditLog) Append(
+	entry ports.AuditEntry,
+) (*ports.AuditEntry, error) {
+	m.entries = append(m.entries, entry)
+	return &entry, nil
+}
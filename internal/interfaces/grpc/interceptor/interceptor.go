@@ -6,20 +6,67 @@ import (
 	"google.golang.org/grpc"
 )
 
-// UnaryInterceptor returns the unary interceptor
-func UnaryInterceptor(dbManager *dbbadger.DbManager) grpc.ServerOption {
+// UnaryInterceptor returns the unary interceptor of the operator interface,
+// that additionally records every call with the given auditor
+func UnaryInterceptor(
+	dbManager *dbbadger.DbManager,
+	auditor *Auditor,
+) grpc.ServerOption {
 	return grpc.UnaryInterceptor(
 		middleware.ChainUnaryServer(
+			unaryRequestID,
 			unaryLogger,
+			auditor.unaryInterceptor,
 		),
 	)
 }
 
-// StreamInterceptor returns the stream interceptor with a logrus log
-func StreamInterceptor(dbManager *dbbadger.DbManager) grpc.ServerOption {
+// StreamInterceptor returns the stream interceptor of the operator interface
+// with a logrus log, that additionally records every call with the given
+// auditor
+func StreamInterceptor(
+	dbManager *dbbadger.DbManager,
+	auditor *Auditor,
+) grpc.ServerOption {
 	return grpc.StreamInterceptor(
 		middleware.ChainStreamServer(
+			streamRequestID,
 			streamLogger,
+			auditor.streamInterceptor,
+		),
+	)
+}
+
+// TraderUnaryInterceptor returns the unary interceptor of the trader
+// interface, that additionally enforces the limits of the given rate limiter
+// and records the trade metrics
+func TraderUnaryInterceptor(
+	dbManager *dbbadger.DbManager,
+	limiter *RateLimiter,
+) grpc.ServerOption {
+	return grpc.UnaryInterceptor(
+		middleware.ChainUnaryServer(
+			unaryRequestID,
+			unaryLogger,
+			limiter.unaryInterceptor,
+			unaryMetrics,
+		),
+	)
+}
+
+// TraderStreamInterceptor returns the stream interceptor of the trader
+// interface, that additionally enforces the limits of the given rate limiter
+// and records the trade metrics
+func TraderStreamInterceptor(
+	dbManager *dbbadger.DbManager,
+	limiter *RateLimiter,
+) grpc.ServerOption {
+	return grpc.StreamInterceptor(
+		middleware.ChainStreamServer(
+			streamRequestID,
+			streamLogger,
+			limiter.streamInterceptor,
+			streamMetrics,
 		),
 	)
 }
@@ -2,9 +2,12 @@ package interceptor
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 )
 
 func unaryLogger(
@@ -13,8 +16,10 @@ func unaryLogger(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
-	log.Debug(info.FullMethod)
-	return handler(ctx, req)
+	start := time.Now()
+	res, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return res, err
 }
 
 func streamLogger(
@@ -23,6 +28,26 @@ func streamLogger(
 	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
-	log.Debug(info.FullMethod)
-	return handler(srv, stream)
+	start := time.Now()
+	err := handler(srv, stream)
+	logCall(stream.Context(), info.FullMethod, start, err)
+	return err
+}
+
+// logCall logs the outcome of a call, along with its method and duration.
+// Calls to the health service are skipped since periodically made by probes
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return
+	}
+
+	entry := log.WithContext(ctx).WithFields(log.Fields{
+		"method":   method,
+		"code":     status.Code(err).String(),
+		"duration": time.Since(start).String(),
+	})
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	entry.Debug("served request")
 }
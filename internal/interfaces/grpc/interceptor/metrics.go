@@ -0,0 +1,101 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/internal/interfaces/metrics"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	tradeResultAccepted = "accepted"
+	tradeResultFailed   = "failed"
+	tradeResultError    = "error"
+)
+
+// unaryMetrics records the latency of multi-asset trade proposals and the
+// SwapFail messages returned
+func unaryMetrics(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if info.FullMethod != tradeProposeMultiAssetMethod {
+		return handler(ctx, req)
+	}
+
+	start := time.Now()
+	res, err := handler(ctx, req)
+
+	result := tradeResultError
+	if reply, ok := res.(*rpcext.TradeProposeMultiAssetReply); ok && err == nil {
+		result = tradeResultAccepted
+		if len(reply.SwapFail) > 0 {
+			result = tradeResultFailed
+			swapFail := &pbswap.SwapFail{}
+			if proto.Unmarshal(reply.SwapFail, swapFail) == nil {
+				metrics.ObserveSwapFail(info.FullMethod, swapFail.GetFailureCode())
+			}
+		}
+	}
+	metrics.ObserveTradePropose(info.FullMethod, result, time.Since(start))
+	return res, err
+}
+
+// streamMetrics records the latency of trade proposals and the SwapFail
+// messages returned by TradePropose and TradeComplete
+func streamMetrics(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if info.FullMethod != tradeProposeMethod &&
+		info.FullMethod != tradeCompleteMethod {
+		return handler(srv, stream)
+	}
+
+	start := time.Now()
+	wrapped := &metricsServerStream{ServerStream: stream, method: info.FullMethod}
+	err := handler(srv, wrapped)
+
+	if info.FullMethod == tradeProposeMethod {
+		result := wrapped.result
+		if err != nil || result == "" {
+			result = tradeResultError
+		}
+		metrics.ObserveTradePropose(info.FullMethod, result, time.Since(start))
+	}
+	return err
+}
+
+// metricsServerStream inspects the replies sent to count the SwapFail
+// messages
+type metricsServerStream struct {
+	grpc.ServerStream
+	method string
+	result string
+}
+
+func (s *metricsServerStream) SendMsg(m interface{}) error {
+	var swapFail *pbswap.SwapFail
+	switch reply := m.(type) {
+	case *pbtrade.TradeProposeReply:
+		swapFail = reply.GetSwapFail()
+	case *pbtrade.TradeCompleteReply:
+		swapFail = reply.GetSwapFail()
+	}
+
+	s.result = tradeResultAccepted
+	if swapFail != nil {
+		s.result = tradeResultFailed
+		metrics.ObserveSwapFail(s.method, swapFail.GetFailureCode())
+	}
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,582 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	tradeProposeMethod           = "/Trade/TradePropose"
+	tradeCompleteMethod          = "/Trade/TradeComplete"
+	tradeProposeMultiAssetMethod = "/TradeExtension/TradeProposeMultiAsset"
+
+	// RejectReasonBanned labels the calls rejected because coming from a
+	// banned address
+	RejectReasonBanned = "banned"
+	// RejectReasonIPRateLimit labels the trade proposals rejected because
+	// exceeding the rate limit of the client address
+	RejectReasonIPRateLimit = "ip_rate_limit"
+	// RejectReasonMarketRateLimit labels the trade proposals rejected because
+	// exceeding the rate limit of the market
+	RejectReasonMarketRateLimit = "market_rate_limit"
+	// RejectReasonPendingTrades labels the trade proposals rejected because the
+	// market has reached the max number of pending trades
+	RejectReasonPendingTrades = "pending_trades"
+)
+
+var (
+	// ErrBannedAddress is returned when the client address is in the ban list
+	ErrBannedAddress = status.Error(
+		codes.PermissionDenied, "address is banned",
+	)
+	// ErrIPRateLimitExceeded is returned when a client proposes trades too often
+	ErrIPRateLimitExceeded = status.Error(
+		codes.ResourceExhausted, "too many trade proposals: try later",
+	)
+	// ErrMarketRateLimitExceeded is returned when a market receives trade
+	// proposals too often
+	ErrMarketRateLimitExceeded = status.Error(
+		codes.ResourceExhausted, "market is receiving too many trade proposals: try later",
+	)
+	// ErrTooManyPendingTrades is returned when a market has reached the max
+	// number of trades accepted and not yet completed or expired
+	ErrTooManyPendingTrades = status.Error(
+		codes.ResourceExhausted, "market has too many pending trades: try later",
+	)
+)
+
+// RateLimiterOpts defines the limits enforced by a RateLimiter. Rates are
+// expressed in trade proposals per minute, that is also the max burst allowed.
+// Zero values disable the related limit.
+type RateLimiterOpts struct {
+	TradeProposalsPerIP       int
+	TradeProposalsPerMarket   int
+	MaxPendingTradesPerMarket int
+	// BanList is a list of IP addresses or CIDR blocks
+	BanList []string
+	// BaseAsset is the asset shared by all markets. The legs of multi-asset
+	// trade proposals in base asset don't count for any market.
+	BaseAsset string
+}
+
+// RateLimiter protects the trader interface from clients that freeze the
+// liquidity of the markets by proposing trades and never completing them.
+// It bans the configured addresses, limits the rate of trade proposals per
+// client address and per market, and caps the number of pending trades of
+// every market.
+type RateLimiter struct {
+	opts         RateLimiterOpts
+	bannedIPs    map[string]bool
+	bannedNets   []*net.IPNet
+	ipBuckets    *bucketSet
+	marketBucket *bucketSet
+	// pendingTrades maps every market to the expiry time of its accepted
+	// trades, indexed by swap accept id. A trade proposal being handled holds
+	// a slot in its markets under a reservation id until it's either accepted
+	// or rejected, so that concurrent proposals can't exceed the limit.
+	pendingTrades   map[string]map[string]uint64
+	lastReservation uint64
+	rejectedCalls   map[string]uint64
+	lock            *sync.Mutex
+}
+
+// NewRateLimiter returns a new RateLimiter enforcing the given limits
+func NewRateLimiter(opts RateLimiterOpts) (*RateLimiter, error) {
+	bannedIPs := map[string]bool{}
+	bannedNets := make([]*net.IPNet, 0)
+	for _, entry := range opts.BanList {
+		entry = strings.TrimSpace(entry)
+		if len(entry) <= 0 {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, err
+			}
+			bannedNets = append(bannedNets, ipNet)
+			continue
+		}
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: entry}
+		}
+		bannedIPs[ip.String()] = true
+	}
+
+	return &RateLimiter{
+		opts:          opts,
+		bannedIPs:     bannedIPs,
+		bannedNets:    bannedNets,
+		ipBuckets:     newBucketSet(opts.TradeProposalsPerIP),
+		marketBucket:  newBucketSet(opts.TradeProposalsPerMarket),
+		pendingTrades: map[string]map[string]uint64{},
+		rejectedCalls: map[string]uint64{},
+		lock:          &sync.Mutex{},
+	}, nil
+}
+
+// LoadPendingTrades tracks the trades accepted and not yet expired found in the
+// repository, so that a restart doesn't reset the pending trades of the
+// markets while their unspents are still locked
+func (r *RateLimiter) LoadPendingTrades(
+	ctx context.Context,
+	tradeRepository domain.TradeRepository,
+) error {
+	trades, err := tradeRepository.GetAcceptedTrades(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, trade := range trades {
+		if trade.IsExpired() {
+			continue
+		}
+		markets := []string{trade.MarketQuoteAsset}
+		if len(trade.MarketTrades) > 0 {
+			markets = make([]string, 0, len(trade.MarketTrades))
+			for _, mt := range trade.MarketTrades {
+				markets = append(markets, mt.QuoteAsset)
+			}
+		}
+		r.confirmPendingTrade(
+			"", markets, trade.SwapAccept.ID, trade.SwapExpiryTime(),
+		)
+	}
+	return nil
+}
+
+// RejectedCalls returns the number of calls rejected so far, indexed by the
+// reason of the rejection
+func (r *RateLimiter) RejectedCalls() map[string]uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	rejectedCalls := make(map[string]uint64, len(r.rejectedCalls))
+	for reason, count := range r.rejectedCalls {
+		rejectedCalls[reason] = count
+	}
+	return rejectedCalls
+}
+
+func (r *RateLimiter) unaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ip := clientIP(ctx)
+	if err := r.checkBanned(ip); err != nil {
+		return nil, err
+	}
+	if info.FullMethod != tradeProposeMultiAssetMethod {
+		return handler(ctx, req)
+	}
+
+	if err := r.checkIPRateLimit(ip); err != nil {
+		return nil, err
+	}
+	// a multi-asset trade is settled by the markets of all its legs, therefore
+	// it's subject to the limits of each of them
+	markets := r.multiAssetTradeMarkets(req)
+	reservationID, err := r.checkMarkets(markets)
+	if err != nil {
+		return nil, err
+	}
+	defer r.releasePendingTrade(reservationID)
+
+	reply, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if swapAccept, expiryTime := multiAssetSwapAccept(reply); swapAccept != nil {
+		r.confirmPendingTrade(
+			reservationID, markets, swapAccept.GetId(), expiryTime,
+		)
+	}
+	return reply, nil
+}
+
+func (r *RateLimiter) streamInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ip := clientIP(stream.Context())
+	if err := r.checkBanned(ip); err != nil {
+		return err
+	}
+
+	switch info.FullMethod {
+	case tradeProposeMethod:
+		if err := r.checkIPRateLimit(ip); err != nil {
+			return err
+		}
+		proposeStream := &tradeProposeStream{ServerStream: stream, limiter: r}
+		defer proposeStream.release()
+		return handler(srv, proposeStream)
+	case tradeCompleteMethod:
+		return handler(srv, &tradeCompleteStream{stream, r, nil})
+	default:
+		return handler(srv, stream)
+	}
+}
+
+func (r *RateLimiter) checkBanned(ip net.IP) error {
+	if ip == nil {
+		return nil
+	}
+	banned := r.bannedIPs[ip.String()]
+	for _, ipNet := range r.bannedNets {
+		if banned {
+			break
+		}
+		banned = ipNet.Contains(ip)
+	}
+	if banned {
+		r.reject(RejectReasonBanned, ip.String())
+		return ErrBannedAddress
+	}
+	return nil
+}
+
+func (r *RateLimiter) checkIPRateLimit(ip net.IP) error {
+	if ip == nil {
+		return nil
+	}
+	if !r.ipBuckets.take(ip.String()) {
+		r.reject(RejectReasonIPRateLimit, ip.String())
+		return ErrIPRateLimitExceeded
+	}
+	return nil
+}
+
+// checkMarkets checks the limits of the given markets and, if none is
+// exceeded, reserves a pending trade slot in each of them. The returned
+// reservation id must be either confirmed or released once the trade
+// proposal is handled.
+func (r *RateLimiter) checkMarkets(markets []string) (string, error) {
+	reservationID, market, ok := r.reservePendingTrade(markets)
+	if !ok {
+		r.reject(RejectReasonPendingTrades, market)
+		return "", ErrTooManyPendingTrades
+	}
+	for _, market := range markets {
+		if !r.marketBucket.take(market) {
+			r.releasePendingTrade(reservationID)
+			r.reject(RejectReasonMarketRateLimit, market)
+			return "", ErrMarketRateLimitExceeded
+		}
+	}
+	return reservationID, nil
+}
+
+// multiAssetTradeMarkets returns the markets of the legs of a multi-asset
+// trade proposal, ie. the assets other than the base one
+func (r *RateLimiter) multiAssetTradeMarkets(req interface{}) []string {
+	request, ok := req.(*rpcext.TradeProposeMultiAssetRequest)
+	if !ok {
+		return nil
+	}
+
+	markets := make([]string, 0, len(request.LegsP)+len(request.LegsR))
+	found := map[string]bool{}
+	for _, leg := range append(request.LegsP, request.LegsR...) {
+		if leg == nil || leg.Asset == r.opts.BaseAsset || found[leg.Asset] {
+			continue
+		}
+		found[leg.Asset] = true
+		markets = append(markets, leg.Asset)
+	}
+	return markets
+}
+
+func (r *RateLimiter) countPendingTrades(market string) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.countPendingTradesLocked(market)
+}
+
+func (r *RateLimiter) countPendingTradesLocked(market string) int {
+	now := uint64(time.Now().Unix())
+	for swapAcceptID, expiryTime := range r.pendingTrades[market] {
+		if now >= expiryTime {
+			delete(r.pendingTrades[market], swapAcceptID)
+		}
+	}
+	return len(r.pendingTrades[market])
+}
+
+// reservePendingTrade reserves a pending trade slot in every given market,
+// unless any of them has reached the max number of pending trades, in which
+// case it's returned. The slots are reserved with no expiry, until the
+// reservation is either confirmed or released.
+func (r *RateLimiter) reservePendingTrade(
+	markets []string,
+) (reservationID, fullMarket string, ok bool) {
+	if r.opts.MaxPendingTradesPerMarket <= 0 {
+		return "", "", true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, market := range markets {
+		if r.countPendingTradesLocked(market) >= r.opts.MaxPendingTradesPerMarket {
+			return "", market, false
+		}
+	}
+
+	r.lastReservation++
+	reservationID = fmt.Sprintf("reservation-%d", r.lastReservation)
+	for _, market := range markets {
+		r.addPendingTradeLocked(market, reservationID, math.MaxUint64)
+	}
+	return reservationID, "", true
+}
+
+// confirmPendingTrade replaces the slots held by the given reservation, if
+// any, with the accepted trade
+func (r *RateLimiter) confirmPendingTrade(
+	reservationID string,
+	markets []string,
+	swapAcceptID string,
+	expiryTime uint64,
+) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, market := range markets {
+		if reservationID != "" {
+			delete(r.pendingTrades[market], reservationID)
+		}
+		r.addPendingTradeLocked(market, swapAcceptID, expiryTime)
+	}
+}
+
+// releasePendingTrade frees the slots held by a reservation not confirmed
+func (r *RateLimiter) releasePendingTrade(reservationID string) {
+	if reservationID == "" {
+		return
+	}
+	r.removePendingTrade(reservationID)
+}
+
+func (r *RateLimiter) addPendingTradeLocked(
+	market, swapAcceptID string,
+	expiryTime uint64,
+) {
+	if _, ok := r.pendingTrades[market]; !ok {
+		r.pendingTrades[market] = map[string]uint64{}
+	}
+	r.pendingTrades[market][swapAcceptID] = expiryTime
+}
+
+func (r *RateLimiter) removePendingTrade(swapAcceptID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, trades := range r.pendingTrades {
+		delete(trades, swapAcceptID)
+	}
+}
+
+func (r *RateLimiter) reject(reason, key string) {
+	r.lock.Lock()
+	r.rejectedCalls[reason]++
+	r.lock.Unlock()
+
+	log.WithField("reason", reason).Debugf("rejected call from %s", key)
+}
+
+// tradeProposeStream checks the limits of the market before the trade
+// proposal is handled, and keeps track of it if accepted. The slot reserved
+// for the trade proposal must be released once the handler returns, in case
+// it has been rejected.
+type tradeProposeStream struct {
+	grpc.ServerStream
+	limiter       *RateLimiter
+	market        string
+	reservationID string
+}
+
+func (s *tradeProposeStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if req, ok := m.(*pbtrade.TradeProposeRequest); ok {
+		s.market = req.GetMarket().GetQuoteAsset()
+		reservationID, err := s.limiter.checkMarkets([]string{s.market})
+		if err != nil {
+			return err
+		}
+		s.reservationID = reservationID
+	}
+	return nil
+}
+
+func (s *tradeProposeStream) SendMsg(m interface{}) error {
+	// the trade is accepted, and its unspents locked, even if the reply can't
+	// be sent, therefore it's tracked anyway
+	if reply, ok := m.(*pbtrade.TradeProposeReply); ok &&
+		reply.GetSwapAccept() != nil {
+		s.limiter.confirmPendingTrade(
+			s.reservationID,
+			[]string{s.market},
+			reply.GetSwapAccept().GetId(),
+			reply.GetExpiryTimeUnix(),
+		)
+		s.reservationID = ""
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *tradeProposeStream) release() {
+	s.limiter.releasePendingTrade(s.reservationID)
+}
+
+// tradeCompleteStream stops tracking a pending trade once the trader either
+// completes or aborts it
+type tradeCompleteStream struct {
+	grpc.ServerStream
+	limiter *RateLimiter
+	req     *pbtrade.TradeCompleteRequest
+}
+
+func (s *tradeCompleteStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if req, ok := m.(*pbtrade.TradeCompleteRequest); ok {
+		s.req = req
+	}
+	return nil
+}
+
+func (s *tradeCompleteStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	reply, ok := m.(*pbtrade.TradeCompleteReply)
+	if !ok || s.req == nil {
+		return nil
+	}
+	if s.req.GetSwapFail() != nil {
+		s.limiter.removePendingTrade(s.req.GetSwapFail().GetMessageId())
+		return nil
+	}
+	if len(reply.GetTxid()) > 0 {
+		s.limiter.removePendingTrade(s.req.GetSwapComplete().GetAcceptId())
+	}
+	return nil
+}
+
+// multiAssetSwapAccept returns the SwapAccept message and the expiry time of
+// an accepted multi-asset trade proposal, if any
+func multiAssetSwapAccept(reply interface{}) (*pbswap.SwapAccept, uint64) {
+	r, ok := reply.(*rpcext.TradeProposeMultiAssetReply)
+	if !ok || len(r.SwapAccept) <= 0 {
+		return nil, 0
+	}
+	swapAccept := &pbswap.SwapAccept{}
+	if err := proto.Unmarshal(r.SwapAccept, swapAccept); err != nil {
+		return nil, 0
+	}
+	return swapAccept, r.ExpiryTimeUnix
+}
+
+func clientIP(ctx context.Context) net.IP {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return net.ParseIP(host)
+}
+
+// bucketSet is a set of token buckets, one per key, that are refilled at a
+// rate of limit tokens per minute up to limit tokens
+type bucketSet struct {
+	limit     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	lock      *sync.Mutex
+}
+
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newBucketSet(limit int) *bucketSet {
+	return &bucketSet{
+		limit:     float64(limit),
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+		lock:      &sync.Mutex{},
+	}
+}
+
+// take consumes a token from the bucket of the given key and returns whether
+// it was available. It always returns true if the limit is disabled.
+func (s *bucketSet) take(key string) bool {
+	if s.limit <= 0 {
+		return true
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	// Buckets refilled up to the limit are equivalent to missing ones, so they
+	// are periodically dropped to not grow the set indefinitely.
+	if now.Sub(s.lastSweep) >= time.Minute {
+		for k, b := range s.buckets {
+			if s.refill(b, now) >= s.limit {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: s.limit, lastRefill: now}
+		s.buckets[key] = b
+	}
+	if s.refill(b, now) < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (s *bucketSet) refill(b *bucket, now time.Time) float64 {
+	b.tokens += now.Sub(b.lastRefill).Minutes() * s.limit
+	if b.tokens > s.limit {
+		b.tokens = s.limit
+	}
+	b.lastRefill = now
+	return b.tokens
+}
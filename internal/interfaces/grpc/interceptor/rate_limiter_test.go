@@ -0,0 +1,343 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
+	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBucketSet(t *testing.T) {
+	buckets := newBucketSet(2)
+	assert.Equal(t, true, buckets.take("a"))
+	assert.Equal(t, true, buckets.take("a"))
+	assert.Equal(t, false, buckets.take("a"))
+	assert.Equal(t, true, buckets.take("b"))
+
+	// a minute later the bucket is refilled
+	buckets.buckets["a"].lastRefill = time.Now().Add(-time.Minute)
+	assert.Equal(t, true, buckets.take("a"))
+
+	unlimited := newBucketSet(0)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, true, unlimited.take("a"))
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOpts{
+		TradeProposalsPerIP:       1,
+		TradeProposalsPerMarket:   10,
+		MaxPendingTradesPerMarket: 1,
+		BanList:                   []string{"10.0.0.1", " 192.168.0.0/16", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ban list", func(t *testing.T) {
+		for _, addr := range []string{"10.0.0.1:9945", "192.168.1.1:9945"} {
+			ip := clientIP(peerContext(t, addr))
+			assert.Equal(t, ErrBannedAddress, limiter.checkBanned(ip))
+		}
+		ip := clientIP(peerContext(t, "10.0.0.2:9945"))
+		assert.NoError(t, limiter.checkBanned(ip))
+	})
+
+	t.Run("ip rate limit", func(t *testing.T) {
+		ip := clientIP(peerContext(t, "10.0.0.2:9945"))
+		assert.NoError(t, limiter.checkIPRateLimit(ip))
+		assert.Equal(t, ErrIPRateLimitExceeded, limiter.checkIPRateLimit(ip))
+	})
+
+	t.Run("pending trades", func(t *testing.T) {
+		now := uint64(time.Now().Unix())
+		checkMarket := func(market string) error {
+			reservationID, err := limiter.checkMarkets([]string{market})
+			limiter.releasePendingTrade(reservationID)
+			return err
+		}
+		assert.NoError(t, checkMarket("market"))
+
+		limiter.confirmPendingTrade("", []string{"market"}, "accept", now+60)
+		assert.Equal(t, ErrTooManyPendingTrades, checkMarket("market"))
+		assert.NoError(t, checkMarket("other_market"))
+
+		limiter.removePendingTrade("accept")
+		assert.NoError(t, checkMarket("market"))
+
+		limiter.confirmPendingTrade(
+			"", []string{"market"}, "expired_accept", now-1,
+		)
+		assert.NoError(t, checkMarket("market"))
+
+		// a trade proposal being handled holds a slot until accepted
+		reservationID, err := limiter.checkMarkets([]string{"market"})
+		assert.NoError(t, err)
+		assert.Equal(t, ErrTooManyPendingTrades, checkMarket("market"))
+		limiter.confirmPendingTrade(
+			reservationID, []string{"market"}, "accept", now+60,
+		)
+		assert.Equal(t, 1, limiter.countPendingTrades("market"))
+		limiter.removePendingTrade("accept")
+	})
+
+	assert.Equal(t, map[string]uint64{
+		RejectReasonBanned:        2,
+		RejectReasonIPRateLimit:   1,
+		RejectReasonPendingTrades: 2,
+	}, limiter.RejectedCalls())
+}
+
+func TestRateLimiterMultiAsset(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOpts{
+		MaxPendingTradesPerMarket: 1,
+		BaseAsset:                 "base",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swapAccept, err := proto.Marshal(&pbswap.SwapAccept{Id: "accept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiryTime := uint64(time.Now().Unix()) + 60
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &rpcext.TradeProposeMultiAssetReply{
+			SwapAccept:     swapAccept,
+			ExpiryTimeUnix: expiryTime,
+		}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: tradeProposeMultiAssetMethod}
+	ctx := peerContext(t, "10.0.0.2:9945")
+	req := &rpcext.TradeProposeMultiAssetRequest{
+		LegsP: []*rpcext.Leg{{Asset: "market1", Amount: 1}},
+		LegsR: []*rpcext.Leg{
+			{Asset: "base", Amount: 1},
+			{Asset: "market2", Amount: 1},
+		},
+	}
+
+	_, err = limiter.unaryInterceptor(ctx, req, info, handler)
+	assert.NoError(t, err)
+
+	// the accepted trade is pending in every market it touches
+	assert.Equal(t, 1, limiter.countPendingTrades("market1"))
+	assert.Equal(t, 1, limiter.countPendingTrades("market2"))
+	assert.Equal(t, 0, limiter.countPendingTrades("base"))
+	_, err = limiter.checkMarkets([]string{"market2"})
+	assert.Equal(t, ErrTooManyPendingTrades, err)
+
+	req.LegsP = []*rpcext.Leg{{Asset: "market3", Amount: 1}}
+	_, err = limiter.unaryInterceptor(ctx, req, info, handler)
+	assert.Equal(t, ErrTooManyPendingTrades, err)
+
+	// the slots reserved for a trade proposal not accepted are released
+	limiter.removePendingTrade("accept")
+	failingHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	}
+	_, err = limiter.unaryInterceptor(ctx, req, info, failingHandler)
+	assert.Error(t, err)
+	assert.Equal(t, 0, limiter.countPendingTrades("market2"))
+	assert.Equal(t, 0, limiter.countPendingTrades("market3"))
+
+	_, err = limiter.unaryInterceptor(ctx, req, info, handler)
+	assert.NoError(t, err)
+}
+
+func TestRateLimiterConcurrentTradeProposals(t *testing.T) {
+	maxPendingTrades := 3
+	limiter, err := NewRateLimiter(RateLimiterOpts{
+		MaxPendingTradesPerMarket: maxPendingTrades,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every trade proposal is accepted only after all of them have passed the
+	// checks of the limiter, or have been rejected
+	numProposals := 10
+	start := make(chan struct{})
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		req := &pbtrade.TradeProposeRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		<-start
+		return stream.SendMsg(&pbtrade.TradeProposeReply{
+			SwapAccept:     &pbswap.SwapAccept{Id: uuid.New().String()},
+			ExpiryTimeUnix: uint64(time.Now().Unix()) + 60,
+		})
+	}
+	info := &grpc.StreamServerInfo{FullMethod: tradeProposeMethod}
+
+	wg := &sync.WaitGroup{}
+	errs := make(chan error, numProposals)
+	for i := 0; i < numProposals; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- limiter.streamInterceptor(nil, &mockedStream{
+				ctx: peerContext(t, "10.0.0.2:9945"),
+				req: &pbtrade.TradeProposeRequest{
+					Market: &pbtypes.Market{QuoteAsset: "market"},
+				},
+			}, info, handler)
+		}()
+	}
+	for len(errs) < numProposals-maxPendingTrades {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	rejected := 0
+	for err := range errs {
+		if err != nil {
+			assert.Equal(t, ErrTooManyPendingTrades, err)
+			rejected++
+		}
+	}
+	assert.Equal(t, numProposals-maxPendingTrades, rejected)
+	assert.Equal(t, maxPendingTrades, limiter.countPendingTrades("market"))
+}
+
+func TestRateLimiterTradeProposeFail(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOpts{
+		MaxPendingTradesPerMarket: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		req := &pbtrade.TradeProposeRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		return stream.SendMsg(&pbtrade.TradeProposeReply{
+			SwapFail: &pbswap.SwapFail{Id: "fail"},
+		})
+	}
+	info := &grpc.StreamServerInfo{FullMethod: tradeProposeMethod}
+	for i := 0; i < 2; i++ {
+		err := limiter.streamInterceptor(nil, &mockedStream{
+			ctx: peerContext(t, "10.0.0.2:9945"),
+			req: &pbtrade.TradeProposeRequest{
+				Market: &pbtypes.Market{QuoteAsset: "market"},
+			},
+		}, info, handler)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, limiter.countPendingTrades("market"))
+	}
+}
+
+func TestRateLimiterLoadPendingTrades(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOpts{
+		MaxPendingTradesPerMarket: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := uint64(time.Now().Unix())
+	repository := &mockedTradeRepository{
+		trades: []*domain.Trade{
+			{
+				MarketQuoteAsset: "market1",
+				Status:           domain.AcceptedStatus,
+				SwapAccept:       domain.Swap{ID: "accept1"},
+				Timestamp:        domain.Timestamp{Expiry: now + 60},
+			},
+			{
+				MarketQuoteAsset: "market2",
+				Status:           domain.AcceptedStatus,
+				SwapAccept:       domain.Swap{ID: "expired_accept"},
+				Timestamp:        domain.Timestamp{Expiry: now - 1},
+			},
+			{
+				MarketQuoteAsset: "market3",
+				Status:           domain.AcceptedStatus,
+				SwapAccept:       domain.Swap{ID: "accept2"},
+				Timestamp:        domain.Timestamp{Expiry: now + 60},
+				MarketTrades: []domain.MarketTrade{
+					{QuoteAsset: "market3"},
+					{QuoteAsset: "market4"},
+				},
+			},
+		},
+	}
+	if err := limiter.LoadPendingTrades(
+		context.Background(), repository,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, limiter.countPendingTrades("market1"))
+	assert.Equal(t, 0, limiter.countPendingTrades("market2"))
+	assert.Equal(t, 1, limiter.countPendingTrades("market3"))
+	assert.Equal(t, 1, limiter.countPendingTrades("market4"))
+	_, err = limiter.checkMarkets([]string{"market4"})
+	assert.Equal(t, ErrTooManyPendingTrades, err)
+}
+
+func TestInvalidBanList(t *testing.T) {
+	for _, banList := range [][]string{{"10.0.0"}, {"10.0.0.0/33"}} {
+		_, err := NewRateLimiter(RateLimiterOpts{BanList: banList})
+		assert.Error(t, err)
+	}
+}
+
+type mockedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req *pbtrade.TradeProposeRequest
+}
+
+func (s *mockedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *mockedStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+func (s *mockedStream) SendMsg(m interface{}) error {
+	return nil
+}
+
+type mockedTradeRepository struct {
+	domain.TradeRepository
+	trades []*domain.Trade
+}
+
+func (r *mockedTradeRepository) GetAcceptedTrades(
+	ctx context.Context,
+) ([]*domain.Trade, error) {
+	return r.trades, nil
+}
+
+func peerContext(t *testing.T, addr string) context.Context {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: tcpAddr})
+}
@@ -0,0 +1,61 @@
+package interceptor
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/tdex-network/tdex-daemon/internal/interfaces/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDMetadataKey is the metadata key a client can use to set the ID of
+// its request. The ID is always returned in the header of the response
+const requestIDMetadataKey = "x-request-id"
+
+// validRequestID restricts the IDs set by clients to reasonable strings,
+// since they end up in the log lines
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// unaryRequestID adds the ID of the request to its context
+func unaryRequestID(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	requestID := requestIDFromMetadata(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+	return handler(logging.ContextWithRequestID(ctx, requestID), req)
+}
+
+// streamRequestID adds the ID of the request to the context of the stream
+func streamRequestID(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	requestID := requestIDFromMetadata(stream.Context())
+	stream.SetHeader(metadata.Pairs(requestIDMetadataKey, requestID))
+
+	wrapped := middleware.WrapServerStream(stream)
+	wrapped.WrappedContext = logging.ContextWithRequestID(
+		stream.Context(), requestID,
+	)
+	return handler(srv, wrapped)
+}
+
+// requestIDFromMetadata returns the request ID set by the client, if valid,
+// or a new random one otherwise
+func requestIDFromMetadata(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDMetadataKey); len(ids) > 0 &&
+			validRequestID.MatchString(ids[0]) {
+			return ids[0]
+		}
+	}
+	return uuid.New().String()
+}
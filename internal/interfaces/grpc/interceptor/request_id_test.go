@@ -0,0 +1,55 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/interfaces/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDFromMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(
+		context.Background(), metadata.Pairs(requestIDMetadataKey, "req-42"),
+	)
+	assert.Equal(t, "req-42", requestIDFromMetadata(ctx))
+
+	// IDs not valid are replaced by random ones
+	for _, id := range []string{"", "id with spaces", strings.Repeat("a", 65)} {
+		ctx := metadata.NewIncomingContext(
+			context.Background(), metadata.Pairs(requestIDMetadataKey, id),
+		)
+		requestID := requestIDFromMetadata(ctx)
+		assert.NotEqual(t, id, requestID)
+		assert.Len(t, requestID, 36)
+	}
+
+	assert.NotEqual(
+		t,
+		requestIDFromMetadata(context.Background()),
+		requestIDFromMetadata(context.Background()),
+	)
+}
+
+func TestUnaryRequestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(
+		context.Background(), metadata.Pairs(requestIDMetadataKey, "req-42"),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/Trade/Markets"}
+
+	var requestID string
+	_, err := unaryRequestID(
+		ctx,
+		nil,
+		info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			requestID = logging.RequestID(ctx)
+			return nil, nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-42", requestID)
+}
@@ -0,0 +1,246 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/internal/core/application"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// StatusOK is the status of a working component
+	StatusOK = "ok"
+	// StatusFail is the status of a component not working
+	StatusFail = "fail"
+
+	// ComponentDb is the component checking that the stores are open
+	ComponentDb = "db"
+	// ComponentWallet is the component checking that the wallet is ready to
+	// trade, ie. initialized, synced and unlocked
+	ComponentWallet = "wallet"
+	// ComponentExplorer is the component checking that the explorer is
+	// reachable
+	ComponentExplorer = "explorer"
+	// ComponentCrawler is the component checking that the crawler completed a
+	// cycle recently
+	ComponentCrawler = "crawler"
+
+	explorerTimeout = 5 * time.Second
+	// minCrawlerStaleness is the min time after which a crawler that didn't
+	// complete a cycle is considered stuck
+	minCrawlerStaleness = time.Minute
+)
+
+// ComponentStatus is the result of the check of a component
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report is the result of a liveness or readiness check. Status is ok only
+// if all the components checked are ok.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// CheckerOpts defines the sources of the checks of a Checker
+type CheckerOpts struct {
+	WalletSvc        application.WalletService
+	DbStatus         func() error
+	ExplorerEndpoint string
+	// CrawlInterval is the interval between two cycles of the crawler
+	CrawlInterval time.Duration
+}
+
+// Checker reports the liveness and the readiness of the daemon, both over
+// HTTP and through the standard gRPC health service
+type Checker struct {
+	opts          CheckerOpts
+	httpClient    *http.Client
+	started       time.Time
+	crawlInterval time.Duration
+	lastCycle     time.Time
+	lastCycleLock *sync.RWMutex
+	grpcServer    *health.Server
+}
+
+// NewChecker returns a new Checker for the given sources
+func NewChecker(opts CheckerOpts) *Checker {
+	return &Checker{
+		opts:          opts,
+		httpClient:    &http.Client{Timeout: explorerTimeout},
+		started:       time.Now(),
+		crawlInterval: opts.CrawlInterval,
+		lastCycleLock: &sync.RWMutex{},
+		grpcServer:    health.NewServer(),
+	}
+}
+
+// ObserveCrawlerCycle records the completion of a cycle of the crawler, it's
+// meant to be used as (part of) the crawler's cycle handler
+func (c *Checker) ObserveCrawlerCycle(observables int, duration time.Duration) {
+	c.lastCycleLock.Lock()
+	defer c.lastCycleLock.Unlock()
+
+	c.lastCycle = time.Now()
+}
+
+// SetCrawlInterval updates the interval between two cycles of the crawler,
+// after which it's considered stuck, when changed at runtime
+func (c *Checker) SetCrawlInterval(interval time.Duration) {
+	c.lastCycleLock.Lock()
+	defer c.lastCycleLock.Unlock()
+
+	c.crawlInterval = interval
+}
+
+// Liveness checks the components whose failure requires a restart of the
+// daemon, ie. db and crawler
+func (c *Checker) Liveness(ctx context.Context) Report {
+	return newReport(map[string]ComponentStatus{
+		ComponentDb:      c.checkDb(),
+		ComponentCrawler: c.checkCrawler(),
+	})
+}
+
+// Readiness checks all the components required to serve traders
+func (c *Checker) Readiness(ctx context.Context) Report {
+	return newReport(map[string]ComponentStatus{
+		ComponentDb:       c.checkDb(),
+		ComponentCrawler:  c.checkCrawler(),
+		ComponentWallet:   c.checkWallet(ctx),
+		ComponentExplorer: c.checkExplorer(ctx),
+	})
+}
+
+// GRPCServer returns the gRPC health service, whose overall status (empty
+// service name) reflects the readiness of the daemon
+func (c *Checker) GRPCServer() healthpb.HealthServer {
+	return c.grpcServer
+}
+
+// Watch updates the status of the gRPC health service with the readiness of
+// the daemon at the given interval, until the context is canceled
+func (c *Checker) Watch(ctx context.Context, interval time.Duration) {
+	c.updateGRPCStatus(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.updateGRPCStatus(ctx)
+		case <-ctx.Done():
+			c.grpcServer.Shutdown()
+			return
+		}
+	}
+}
+
+func (c *Checker) updateGRPCStatus(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if c.Readiness(ctx).Status != StatusOK {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	c.grpcServer.SetServingStatus("", status)
+}
+
+func (c *Checker) checkDb() ComponentStatus {
+	if c.opts.DbStatus == nil {
+		return ok("")
+	}
+	if err := c.opts.DbStatus(); err != nil {
+		return fail(err.Error())
+	}
+	return ok("")
+}
+
+func (c *Checker) checkWallet(ctx context.Context) ComponentStatus {
+	if c.opts.WalletSvc == nil {
+		return ok("")
+	}
+	status := c.opts.WalletSvc.WalletStatus(ctx)
+	if status.Syncing {
+		return fail("syncing")
+	}
+	if !status.Initialized {
+		return fail("not initialized")
+	}
+	if !status.Unlocked {
+		return fail("locked")
+	}
+	return ok("unlocked")
+}
+
+func (c *Checker) checkExplorer(ctx context.Context) ComponentStatus {
+	if c.opts.ExplorerEndpoint == "" {
+		return ok("")
+	}
+
+	url := fmt.Sprintf(
+		"%s/blocks/tip/height", strings.TrimSuffix(c.opts.ExplorerEndpoint, "/"),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fail(err.Error())
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fail(err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fail(fmt.Sprintf("unexpected response status %s", resp.Status))
+	}
+	return ok("")
+}
+
+func (c *Checker) checkCrawler() ComponentStatus {
+	c.lastCycleLock.RLock()
+	lastCycle := c.lastCycle
+	staleness := 10 * c.crawlInterval
+	c.lastCycleLock.RUnlock()
+
+	if staleness < minCrawlerStaleness {
+		staleness = minCrawlerStaleness
+	}
+
+	if lastCycle.IsZero() {
+		if time.Since(c.started) > staleness {
+			return fail("no cycle completed since start")
+		}
+		return ok("starting")
+	}
+	if since := time.Since(lastCycle); since > staleness {
+		return fail(fmt.Sprintf(
+			"last cycle completed %s ago", since.Round(time.Second),
+		))
+	}
+	return ok(fmt.Sprintf("last cycle at %s", lastCycle.UTC().Format(time.RFC3339)))
+}
+
+func newReport(components map[string]ComponentStatus) Report {
+	status := StatusOK
+	for _, c := range components {
+		if c.Status != StatusOK {
+			status = StatusFail
+		}
+	}
+	return Report{Status: status, Components: components}
+}
+
+func ok(detail string) ComponentStatus {
+	return ComponentStatus{Status: StatusOK, Detail: detail}
+}
+
+func fail(detail string) ComponentStatus {
+	return ComponentStatus{Status: StatusFail, Detail: detail}
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tdex-network/tdex-daemon/internal/core/application"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestReadiness(t *testing.T) {
+	explorer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/blocks/tip/height" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte("100"))
+		},
+	))
+	defer explorer.Close()
+
+	walletSvc := &mockedWalletService{}
+	var dbErr error
+	checker := NewChecker(CheckerOpts{
+		WalletSvc:        walletSvc,
+		DbStatus:         func() error { return dbErr },
+		ExplorerEndpoint: explorer.URL,
+		CrawlInterval:    time.Second,
+	})
+	ctx := context.Background()
+
+	report := checker.Readiness(ctx)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, fail("not initialized"), report.Components[ComponentWallet])
+	assert.Equal(t, ok("starting"), report.Components[ComponentCrawler])
+	assert.Equal(t, StatusOK, report.Components[ComponentExplorer].Status)
+
+	walletSvc.status = application.WalletStatus{Initialized: true, Syncing: true}
+	report = checker.Readiness(ctx)
+	assert.Equal(t, fail("syncing"), report.Components[ComponentWallet])
+
+	walletSvc.status = application.WalletStatus{Initialized: true}
+	report = checker.Readiness(ctx)
+	assert.Equal(t, fail("locked"), report.Components[ComponentWallet])
+
+	walletSvc.status.Unlocked = true
+	checker.ObserveCrawlerCycle(1, time.Millisecond)
+	report = checker.Readiness(ctx)
+	assert.Equal(t, StatusOK, report.Status)
+
+	dbErr = errors.New("main store is closed")
+	report = checker.Liveness(ctx)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, fail(dbErr.Error()), report.Components[ComponentDb])
+	assert.NotContains(t, report.Components, ComponentWallet)
+
+	explorer.Close()
+	report = checker.Readiness(ctx)
+	assert.Equal(t, StatusFail, report.Components[ComponentExplorer].Status)
+}
+
+func TestCrawlerStaleness(t *testing.T) {
+	checker := NewChecker(CheckerOpts{CrawlInterval: time.Second})
+
+	checker.started = time.Now().Add(-2 * minCrawlerStaleness)
+	assert.Equal(t, StatusFail, checker.checkCrawler().Status)
+
+	checker.ObserveCrawlerCycle(0, 0)
+	assert.Equal(t, StatusOK, checker.checkCrawler().Status)
+
+	checker.lastCycle = time.Now().Add(-2 * minCrawlerStaleness)
+	assert.Equal(t, StatusFail, checker.checkCrawler().Status)
+
+	// a longer interval set at runtime makes the crawler stale later
+	checker.SetCrawlInterval(minCrawlerStaleness)
+	assert.Equal(t, StatusOK, checker.checkCrawler().Status)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	walletSvc := &mockedWalletService{}
+	checker := NewChecker(CheckerOpts{WalletSvc: walletSvc})
+	server := httptest.NewServer(checker.HTTPHandler())
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{LivenessPath, http.StatusOK},
+		{ReadinessPath, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + tt.path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, tt.status, resp.StatusCode)
+
+		report := Report{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		assert.Contains(t, report.Components, ComponentDb)
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	walletSvc := &mockedWalletService{}
+	checker := NewChecker(CheckerOpts{WalletSvc: walletSvc})
+	ctx := context.Background()
+	req := &healthpb.HealthCheckRequest{}
+
+	checker.updateGRPCStatus(ctx)
+	resp, err := checker.GRPCServer().Check(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	walletSvc.status = application.WalletStatus{Initialized: true, Unlocked: true}
+	checker.updateGRPCStatus(ctx)
+	resp, err = checker.GRPCServer().Check(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+type mockedWalletService struct {
+	application.WalletService
+	status application.WalletStatus
+}
+
+func (m *mockedWalletService) WalletStatus(
+	ctx context.Context,
+) application.WalletStatus {
+	return m.status
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// LivenessPath is the HTTP path of the liveness probe
+	LivenessPath = "/healthz"
+	// ReadinessPath is the HTTP path of the readiness probe
+	ReadinessPath = "/readyz"
+)
+
+// HTTPHandler returns the handler serving the liveness and readiness reports
+// as JSON, with status 200 if ok or 503 otherwise
+func (c *Checker) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, c.serveReport(c.Liveness))
+	mux.HandleFunc(ReadinessPath, c.serveReport(c.Readiness))
+	return mux
+}
+
+func (c *Checker) serveReport(
+	check func(ctx context.Context) Report,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := check(req.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != StatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// FormatText is the default, human readable, format of the log lines
+	FormatText = "text"
+	// FormatJSON formats every log line as a JSON object
+	FormatJSON = "json"
+
+	// RequestIDField is the field of the log lines emitted while serving a
+	// request that identifies it
+	RequestIDField = "request_id"
+)
+
+type requestIDKey struct{}
+
+// Opts defines the level, the format and the outputs of the logs
+type Opts struct {
+	Level  log.Level
+	Format string
+	// FilePath is the path of the log file written in addition to the
+	// standard output, empty means no log file
+	FilePath string
+	// MaxSize is the size in MB after which the log file is rotated
+	MaxSize int
+	// MaxBackups is the max number of rotated log files retained
+	MaxBackups int
+	// MaxAge is the max number of days rotated log files are retained for
+	MaxAge int
+}
+
+// Init configures the standard logger with the given options and makes every
+// log line created with log.WithContext carry the request ID, if any
+func Init(opts Opts) {
+	log.SetLevel(opts.Level)
+	if opts.Format == FormatJSON {
+		log.SetFormatter(&log.JSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.FilePath != "" {
+		out = io.MultiWriter(os.Stdout, &lumberjack.Logger{
+			Filename:   opts.FilePath,
+			MaxSize:    opts.MaxSize,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAge,
+		})
+	}
+	log.SetOutput(out)
+	log.AddHook(contextHook{})
+}
+
+// ContextWithRequestID returns a copy of the given context carrying the
+// request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the ID of the request the given context belongs to, if
+// any
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHook adds the request ID found in the context of an entry to its
+// fields
+type contextHook struct{}
+
+func (contextHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (contextHook) Fire(entry *log.Entry) error {
+	if requestID := RequestID(entry.Context); requestID != "" {
+		entry.Data[RequestIDField] = requestID
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "", RequestID(context.Background()))
+	assert.Equal(t, "", RequestID(nil))
+
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+	assert.Equal(t, "req-42", RequestID(ctx))
+}
+
+func TestContextHook(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&log.JSONFormatter{})
+	logger.AddHook(contextHook{})
+
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+	logger.WithContext(ctx).WithField("trade_id", "t1").Info("trade completed")
+
+	line := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-42", line[RequestIDField])
+	assert.Equal(t, "t1", line["trade_id"])
+	assert.Equal(t, "trade completed", line["msg"])
+
+	// entries without a request in their context are left untouched
+	buf.Reset()
+	logger.WithContext(context.Background()).Info("starting daemon")
+	line = map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.NotContains(t, line, RequestIDField)
+}
+
+func TestInitLogFile(t *testing.T) {
+	level := log.GetLevel()
+	hooks := log.StandardLogger().ReplaceHooks(log.LevelHooks{})
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFormatter(&log.TextFormatter{})
+		log.SetLevel(level)
+		log.StandardLogger().ReplaceHooks(hooks)
+	}()
+
+	dir, err := ioutil.TempDir("", "logging")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tdexd.log")
+	Init(Opts{
+		Level:    log.InfoLevel,
+		Format:   FormatJSON,
+		FilePath: path,
+		MaxSize:  1,
+	})
+
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+	log.WithContext(ctx).Info("served request")
+	log.Debug("not logged")
+
+	buf, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(buf), []byte("\n"))
+	require.Len(t, lines, 1)
+
+	line := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(lines[0], &line))
+	assert.Equal(t, "req-42", line[RequestIDField])
+}
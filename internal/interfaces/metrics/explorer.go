@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+)
+
+// explorerService wraps an explorer.Service to record latency and errors of
+// every request
+type explorerService struct {
+	explorer.Service
+}
+
+// NewExplorerService returns an explorer.Service that records the latency and
+// the errors of the requests made with the given one
+func NewExplorerService(svc explorer.Service) explorer.Service {
+	return &explorerService{svc}
+}
+
+func (e *explorerService) GetUnspents(
+	addr string,
+	blindKeys [][]byte,
+) ([]explorer.Utxo, error) {
+	defer observeExplorerRequest("GetUnspents", time.Now())()
+	utxos, err := e.Service.GetUnspents(addr, blindKeys)
+	countExplorerError("GetUnspents", err)
+	return utxos, err
+}
+
+func (e *explorerService) GetTransactionHex(hash string) (string, error) {
+	defer observeExplorerRequest("GetTransactionHex", time.Now())()
+	txHex, err := e.Service.GetTransactionHex(hash)
+	countExplorerError("GetTransactionHex", err)
+	return txHex, err
+}
+
+func (e *explorerService) IsTransactionConfirmed(txID string) (bool, error) {
+	defer observeExplorerRequest("IsTransactionConfirmed", time.Now())()
+	confirmed, err := e.Service.IsTransactionConfirmed(txID)
+	countExplorerError("IsTransactionConfirmed", err)
+	return confirmed, err
+}
+
+func (e *explorerService) GetTransactionStatus(
+	txID string,
+) (map[string]interface{}, error) {
+	defer observeExplorerRequest("GetTransactionStatus", time.Now())()
+	status, err := e.Service.GetTransactionStatus(txID)
+	countExplorerError("GetTransactionStatus", err)
+	return status, err
+}
+
+func (e *explorerService) GetTransactionsForAddress(
+	address string,
+) ([]explorer.Transaction, error) {
+	defer observeExplorerRequest("GetTransactionsForAddress", time.Now())()
+	txs, err := e.Service.GetTransactionsForAddress(address)
+	countExplorerError("GetTransactionsForAddress", err)
+	return txs, err
+}
+
+func (e *explorerService) BroadcastTransaction(txHex string) (string, error) {
+	defer observeExplorerRequest("BroadcastTransaction", time.Now())()
+	txID, err := e.Service.BroadcastTransaction(txHex)
+	countExplorerError("BroadcastTransaction", err)
+	return txID, err
+}
+
+func (e *explorerService) GetSpendingTransactionID(
+	txID string,
+	vout uint32,
+) (string, error) {
+	defer observeExplorerRequest("GetSpendingTransactionID", time.Now())()
+	spendingTxID, err := e.Service.GetSpendingTransactionID(txID, vout)
+	countExplorerError("GetSpendingTransactionID", err)
+	return spendingTxID, err
+}
+
+func (e *explorerService) GetUnspentsForAddresses(
+	addresses []string,
+	blindingKeys [][]byte,
+) ([]explorer.Utxo, error) {
+	defer observeExplorerRequest("GetUnspentsForAddresses", time.Now())()
+	utxos, err := e.Service.GetUnspentsForAddresses(addresses, blindingKeys)
+	countExplorerError("GetUnspentsForAddresses", err)
+	return utxos, err
+}
+
+func observeExplorerRequest(method string, start time.Time) func() {
+	return func() {
+		explorerRequestDuration.WithLabelValues(method).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+func countExplorerError(method string, err error) {
+	// a transaction not found is a legit answer rather than a failure
+	if err != nil && err != explorer.ErrTransactionNotFound {
+		explorerRequestErrors.WithLabelValues(method).Inc()
+	}
+}
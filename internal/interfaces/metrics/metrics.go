@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+const namespace = "tdex"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	swapFails = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "swap_fail_total",
+			Help:      "Number of SwapFail messages returned to traders by RPC and failure code.",
+		},
+		[]string{"method", "code"},
+	)
+	tradeProposeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "trade_propose_duration_seconds",
+			Help:      "Time spent serving trade proposals by result (accepted, failed or error).",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method", "result"},
+	)
+	explorerRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "explorer_request_duration_seconds",
+			Help:      "Latency of the requests made to the explorer by method.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method"},
+	)
+	explorerRequestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "explorer_request_errors_total",
+			Help:      "Number of failed requests made to the explorer by method.",
+		},
+		[]string{"method"},
+	)
+	crawlerCycleDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "crawler_cycle_duration_seconds",
+			Help:      "Time taken by the crawler to check all the observables once.",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
+	crawlerObservables = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "crawler_observables",
+			Help:      "Number of addresses and transactions observed by the crawler.",
+		},
+	)
+	doubleSpentTrades = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "double_spent_trades_total",
+			Help:      "Number of completed trades whose transaction has been double-spent by market quote asset.",
+		},
+		[]string{"market"},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		swapFails,
+		tradeProposeDuration,
+		explorerRequestDuration,
+		explorerRequestErrors,
+		crawlerCycleDuration,
+		crawlerObservables,
+		doubleSpentTrades,
+	)
+}
+
+// Register adds the given collectors to the ones exposed at /metrics
+func Register(collectors ...prometheus.Collector) error {
+	for _, c := range collectors {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Serve starts an HTTP server exposing the metrics at /metrics on the given
+// address, in background
+func Serve(address string) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	go func() {
+		if err := http.Serve(lis, mux); err != nil {
+			log.WithError(err).Warn("metrics listener stopped")
+		}
+	}()
+	return nil
+}
+
+// ObserveSwapFail counts a SwapFail message with the given failure code
+// returned by the given RPC
+func ObserveSwapFail(method string, code uint32) {
+	swapFails.WithLabelValues(method, strconv.Itoa(int(code))).Inc()
+}
+
+// ObserveTradePropose records the time spent serving a trade proposal with
+// the given result
+func ObserveTradePropose(method, result string, duration time.Duration) {
+	tradeProposeDuration.WithLabelValues(method, result).
+		Observe(duration.Seconds())
+}
+
+// ObserveCrawlerCycle records the duration of a crawler cycle and the number
+// of observables checked
+func ObserveCrawlerCycle(observables int, duration time.Duration) {
+	crawlerCycleDuration.Observe(duration.Seconds())
+	crawlerObservables.Set(float64(observables))
+}
+
+// ObserveDoubleSpentTrade counts a completed trade of the given market whose
+// transaction has been double-spent
+func ObserveDoubleSpentTrade(market string) {
+	doubleSpentTrades.WithLabelValues(market).Inc()
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+)
+
+func TestExplorerService(t *testing.T) {
+	svc := NewExplorerService(&mockedExplorer{})
+
+	_, err := svc.GetTransactionHex("notfound")
+	assert.Equal(t, explorer.ErrTransactionNotFound, err)
+	_, err = svc.GetTransactionHex("fail")
+	assert.Error(t, err)
+	_, err = svc.GetTransactionHex("ok")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(
+		explorerRequestDuration, "tdex_explorer_request_duration_seconds",
+	))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		explorerRequestErrors.WithLabelValues("GetTransactionHex"),
+	))
+}
+
+func TestObserve(t *testing.T) {
+	ObserveSwapFail("/Trade/TradePropose", 1)
+	ObserveSwapFail("/Trade/TradePropose", 1)
+	assert.Equal(t, float64(2), testutil.ToFloat64(
+		swapFails.WithLabelValues("/Trade/TradePropose", "1"),
+	))
+
+	ObserveCrawlerCycle(3, time.Second)
+	assert.Equal(t, float64(3), testutil.ToFloat64(crawlerObservables))
+
+	ObserveDoubleSpentTrade("quote")
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		doubleSpentTrades.WithLabelValues("quote"),
+	))
+}
+
+func TestStateCollector(t *testing.T) {
+	collector := NewStateCollector(StateCollectorOpts{
+		TradeRepository: &mockedTradeRepository{
+			counts: []domain.TradeCount{
+				{MarketQuoteAsset: "quote", Status: domain.CompletedStatus, Count: 2},
+				{
+					MarketQuoteAsset: "quote",
+					Status:           domain.FailedToCompleteStatus,
+					Count:            1,
+				},
+			},
+		},
+		RejectedCalls: func() map[string]uint64 {
+			return map[string]uint64{"banned": 4}
+		},
+	})
+
+	expected := `
+# HELP tdex_trades Number of trades by market quote asset and status.
+# TYPE tdex_trades gauge
+tdex_trades{failed="false",market="quote",status="complete"} 2
+tdex_trades{failed="true",market="quote",status="accept"} 1
+# HELP tdex_trader_rejected_calls_total Number of calls to the trader interface rejected by the rate limiter by reason.
+# TYPE tdex_trader_rejected_calls_total counter
+tdex_trader_rejected_calls_total{reason="banned"} 4
+`
+	assert.NoError(t, testutil.CollectAndCompare(
+		collector, strings.NewReader(expected),
+	))
+}
+
+type mockedExplorer struct {
+	explorer.Service
+}
+
+func (m *mockedExplorer) GetTransactionHex(hash string) (string, error) {
+	switch hash {
+	case "notfound":
+		return "", explorer.ErrTransactionNotFound
+	case "fail":
+		return "", errors.New("connection refused")
+	}
+	return "00", nil
+}
+
+type mockedTradeRepository struct {
+	domain.TradeRepository
+	counts []domain.TradeCount
+}
+
+func (m *mockedTradeRepository) CountTradesByMarketAndStatus(
+	ctx context.Context,
+) ([]domain.TradeCount, error) {
+	return m.counts, nil
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/tdex-network/tdex-daemon/config"
+	"github.com/tdex-network/tdex-daemon/internal/core/domain"
+)
+
+var (
+	tradesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "trades"),
+		"Number of trades by market quote asset and status.",
+		[]string{"market", "status", "failed"}, nil,
+	)
+	utxosDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "utxos"),
+		"Number of unspents by account and state (available, locked or unconfirmed).",
+		[]string{"account", "state"}, nil,
+	)
+	feeAccountBalanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "fee_account_balance"),
+		"Balance of the fee account in satoshis of the base asset.",
+		nil, nil,
+	)
+	rejectedCallsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "trader_rejected_calls_total"),
+		"Number of calls to the trader interface rejected by the rate limiter by reason.",
+		[]string{"reason"}, nil,
+	)
+)
+
+// StateCollectorOpts defines the sources read by the StateCollector. Any of
+// them can be omitted to skip the related metrics.
+type StateCollectorOpts struct {
+	TradeRepository   domain.TradeRepository
+	VaultRepository   domain.VaultRepository
+	UnspentRepository domain.UnspentRepository
+	RejectedCalls     func() map[string]uint64
+}
+
+// StateCollector is a prometheus.Collector that reads the state of trades,
+// unspents and fee account from the repositories at every scrape
+type StateCollector struct {
+	opts StateCollectorOpts
+}
+
+// NewStateCollector returns a new StateCollector for the given sources
+func NewStateCollector(opts StateCollectorOpts) *StateCollector {
+	return &StateCollector{opts}
+}
+
+// Describe implements prometheus.Collector
+func (s *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tradesDesc
+	ch <- utxosDesc
+	ch <- feeAccountBalanceDesc
+	ch <- rejectedCallsDesc
+}
+
+// Collect implements prometheus.Collector
+func (s *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	if s.opts.TradeRepository != nil {
+		if err := s.collectTrades(ctx, ch); err != nil {
+			log.WithError(err).Warn("unable to collect trade metrics")
+		}
+	}
+	if s.opts.VaultRepository != nil && s.opts.UnspentRepository != nil {
+		if err := s.collectUnspents(ctx, ch); err != nil {
+			log.WithError(err).Debug("unable to collect unspent metrics")
+		}
+	}
+	if s.opts.RejectedCalls != nil {
+		for reason, count := range s.opts.RejectedCalls() {
+			ch <- prometheus.MustNewConstMetric(
+				rejectedCallsDesc, prometheus.CounterValue, float64(count), reason,
+			)
+		}
+	}
+}
+
+func (s *StateCollector) collectTrades(
+	ctx context.Context,
+	ch chan<- prometheus.Metric,
+) error {
+	counts, err := s.opts.TradeRepository.CountTradesByMarketAndStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			tradesDesc, prometheus.GaugeValue, float64(c.Count),
+			c.MarketQuoteAsset,
+			strings.ToLower(c.Status.Code.String()),
+			strconv.FormatBool(c.Status.Failed),
+		)
+	}
+	return nil
+}
+
+// collectUnspents exports the number of unspents of every account and the
+// balance of the fee account. Confirmed unspents not available are the ones
+// locked by trades.
+func (s *StateCollector) collectUnspents(
+	ctx context.Context,
+	ch chan<- prometheus.Metric,
+) error {
+	vault, err := s.opts.VaultRepository.GetOrCreateVault(ctx, nil, "")
+	if err != nil {
+		return err
+	}
+
+	addressesByAccount := map[int][]string{}
+	for addr, info := range vault.AccountAndKeyByAddress {
+		addressesByAccount[info.AccountIndex] = append(
+			addressesByAccount[info.AccountIndex], addr,
+		)
+	}
+
+	for accountIndex, addresses := range addressesByAccount {
+		unspents, err := s.opts.UnspentRepository.GetAllUnspentsForAddresses(
+			ctx, addresses,
+		)
+		if err != nil {
+			return err
+		}
+		available, err := s.opts.UnspentRepository.
+			GetAvailableUnspentsForAddresses(ctx, addresses)
+		if err != nil {
+			return err
+		}
+		confirmed, unconfirmed := 0, 0
+		for _, u := range unspents {
+			if u.IsSpent() {
+				continue
+			}
+			if u.IsConfirmed() {
+				confirmed++
+			} else {
+				unconfirmed++
+			}
+		}
+		locked := confirmed - len(available)
+
+		account := strconv.Itoa(accountIndex)
+		for state, c := range map[string]int{
+			"available":   len(available),
+			"locked":      locked,
+			"unconfirmed": unconfirmed,
+		} {
+			ch <- prometheus.MustNewConstMetric(
+				utxosDesc, prometheus.GaugeValue, float64(c), account, state,
+			)
+		}
+
+		if accountIndex == domain.FeeAccount {
+			balance, err := s.opts.UnspentRepository.GetBalance(
+				ctx, addresses, config.GetString(config.BaseAssetKey),
+			)
+			if err != nil {
+				return err
+			}
+			ch <- prometheus.MustNewConstMetric(
+				feeAccountBalanceDesc, prometheus.GaugeValue, float64(balance),
+			)
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ const (
 	MarketAccountDeposit
 	TransactionConfirmed
 	TransactionUnConfirmed
+	TransactionNotFound
 )
 
 type EventType int
@@ -21,6 +22,8 @@ func (et EventType) String() string {
 		return "TransactionConfirmed"
 	case TransactionUnConfirmed:
 		return "TransactionUnConfirmed"
+	case TransactionNotFound:
+		return "TransactionNotFound"
 	default:
 		return "Unknown"
 	}
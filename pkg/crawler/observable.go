@@ -29,6 +29,7 @@ func (a *AddressObservable) observe(
 	unspents, err := explorerSvc.GetUnspents(a.Address, [][]byte{a.BlindingKey})
 	if err != nil {
 		errChan <- err
+		return
 	}
 	var eventType EventType
 	switch a.AccountIndex {
@@ -80,6 +81,7 @@ func (t *TransactionObservable) observe(
 	txStatus, err := explorerSvc.GetTransactionStatus(t.TxID)
 	if err != nil {
 		errChan <- err
+		return
 	}
 
 	var confirmed bool
@@ -107,6 +109,16 @@ func (t *TransactionObservable) observe(
 	trxStatus := TransactionUnConfirmed
 	if confirmed {
 		trxStatus = TransactionConfirmed
+	} else {
+		// the status of an unknown tx is reported as unconfirmed, therefore
+		// its existence must be checked explicitly
+		if _, err := explorerSvc.GetTransactionHex(t.TxID); err != nil {
+			if err != explorer.ErrTransactionNotFound {
+				errChan <- err
+				return
+			}
+			trxStatus = TransactionNotFound
+		}
 	}
 
 	event := TransactionEvent{
@@ -32,16 +32,19 @@ type Service interface {
 	RemoveObservable(observable Observable)
 	IsObservingAddresses(addresses []string) bool
 	GetEventChannel() chan Event
+	SetInterval(intervalInMilliseconds int)
 }
 
 type utxoCrawler struct {
 	interval     *time.Ticker
+	intervalChan chan time.Duration
 	explorerSvc  explorer.Service
 	errChan      chan error
 	quitChan     chan int
 	eventChan    chan Event
 	observables  []Observable
 	errorHandler func(err error)
+	cycleHandler func(observables int, duration time.Duration)
 	mutex        *sync.RWMutex
 }
 
@@ -51,6 +54,9 @@ type Opts struct {
 	IntervalInMilliseconds int
 	Observables            []Observable
 	ErrorHandler           func(err error)
+	// CycleHandler is optional and, if defined, is called at the end of every
+	// cycle with the number of observables checked and the time taken
+	CycleHandler func(observables int, duration time.Duration)
 }
 
 // NewService returns an utxoCrawelr that is ready for watch for blockchain activites. Use Start and Stop methods to manage it.
@@ -60,12 +66,14 @@ func NewService(opts Opts) Service {
 
 	return &utxoCrawler{
 		interval:     interval,
+		intervalChan: make(chan time.Duration, 1),
 		explorerSvc:  opts.ExplorerSvc,
 		errChan:      make(chan error),
 		quitChan:     make(chan int),
 		eventChan:    make(chan Event),
 		observables:  opts.Observables,
 		errorHandler: opts.ErrorHandler,
+		cycleHandler: opts.CycleHandler,
 		mutex:        &sync.RWMutex{},
 	}
 }
@@ -80,6 +88,9 @@ func (u *utxoCrawler) Start() {
 		case <-u.interval.C:
 			log.Debug("observe interval")
 			u.observeAll(&wg)
+		case interval := <-u.intervalChan:
+			u.interval.Stop()
+			u.interval = time.NewTicker(interval)
 		case err := <-u.errChan:
 			u.errorHandler(err)
 		case <-u.quitChan:
@@ -97,6 +108,18 @@ func (u *utxoCrawler) Stop() {
 	u.quitChan <- 1
 }
 
+// SetInterval changes the interval between two cycles of the crawler, the
+// new one is applied from the next cycle
+func (u *utxoCrawler) SetInterval(intervalInMilliseconds int) {
+	// only the latest interval set matters if the crawler didn't apply the
+	// previous one yet
+	select {
+	case <-u.intervalChan:
+	default:
+	}
+	u.intervalChan <- time.Duration(intervalInMilliseconds) * time.Millisecond
+}
+
 // GetEventChannel returns Event channel which can be used to "listen" to
 // blockchain events
 func (u *utxoCrawler) GetEventChannel() chan Event {
@@ -153,10 +176,28 @@ func (u *utxoCrawler) IsObservingAddresses(addresses []string) bool {
 
 func (u *utxoCrawler) observeAll(w *sync.WaitGroup) {
 	observables := u.getObservable()
+	if u.cycleHandler == nil {
+		for _, o := range observables {
+			w.Add(1)
+			go o.observe(w, u.explorerSvc, u.errChan, u.eventChan)
+		}
+		return
+	}
+
+	start := time.Now()
+	cycle := &sync.WaitGroup{}
 	for _, o := range observables {
 		w.Add(1)
-		go o.observe(w, u.explorerSvc, u.errChan, u.eventChan)
+		cycle.Add(1)
+		go func(o Observable) {
+			defer w.Done()
+			o.observe(cycle, u.explorerSvc, u.errChan, u.eventChan)
+		}(o)
 	}
+	go func() {
+		cycle.Wait()
+		u.cycleHandler(len(observables), time.Since(start))
+	}()
 }
 
 func (u *utxoCrawler) getObservable() []Observable {
@@ -175,7 +216,7 @@ func (u *utxoCrawler) removeAddressObservable(
 				newObservableList = append(newObservableList, o)
 			}
 		} else {
-			newObservableList = append(newObservableList, o)
+			newObservableList = append(newObservableList, obs)
 		}
 	}
 	u.observables = newObservableList
@@ -191,7 +232,7 @@ func (u *utxoCrawler) removeTransactionObservable(
 				newObservableList = append(newObservableList, o)
 			}
 		} else {
-			newObservableList = append(newObservableList, o)
+			newObservableList = append(newObservableList, obs)
 		}
 	}
 	u.observables = newObservableList
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -61,6 +62,124 @@ func TestCrawler(t *testing.T) {
 
 }
 
+func TestCrawlerCycleHandler(t *testing.T) {
+	cycles := make(chan int, 10)
+	crawlSvc := NewService(Opts{
+		ExplorerSvc: MockExplorer{},
+		Observables: []Observable{
+			&AddressObservable{AccountIndex: 1, Address: "1"},
+			&TransactionObservable{TxID: "1"},
+		},
+		ErrorHandler: func(err error) {},
+		CycleHandler: func(observables int, duration time.Duration) {
+			cycles <- observables
+		},
+		IntervalInMilliseconds: 100,
+	})
+
+	go crawlSvc.Start()
+	go func() {
+		for range crawlSvc.GetEventChannel() {
+		}
+	}()
+
+	select {
+	case observables := <-cycles:
+		if observables != 2 {
+			t.Fatalf("expected 2 observables, got %d", observables)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cycle handler not called")
+	}
+	crawlSvc.Stop()
+}
+
+func TestCrawlerSetInterval(t *testing.T) {
+	cycles := make(chan int, 10)
+	crawlSvc := NewService(Opts{
+		ExplorerSvc: MockExplorer{},
+		Observables: []Observable{
+			&TransactionObservable{TxID: "1"},
+		},
+		ErrorHandler: func(err error) {},
+		CycleHandler: func(observables int, duration time.Duration) {
+			cycles <- observables
+		},
+		IntervalInMilliseconds: 60 * 60 * 1000,
+	})
+
+	go crawlSvc.Start()
+	go func() {
+		for range crawlSvc.GetEventChannel() {
+		}
+	}()
+
+	crawlSvc.SetInterval(100)
+
+	select {
+	case <-cycles:
+	case <-time.After(5 * time.Second):
+		t.Fatal("new interval not applied")
+	}
+	crawlSvc.Stop()
+}
+
+func TestRemoveObservable(t *testing.T) {
+	crawlSvc := NewService(Opts{
+		ExplorerSvc: MockExplorer{},
+		Observables: []Observable{
+			&AddressObservable{AccountIndex: 1, Address: "1"},
+			&TransactionObservable{TxID: "1"},
+		},
+		ErrorHandler:           func(err error) {},
+		IntervalInMilliseconds: 500,
+	})
+
+	crawlSvc.RemoveObservable(&TransactionObservable{TxID: "1"})
+	if !crawlSvc.IsObservingAddresses([]string{"1"}) {
+		t.Fatal("address observable must not be removed with a transaction one")
+	}
+
+	crawlSvc.AddObservable(&TransactionObservable{TxID: "2"})
+	crawlSvc.RemoveObservable(&AddressObservable{AccountIndex: 1, Address: "1"})
+	observables := crawlSvc.(*utxoCrawler).getObservable()
+	if len(observables) != 1 {
+		t.Fatalf("expected 1 observable, got %d", len(observables))
+	}
+	if o, ok := observables[0].(*TransactionObservable); !ok || o.TxID != "2" {
+		t.Fatal("transaction observable must not be removed with an address one")
+	}
+}
+
+func TestTransactionObservableEvents(t *testing.T) {
+	tests := []struct {
+		txID              string
+		expectedEventType EventType
+	}{
+		{"4", TransactionConfirmed},
+		{"7", TransactionUnConfirmed},
+		{"notfound", TransactionNotFound},
+	}
+
+	for _, tt := range tests {
+		var wg sync.WaitGroup
+		errChan := make(chan error, 1)
+		eventChan := make(chan Event, 1)
+
+		wg.Add(1)
+		observable := &TransactionObservable{TxID: tt.txID}
+		observable.observe(&wg, MockExplorer{}, errChan, eventChan)
+
+		event := (<-eventChan).(TransactionEvent)
+		if event.Type() != tt.expectedEventType {
+			t.Fatalf(
+				"expected event %s for tx %s, got %s",
+				tt.expectedEventType, tt.txID, event.Type(),
+			)
+		}
+	}
+}
+
 func stopCrawlerAfterTimeout(crawler Service) {
 	time.Sleep(7 * time.Second)
 	crawler.Stop()
@@ -138,7 +257,10 @@ func (m MockExplorer) GetUnspents(addr string, blindKeys [][]byte) (
 }
 
 func (m MockExplorer) GetTransactionHex(txID string) (string, error) {
-	return "", errors.New("implement me")
+	if txID == "notfound" {
+		return "", explorer.ErrTransactionNotFound
+	}
+	return "", nil
 }
 func (m MockExplorer) GetTransactionsForAddress(addr string) ([]explorer.Transaction, error) {
 	return nil, errors.New("implement me")
@@ -146,6 +268,9 @@ func (m MockExplorer) GetTransactionsForAddress(addr string) ([]explorer.Transac
 func (m MockExplorer) BroadcastTransaction(txHex string) (string, error) {
 	return "", errors.New("implement me")
 }
+func (m MockExplorer) GetSpendingTransactionID(txID string, vout uint32) (string, error) {
+	return "", errors.New("implement me")
+}
 func (m MockExplorer) Faucet(addr string) (string, error) {
 	return "", errors.New("implement me")
 }
@@ -220,3 +345,15 @@ func (m MockUtxo) Parse() (*transaction.TxInput, *transaction.TxOutput, error) {
 func (m MockUtxo) IsConfirmed() bool {
 	panic("implement me")
 }
+
+func (m MockUtxo) BlockHeight() uint64 {
+	panic("implement me")
+}
+
+func (m MockUtxo) BlockHash() string {
+	panic("implement me")
+}
+
+func (m MockUtxo) BlockTime() uint64 {
+	panic("implement me")
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 )
 
+// ErrTransactionNotFound is returned when the explorer does not know the
+// requested transaction, ie. it's neither in mempool nor in the blockchain
+var ErrTransactionNotFound = errors.New("transaction not found")
+
 type Service interface {
 	GetUnspents(addr string, blindKeys [][]byte) ([]Utxo, error)
 	GetTransactionHex(hash string) (string, error)
@@ -12,6 +16,7 @@ type Service interface {
 	GetTransactionStatus(txID string) (map[string]interface{}, error)
 	GetTransactionsForAddress(address string) ([]Transaction, error)
 	BroadcastTransaction(txHex string) (string, error)
+	GetSpendingTransactionID(txID string, vout uint32) (string, error)
 	// regtest only
 	Faucet(address string) (string, error)
 	Mint(address string, amount int) (string, string, error)
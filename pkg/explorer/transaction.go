@@ -20,6 +20,9 @@ func (e *explorer) GetTransactionHex(hash string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if status == http.StatusNotFound {
+		return "", ErrTransactionNotFound
+	}
 	if status != http.StatusOK {
 		return "", fmt.Errorf(resp)
 	}
@@ -53,7 +56,7 @@ func (e *explorer) GetTransactionStatus(txID string) (map[string]interface{}, er
 		return nil, err
 	}
 	if status != http.StatusOK {
-		return nil, err
+		return nil, fmt.Errorf(resp)
 	}
 
 	var trxStatus map[string]interface{}
@@ -100,6 +103,37 @@ func (e *explorer) BroadcastTransaction(txHex string) (string, error) {
 	return resp, nil
 }
 
+// GetSpendingTransactionID returns the hash of the transaction spending the
+// given output, or an empty string if it's still unspent
+func (e *explorer) GetSpendingTransactionID(
+	txID string,
+	vout uint32,
+) (string, error) {
+	url := fmt.Sprintf("%s/tx/%s/outspend/%d", e.apiUrl, txID, vout)
+	status, resp, err := httputil.NewHTTPRequest("GET", url, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusNotFound {
+		return "", ErrTransactionNotFound
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf(resp)
+	}
+
+	var outspend struct {
+		Spent bool   `json:"spent"`
+		TxID  string `json:"txid"`
+	}
+	if err := json.Unmarshal([]byte(resp), &outspend); err != nil {
+		return "", err
+	}
+	if !outspend.Spent {
+		return "", nil
+	}
+	return outspend.TxID, nil
+}
+
 func (e *explorer) Faucet(address string) (string, error) {
 	url := fmt.Sprintf("%s/faucet", e.apiUrl)
 	payload := map[string]string{"address": address}
@@ -23,6 +23,9 @@ type Utxo interface {
 	SurjectionProof() []byte
 	IsConfidential() bool
 	IsConfirmed() bool
+	BlockHeight() uint64
+	BlockHash() string
+	BlockTime() uint64
 	SetScript(script []byte)
 	SetUnconfidential(asset string, value uint64)
 	SetConfidential(nonce, rangeProof, surjectionProof []byte)
@@ -69,6 +72,8 @@ func NewWitnessUtxo(
 	valueCommitment, assetCommitment string,
 	script, nonce, rangeProof, surjectionProof []byte,
 	confirmed bool,
+	blockHeight uint64,
+	blockHash string,
 ) Utxo {
 	return witnessUtxo{
 		UHash:            hash,
@@ -81,12 +86,19 @@ func NewWitnessUtxo(
 		UNonce:           nonce,
 		URangeProof:      rangeProof,
 		USurjectionProof: surjectionProof,
-		UStatus:          status{Confirmed: confirmed},
+		UStatus: status{
+			Confirmed:   confirmed,
+			BlockHeight: blockHeight,
+			BlockHash:   blockHash,
+		},
 	}
 }
 
 type status struct {
-	Confirmed bool `json:"confirmed"`
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   uint64 `json:"block_time"`
 }
 
 type witnessUtxo struct {
@@ -151,6 +163,18 @@ func (wu witnessUtxo) IsConfirmed() bool {
 	return wu.UStatus.Confirmed
 }
 
+func (wu witnessUtxo) BlockHeight() uint64 {
+	return wu.UStatus.BlockHeight
+}
+
+func (wu witnessUtxo) BlockHash() string {
+	return wu.UStatus.BlockHash
+}
+
+func (wu witnessUtxo) BlockTime() uint64 {
+	return wu.UStatus.BlockTime
+}
+
 func (wu witnessUtxo) SetScript(script []byte) {
 	wu.UScript = script
 }
@@ -272,7 +296,12 @@ func (e *explorer) getUtxoDetails(
 		unspent.USurjectionProof = prevout.SurjectionProof
 	}
 	unspent.UScript = prevout.Script
-	unspent.UStatus = status{Confirmed: confirmed}
+	// the block info returned along with the utxo is kept only if the
+	// transaction is still confirmed
+	if !confirmed {
+		unspent.UStatus = status{}
+	}
+	unspent.UStatus.Confirmed = confirmed
 
 	chUnspents <- unspent
 }
@@ -0,0 +1,142 @@
+package formula
+
+import (
+	"errors"
+	"math"
+
+	"github.com/shopspring/decimal"
+	"github.com/tdex-network/tdex-daemon/pkg/marketmaking"
+	"github.com/tdex-network/tdex-daemon/pkg/mathutil"
+)
+
+const (
+	UnbalancedReservesType = 2
+)
+
+var (
+	// ErrInvalidWeights ...
+	ErrInvalidWeights = errors.New("reserve weights must be greater than zero")
+)
+
+// UnbalancedReserves defines an AMM strategy whose reserves are weighted
+// with the target ratio set by the operator, instead of the fixed 50/50.
+// The price depends on both the current ratio of the reserves and the target
+// one: the more the reserves drift from the target, the more the price
+// rewards the trades that bring them back.
+type UnbalancedReserves struct{}
+
+// SpotPrice calculates the spot price (without fees) given the balances and
+// the weights of the two reserves:
+//
+//	SpotPrice = (BalanceOut / WeightOut) / (BalanceIn / WeightIn)
+func (UnbalancedReserves) SpotPrice(opts *marketmaking.FormulaOpts) (spotPrice decimal.Decimal, err error) {
+	if err = validateWeights(opts); err != nil {
+		return
+	}
+	if opts.BalanceIn == 0 || opts.BalanceOut == 0 {
+		err = ErrBalanceTooLow
+		return
+	}
+
+	numer := mathutil.Div(opts.BalanceOut, opts.WeightOut)
+	denom := mathutil.Div(opts.BalanceIn, opts.WeightIn)
+	spotPrice = mathutil.DivDecimal(numer, denom)
+	return
+}
+
+// OutGivenIn returns the amountOut of asset that will be exchanged for the
+// given amountIn:
+//
+//	AmountOut = BalanceOut * (1 - (BalanceIn / (BalanceIn + AmountIn))^(WeightIn / WeightOut))
+func (UnbalancedReserves) OutGivenIn(opts *marketmaking.FormulaOpts, amountIn uint64) (amountOut uint64, err error) {
+	if amountIn == 0 {
+		err = ErrAmountTooLow
+		return
+	}
+	if err = validateWeights(opts); err != nil {
+		return
+	}
+	if opts.BalanceIn == 0 || opts.BalanceOut == 0 {
+		err = ErrBalanceTooLow
+		return
+	}
+
+	factor := powRatio(
+		opts.BalanceIn, opts.BalanceIn+amountIn, opts.WeightIn, opts.WeightOut,
+	)
+	// the next balance is rounded up not to pay more than due
+	nextOutBalance := decimal.NewFromInt(int64(opts.BalanceOut)).
+		Mul(factor).Ceil().BigInt().Uint64()
+	if nextOutBalance >= opts.BalanceOut {
+		err = ErrAmountTooLow
+		return
+	}
+	amountOutWithoutFees := opts.BalanceOut - nextOutBalance
+
+	if opts.ChargeFeeOnTheWayIn {
+		amountOut, _ = mathutil.LessFee(amountOutWithoutFees, opts.Fee)
+	} else {
+		amountOut, _ = mathutil.PlusFee(amountOutWithoutFees, opts.Fee)
+	}
+
+	return
+}
+
+// InGivenOut returns the amountIn of assets that will be needed for having
+// the desired amountOut in return:
+//
+//	AmountIn = BalanceIn * ((BalanceOut / (BalanceOut - AmountOut))^(WeightOut / WeightIn) - 1)
+func (UnbalancedReserves) InGivenOut(opts *marketmaking.FormulaOpts, amountOut uint64) (amountIn uint64, err error) {
+	if amountOut == 0 {
+		err = ErrAmountTooLow
+		return
+	}
+	if amountOut >= opts.BalanceOut {
+		err = ErrAmountTooBig
+		return
+	}
+	if err = validateWeights(opts); err != nil {
+		return
+	}
+	if opts.BalanceIn == 0 || opts.BalanceOut == 0 {
+		err = ErrBalanceTooLow
+		return
+	}
+
+	factor := powRatio(
+		opts.BalanceOut, opts.BalanceOut-amountOut, opts.WeightOut, opts.WeightIn,
+	)
+	// the next balance is rounded up not to receive less than due
+	nextInBalance := decimal.NewFromInt(int64(opts.BalanceIn)).
+		Mul(factor).Ceil().BigInt().Uint64()
+	amountInWithoutFees := nextInBalance - opts.BalanceIn
+
+	if opts.ChargeFeeOnTheWayIn {
+		amountIn, _ = mathutil.PlusFee(amountInWithoutFees, opts.Fee)
+	} else {
+		amountIn, _ = mathutil.LessFee(amountInWithoutFees, opts.Fee)
+	}
+
+	return
+}
+
+func (UnbalancedReserves) FormulaType() int {
+	return UnbalancedReservesType
+}
+
+func validateWeights(opts *marketmaking.FormulaOpts) error {
+	if opts.WeightIn == 0 || opts.WeightOut == 0 {
+		return ErrInvalidWeights
+	}
+	return nil
+}
+
+// powRatio returns (x / y)^(wx / wy). Decimal supports only integer
+// exponents and divides with a precision too low for ratios close to 1,
+// therefore the power is calculated with float64 precision, that is enough
+// for amounts in satoshis.
+func powRatio(x, y, wx, wy uint64) decimal.Decimal {
+	return decimal.NewFromFloat(
+		math.Pow(float64(x)/float64(y), float64(wx)/float64(wy)),
+	)
+}
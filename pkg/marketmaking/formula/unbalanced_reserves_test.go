@@ -0,0 +1,317 @@
+package formula
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tdex-network/tdex-daemon/pkg/marketmaking"
+	"github.com/tdex-network/tdex-daemon/pkg/mathutil"
+)
+
+func TestUnbalancedReserves_SpotPrice(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          *marketmaking.FormulaOpts
+		wantSpotPrice decimal.Decimal
+	}{
+		{
+			"SpotPrice with even weights",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  2 * mathutil.BigOne,
+				BalanceOut: 2 * 9760 * mathutil.BigOne,
+				WeightIn:   50,
+				WeightOut:  50,
+			},
+			decimal.NewFromInt(9760),
+		},
+		{
+			"SpotPrice with reserves matching the target ratio",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  2 * mathutil.BigOne,
+				BalanceOut: 8 * 9760 * mathutil.BigOne,
+				WeightIn:   20,
+				WeightOut:  80,
+			},
+			decimal.NewFromInt(9760),
+		},
+		{
+			"SpotPrice with reserves short of the asset out",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  2 * mathutil.BigOne,
+				BalanceOut: 2 * 9760 * mathutil.BigOne,
+				WeightIn:   20,
+				WeightOut:  80,
+			},
+			decimal.NewFromInt(2440),
+		},
+	}
+
+	u := UnbalancedReserves{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSpotPrice, err := u.SpotPrice(tt.opts)
+			require.NoError(t, err)
+			assert.True(
+				t,
+				tt.wantSpotPrice.Equal(gotSpotPrice),
+				"expected %s, got %s", tt.wantSpotPrice, gotSpotPrice,
+			)
+		})
+	}
+
+	// with even weights it prices like the balanced reserves
+	opts := tests[0].opts
+	balancedSpotPrice, err := BalancedReserves{}.SpotPrice(opts)
+	require.NoError(t, err)
+	gotSpotPrice, err := u.SpotPrice(opts)
+	require.NoError(t, err)
+	assert.True(t, balancedSpotPrice.Equal(gotSpotPrice))
+}
+
+func TestUnbalancedReserves_OutGivenIn(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          *marketmaking.FormulaOpts
+		amountIn      uint64
+		wantAmountOut uint64
+	}{
+		{
+			"OutGivenIn with even weights",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  100000000,
+				BalanceOut: 650000000000,
+				WeightIn:   50,
+				WeightOut:  50,
+			},
+			10000,
+			64993500,
+		},
+		{
+			"OutGivenIn with the target ratio favouring the asset in",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  100000000,
+				BalanceOut: 650000000000,
+				WeightIn:   70,
+				WeightOut:  30,
+			},
+			10000,
+			151641392,
+		},
+		{
+			"OutGivenIn with the target ratio favouring the asset out",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  100000000,
+				BalanceOut: 650000000000,
+				WeightIn:   30,
+				WeightOut:  70,
+			},
+			10000,
+			27855153,
+		},
+	}
+
+	u := UnbalancedReserves{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAmountOut, err := u.OutGivenIn(tt.opts, tt.amountIn)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAmountOut, gotAmountOut)
+		})
+	}
+
+	// with even weights, amounts differ from those of the balanced reserves
+	// at most for rounding
+	opts := &marketmaking.FormulaOpts{
+		BalanceIn:           100000000,
+		BalanceOut:          650000000000,
+		WeightIn:            50,
+		WeightOut:           50,
+		Fee:                 25,
+		ChargeFeeOnTheWayIn: true,
+	}
+	balancedAmountOut, err := BalancedReserves{}.OutGivenIn(opts, 10000)
+	require.NoError(t, err)
+	gotAmountOut, err := u.OutGivenIn(opts, 10000)
+	require.NoError(t, err)
+	assert.InDelta(t, balancedAmountOut, gotAmountOut, 1)
+
+	failingTests := []struct {
+		name      string
+		opts      *marketmaking.FormulaOpts
+		amountIn  uint64
+		wantError error
+	}{
+		{
+			"OutGivenIn fails if provided amount is 0",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  100000000,
+				BalanceOut: 650000000000,
+				WeightIn:   70,
+				WeightOut:  30,
+			},
+			0,
+			ErrAmountTooLow,
+		},
+		{
+			"OutGivenIn fails if weights are not set",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  100000000,
+				BalanceOut: 650000000000,
+			},
+			10000,
+			ErrInvalidWeights,
+		},
+		{
+			"OutGivenIn fails if a reserve is empty",
+			&marketmaking.FormulaOpts{
+				BalanceIn: 100000000,
+				WeightIn:  70,
+				WeightOut: 30,
+			},
+			10000,
+			ErrBalanceTooLow,
+		},
+	}
+
+	for _, tt := range failingTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.OutGivenIn(tt.opts, tt.amountIn)
+			assert.Equal(t, tt.wantError, err)
+		})
+	}
+}
+
+func TestUnbalancedReserves_InGivenOut(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         *marketmaking.FormulaOpts
+		amountOut    uint64
+		wantAmountIn uint64
+	}{
+		{
+			"InGivenOut with the target ratio favouring the asset in",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  650000000000,
+				BalanceOut: 100000000,
+				WeightIn:   70,
+				WeightOut:  30,
+			},
+			10000,
+			27859133,
+		},
+		{
+			"InGivenOut with the target ratio favouring the asset out",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  650000000000,
+				BalanceOut: 100000000,
+				WeightIn:   30,
+				WeightOut:  70,
+			},
+			10000,
+			151691949,
+		},
+	}
+
+	u := UnbalancedReserves{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAmountIn, err := u.InGivenOut(tt.opts, tt.amountOut)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAmountIn, gotAmountIn)
+		})
+	}
+
+	failingTests := []struct {
+		name      string
+		opts      *marketmaking.FormulaOpts
+		amountOut uint64
+		wantError error
+	}{
+		{
+			"InGivenOut fails if provided amount is 0",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  650000000000,
+				BalanceOut: 100000000,
+				WeightIn:   70,
+				WeightOut:  30,
+			},
+			0,
+			ErrAmountTooLow,
+		},
+		{
+			"InGivenOut fails if provided amount is equal or exceeds the balance",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  650000000000,
+				BalanceOut: 100000000,
+				WeightIn:   70,
+				WeightOut:  30,
+			},
+			100000000,
+			ErrAmountTooBig,
+		},
+		{
+			"InGivenOut fails if weights are not set",
+			&marketmaking.FormulaOpts{
+				BalanceIn:  650000000000,
+				BalanceOut: 100000000,
+			},
+			10000,
+			ErrInvalidWeights,
+		},
+	}
+
+	for _, tt := range failingTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.InGivenOut(tt.opts, tt.amountOut)
+			assert.Equal(t, tt.wantError, err)
+		})
+	}
+}
+
+// TestUnbalancedReserves_Symmetry checks that OutGivenIn and InGivenOut are
+// one the inverse of the other: the amount in required for the amount out
+// returned for a given amount in never exceeds the latter, and differs from
+// it at most for the value of a unit of the asset out.
+func TestUnbalancedReserves_Symmetry(t *testing.T) {
+	balances := []struct{ in, out uint64 }{
+		{100000000, 650000000000},
+		{650000000000, 100000000},
+		{2 * mathutil.BigOne, 3 * mathutil.BigOne},
+	}
+	weights := []struct{ in, out uint64 }{
+		{50, 50}, {70, 30}, {30, 70}, {90, 10}, {1, 99},
+	}
+	amounts := []uint64{10000, 1000000, 10000000}
+
+	u := UnbalancedReserves{}
+	for _, b := range balances {
+		for _, w := range weights {
+			opts := &marketmaking.FormulaOpts{
+				BalanceIn:  b.in,
+				BalanceOut: b.out,
+				WeightIn:   w.in,
+				WeightOut:  w.out,
+			}
+			spotPrice, err := u.SpotPrice(opts)
+			require.NoError(t, err)
+			// the value in asset in of a unit of asset out
+			unitValue, _ := decimal.NewFromInt(1).Div(spotPrice).Float64()
+			delta := unitValue + 1
+
+			for _, amountIn := range amounts {
+				amountOut, err := u.OutGivenIn(opts, amountIn)
+				if err == ErrAmountTooLow {
+					continue
+				}
+				require.NoError(t, err)
+
+				gotAmountIn, err := u.InGivenOut(opts, amountOut)
+				require.NoError(t, err)
+				assert.LessOrEqual(t, gotAmountIn, amountIn)
+				assert.InDelta(t, amountIn, gotAmountIn, delta)
+			}
+		}
+	}
+}
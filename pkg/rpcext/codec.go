@@ -0,0 +1,40 @@
+// Package rpcext defines the gRPC services that extend the tdex-protobuf API
+// with daemon specific features. Messages are plain Go structs serialized
+// with a JSON codec; the clients of this package take care of selecting it,
+// while any other client must call the services with the CallOption returned
+// by JSONCodec.
+package rpcext
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the name of the codec used to serialize messages of the
+// extension services. It is also the content-subtype of the gRPC requests.
+const CodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+// JSONCodec returns the CallOption to use for calling the extension services
+func JSONCodec() grpc.CallOption {
+	return grpc.CallContentSubtype(CodecName)
+}
@@ -0,0 +1,225 @@
+package rpcext
+
+// ExportRequest is the request message of the Export RPC. Times are unix
+// timestamps in seconds, both ends included. A zero ToTimeUnix means up to now.
+type ExportRequest struct {
+	FromTimeUnix uint64 `json:"from_time_unix"`
+	ToTimeUnix   uint64 `json:"to_time_unix"`
+}
+
+// ExportReply is the message streamed by the Export RPC, one for every
+// exported record.
+type ExportReply struct {
+	Record *ExportRecord `json:"record"`
+}
+
+// ExportRecord describes a completed trade, a deposit or a withdrawal from
+// the point of view of the provider: the "in" asset and amount are the funds
+// received, the "out" ones those sent away.
+type ExportRecord struct {
+	Kind         string `json:"kind"`
+	ID           string `json:"id"`
+	TimeUnix     uint64 `json:"time_unix"`
+	AccountIndex int    `json:"account_index"`
+	BaseAsset    string `json:"base_asset"`
+	QuoteAsset   string `json:"quote_asset"`
+	AssetIn      string `json:"asset_in"`
+	AmountIn     uint64 `json:"amount_in"`
+	AssetOut     string `json:"asset_out"`
+	AmountOut    uint64 `json:"amount_out"`
+	// Price is the executed price of a trade expressed as amount of quote
+	// asset per unit of base asset
+	Price            string `json:"price"`
+	FeeAsset         string `json:"fee_asset"`
+	FeeAmount        uint64 `json:"fee_amount"`
+	TxID             string `json:"txid"`
+	RequestTimeUnix  uint64 `json:"request_time_unix"`
+	AcceptTimeUnix   uint64 `json:"accept_time_unix"`
+	CompleteTimeUnix uint64 `json:"complete_time_unix"`
+}
+
+// MarketReportRequest is the request message of the MarketReport RPC. Times
+// are unix timestamps in seconds, both ends included. A zero ToTimeUnix means
+// up to now.
+type MarketReportRequest struct {
+	BaseAsset    string `json:"base_asset"`
+	QuoteAsset   string `json:"quote_asset"`
+	FromTimeUnix uint64 `json:"from_time_unix"`
+	ToTimeUnix   uint64 `json:"to_time_unix"`
+}
+
+// MarketReportReply is the response message of the MarketReport RPC.
+type MarketReportReply struct {
+	Report *MarketReport `json:"report"`
+}
+
+// MarketReport summarizes how a market performed in a time range. Balances
+// are amounts of base and quote asset, while values are amounts of base
+// asset calculated with the price at the end of the range (current price for
+// the inventory).
+type MarketReport struct {
+	BaseAsset      string   `json:"base_asset"`
+	QuoteAsset     string   `json:"quote_asset"`
+	FromTimeUnix   uint64   `json:"from_time_unix"`
+	ToTimeUnix     uint64   `json:"to_time_unix"`
+	StartBalance   *Balance `json:"start_balance"`
+	EndBalance     *Balance `json:"end_balance"`
+	CurrentBalance *Balance `json:"current_balance"`
+	StartPrice     *Price   `json:"start_price"`
+	EndPrice       *Price   `json:"end_price"`
+	CurrentPrice   *Price   `json:"current_price"`
+	TradesCount    int      `json:"trades_count"`
+	Volume         *Balance `json:"volume"`
+	CollectedFees  *Balance `json:"collected_fees"`
+	// CollectedFeesValue is the value of the collected fees
+	CollectedFeesValue int64 `json:"collected_fees_value"`
+	// HoldValue is the value of the starting reserves if they had been just held
+	HoldValue int64 `json:"hold_value"`
+	// TradedValue is the value of the starting reserves updated with the
+	// trades of the period, fees included
+	TradedValue int64 `json:"traded_value"`
+	// ImpermanentLoss is the value lost with respect to holding the starting
+	// reserves because of the change in price, fees excluded
+	ImpermanentLoss int64 `json:"impermanent_loss"`
+	// InventoryValue is the value of the current reserves at current price
+	InventoryValue int64 `json:"inventory_value"`
+}
+
+// Balance is a couple of base and quote asset amounts. They may be negative
+// for reserves reconstructed from an incomplete history.
+type Balance struct {
+	BaseAmount  int64 `json:"base_amount"`
+	QuoteAmount int64 `json:"quote_amount"`
+}
+
+// Price holds the price of the base asset in quote asset (quote_price) and
+// vice versa (base_price), as decimal strings.
+type Price struct {
+	BasePrice  string `json:"base_price"`
+	QuotePrice string `json:"quote_price"`
+}
+
+// AddWithdrawalAddressRequest is the request message of the
+// AddWithdrawalAddress RPC. The passphrase of the wallet is required to
+// whitelist a new destination.
+type AddWithdrawalAddressRequest struct {
+	Address    string `json:"address"`
+	Label      string `json:"label"`
+	Passphrase string `json:"passphrase"`
+}
+
+// AddWithdrawalAddressReply is the response message of the
+// AddWithdrawalAddress RPC.
+type AddWithdrawalAddressReply struct {
+	WithdrawalAddress *WithdrawalAddress `json:"withdrawal_address"`
+}
+
+// RemoveWithdrawalAddressRequest is the request message of the
+// RemoveWithdrawalAddress RPC.
+type RemoveWithdrawalAddressRequest struct {
+	Address string `json:"address"`
+}
+
+// RemoveWithdrawalAddressReply is the response message of the
+// RemoveWithdrawalAddress RPC.
+type RemoveWithdrawalAddressReply struct{}
+
+// ListWithdrawalAddressesRequest is the request message of the
+// ListWithdrawalAddresses RPC.
+type ListWithdrawalAddressesRequest struct{}
+
+// ListWithdrawalAddressesReply is the response message of the
+// ListWithdrawalAddresses RPC.
+type ListWithdrawalAddressesReply struct {
+	WithdrawalAddresses []*WithdrawalAddress `json:"withdrawal_addresses"`
+}
+
+// WithdrawalAddress is a whitelisted destination of withdrawals. It can be
+// used only from ActiveFromUnix on, once its cool-down period is over.
+type WithdrawalAddress struct {
+	Address        string `json:"address"`
+	Label          string `json:"label"`
+	CreatedAtUnix  uint64 `json:"created_at_unix"`
+	ActiveFromUnix uint64 `json:"active_from_unix"`
+}
+
+// ListPaymentsRequest is the request message of the ListPayments RPC.
+type ListPaymentsRequest struct{}
+
+// ListPaymentsReply is the response message of the ListPayments RPC.
+type ListPaymentsReply struct {
+	Payments []*Payment `json:"payments"`
+}
+
+// Payment is the audit record of a transaction, crafted by the daemon,
+// sending funds to outputs external to the wallet.
+type Payment struct {
+	TxID            string           `json:"txid"`
+	AccountIndex    int              `json:"account_index"`
+	Outputs         []*PaymentOutput `json:"outputs"`
+	MillisatPerByte int64            `json:"millisat_per_byte"`
+	Broadcasted     bool             `json:"broadcasted"`
+	TimeUnix        uint64           `json:"time_unix"`
+}
+
+// PaymentOutput is an output of a payment.
+type PaymentOutput struct {
+	Asset   string `json:"asset"`
+	Amount  uint64 `json:"amount"`
+	Address string `json:"address"`
+}
+
+// ListAuditEntriesRequest is the request message of the ListAuditEntries RPC.
+// Times are unix timestamps in seconds, both ends included. A zero ToTimeUnix
+// means up to now.
+type ListAuditEntriesRequest struct {
+	FromTimeUnix uint64 `json:"from_time_unix"`
+	ToTimeUnix   uint64 `json:"to_time_unix"`
+}
+
+// ListAuditEntriesReply is the response message of the ListAuditEntries RPC.
+type ListAuditEntriesReply struct {
+	Entries []*AuditEntry `json:"entries"`
+}
+
+// AuditEntry is the record of a call made on the operator interface. Params
+// is the JSON encoded request with secrets redacted, Result the gRPC status
+// code of the response. Every entry commits to the hash of the previous one.
+type AuditEntry struct {
+	Index    uint64 `json:"index"`
+	TimeUnix uint64 `json:"time_unix"`
+	Method   string `json:"method"`
+	Caller   string `json:"caller"`
+	Params   string `json:"params"`
+	Result   string `json:"result"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// VerifyAuditLogRequest is the request message of the VerifyAuditLog RPC.
+type VerifyAuditLogRequest struct{}
+
+// VerifyAuditLogReply is the response message of the VerifyAuditLog RPC.
+// LastHash commits to the whole log and can be stored elsewhere to detect a
+// later truncation. FirstInvalidIndex and Reason are set only if not valid.
+type VerifyAuditLogReply struct {
+	Valid             bool   `json:"valid"`
+	EntriesCount      uint64 `json:"entries_count"`
+	LastHash          string `json:"last_hash"`
+	FirstInvalidIndex uint64 `json:"first_invalid_index,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// UpdateMarketStrategyUnbalancedRequest is the request message of the
+// UpdateMarketStrategyUnbalanced RPC. BaseAssetWeight is the target share, in
+// percentage, of the value of the reserves held in base asset.
+type UpdateMarketStrategyUnbalancedRequest struct {
+	BaseAsset       string `json:"base_asset"`
+	QuoteAsset      string `json:"quote_asset"`
+	BaseAssetWeight uint64 `json:"base_asset_weight"`
+}
+
+// UpdateMarketStrategyUnbalancedReply is the response message of the
+// UpdateMarketStrategyUnbalanced RPC.
+type UpdateMarketStrategyUnbalancedReply struct{}
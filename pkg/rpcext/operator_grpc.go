@@ -0,0 +1,434 @@
+package rpcext
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// OperatorExtensionClient is the client API for OperatorExtension service.
+type OperatorExtensionClient interface {
+	// Export streams the completed trades, the deposits and the withdrawals
+	// for the given time range, one record at a time.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (OperatorExtension_ExportClient, error)
+	// MarketReport returns reserves, volume, fees and impermanent loss of a
+	// market for the given time range.
+	MarketReport(ctx context.Context, in *MarketReportRequest, opts ...grpc.CallOption) (*MarketReportReply, error)
+	// AddWithdrawalAddress whitelists a destination of withdrawals. It requires
+	// the wallet passphrase and the address is usable only after a cool-down.
+	AddWithdrawalAddress(ctx context.Context, in *AddWithdrawalAddressRequest, opts ...grpc.CallOption) (*AddWithdrawalAddressReply, error)
+	// RemoveWithdrawalAddress removes a destination from the whitelist.
+	RemoveWithdrawalAddress(ctx context.Context, in *RemoveWithdrawalAddressRequest, opts ...grpc.CallOption) (*RemoveWithdrawalAddressReply, error)
+	// ListWithdrawalAddresses returns the whitelisted destinations.
+	ListWithdrawalAddresses(ctx context.Context, in *ListWithdrawalAddressesRequest, opts ...grpc.CallOption) (*ListWithdrawalAddressesReply, error)
+	// ListPayments returns the audit records of every outgoing payment.
+	ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsReply, error)
+	// ListAuditEntries returns the entries of the audit log of the calls made
+	// on the operator interface for the given time range.
+	ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesReply, error)
+	// VerifyAuditLog checks the hash chain of the audit log.
+	VerifyAuditLog(ctx context.Context, in *VerifyAuditLogRequest, opts ...grpc.CallOption) (*VerifyAuditLogReply, error)
+	// UpdateMarketStrategyUnbalanced makes a closed market use the unbalanced
+	// reserves strategy with the given target ratio.
+	UpdateMarketStrategyUnbalanced(ctx context.Context, in *UpdateMarketStrategyUnbalancedRequest, opts ...grpc.CallOption) (*UpdateMarketStrategyUnbalancedReply, error)
+}
+
+type operatorExtensionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewOperatorExtensionClient returns a client for the OperatorExtension
+// service that uses the JSON codec for every call.
+func NewOperatorExtensionClient(cc grpc.ClientConnInterface) OperatorExtensionClient {
+	return &operatorExtensionClient{cc}
+}
+
+func (c *operatorExtensionClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (OperatorExtension_ExportClient, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &_OperatorExtension_serviceDesc.Streams[0], "/OperatorExtension/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &operatorExtensionExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (c *operatorExtensionClient) MarketReport(ctx context.Context, in *MarketReportRequest, opts ...grpc.CallOption) (*MarketReportReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(MarketReportReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/MarketReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) AddWithdrawalAddress(ctx context.Context, in *AddWithdrawalAddressRequest, opts ...grpc.CallOption) (*AddWithdrawalAddressReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(AddWithdrawalAddressReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/AddWithdrawalAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) RemoveWithdrawalAddress(ctx context.Context, in *RemoveWithdrawalAddressRequest, opts ...grpc.CallOption) (*RemoveWithdrawalAddressReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(RemoveWithdrawalAddressReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/RemoveWithdrawalAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) ListWithdrawalAddresses(ctx context.Context, in *ListWithdrawalAddressesRequest, opts ...grpc.CallOption) (*ListWithdrawalAddressesReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(ListWithdrawalAddressesReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/ListWithdrawalAddresses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(ListPaymentsReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/ListPayments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(ListAuditEntriesReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/ListAuditEntries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) VerifyAuditLog(ctx context.Context, in *VerifyAuditLogRequest, opts ...grpc.CallOption) (*VerifyAuditLogReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(VerifyAuditLogReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/VerifyAuditLog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorExtensionClient) UpdateMarketStrategyUnbalanced(ctx context.Context, in *UpdateMarketStrategyUnbalancedRequest, opts ...grpc.CallOption) (*UpdateMarketStrategyUnbalancedReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(UpdateMarketStrategyUnbalancedReply)
+	err := c.cc.Invoke(ctx, "/OperatorExtension/UpdateMarketStrategyUnbalanced", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type OperatorExtension_ExportClient interface {
+	Recv() (*ExportReply, error)
+	grpc.ClientStream
+}
+
+type operatorExtensionExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *operatorExtensionExportClient) Recv() (*ExportReply, error) {
+	m := new(ExportReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// OperatorExtensionServer is the server API for OperatorExtension service.
+type OperatorExtensionServer interface {
+	// Export streams the completed trades, the deposits and the withdrawals
+	// for the given time range, one record at a time.
+	Export(*ExportRequest, OperatorExtension_ExportServer) error
+	// MarketReport returns reserves, volume, fees and impermanent loss of a
+	// market for the given time range.
+	MarketReport(context.Context, *MarketReportRequest) (*MarketReportReply, error)
+	// AddWithdrawalAddress whitelists a destination of withdrawals. It requires
+	// the wallet passphrase and the address is usable only after a cool-down.
+	AddWithdrawalAddress(context.Context, *AddWithdrawalAddressRequest) (*AddWithdrawalAddressReply, error)
+	// RemoveWithdrawalAddress removes a destination from the whitelist.
+	RemoveWithdrawalAddress(context.Context, *RemoveWithdrawalAddressRequest) (*RemoveWithdrawalAddressReply, error)
+	// ListWithdrawalAddresses returns the whitelisted destinations.
+	ListWithdrawalAddresses(context.Context, *ListWithdrawalAddressesRequest) (*ListWithdrawalAddressesReply, error)
+	// ListPayments returns the audit records of every outgoing payment.
+	ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsReply, error)
+	// ListAuditEntries returns the entries of the audit log of the calls made
+	// on the operator interface for the given time range.
+	ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesReply, error)
+	// VerifyAuditLog checks the hash chain of the audit log.
+	VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*VerifyAuditLogReply, error)
+	// UpdateMarketStrategyUnbalanced makes a closed market use the unbalanced
+	// reserves strategy with the given target ratio.
+	UpdateMarketStrategyUnbalanced(context.Context, *UpdateMarketStrategyUnbalancedRequest) (*UpdateMarketStrategyUnbalancedReply, error)
+}
+
+// UnimplementedOperatorExtensionServer can be embedded to have forward compatible implementations.
+type UnimplementedOperatorExtensionServer struct {
+}
+
+func (*UnimplementedOperatorExtensionServer) Export(*ExportRequest, OperatorExtension_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) MarketReport(context.Context, *MarketReportRequest) (*MarketReportReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarketReport not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) AddWithdrawalAddress(context.Context, *AddWithdrawalAddressRequest) (*AddWithdrawalAddressReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddWithdrawalAddress not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) RemoveWithdrawalAddress(context.Context, *RemoveWithdrawalAddressRequest) (*RemoveWithdrawalAddressReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveWithdrawalAddress not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) ListWithdrawalAddresses(context.Context, *ListWithdrawalAddressesRequest) (*ListWithdrawalAddressesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWithdrawalAddresses not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPayments not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEntries not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*VerifyAuditLogReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAuditLog not implemented")
+}
+func (*UnimplementedOperatorExtensionServer) UpdateMarketStrategyUnbalanced(context.Context, *UpdateMarketStrategyUnbalancedRequest) (*UpdateMarketStrategyUnbalancedReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMarketStrategyUnbalanced not implemented")
+}
+
+func RegisterOperatorExtensionServer(s *grpc.Server, srv OperatorExtensionServer) {
+	s.RegisterService(&_OperatorExtension_serviceDesc, srv)
+}
+
+func _OperatorExtension_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OperatorExtensionServer).Export(m, &operatorExtensionExportServer{stream})
+}
+
+type OperatorExtension_ExportServer interface {
+	Send(*ExportReply) error
+	grpc.ServerStream
+}
+
+type operatorExtensionExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *operatorExtensionExportServer) Send(m *ExportReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OperatorExtension_MarketReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarketReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).MarketReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/MarketReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).MarketReport(ctx, req.(*MarketReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_AddWithdrawalAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddWithdrawalAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).AddWithdrawalAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/AddWithdrawalAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).AddWithdrawalAddress(ctx, req.(*AddWithdrawalAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_RemoveWithdrawalAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveWithdrawalAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).RemoveWithdrawalAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/RemoveWithdrawalAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).RemoveWithdrawalAddress(ctx, req.(*RemoveWithdrawalAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_ListWithdrawalAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWithdrawalAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).ListWithdrawalAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/ListWithdrawalAddresses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).ListWithdrawalAddresses(ctx, req.(*ListWithdrawalAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_ListPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).ListPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/ListPayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).ListPayments(ctx, req.(*ListPaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_ListAuditEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).ListAuditEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/ListAuditEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).ListAuditEntries(ctx, req.(*ListAuditEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_VerifyAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).VerifyAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/VerifyAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).VerifyAuditLog(ctx, req.(*VerifyAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OperatorExtension_UpdateMarketStrategyUnbalanced_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMarketStrategyUnbalancedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorExtensionServer).UpdateMarketStrategyUnbalanced(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/OperatorExtension/UpdateMarketStrategyUnbalanced",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorExtensionServer).UpdateMarketStrategyUnbalanced(ctx, req.(*UpdateMarketStrategyUnbalancedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _OperatorExtension_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "OperatorExtension",
+	HandlerType: (*OperatorExtensionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MarketReport",
+			Handler:    _OperatorExtension_MarketReport_Handler,
+		},
+		{
+			MethodName: "AddWithdrawalAddress",
+			Handler:    _OperatorExtension_AddWithdrawalAddress_Handler,
+		},
+		{
+			MethodName: "RemoveWithdrawalAddress",
+			Handler:    _OperatorExtension_RemoveWithdrawalAddress_Handler,
+		},
+		{
+			MethodName: "ListWithdrawalAddresses",
+			Handler:    _OperatorExtension_ListWithdrawalAddresses_Handler,
+		},
+		{
+			MethodName: "ListPayments",
+			Handler:    _OperatorExtension_ListPayments_Handler,
+		},
+		{
+			MethodName: "ListAuditEntries",
+			Handler:    _OperatorExtension_ListAuditEntries_Handler,
+		},
+		{
+			MethodName: "VerifyAuditLog",
+			Handler:    _OperatorExtension_VerifyAuditLog_Handler,
+		},
+		{
+			MethodName: "UpdateMarketStrategyUnbalanced",
+			Handler:    _OperatorExtension_UpdateMarketStrategyUnbalanced_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _OperatorExtension_Export_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rpcext/operator",
+}
@@ -0,0 +1,142 @@
+package rpcext
+
+// IsLockedRequest is the request message of the IsLocked RPC.
+type IsLockedRequest struct{}
+
+// IsLockedReply is the response message of the IsLocked RPC.
+type IsLockedReply struct {
+	Locked bool `json:"locked"`
+}
+
+// UnlockRequest is the request message of the Unlock RPC. The signer
+// decrypts the mnemonic of the HD wallet with Passphrase, and holds in memory
+// the master keys derived from it.
+type UnlockRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// UnlockReply is the response message of the Unlock RPC.
+type UnlockReply struct{}
+
+// ChangePassphraseRequest is the request message of the ChangePassphrase RPC.
+type ChangePassphraseRequest struct {
+	CurrentPassphrase string `json:"current_passphrase"`
+	NewPassphrase     string `json:"new_passphrase"`
+}
+
+// ChangePassphraseReply is the response message of the ChangePassphrase RPC.
+type ChangePassphraseReply struct{}
+
+// IsValidPassphraseRequest is the request message of the IsValidPassphrase
+// RPC.
+type IsValidPassphraseRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// IsValidPassphraseReply is the response message of the IsValidPassphrase
+// RPC.
+type IsValidPassphraseReply struct {
+	Valid bool `json:"valid"`
+}
+
+// LockRequest is the request message of the Lock RPC.
+type LockRequest struct{}
+
+// LockReply is the response message of the Lock RPC.
+type LockReply struct{}
+
+// DeriveConfidentialAddressRequest is the request message of the
+// DeriveConfidentialAddress RPC.
+type DeriveConfidentialAddressRequest struct {
+	DerivationPath string `json:"derivation_path"`
+}
+
+// DeriveConfidentialAddressReply is the response message of the
+// DeriveConfidentialAddress RPC.
+type DeriveConfidentialAddressReply struct {
+	Address     string `json:"address"`
+	Script      []byte `json:"script"`
+	BlindingKey []byte `json:"blinding_key"`
+}
+
+// Utxo is an unspent output of the signer's wallet, either confidential or
+// not.
+type Utxo struct {
+	TxID            string `json:"txid"`
+	VOut            uint32 `json:"vout"`
+	Value           uint64 `json:"value"`
+	Asset           string `json:"asset"`
+	ValueCommitment string `json:"value_commitment"`
+	AssetCommitment string `json:"asset_commitment"`
+	Script          []byte `json:"script"`
+	Nonce           []byte `json:"nonce"`
+	RangeProof      []byte `json:"range_proof"`
+	SurjectionProof []byte `json:"surjection_proof"`
+}
+
+// TxOutput is an output of a transaction in its serialized form.
+type TxOutput struct {
+	Asset           []byte `json:"asset"`
+	Value           []byte `json:"value"`
+	Nonce           []byte `json:"nonce"`
+	Script          []byte `json:"script"`
+	RangeProof      []byte `json:"range_proof"`
+	SurjectionProof []byte `json:"surjection_proof"`
+}
+
+// MarketSwap defines the inputs and outputs that a market adds to a swap
+// transaction: the market pays InputAmount of InputAsset and receives
+// OutputAmount of OutputAsset.
+type MarketSwap struct {
+	Unspents             []Utxo `json:"unspents"`
+	InputAsset           string `json:"input_asset"`
+	InputAmount          uint64 `json:"input_amount"`
+	OutputAsset          string `json:"output_asset"`
+	OutputAmount         uint64 `json:"output_amount"`
+	OutputDerivationPath string `json:"output_derivation_path"`
+	ChangeDerivationPath string `json:"change_derivation_path"`
+}
+
+// AcceptSwapRequest is the request message of the AcceptSwap RPC.
+// SwapRequest is the serialized SwapRequest message to accept, while LegsP
+// and LegsR are set only for multi-asset swaps.
+type AcceptSwapRequest struct {
+	SwapRequest                []byte            `json:"swap_request"`
+	LegsP                      []Leg             `json:"legs_p"`
+	LegsR                      []Leg             `json:"legs_r"`
+	MarketSwaps                []MarketSwap      `json:"market_swaps"`
+	FeeUnspents                []Utxo            `json:"fee_unspents"`
+	MarketBlindingKeysByScript map[string][]byte `json:"market_blinding_keys_by_script"`
+	FeeBlindingKeysByScript    map[string][]byte `json:"fee_blinding_keys_by_script"`
+	OutputBlindingKeysByScript map[string][]byte `json:"output_blinding_keys_by_script"`
+	MarketDerivationPaths      map[string]string `json:"market_derivation_paths"`
+	FeeDerivationPaths         map[string]string `json:"fee_derivation_paths"`
+	FeeChangeDerivationPath    string            `json:"fee_change_derivation_path"`
+}
+
+// AcceptSwapReply is the response message of the AcceptSwap RPC.
+type AcceptSwapReply struct {
+	PsetBase64         string            `json:"pset_base64"`
+	SelectedUnspents   []Utxo            `json:"selected_unspents"`
+	InputBlindingKeys  map[string][]byte `json:"input_blinding_keys"`
+	OutputBlindingKeys map[string][]byte `json:"output_blinding_keys"`
+}
+
+// SendToManyRequest is the request message of the SendToMany RPC.
+type SendToManyRequest struct {
+	Unspents              []Utxo            `json:"unspents"`
+	FeeUnspents           []Utxo            `json:"fee_unspents"`
+	Outputs               []TxOutput        `json:"outputs"`
+	OutputsBlindingKeys   [][]byte          `json:"outputs_blinding_keys"`
+	ChangePathsByAsset    map[string]string `json:"change_paths_by_asset"`
+	FeeChangePathByAsset  map[string]string `json:"fee_change_path_by_asset"`
+	InputPathsByScript    map[string]string `json:"input_paths_by_script"`
+	FeeInputPathsByScript map[string]string `json:"fee_input_paths_by_script"`
+	MilliSatPerByte       int               `json:"milli_sat_per_byte"`
+}
+
+// SendToManyReply is the response message of the SendToMany RPC.
+type SendToManyReply struct {
+	TxHex string `json:"tx_hex"`
+	TxID  string `json:"tx_id"`
+}
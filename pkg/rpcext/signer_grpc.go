@@ -0,0 +1,371 @@
+package rpcext
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// SignerClient is the client API for Signer service.
+type SignerClient interface {
+	// IsLocked returns whether the signer holds the keys of the wallet.
+	IsLocked(ctx context.Context, in *IsLockedRequest, opts ...grpc.CallOption) (*IsLockedReply, error)
+	// Unlock decrypts the mnemonic of the wallet with a passphrase and holds
+	// the keys derived from it in memory until Lock is called.
+	Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockReply, error)
+	// Lock wipes the keys of the wallet from memory.
+	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockReply, error)
+	// ChangePassphrase encrypts the mnemonic of the locked wallet with a new
+	// passphrase.
+	ChangePassphrase(ctx context.Context, in *ChangePassphraseRequest, opts ...grpc.CallOption) (*ChangePassphraseReply, error)
+	// IsValidPassphrase returns whether the mnemonic of the wallet can be
+	// decrypted with a passphrase.
+	IsValidPassphrase(ctx context.Context, in *IsValidPassphraseRequest, opts ...grpc.CallOption) (*IsValidPassphraseReply, error)
+	// DeriveConfidentialAddress returns the confidential address, the
+	// output script and the private blinding key for a derivation path.
+	DeriveConfidentialAddress(ctx context.Context, in *DeriveConfidentialAddressRequest, opts ...grpc.CallOption) (*DeriveConfidentialAddressReply, error)
+	// AcceptSwap adds the inputs and outputs of the daemon's markets to the
+	// transaction of a swap request, then blinds and signs it, if allowed by
+	// the signer policy.
+	AcceptSwap(ctx context.Context, in *AcceptSwapRequest, opts ...grpc.CallOption) (*AcceptSwapReply, error)
+	// SendToMany crafts, blinds and signs a withdrawal transaction, if
+	// allowed by the signer policy.
+	SendToMany(ctx context.Context, in *SendToManyRequest, opts ...grpc.CallOption) (*SendToManyReply, error)
+}
+
+type signerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewSignerClient returns a client for the Signer service that uses the JSON
+// codec for every call.
+func NewSignerClient(cc grpc.ClientConnInterface) SignerClient {
+	return &signerClient{cc}
+}
+
+func (c *signerClient) IsLocked(ctx context.Context, in *IsLockedRequest, opts ...grpc.CallOption) (*IsLockedReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(IsLockedReply)
+	err := c.cc.Invoke(ctx, "/Signer/IsLocked", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(UnlockReply)
+	err := c.cc.Invoke(ctx, "/Signer/Unlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(LockReply)
+	err := c.cc.Invoke(ctx, "/Signer/Lock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) ChangePassphrase(ctx context.Context, in *ChangePassphraseRequest, opts ...grpc.CallOption) (*ChangePassphraseReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(ChangePassphraseReply)
+	err := c.cc.Invoke(ctx, "/Signer/ChangePassphrase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) IsValidPassphrase(ctx context.Context, in *IsValidPassphraseRequest, opts ...grpc.CallOption) (*IsValidPassphraseReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(IsValidPassphraseReply)
+	err := c.cc.Invoke(ctx, "/Signer/IsValidPassphrase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) DeriveConfidentialAddress(ctx context.Context, in *DeriveConfidentialAddressRequest, opts ...grpc.CallOption) (*DeriveConfidentialAddressReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(DeriveConfidentialAddressReply)
+	err := c.cc.Invoke(ctx, "/Signer/DeriveConfidentialAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) AcceptSwap(ctx context.Context, in *AcceptSwapRequest, opts ...grpc.CallOption) (*AcceptSwapReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(AcceptSwapReply)
+	err := c.cc.Invoke(ctx, "/Signer/AcceptSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signerClient) SendToMany(ctx context.Context, in *SendToManyRequest, opts ...grpc.CallOption) (*SendToManyReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(SendToManyReply)
+	err := c.cc.Invoke(ctx, "/Signer/SendToMany", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SignerServer is the server API for Signer service.
+type SignerServer interface {
+	// IsLocked returns whether the signer holds the keys of the wallet.
+	IsLocked(context.Context, *IsLockedRequest) (*IsLockedReply, error)
+	// Unlock decrypts the mnemonic of the wallet with a passphrase and holds
+	// the keys derived from it in memory until Lock is called.
+	Unlock(context.Context, *UnlockRequest) (*UnlockReply, error)
+	// Lock wipes the keys of the wallet from memory.
+	Lock(context.Context, *LockRequest) (*LockReply, error)
+	// ChangePassphrase encrypts the mnemonic of the locked wallet with a new
+	// passphrase.
+	ChangePassphrase(context.Context, *ChangePassphraseRequest) (*ChangePassphraseReply, error)
+	// IsValidPassphrase returns whether the mnemonic of the wallet can be
+	// decrypted with a passphrase.
+	IsValidPassphrase(context.Context, *IsValidPassphraseRequest) (*IsValidPassphraseReply, error)
+	// DeriveConfidentialAddress returns the confidential address, the
+	// output script and the private blinding key for a derivation path.
+	DeriveConfidentialAddress(context.Context, *DeriveConfidentialAddressRequest) (*DeriveConfidentialAddressReply, error)
+	// AcceptSwap adds the inputs and outputs of the daemon's markets to the
+	// transaction of a swap request, then blinds and signs it, if allowed by
+	// the signer policy.
+	AcceptSwap(context.Context, *AcceptSwapRequest) (*AcceptSwapReply, error)
+	// SendToMany crafts, blinds and signs a withdrawal transaction, if
+	// allowed by the signer policy.
+	SendToMany(context.Context, *SendToManyRequest) (*SendToManyReply, error)
+}
+
+// UnimplementedSignerServer can be embedded to have forward compatible implementations.
+type UnimplementedSignerServer struct {
+}
+
+func (*UnimplementedSignerServer) IsLocked(context.Context, *IsLockedRequest) (*IsLockedReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IsLocked not implemented")
+}
+func (*UnimplementedSignerServer) Unlock(context.Context, *UnlockRequest) (*UnlockReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unlock not implemented")
+}
+func (*UnimplementedSignerServer) Lock(context.Context, *LockRequest) (*LockReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lock not implemented")
+}
+func (*UnimplementedSignerServer) ChangePassphrase(context.Context, *ChangePassphraseRequest) (*ChangePassphraseReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassphrase not implemented")
+}
+func (*UnimplementedSignerServer) IsValidPassphrase(context.Context, *IsValidPassphraseRequest) (*IsValidPassphraseReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IsValidPassphrase not implemented")
+}
+func (*UnimplementedSignerServer) DeriveConfidentialAddress(context.Context, *DeriveConfidentialAddressRequest) (*DeriveConfidentialAddressReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeriveConfidentialAddress not implemented")
+}
+func (*UnimplementedSignerServer) AcceptSwap(context.Context, *AcceptSwapRequest) (*AcceptSwapReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptSwap not implemented")
+}
+func (*UnimplementedSignerServer) SendToMany(context.Context, *SendToManyRequest) (*SendToManyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendToMany not implemented")
+}
+
+func RegisterSignerServer(s *grpc.Server, srv SignerServer) {
+	s.RegisterService(&_Signer_serviceDesc, srv)
+}
+
+func _Signer_IsLocked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IsLockedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).IsLocked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/IsLocked",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).IsLocked(ctx, req.(*IsLockedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_Unlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).Unlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/Unlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).Unlock(ctx, req.(*UnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).Lock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/Lock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).Lock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_ChangePassphrase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePassphraseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).ChangePassphrase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/ChangePassphrase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).ChangePassphrase(ctx, req.(*ChangePassphraseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_IsValidPassphrase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IsValidPassphraseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).IsValidPassphrase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/IsValidPassphrase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).IsValidPassphrase(ctx, req.(*IsValidPassphraseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_DeriveConfidentialAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeriveConfidentialAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).DeriveConfidentialAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/DeriveConfidentialAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).DeriveConfidentialAddress(ctx, req.(*DeriveConfidentialAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_AcceptSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptSwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).AcceptSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/AcceptSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).AcceptSwap(ctx, req.(*AcceptSwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signer_SendToMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendToManyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SignerServer).SendToMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Signer/SendToMany",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SignerServer).SendToMany(ctx, req.(*SendToManyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Signer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "Signer",
+	HandlerType: (*SignerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "IsLocked",
+			Handler:    _Signer_IsLocked_Handler,
+		},
+		{
+			MethodName: "Unlock",
+			Handler:    _Signer_Unlock_Handler,
+		},
+		{
+			MethodName: "Lock",
+			Handler:    _Signer_Lock_Handler,
+		},
+		{
+			MethodName: "ChangePassphrase",
+			Handler:    _Signer_ChangePassphrase_Handler,
+		},
+		{
+			MethodName: "IsValidPassphrase",
+			Handler:    _Signer_IsValidPassphrase_Handler,
+		},
+		{
+			MethodName: "DeriveConfidentialAddress",
+			Handler:    _Signer_DeriveConfidentialAddress_Handler,
+		},
+		{
+			MethodName: "AcceptSwap",
+			Handler:    _Signer_AcceptSwap_Handler,
+		},
+		{
+			MethodName: "SendToMany",
+			Handler:    _Signer_SendToMany_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rpcext/signer",
+}
@@ -0,0 +1,76 @@
+package rpcext
+
+// MultiAssetSwapsHeader is the header set by the Markets RPC of the Trade
+// service when the provider accepts multi-asset swaps.
+const MultiAssetSwapsHeader = "tdex-multi-asset-swaps"
+
+// QuoteIDHeader is the metadata key under which a trader sends the ID of a
+// quote along with the TradePropose RPC of the Trade service.
+const QuoteIDHeader = "tdex-quote-id"
+
+// QuoteRequest is the request message of the Quote RPC. Like for the
+// MarketPrice RPC of the Trade service, Amount is the amount of base asset to
+// buy or sell, and Type is either 0 (BUY) or 1 (SELL).
+type QuoteRequest struct {
+	BaseAsset  string `json:"base_asset"`
+	QuoteAsset string `json:"quote_asset"`
+	Type       int    `json:"type"`
+	Amount     uint64 `json:"amount"`
+}
+
+// QuoteReply is the response message of the Quote RPC. Amount is the amount of
+// quote asset to send (BUY) or to receive (SELL) that is honoured for a trade
+// proposal sent with QuoteID before ExpiryTimeUnix.
+type QuoteReply struct {
+	QuoteID        string `json:"quote_id"`
+	Amount         uint64 `json:"amount"`
+	BasePrice      string `json:"base_price"`
+	QuotePrice     string `json:"quote_price"`
+	FeeAsset       string `json:"fee_asset"`
+	FeeBasisPoint  int64  `json:"fee_basis_point"`
+	ExpiryTimeUnix uint64 `json:"expiry_time_unix"`
+}
+
+// Leg is an amount of some asset sent by one party of a multi-asset swap to
+// the other.
+type Leg struct {
+	Asset  string `json:"asset"`
+	Amount uint64 `json:"amount"`
+}
+
+// TradeProposeMultiAssetRequest is the request message of the
+// TradeProposeMultiAsset RPC. SwapRequest is the serialized SwapRequest
+// protobuf message whose asset_p/amount_p and asset_r/amount_r must match the
+// first legs sent (LegsP) and received (LegsR) by the trader.
+type TradeProposeMultiAssetRequest struct {
+	SwapRequest []byte `json:"swap_request"`
+	LegsP       []*Leg `json:"legs_p"`
+	LegsR       []*Leg `json:"legs_r"`
+}
+
+// TradeProposeMultiAssetReply is the response message of the
+// TradeProposeMultiAsset RPC. Either SwapAccept or SwapFail is set with the
+// serialized protobuf message.
+type TradeProposeMultiAssetReply struct {
+	SwapAccept     []byte `json:"swap_accept"`
+	SwapFail       []byte `json:"swap_fail"`
+	ExpiryTimeUnix uint64 `json:"expiry_time_unix"`
+}
+
+// SwapStatusRequest is the request message of the SwapStatus RPC. SwapID is
+// either the id of a SwapRequest or that of a SwapAccept message.
+type SwapStatusRequest struct {
+	SwapID string `json:"swap_id"`
+}
+
+// SwapStatusReply is the response message of the SwapStatus RPC. Status is
+// one of "pending", "broadcast", "confirmed", "failed" or "expired". TxID is
+// set once the swap is completed, while BlockTimeUnix once its transaction is
+// confirmed.
+type SwapStatusReply struct {
+	Status         string `json:"status"`
+	TxID           string `json:"txid"`
+	BlockTimeUnix  uint64 `json:"block_time_unix"`
+	ExpiryTimeUnix uint64 `json:"expiry_time_unix"`
+	FailureMessage string `json:"failure_message"`
+}
@@ -0,0 +1,169 @@
+package rpcext
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// TradeExtensionClient is the client API for TradeExtension service.
+type TradeExtensionClient interface {
+	// Quote returns a price preview that is honoured for a short time by the
+	// TradePropose RPC of the Trade service.
+	Quote(ctx context.Context, in *QuoteRequest, opts ...grpc.CallOption) (*QuoteReply, error)
+	// TradeProposeMultiAsset proposes a swap with any number of assets sent
+	// and received by the trader.
+	TradeProposeMultiAsset(ctx context.Context, in *TradeProposeMultiAssetRequest, opts ...grpc.CallOption) (*TradeProposeMultiAssetReply, error)
+	// SwapStatus returns the status of a swap identified by the id of its
+	// request or accept message.
+	SwapStatus(ctx context.Context, in *SwapStatusRequest, opts ...grpc.CallOption) (*SwapStatusReply, error)
+}
+
+type tradeExtensionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewTradeExtensionClient returns a client for the TradeExtension service
+// that uses the JSON codec for every call.
+func NewTradeExtensionClient(cc grpc.ClientConnInterface) TradeExtensionClient {
+	return &tradeExtensionClient{cc}
+}
+
+func (c *tradeExtensionClient) Quote(ctx context.Context, in *QuoteRequest, opts ...grpc.CallOption) (*QuoteReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(QuoteReply)
+	err := c.cc.Invoke(ctx, "/TradeExtension/Quote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeExtensionClient) TradeProposeMultiAsset(ctx context.Context, in *TradeProposeMultiAssetRequest, opts ...grpc.CallOption) (*TradeProposeMultiAssetReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(TradeProposeMultiAssetReply)
+	err := c.cc.Invoke(ctx, "/TradeExtension/TradeProposeMultiAsset", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeExtensionClient) SwapStatus(ctx context.Context, in *SwapStatusRequest, opts ...grpc.CallOption) (*SwapStatusReply, error) {
+	opts = append([]grpc.CallOption{JSONCodec()}, opts...)
+	out := new(SwapStatusReply)
+	err := c.cc.Invoke(ctx, "/TradeExtension/SwapStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TradeExtensionServer is the server API for TradeExtension service.
+type TradeExtensionServer interface {
+	// Quote returns a price preview that is honoured for a short time by the
+	// TradePropose RPC of the Trade service.
+	Quote(context.Context, *QuoteRequest) (*QuoteReply, error)
+	// TradeProposeMultiAsset proposes a swap with any number of assets sent
+	// and received by the trader.
+	TradeProposeMultiAsset(context.Context, *TradeProposeMultiAssetRequest) (*TradeProposeMultiAssetReply, error)
+	// SwapStatus returns the status of a swap identified by the id of its
+	// request or accept message.
+	SwapStatus(context.Context, *SwapStatusRequest) (*SwapStatusReply, error)
+}
+
+// UnimplementedTradeExtensionServer can be embedded to have forward compatible implementations.
+type UnimplementedTradeExtensionServer struct {
+}
+
+func (*UnimplementedTradeExtensionServer) Quote(context.Context, *QuoteRequest) (*QuoteReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Quote not implemented")
+}
+func (*UnimplementedTradeExtensionServer) TradeProposeMultiAsset(context.Context, *TradeProposeMultiAssetRequest) (*TradeProposeMultiAssetReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TradeProposeMultiAsset not implemented")
+}
+
+func (*UnimplementedTradeExtensionServer) SwapStatus(context.Context, *SwapStatusRequest) (*SwapStatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwapStatus not implemented")
+}
+
+func RegisterTradeExtensionServer(s *grpc.Server, srv TradeExtensionServer) {
+	s.RegisterService(&_TradeExtension_serviceDesc, srv)
+}
+
+func _TradeExtension_Quote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeExtensionServer).Quote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/TradeExtension/Quote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeExtensionServer).Quote(ctx, req.(*QuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeExtension_TradeProposeMultiAsset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TradeProposeMultiAssetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeExtensionServer).TradeProposeMultiAsset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/TradeExtension/TradeProposeMultiAsset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeExtensionServer).TradeProposeMultiAsset(ctx, req.(*TradeProposeMultiAssetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeExtension_SwapStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwapStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeExtensionServer).SwapStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/TradeExtension/SwapStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeExtensionServer).SwapStatus(ctx, req.(*SwapStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TradeExtension_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "TradeExtension",
+	HandlerType: (*TradeExtensionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Quote",
+			Handler:    _TradeExtension_Quote_Handler,
+		},
+		{
+			MethodName: "TradeProposeMultiAsset",
+			Handler:    _TradeExtension_TradeProposeMultiAsset_Handler,
+		},
+		{
+			MethodName: "SwapStatus",
+			Handler:    _TradeExtension_SwapStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rpcext/trade",
+}
@@ -1,19 +1,29 @@
 package swap
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
 
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	"github.com/tdex-network/tdex-daemon/pkg/transactionutil"
 	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
 	"github.com/thanhpk/randstr"
+	"github.com/vulpemventures/go-elements/pset"
+	"github.com/vulpemventures/go-elements/transaction"
 	"google.golang.org/protobuf/proto"
 )
 
-// AcceptOpts is the struct given to Accept method
+// AcceptOpts is the struct given to Accept method. LegsP and LegsR are
+// required only for accepting multi-asset swaps.
 type AcceptOpts struct {
 	Message            []byte
 	PsetBase64         string
 	InputBlindingKeys  map[string][]byte
 	OutputBlindingKeys map[string][]byte
+	LegsP              []Leg
+	LegsR              []Leg
 }
 
 // Accept takes a AcceptOpts and returns the id of the SwapAccept entity and
@@ -34,7 +44,9 @@ func Accept(accept AcceptOpts) (string, []byte, error) {
 		OutputBlindingKey: accept.OutputBlindingKeys,
 	}
 
-	if err := compareMessagesAndTransaction(&msgRequest, msgAccept); err != nil {
+	if err := compareSwapAndTransaction(
+		&msgRequest, msgAccept, accept.LegsP, accept.LegsR,
+	); err != nil {
 		return "", nil, err
 	}
 
@@ -44,3 +56,99 @@ func Accept(accept AcceptOpts) (string, []byte, error) {
 	}
 	return randomID, msgAcceptSerialized, nil
 }
+
+// ValidateAcceptOpts is the struct given to ValidateAccept method. LegsP and
+// LegsR are required only for validating multi-asset swaps.
+type ValidateAcceptOpts struct {
+	Request []byte
+	Accept  []byte
+	LegsP   []Leg
+	LegsR   []Leg
+}
+
+// ValidateAccept takes a ValidateAcceptOpts and returns whether the
+// transaction of the serialized SwapAccept message still respects the terms of
+// the serialized SwapRequest it refers to. Other than the checks made on the
+// counter-party side, it makes sure that all the outputs of the proposer are
+// left untouched, so that, for example, its change is not stolen.
+func ValidateAccept(opts ValidateAcceptOpts) error {
+	var msgRequest pb.SwapRequest
+	if err := proto.Unmarshal(opts.Request, &msgRequest); err != nil {
+		return fmt.Errorf("unmarshal swap request %w", err)
+	}
+	var msgAccept pb.SwapAccept
+	if err := proto.Unmarshal(opts.Accept, &msgAccept); err != nil {
+		return fmt.Errorf("unmarshal swap accept %w", err)
+	}
+
+	if err := compareSwapAndTransaction(
+		&msgRequest, &msgAccept, opts.LegsP, opts.LegsR,
+	); err != nil {
+		return err
+	}
+
+	decodedFromRequest, err := pset.NewPsetFromBase64(msgRequest.GetTransaction())
+	if err != nil {
+		return err
+	}
+	decodedFromAccept, err := pset.NewPsetFromBase64(msgAccept.GetTransaction())
+	if err != nil {
+		return err
+	}
+
+	outputBlindingKeys := make(map[string][]byte)
+	for script, key := range msgAccept.GetOutputBlindingKey() {
+		outputBlindingKeys[script] = key
+	}
+	for script, key := range msgRequest.GetOutputBlindingKey() {
+		outputBlindingKeys[script] = key
+	}
+
+	for _, output := range decodedFromRequest.UnsignedTx.Outputs {
+		value := bufferutil.ValueFromBytes(output.Value)
+		asset := bufferutil.AssetHashFromBytes(output.Asset)
+		if output.IsConfidential() {
+			script := hex.EncodeToString(output.Script)
+			blindingKey, ok := outputBlindingKeys[script]
+			if !ok {
+				return errors.New("No blinding private key for script: " + script)
+			}
+			unblinded, ok := transactionutil.UnblindOutput(output, blindingKey)
+			if !ok {
+				return errors.New("Unable to unblind output with script: " + script)
+			}
+			value = unblinded.Value
+			asset = unblinded.AssetHash
+		}
+
+		found, err := outputFoundInTransaction(
+			outputsWithScript(decodedFromAccept.UnsignedTx.Outputs, output.Script),
+			value,
+			asset,
+			outputBlindingKeys,
+		)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New(
+				"SwapAccept transaction does not contain all the outputs of SwapRequest transaction",
+			)
+		}
+	}
+
+	return nil
+}
+
+func outputsWithScript(
+	outputs []*transaction.TxOutput,
+	script []byte,
+) []*transaction.TxOutput {
+	filtered := make([]*transaction.TxOutput, 0)
+	for _, output := range outputs {
+		if bytes.Equal(output.Script, script) {
+			filtered = append(filtered, output)
+		}
+	}
+	return filtered
+}
@@ -36,3 +36,46 @@ func TestCore_Accept(t *testing.T) {
 	})
 
 }
+
+func TestValidateAccept(t *testing.T) {
+	requestOpts := RequestOpts{
+		AssetToBeSent:   USDT,
+		AmountToBeSent:  30000000000,
+		AssetToReceive:  LBTC,
+		AmountToReceive: 5000000,
+		PsetBase64:      initialPsbtOfAlice,
+	}
+	messageRequest, err := Request(requestOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, messageAccept, err := Accept(AcceptOpts{
+		Message:    messageRequest,
+		PsetBase64: initialPsbtOfBob,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Alice can validate the SwapAccept message received by Bob", func(t *testing.T) {
+		if err := ValidateAccept(ValidateAcceptOpts{
+			Request: messageRequest,
+			Accept:  messageAccept,
+		}); err != nil {
+			t.Errorf("ValidateAccept() error = %v", err)
+		}
+	})
+
+	t.Run("Alice refuses a SwapAccept message not related to her SwapRequest", func(t *testing.T) {
+		otherMessageRequest, err := Request(requestOpts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidateAccept(ValidateAcceptOpts{
+			Request: otherMessageRequest,
+			Accept:  messageAccept,
+		}); err == nil {
+			t.Error("ValidateAccept() expected error, got nil")
+		}
+	})
+}
@@ -52,12 +52,15 @@ func Complete(complete CompleteOpts) (string, []byte, error) {
 	return randomID, msgCompleteSerialized, nil
 }
 
-// ValidateCompletePsetOpts is the struct given to the ValidateCompletePset method
+// ValidateCompletePsetOpts is the struct given to the ValidateCompletePset
+// method. LegsP and LegsR are required only for multi-asset swaps.
 type ValidateCompletePsetOpts struct {
 	PsetBase64         string
 	InputBlindingKeys  map[string][]byte
 	OutputBlindingKeys map[string][]byte
 	SwapRequest        *pb.SwapRequest
+	LegsP              []Leg
+	LegsR              []Leg
 }
 
 // ValidateCompletePset takes a VerifyCompeltePsetOpts and returns whether the
@@ -69,6 +72,14 @@ func ValidateCompletePset(opts ValidateCompletePsetOpts) error {
 	}
 
 	swapRequest := opts.SwapRequest
+	if len(opts.LegsP) > 0 || len(opts.LegsR) > 0 {
+		if err := validateLegs(swapRequest, opts.LegsP, opts.LegsR); err != nil {
+			return err
+		}
+		return validateCompletePsetLegs(
+			ptx, opts.InputBlindingKeys, opts.OutputBlindingKeys, opts.LegsP, opts.LegsR,
+		)
+	}
 
 	totalP, err := countCumulativeAmount(ptx.Inputs, swapRequest.GetAssetP(), opts.InputBlindingKeys)
 	if err != nil {
@@ -110,3 +121,38 @@ func ValidateCompletePset(opts ValidateCompletePsetOpts) error {
 	}
 	return nil
 }
+
+// validateCompletePsetLegs checks that the inputs of the final pset cover
+// every leg of a multi-asset swap and that there's an output for each of them
+func validateCompletePsetLegs(
+	ptx *pset.Pset,
+	inputBlindingKeys, outputBlindingKeys map[string][]byte,
+	legsP, legsR []Leg,
+) error {
+	for _, leg := range append(append([]Leg{}, legsP...), legsR...) {
+		total, err := countCumulativeAmount(ptx.Inputs, leg.Asset, inputBlindingKeys)
+		if err != nil {
+			return err
+		}
+		if total < leg.Amount {
+			return fmt.Errorf(
+				"cumulative utxos count is not enough to cover amount %d of asset %s",
+				leg.Amount, leg.Asset,
+			)
+		}
+
+		found, err := outputFoundInTransaction(
+			ptx.UnsignedTx.Outputs, leg.Amount, leg.Asset, outputBlindingKeys,
+		)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf(
+				"no output of amount %d of asset %s in the provided pset",
+				leg.Amount, leg.Asset,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package swap
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	"github.com/vulpemventures/go-elements/pset"
+)
+
+var (
+	// ErrNullLegs ...
+	ErrNullLegs = errors.New("both lists of legs must not be empty")
+	// ErrInvalidLeg ...
+	ErrInvalidLeg = errors.New("every leg must have a valid asset and a non zero amount")
+	// ErrDuplicatedLegAsset ...
+	ErrDuplicatedLegAsset = errors.New("an asset must not appear in more than one leg")
+	// ErrPrimaryLegsMismatch ...
+	ErrPrimaryLegsMismatch = errors.New(
+		"first legs must match SwapRequest asset_p/amount_p and asset_r/amount_r",
+	)
+)
+
+// Leg is an amount of some asset sent by one party of a swap to the other.
+// A swap request with many legs for either side is a multi-asset swap, while
+// a plain SwapRequest message is a swap with just one leg per side.
+type Leg struct {
+	Asset  string
+	Amount uint64
+}
+
+// RequestLegs returns the legs of the given SwapRequest message, that are
+// one for the assets sent (P) and one for the assets received (R) by the
+// proposer.
+func RequestLegs(request *pb.SwapRequest) (legsP, legsR []Leg) {
+	legsP = []Leg{{request.GetAssetP(), request.GetAmountP()}}
+	legsR = []Leg{{request.GetAssetR(), request.GetAmountR()}}
+	return
+}
+
+// validateLegs checks that the legs of a multi-asset swap are well formed.
+// Since SwapRequest messages can't carry more than one asset per side, the
+// first leg of each list is expected to match the asset and amount of the
+// message, so that parties unaware of the other legs still see a valid swap.
+func validateLegs(request *pb.SwapRequest, legsP, legsR []Leg) error {
+	if len(legsP) <= 0 || len(legsR) <= 0 {
+		return ErrNullLegs
+	}
+	if legsP[0].Asset != request.GetAssetP() ||
+		legsP[0].Amount != request.GetAmountP() ||
+		legsR[0].Asset != request.GetAssetR() ||
+		legsR[0].Amount != request.GetAmountR() {
+		return ErrPrimaryLegsMismatch
+	}
+
+	assets := make(map[string]bool)
+	for _, leg := range append(append([]Leg{}, legsP...), legsR...) {
+		if len(leg.Asset) != 64 || leg.Amount <= 0 {
+			return ErrInvalidLeg
+		}
+		if assets[leg.Asset] {
+			return ErrDuplicatedLegAsset
+		}
+		assets[leg.Asset] = true
+	}
+	return nil
+}
+
+// compareLegsAndTransaction generalizes compareMessagesAndTransaction to any
+// number of legs: the inputs of the request transaction must cover every leg
+// sent by the proposer and an output must exist for every leg it receives.
+// If the accept message is given, the same is checked for the counter-party
+// over the accept transaction.
+func compareLegsAndTransaction(
+	request *pb.SwapRequest,
+	accept *pb.SwapAccept,
+	legsP, legsR []Leg,
+) error {
+	decodedFromRequest, err := pset.NewPsetFromBase64(request.GetTransaction())
+	if err != nil {
+		return err
+	}
+
+	for index, input := range decodedFromRequest.Inputs {
+		if input.WitnessUtxo == nil && input.NonWitnessUtxo != nil {
+			inputVout := decodedFromRequest.UnsignedTx.Inputs[index].Index
+			decodedFromRequest.Inputs[index].WitnessUtxo = input.NonWitnessUtxo.Outputs[inputVout]
+		}
+	}
+
+	for _, leg := range legsP {
+		total, err := countCumulativeAmount(
+			decodedFromRequest.Inputs, leg.Asset, request.GetInputBlindingKey(),
+		)
+		if err != nil {
+			return err
+		}
+		if total < leg.Amount {
+			return fmt.Errorf(
+				"cumulative utxos count is not enough to cover amount %d of asset %s sent by the proposer",
+				leg.Amount, leg.Asset,
+			)
+		}
+	}
+
+	for _, leg := range legsR {
+		found, err := outputFoundInTransaction(
+			decodedFromRequest.UnsignedTx.Outputs,
+			leg.Amount,
+			leg.Asset,
+			request.GetOutputBlindingKey(),
+		)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf(
+				"no output of amount %d of asset %s for the proposer in the provided pset",
+				leg.Amount, leg.Asset,
+			)
+		}
+	}
+
+	if accept == nil {
+		return nil
+	}
+
+	decodedFromAccept, err := pset.NewPsetFromBase64(accept.GetTransaction())
+	if err != nil {
+		return err
+	}
+
+	if request.GetId() != accept.GetRequestId() {
+		return errors.New("id mismatch: SwapRequest.id and SwapAccept.request_id are not the same")
+	}
+
+	for _, leg := range legsR {
+		total, err := countCumulativeAmount(
+			decodedFromAccept.Inputs, leg.Asset, accept.GetInputBlindingKey(),
+		)
+		if err != nil {
+			return err
+		}
+		if total < leg.Amount {
+			return fmt.Errorf(
+				"cumulative utxos count is not enough to cover amount %d of asset %s received by the proposer",
+				leg.Amount, leg.Asset,
+			)
+		}
+	}
+
+	for _, leg := range legsP {
+		found, err := outputFoundInTransaction(
+			decodedFromAccept.UnsignedTx.Outputs,
+			leg.Amount,
+			leg.Asset,
+			accept.GetOutputBlindingKey(),
+		)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf(
+				"no output of amount %d of asset %s for the counter-party in the provided pset",
+				leg.Amount, leg.Asset,
+			)
+		}
+	}
+
+	return nil
+}
+
+// compareSwapAndTransaction checks the transactions of the given messages
+// against the given legs, if any, or against the assets and amounts of the
+// SwapRequest message otherwise.
+func compareSwapAndTransaction(
+	request *pb.SwapRequest,
+	accept *pb.SwapAccept,
+	legsP, legsR []Leg,
+) error {
+	if len(legsP) <= 0 && len(legsR) <= 0 {
+		return compareMessagesAndTransaction(request, accept)
+	}
+	if err := validateLegs(request, legsP, legsR); err != nil {
+		return err
+	}
+	return compareLegsAndTransaction(request, accept, legsP, legsR)
+}
@@ -0,0 +1,184 @@
+package swap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	"github.com/vulpemventures/go-elements/pset"
+	"github.com/vulpemventures/go-elements/transaction"
+)
+
+const otherAltcoin = "0e99c1a6da379d1f4151fb9df90449d40d0608f6cb33a5bcbfc8c265f42bab0a"
+
+var (
+	aliceScript = []byte{0x00, 0x14, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14}
+	bobScript   = []byte{0x00, 0x14, 0x14, 0x13, 0x12, 0x11, 0x10, 0x0f, 0x0e, 0x0d, 0x0c, 0x0b, 0x0a, 0x09, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01}
+)
+
+func TestMultiAssetSwap(t *testing.T) {
+	// Alice pays with USDT and altcoin for otherAltcoin plus some LBTC
+	legsP := []Leg{{USDT, 1000}, {altcoin, 2000}}
+	legsR := []Leg{{otherAltcoin, 3000}, {LBTC, 100}}
+
+	requestPset := newTestPset(t,
+		[]Leg{{USDT, 1500}, {altcoin, 2000}}, aliceScript,
+		[]Leg{{otherAltcoin, 3000}, {LBTC, 100}, {USDT, 500}}, aliceScript,
+	)
+	messageRequest, err := MultiAssetRequest(MultiAssetRequestOpts{
+		LegsToBeSent:  legsP,
+		LegsToReceive: legsR,
+		PsetBase64:    requestPset,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptPset := addInsAndOuts(t, requestPset,
+		[]Leg{{otherAltcoin, 3000}, {LBTC, 100}}, bobScript,
+		[]Leg{{USDT, 1000}, {altcoin, 2000}}, bobScript,
+	)
+	_, messageAccept, err := Accept(AcceptOpts{
+		Message:    messageRequest,
+		PsetBase64: acceptPset,
+		LegsP:      legsP,
+		LegsR:      legsR,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ValidateAccept(ValidateAcceptOpts{
+		Request: messageRequest,
+		Accept:  messageAccept,
+		LegsP:   legsP,
+		LegsR:   legsR,
+	})
+	assert.NoError(t, err)
+
+	// the counter-party must not be allowed to skip any leg
+	badAcceptPset := addInsAndOuts(t, requestPset,
+		[]Leg{{otherAltcoin, 3000}, {LBTC, 100}}, bobScript,
+		[]Leg{{USDT, 1000}}, bobScript,
+	)
+	_, _, err = Accept(AcceptOpts{
+		Message:    messageRequest,
+		PsetBase64: badAcceptPset,
+		LegsP:      legsP,
+		LegsR:      legsR,
+	})
+	assert.Error(t, err)
+}
+
+func TestFailingMultiAssetRequest(t *testing.T) {
+	requestPset := newTestPset(t,
+		[]Leg{{USDT, 1000}}, aliceScript,
+		[]Leg{{otherAltcoin, 3000}, {LBTC, 100}}, aliceScript,
+	)
+
+	tests := []struct {
+		legsP []Leg
+		legsR []Leg
+		err   error
+	}{
+		{
+			legsP: nil,
+			legsR: []Leg{{otherAltcoin, 3000}},
+			err:   ErrNullLegs,
+		},
+		{
+			legsP: []Leg{{USDT, 1000}},
+			legsR: []Leg{{otherAltcoin, 3000}, {USDT, 100}},
+			err:   ErrDuplicatedLegAsset,
+		},
+		{
+			legsP: []Leg{{USDT, 1000}},
+			legsR: []Leg{{otherAltcoin, 3000}, {LBTC, 0}},
+			err:   ErrInvalidLeg,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := MultiAssetRequest(MultiAssetRequestOpts{
+			LegsToBeSent:  tt.legsP,
+			LegsToReceive: tt.legsR,
+			PsetBase64:    requestPset,
+		})
+		assert.Equal(t, tt.err, err)
+	}
+
+	// the pset must have an output for every leg to receive
+	_, err := MultiAssetRequest(MultiAssetRequestOpts{
+		LegsToBeSent:  []Leg{{USDT, 1000}},
+		LegsToReceive: []Leg{{otherAltcoin, 3000}, {altcoin, 100}},
+		PsetBase64:    requestPset,
+	})
+	assert.Error(t, err)
+}
+
+// newTestPset returns a partial transaction with an unconfidential input for
+// each of the given ins and an unconfidential output for each of the given
+// outs
+func newTestPset(
+	t *testing.T,
+	ins []Leg, inScript []byte,
+	outs []Leg, outScript []byte,
+) string {
+	ptx, err := pset.New([]*transaction.TxInput{}, []*transaction.TxOutput{}, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	psetBase64, err := ptx.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addInsAndOuts(t, psetBase64, ins, inScript, outs, outScript)
+}
+
+func addInsAndOuts(
+	t *testing.T,
+	psetBase64 string,
+	ins []Leg, inScript []byte,
+	outs []Leg, outScript []byte,
+) string {
+	ptx, err := pset.NewPsetFromBase64(psetBase64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater, err := pset.NewUpdater(ptx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, in := range ins {
+		hash := make([]byte, 32)
+		hash[0] = byte(len(ptx.Inputs) + 1)
+		updater.AddInput(transaction.NewTxInput(hash, 0))
+		if err := updater.AddInWitnessUtxo(
+			newTestOutput(t, in, inScript), len(ptx.Inputs)-1,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, out := range outs {
+		updater.AddOutput(newTestOutput(t, out, outScript))
+	}
+
+	psetBase64, err = ptx.ToBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return psetBase64
+}
+
+func newTestOutput(t *testing.T, leg Leg, script []byte) *transaction.TxOutput {
+	asset, err := bufferutil.AssetHashToBytes(leg.Asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := bufferutil.ValueToBytes(leg.Amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transaction.NewTxOutput(asset, value, script)
+}
@@ -26,6 +26,23 @@ func ParseSwapRequest(request *pb.SwapRequest) ([]byte, error) {
 	return proto.Marshal(request)
 }
 
+// ParseMultiAssetSwapRequest checks whether the given swap request is a well
+// formed multi-asset swap with the given legs and returns the byte
+// serialization of the message. The first legs of either list are those of
+// the message.
+func ParseMultiAssetSwapRequest(
+	request *pb.SwapRequest,
+	legsP, legsR []Leg,
+) ([]byte, error) {
+	if err := validateLegs(request, legsP, legsR); err != nil {
+		return nil, err
+	}
+	if err := compareLegsAndTransaction(request, nil, legsP, legsR); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(request)
+}
+
 // Request takes a RequestOpts struct and returns a serialized protobuf message.
 func Request(opts RequestOpts) ([]byte, error) {
 	randomID := randstr.Hex(8)
@@ -41,8 +58,46 @@ func Request(opts RequestOpts) ([]byte, error) {
 		Transaction: opts.PsetBase64,
 		// Blinding keys
 		InputBlindingKey:  opts.InputBlindingKeys,
-		OutputBlindingKey: opts.InputBlindingKeys,
+		OutputBlindingKey: opts.OutputBlindingKeys,
 	}
 
 	return ParseSwapRequest(msg)
 }
+
+// MultiAssetRequestOpts is the struct to be given to the MultiAssetRequest
+// method
+type MultiAssetRequestOpts struct {
+	LegsToBeSent       []Leg
+	LegsToReceive      []Leg
+	PsetBase64         string
+	InputBlindingKeys  map[string][]byte
+	OutputBlindingKeys map[string][]byte
+}
+
+// MultiAssetRequest takes a MultiAssetRequestOpts struct and returns a
+// serialized protobuf message for a swap with many legs per side. The message
+// holds the first leg of each list, therefore the others must be sent to the
+// counter-party along with it.
+func MultiAssetRequest(opts MultiAssetRequestOpts) ([]byte, error) {
+	if len(opts.LegsToBeSent) <= 0 || len(opts.LegsToReceive) <= 0 {
+		return nil, ErrNullLegs
+	}
+
+	randomID := randstr.Hex(8)
+	msg := &pb.SwapRequest{
+		Id: randomID,
+		// Proposer
+		AssetP:  opts.LegsToBeSent[0].Asset,
+		AmountP: opts.LegsToBeSent[0].Amount,
+		// Receiver
+		AssetR:  opts.LegsToReceive[0].Asset,
+		AmountR: opts.LegsToReceive[0].Amount,
+		//PSET
+		Transaction: opts.PsetBase64,
+		// Blinding keys
+		InputBlindingKey:  opts.InputBlindingKeys,
+		OutputBlindingKey: opts.OutputBlindingKeys,
+	}
+
+	return ParseMultiAssetSwapRequest(msg, opts.LegsToBeSent, opts.LegsToReceive)
+}
@@ -49,6 +49,7 @@ func TestCore_Request(t *testing.T) {
 				AmountToReceive: 10000000000,
 				PsetBase64: initialPsetOfAliceLegacyInputs,
 				InputBlindingKeys: inBlindKeys,
+				OutputBlindingKeys: inBlindKeys,
 			}},
 			make([]byte, 12656),
 			false,
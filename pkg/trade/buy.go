@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
 	tradeclient "github.com/tdex-network/tdex-daemon/pkg/trade/client"
 	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
 	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
@@ -25,6 +26,20 @@ var (
 	ErrNullPrivateKey = errors.New("private key must not be null")
 	// ErrNullBlindingKey ...
 	ErrNullBlindingKey = errors.New("blinding key must not be null")
+	// ErrNullWallet ...
+	ErrNullWallet = errors.New("wallet must not be null")
+	// ErrWalletAndKeys ...
+	ErrWalletAndKeys = errors.New(
+		"either a wallet or a private and blinding key must be given, not both",
+	)
+	// ErrWalletNotFunded ...
+	ErrWalletNotFunded = errors.New("wallet is not funded")
+	// ErrTradeProposalRejected is returned, wrapped along with the failure
+	// message, if the provider replies to the proposal with a SwapFail
+	ErrTradeProposalRejected = errors.New("trade proposal has been rejected")
+	// ErrTradeCompleteRejected is returned, wrapped along with the failure
+	// message, if the provider replies to the completion with a SwapFail
+	ErrTradeCompleteRejected = errors.New("trade completion has been rejected")
 )
 
 // BuyOrSellOpts is the struct given to Buy/Sell method
@@ -73,13 +88,16 @@ func (t *Trade) Buy(opts BuyOrSellOpts) ([]byte, error) {
 	)
 }
 
-// BuyOrSellAndCompleteOpts is the struct given to Buy method
+// BuyOrSellAndCompleteOpts is the struct given to Buy method.
+// The trade is funded, blinded and signed either by Wallet, if given, or by
+// the single key pair PrivateKey/BlindingKey.
 type BuyOrSellAndCompleteOpts struct {
 	Market      trademarket.Market
 	TradeType   int
 	Amount      uint64
 	PrivateKey  []byte
 	BlindingKey []byte
+	Wallet      TraderWallet
 }
 
 func (o BuyOrSellAndCompleteOpts) validate() error {
@@ -92,13 +110,7 @@ func (o BuyOrSellAndCompleteOpts) validate() error {
 	if o.Amount <= 0 {
 		return ErrInvalidAmount
 	}
-	if len(o.PrivateKey) <= 0 {
-		return ErrNullPrivateKey
-	}
-	if len(o.BlindingKey) <= 0 {
-		return ErrNullBlindingKey
-	}
-	return nil
+	return validateWalletOrKeys(o.Wallet, o.PrivateKey, o.BlindingKey)
 }
 
 // BuyAndComplete creates a new trade proposal with the give arguments. The
@@ -110,19 +122,92 @@ func (t *Trade) BuyAndComplete(opts BuyOrSellAndCompleteOpts) (string, error) {
 		return "", err
 	}
 
-	w := NewWalletFromKey(opts.PrivateKey, opts.BlindingKey, t.network)
-	swapAcceptMsg, err := t.marketOrderRequest(
-		opts.Market,
-		tradetype.Buy,
-		opts.Amount,
-		w.Address(),
-		opts.BlindingKey,
+	w := t.walletOrKeys(opts.Wallet, opts.PrivateKey, opts.BlindingKey)
+	return t.tradeAndComplete(opts.Market, tradetype.Buy, opts.Amount, w)
+}
+
+// TradeWithWalletOpts is the struct given to BuyAndCompleteWithWallet and
+// SellAndCompleteWithWallet methods
+type TradeWithWalletOpts struct {
+	Market trademarket.Market
+	Amount uint64
+	Wallet TraderWallet
+}
+
+func (o TradeWithWalletOpts) validate() error {
+	if err := o.Market.Validate(); err != nil {
+		return err
+	}
+	if o.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if o.Wallet == nil {
+		return ErrNullWallet
+	}
+	return nil
+}
+
+// BuyAndCompleteWithWallet works like BuyAndComplete, but the trade is funded,
+// blinded and signed by the given wallet instead of a single private key.
+func (t *Trade) BuyAndCompleteWithWallet(
+	opts TradeWithWalletOpts,
+) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+	return t.tradeAndComplete(opts.Market, tradetype.Buy, opts.Amount, opts.Wallet)
+}
+
+// walletOrKeys returns the given wallet, if any, or a single key one
+func (t *Trade) walletOrKeys(
+	w TraderWallet,
+	privateKey, blindingKey []byte,
+) TraderWallet {
+	if w != nil {
+		return w
+	}
+	return NewWalletFromKey(privateKey, blindingKey, t.network)
+}
+
+// validateWalletOrKeys makes sure that either the wallet or the private and
+// blinding keys are given
+func validateWalletOrKeys(
+	w TraderWallet,
+	privateKey, blindingKey []byte,
+) error {
+	if w != nil {
+		if len(privateKey) > 0 || len(blindingKey) > 0 {
+			return ErrWalletAndKeys
+		}
+		return nil
+	}
+	if len(privateKey) <= 0 {
+		return ErrNullPrivateKey
+	}
+	if len(blindingKey) <= 0 {
+		return ErrNullBlindingKey
+	}
+	return nil
+}
+
+func (t *Trade) tradeAndComplete(
+	market trademarket.Market,
+	tradeType tradetype.TradeType,
+	amount uint64,
+	w TraderWallet,
+) (string, error) {
+	swapRequestMsg, swapAcceptMsg, err := t.orderRequest(
+		market,
+		tradeType,
+		amount,
+		w,
+		nil,
 	)
 	if err != nil {
 		return "", err
 	}
 
-	return t.marketOrderComplete(swapAcceptMsg, w)
+	return t.marketOrderComplete(swapRequestMsg, swapAcceptMsg, w, nil)
 }
 
 func (t *Trade) marketOrderRequest(
@@ -132,12 +217,33 @@ func (t *Trade) marketOrderRequest(
 	addr string,
 	blindingKey []byte,
 ) ([]byte, error) {
-	unspents, err := t.explorer.GetUnspents(addr, [][]byte{blindingKey})
+	_, swapAcceptMsg, err := t.orderRequest(
+		market,
+		tradeType,
+		amount,
+		newWatchOnlyWallet(addr, blindingKey, t.network),
+		nil,
+	)
+	return swapAcceptMsg, err
+}
+
+// orderRequest creates and sends a trade proposal funded by the given wallet,
+// returning both the serialized SwapRequest and SwapAccept messages. If a
+// limit price is given, the proposal is not even sent to the provider in case
+// the preview doesn't respect it.
+func (t *Trade) orderRequest(
+	market trademarket.Market,
+	tradeType tradetype.TradeType,
+	amount uint64,
+	w fundingWallet,
+	limit *limitPrice,
+) ([]byte, []byte, error) {
+	unspents, err := w.Unspents(t.explorer)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(unspents) <= 0 {
-		return nil, fmt.Errorf("address '%s' is not funded", addr)
+		return nil, nil, ErrWalletNotFunded
 	}
 
 	preview, err := t.Preview(PreviewOpts{
@@ -145,57 +251,171 @@ func (t *Trade) marketOrderRequest(
 		TradeType: int(tradeType),
 		Amount:    amount,
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := limit.check(preview.AmountToSend, preview.AmountToReceive); err != nil {
+		return nil, nil, err
+	}
+
+	swapRequestMsg, err := newSwapRequest(w, unspents, preview, t.network)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reply, err := t.client.TradePropose(tradeclient.TradeProposeOpts{
+		Market:      market,
+		SwapRequest: swapRequestMsg,
+		TradeType:   tradeType,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fail := reply.GetSwapFail(); fail != nil {
+		return nil, nil, fmt.Errorf(
+			"%w for reason: %s", ErrTradeProposalRejected, fail.GetFailureMessage(),
+		)
+	}
+
+	swapAcceptMsg, err := proto.Marshal(reply.GetSwapAccept())
+	if err != nil {
+		return nil, nil, err
+	}
+	return swapRequestMsg, swapAcceptMsg, nil
+}
+
+// newSwapRequest returns a serialized SwapRequest message for the given
+// preview, spending the needed unspents of the wallet. The blinding keys of
+// the inputs and of the receive and change outputs are looked up by script.
+func newSwapRequest(
+	w fundingWallet,
+	unspents []explorer.Utxo,
+	preview *PreviewResult,
+	net *network.Network,
+) ([]byte, error) {
+	receiveAddress, err := w.ReceiveAddress()
+	if err != nil {
+		return nil, err
+	}
+	changeAddress, err := w.ChangeAddress()
+	if err != nil {
+		return nil, err
+	}
+	outputScript, err := address.ToOutputScript(receiveAddress, *net)
+	if err != nil {
+		return nil, err
+	}
+	changeScript, err := address.ToOutputScript(changeAddress, *net)
 	if err != nil {
 		return nil, err
 	}
 
-	outputScript, _ := address.ToOutputScript(addr, *t.network)
-	outputScriptHex := hex.EncodeToString(outputScript)
+	blindingKeys, inputBlindingKeys, outputBlindingKeys, err := getBlindingKeys(
+		w, unspents, outputScript, changeScript,
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	psetBase64, err := NewSwapTx(
+	psetBase64, err := newSwapTx(
 		unspents,
-		blindingKey,
+		blindingKeys,
 		preview.AssetToSend,
 		preview.AmountToSend,
 		preview.AssetToReceive,
 		preview.AmountToReceive,
 		outputScript,
+		changeScript,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	blindingKeyMap := map[string][]byte{
-		outputScriptHex: blindingKey,
-	}
-
-	swapRequestMsg, err := swap.Request(swap.RequestOpts{
+	return swap.Request(swap.RequestOpts{
 		AssetToBeSent:      preview.AssetToSend,
 		AmountToBeSent:     preview.AmountToSend,
 		AssetToReceive:     preview.AssetToReceive,
 		AmountToReceive:    preview.AmountToReceive,
 		PsetBase64:         psetBase64,
-		InputBlindingKeys:  blindingKeyMap,
-		OutputBlindingKeys: blindingKeyMap,
+		InputBlindingKeys:  inputBlindingKeys,
+		OutputBlindingKeys: outputBlindingKeys,
 	})
-	if err != nil {
-		return nil, err
+}
+
+// getBlindingKeys returns the blinding keys of the given unspents, both as a
+// list and by script, and those of the given output scripts by script
+func getBlindingKeys(
+	w BlindingKeyProvider,
+	unspents []explorer.Utxo,
+	outputScripts ...[]byte,
+) ([][]byte, map[string][]byte, map[string][]byte, error) {
+	blindingKeys := make([][]byte, 0)
+	inputBlindingKeys := make(map[string][]byte)
+	for _, u := range unspents {
+		script := hex.EncodeToString(u.Script())
+		if _, ok := inputBlindingKeys[script]; ok {
+			continue
+		}
+		blindingKey, err := w.BlindingKeyForScript(u.Script())
+		if err != nil {
+			// unconfidential utxos don't need to be unblinded
+			if !u.IsConfidential() {
+				continue
+			}
+			return nil, nil, nil, fmt.Errorf(
+				"utxo %s:%d: %w", u.Hash(), u.Index(), err,
+			)
+		}
+		inputBlindingKeys[script] = blindingKey
+		blindingKeys = append(blindingKeys, blindingKey)
 	}
 
-	reply, err := t.client.TradePropose(tradeclient.TradeProposeOpts{
-		Market:      market,
-		SwapRequest: swapRequestMsg,
-		TradeType:   tradeType,
-	})
+	outputBlindingKeys := make(map[string][]byte)
+	for _, s := range outputScripts {
+		blindingKey, err := w.BlindingKeyForScript(s)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		outputBlindingKeys[hex.EncodeToString(s)] = blindingKey
+	}
 
-	if fail := reply.GetSwapFail(); fail != nil {
-		return nil, fmt.Errorf("trade proposal has been rejected for reason: %s", fail.GetFailureMessage())
+	return blindingKeys, inputBlindingKeys, outputBlindingKeys, nil
+}
+
+// marketOrderComplete signs the transaction of the given SwapAccept message
+// and sends it back to the provider. Before signing, the transaction is
+// validated against the SwapRequest and, if given, the amounts of the swap
+// are checked against the limit price.
+func (t *Trade) marketOrderComplete(
+	swapRequestMsg, swapAcceptMsg []byte,
+	w Signer,
+	limit *limitPrice,
+) (string, error) {
+	if err := swap.ValidateAccept(swap.ValidateAcceptOpts{
+		Request: swapRequestMsg,
+		Accept:  swapAcceptMsg,
+	}); err != nil {
+		return "", fmt.Errorf("invalid trade proposal accept: %w", err)
+	}
+
+	swapRequest := &pb.SwapRequest{}
+	if err := proto.Unmarshal(swapRequestMsg, swapRequest); err != nil {
+		return "", err
+	}
+	if err := limit.check(
+		swapRequest.GetAmountP(),
+		swapRequest.GetAmountR(),
+	); err != nil {
+		return "", err
 	}
 
-	return proto.Marshal(reply.GetSwapAccept())
+	return t.completeSwap(swapAcceptMsg, w)
 }
 
-func (t *Trade) marketOrderComplete(swapAcceptMsg []byte, w *Wallet) (string, error) {
+// completeSwap signs the transaction of the given, already validated,
+// SwapAccept message and sends it back to the provider
+func (t *Trade) completeSwap(swapAcceptMsg []byte, w Signer) (string, error) {
 	swapAccept := &pb.SwapAccept{}
 	proto.Unmarshal(swapAcceptMsg, swapAccept)
 
@@ -220,7 +440,9 @@ func (t *Trade) marketOrderComplete(swapAcceptMsg []byte, w *Wallet) (string, er
 		return "", err
 	}
 	if swapFail := reply.GetSwapFail(); swapFail != nil {
-		return "", errors.New(swapFail.GetFailureMessage())
+		return "", fmt.Errorf(
+			"%w for reason: %s", ErrTradeCompleteRejected, swapFail.GetFailureMessage(),
+		)
 	}
 
 	return reply.GetTxid(), nil
@@ -1,33 +1,138 @@
 package tradeclient
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
 	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
 	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	// ErrNullHost ...
+	ErrNullHost = errors.New("host must not be null")
+	// ErrInvalidPort ...
+	ErrInvalidPort = errors.New("port must be in range [1, 65535]")
+	// ErrInvalidTLSCert ...
+	ErrInvalidTLSCert = errors.New("tls cert must be a valid PEM encoded certificate")
+	// ErrInvalidTimeout ...
+	ErrInvalidTimeout = errors.New("timeout must not be negative")
 )
 
 // Client allows to connect with a trader service and to call its RPCs
 type Client struct {
-	client pbtrade.TradeClient
-	conn   *grpc.ClientConn
+	client  pbtrade.TradeClient
+	ext     rpcext.TradeExtensionClient
+	conn    io.Closer
+	timeout time.Duration
 }
 
 // NewTradeClient returns a new Client connected to the server at the given
 // host and port through an insecure connection
 func NewTradeClient(host string, port int) (*Client, error) {
-	opts := []grpc.DialOption{}
-	opts = append(opts, grpc.WithInsecure())
-	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", host, port), opts...)
-	if err != nil {
+	return NewTradeClientWithOpts(NewTradeClientOpts{
+		Host: host,
+		Port: port,
+	})
+}
+
+// NewTradeClientOpts is the struct given to NewTradeClientWithOpts method.
+// If TLS is enabled, the server certificate is verified against the system
+// root CAs, unless TLSCert is defined, in which case the given PEM encoded
+// certificate is the only one trusted.
+// Timeout, if not zero, is the deadline applied to every call.
+// DialOptions are appended to those derived from the other options and are
+// ignored when GrpcWeb is enabled. GrpcWeb makes the client talk the
+// grpc-web protocol over HTTP/1.1 in place of gRPC over HTTP/2.
+type NewTradeClientOpts struct {
+	Host        string
+	Port        int
+	TLS         bool
+	TLSCert     []byte
+	Timeout     time.Duration
+	DialOptions []grpc.DialOption
+	GrpcWeb     bool
+}
+
+func (o NewTradeClientOpts) validate() error {
+	if len(o.Host) <= 0 {
+		return ErrNullHost
+	}
+	if o.Port <= 0 || o.Port > 65535 {
+		return ErrInvalidPort
+	}
+	if len(o.TLSCert) > 0 {
+		if ok := x509.NewCertPool().AppendCertsFromPEM(o.TLSCert); !ok {
+			return ErrInvalidTLSCert
+		}
+	}
+	if o.Timeout < 0 {
+		return ErrInvalidTimeout
+	}
+	return nil
+}
+
+func (o NewTradeClientOpts) address() string {
+	return fmt.Sprintf("%s:%d", o.Host, o.Port)
+}
+
+// tlsConfig returns the tls configuration for the connection, nil if TLS
+// is not enabled
+func (o NewTradeClientOpts) tlsConfig() *tls.Config {
+	if !o.TLS && len(o.TLSCert) <= 0 {
+		return nil
+	}
+	config := &tls.Config{}
+	if len(o.TLSCert) > 0 {
+		certPool := x509.NewCertPool()
+		certPool.AppendCertsFromPEM(o.TLSCert)
+		config.RootCAs = certPool
+	}
+	return config
+}
+
+// NewTradeClientWithOpts returns a new Client connected to the server at the
+// given host and port, configured with the given options
+func NewTradeClientWithOpts(opts NewTradeClientOpts) (*Client, error) {
+	if err := opts.validate(); err != nil {
 		return nil, err
 	}
 
+	var conn interface {
+		grpc.ClientConnInterface
+		io.Closer
+	}
+	if opts.GrpcWeb {
+		conn = newGrpcWebConn(opts.address(), opts.tlsConfig())
+	} else {
+		dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+		if tlsConfig := opts.tlsConfig(); tlsConfig != nil {
+			dialOpts = []grpc.DialOption{
+				grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+			}
+		}
+		dialOpts = append(dialOpts, opts.DialOptions...)
+
+		grpcConn, err := grpc.Dial(opts.address(), dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+		conn = grpcConn
+	}
+
 	client := pbtrade.NewTradeClient(conn)
-	return &Client{client, conn}, nil
+	ext := rpcext.NewTradeExtensionClient(conn)
+	return &Client{client, ext, conn, opts.Timeout}, nil
 }
 
 // CloseConnection closes the connections between the current client and the
@@ -36,6 +141,15 @@ func (c *Client) CloseConnection() error {
 	return c.conn.Close()
 }
 
+// callContext returns the context for a call, with a deadline if the client
+// has been configured with a timeout
+func (c *Client) callContext() (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(context.Background(), c.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
 func isValidAsset(asset string) bool {
 	buf, err := hex.DecodeString(asset)
 	return err != nil || len(buf) != 32
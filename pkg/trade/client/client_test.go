@@ -0,0 +1,378 @@
+package tradeclient
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/tdex-network/tdex-daemon/pkg/swap"
+	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
+	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
+	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var market = trademarket.Market{
+	BaseAsset:  "5ac9f65c0efcc4775e0baec4ec03abdde22473cd3cf33c0419ca290e0751b225",
+	QuoteAsset: "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3",
+}
+
+func TestGrpcWebClient(t *testing.T) {
+	server := newTestServer(t, false)
+	defer server.Close()
+
+	host, port := hostAndPort(t, server.URL)
+	client, err := NewTradeClientWithOpts(NewTradeClientOpts{
+		Host:    host,
+		Port:    port,
+		GrpcWeb: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.CloseConnection()
+
+	testCalls(t, client)
+}
+
+func TestTLSClient(t *testing.T) {
+	server := newTestServer(t, true)
+	defer server.Close()
+
+	host, port := hostAndPort(t, server.URL)
+	cert := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+
+	for _, grpcWeb := range []bool{false, true} {
+		client, err := NewTradeClientWithOpts(NewTradeClientOpts{
+			Host:    host,
+			Port:    port,
+			TLS:     true,
+			TLSCert: cert,
+			GrpcWeb: grpcWeb,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testCalls(t, client)
+		client.CloseConnection()
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := newTestServer(t, true)
+	defer server.Close()
+
+	host, port := hostAndPort(t, server.URL)
+	cert := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+
+	for _, grpcWeb := range []bool{false, true} {
+		client, err := NewTradeClientWithOpts(NewTradeClientOpts{
+			Host:    host,
+			Port:    port,
+			TLSCert: cert,
+			Timeout: 100 * time.Millisecond,
+			GrpcWeb: grpcWeb,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.Balances(BalancesOpts{Market: market})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		client.CloseConnection()
+	}
+}
+
+func TestFailingNewTradeClient(t *testing.T) {
+	tests := []struct {
+		opts NewTradeClientOpts
+		err  error
+	}{
+		{
+			opts: NewTradeClientOpts{Port: 9945},
+			err:  ErrNullHost,
+		},
+		{
+			opts: NewTradeClientOpts{Host: "localhost", Port: 0},
+			err:  ErrInvalidPort,
+		},
+		{
+			opts: NewTradeClientOpts{Host: "localhost", Port: 65536},
+			err:  ErrInvalidPort,
+		},
+		{
+			opts: NewTradeClientOpts{
+				Host:    "localhost",
+				Port:    9945,
+				TLSCert: []byte("not a cert"),
+			},
+			err: ErrInvalidTLSCert,
+		},
+		{
+			opts: NewTradeClientOpts{
+				Host:    "localhost",
+				Port:    9945,
+				Timeout: -time.Second,
+			},
+			err: ErrInvalidTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := NewTradeClientWithOpts(tt.opts)
+		assert.Equal(t, tt.err, err)
+	}
+}
+
+// testCalls makes sure that unary calls, streams and errors are correctly
+// handled by the client
+func testCalls(t *testing.T, client *Client) {
+	markets, err := client.Markets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(markets.GetMarkets()))
+	assert.Equal(
+		t,
+		market.BaseAsset,
+		markets.GetMarkets()[0].GetMarket().GetBaseAsset(),
+	)
+
+	_, err = client.MarketPrice(MarketPriceOpts{
+		Market:    market,
+		TradeType: tradetype.Buy,
+		Amount:    100,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "market is closed: try later", status.Convert(err).Message())
+
+	reply, err := client.TradePropose(TradeProposeOpts{
+		Market:    market,
+		TradeType: tradetype.Buy,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "swap-fail", reply.GetSwapFail().GetId())
+
+	quote, err := client.Quote(QuoteOpts{
+		Market:    market,
+		TradeType: tradetype.Buy,
+		Amount:    100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err = client.TradePropose(TradeProposeOpts{
+		Market:    market,
+		TradeType: tradetype.Buy,
+		QuoteID:   quote.QuoteID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "quote-100", reply.GetSwapFail().GetFailureMessage())
+
+	ok, err := client.SupportsMultiAssetSwaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, ok)
+
+	swapRequest, _ := proto.Marshal(&pbswap.SwapRequest{Id: "swap-request"})
+	reply, err = client.TradeProposeMultiAsset(TradeProposeMultiAssetOpts{
+		SwapRequest: swapRequest,
+		LegsP:       []swap.Leg{{Asset: market.QuoteAsset, Amount: 100}},
+		LegsR:       []swap.Leg{{Asset: market.BaseAsset, Amount: 10}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "swap-request", reply.GetSwapFail().GetMessageId())
+	assert.Equal(t, uint64(2), reply.GetExpiryTimeUnix())
+
+	_, err = client.SwapStatus("")
+	assert.Equal(t, ErrNullSwapID, err)
+
+	_, err = client.SwapStatus("unknown")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	swapStatus, err := client.SwapStatus("swap-accept")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "confirmed", swapStatus.Status)
+	assert.Equal(t, "txid", swapStatus.TxID)
+}
+
+type mockTradeServer struct {
+	pbtrade.UnimplementedTradeServer
+	rpcext.UnimplementedTradeExtensionServer
+}
+
+func (s *mockTradeServer) Markets(
+	ctx context.Context,
+	_ *pbtrade.MarketsRequest,
+) (*pbtrade.MarketsReply, error) {
+	if err := grpc.SetHeader(
+		ctx, metadata.Pairs(rpcext.MultiAssetSwapsHeader, "true"),
+	); err != nil {
+		return nil, err
+	}
+	return &pbtrade.MarketsReply{
+		Markets: []*pbtypes.MarketWithFee{
+			{
+				Market: &pbtypes.Market{
+					BaseAsset:  market.BaseAsset,
+					QuoteAsset: market.QuoteAsset,
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *mockTradeServer) Balances(
+	ctx context.Context,
+	_ *pbtrade.BalancesRequest,
+) (*pbtrade.BalancesReply, error) {
+	select {
+	case <-time.After(time.Second):
+		return &pbtrade.BalancesReply{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *mockTradeServer) MarketPrice(
+	_ context.Context,
+	_ *pbtrade.MarketPriceRequest,
+) (*pbtrade.MarketPriceReply, error) {
+	return nil, status.Error(codes.InvalidArgument, "market is closed: try later")
+}
+
+// TradePropose echoes the id of the quote, if any, as failure message
+func (s *mockTradeServer) TradePropose(
+	_ *pbtrade.TradeProposeRequest,
+	stream pbtrade.Trade_TradeProposeServer,
+) error {
+	var quoteID string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if values := md.Get(rpcext.QuoteIDHeader); len(values) > 0 {
+			quoteID = values[0]
+		}
+	}
+	return stream.Send(&pbtrade.TradeProposeReply{
+		SwapFail: &pbswap.SwapFail{Id: "swap-fail", FailureMessage: quoteID},
+	})
+}
+
+func (s *mockTradeServer) Quote(
+	_ context.Context,
+	req *rpcext.QuoteRequest,
+) (*rpcext.QuoteReply, error) {
+	return &rpcext.QuoteReply{
+		QuoteID: fmt.Sprintf("quote-%d", req.Amount),
+	}, nil
+}
+
+// TradeProposeMultiAsset echoes the id of the request and the number of legs
+func (s *mockTradeServer) TradeProposeMultiAsset(
+	_ context.Context,
+	req *rpcext.TradeProposeMultiAssetRequest,
+) (*rpcext.TradeProposeMultiAssetReply, error) {
+	swapRequest := &pbswap.SwapRequest{}
+	if err := proto.Unmarshal(req.SwapRequest, swapRequest); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	swapFail, _ := proto.Marshal(&pbswap.SwapFail{MessageId: swapRequest.GetId()})
+	return &rpcext.TradeProposeMultiAssetReply{
+		SwapFail:       swapFail,
+		ExpiryTimeUnix: uint64(len(req.LegsP) + len(req.LegsR)),
+	}, nil
+}
+
+func (s *mockTradeServer) SwapStatus(
+	_ context.Context,
+	req *rpcext.SwapStatusRequest,
+) (*rpcext.SwapStatusReply, error) {
+	if req.SwapID != "swap-accept" {
+		return nil, status.Error(codes.NotFound, "swap not found")
+	}
+	return &rpcext.SwapStatusReply{
+		Status:        "confirmed",
+		TxID:          "txid",
+		BlockTimeUnix: 1,
+	}, nil
+}
+
+// newTestServer returns a server for the mock trade service that, like the
+// daemon, serves both gRPC and grpc-web requests. gRPC requests are served
+// only with TLS, since HTTP/2 is not enabled otherwise.
+func newTestServer(t *testing.T, withTLS bool) *httptest.Server {
+	grpcServer := grpc.NewServer()
+	pbtrade.RegisterTradeServer(grpcServer, &mockTradeServer{})
+	rpcext.RegisterTradeExtensionServer(grpcServer, &mockTradeServer{})
+	grpcWebServer := grpcweb.WrapServer(grpcServer)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if grpcWebServer.IsGrpcWebRequest(r) {
+			grpcWebServer.ServeHTTP(w, r)
+			return
+		}
+		if r.ProtoMajor == 2 &&
+			strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	if !withTLS {
+		return httptest.NewServer(handler)
+	}
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	return server
+}
+
+func hostAndPort(t *testing.T, serverURL string) (string, int) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(errors.New("invalid port"))
+	}
+	return host, port
+}
@@ -0,0 +1,352 @@
+package tradeclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	grpcWebContentType = "application/grpc-web"
+	// every message is prefixed by 1 byte of flags and 4 bytes of length
+	grpcWebFrameHeaderLen = 5
+	// the flag marking a frame as the one containing the trailers
+	grpcWebTrailerFlag = 0x80
+)
+
+// grpcWebConn is a connection that talks the grpc-web protocol over HTTP/1.1
+// with a server. It implements grpc.ClientConnInterface so that it can be
+// given to any gRPC generated client in place of a *grpc.ClientConn.
+type grpcWebConn struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newGrpcWebConn(address string, tlsConfig *tls.Config) *grpcWebConn {
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	return &grpcWebConn{
+		baseURL: fmt.Sprintf("%s://%s", scheme, address),
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}
+}
+
+// Invoke performs a unary RPC
+func (c *grpcWebConn) Invoke(
+	ctx context.Context,
+	method string,
+	args, reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	stream, err := c.NewStream(ctx, nil, method, opts...)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(args); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	if err := stream.RecvMsg(reply); err != nil {
+		return err
+	}
+	// consume the trailers to make sure the call succeeded
+	if err := stream.RecvMsg(reply); err != io.EOF {
+		if err == nil {
+			return status.Error(codes.Internal, "too many response messages")
+		}
+		return err
+	}
+	return nil
+}
+
+// NewStream begins a streaming RPC. Since grpc-web does not support client
+// streaming, the request is sent to the server only once CloseSend is called.
+// Of the given call options, only those selecting the content-subtype and
+// retrieving the header metadata are supported, the others are ignored.
+func (c *grpcWebConn) NewStream(
+	ctx context.Context,
+	_ *grpc.StreamDesc,
+	method string,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	stream := &grpcWebStream{
+		ctx:            ctx,
+		conn:           c,
+		method:         method,
+		contentSubtype: "proto",
+	}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.ContentSubtypeCallOption:
+			stream.contentSubtype = o.ContentSubtype
+		case grpc.HeaderCallOption:
+			stream.headerAddr = o.HeaderAddr
+		}
+	}
+	if stream.contentSubtype != "proto" {
+		stream.codec = encoding.GetCodec(stream.contentSubtype)
+		if stream.codec == nil {
+			return nil, status.Errorf(
+				codes.Internal, "no codec registered for content-subtype %s",
+				stream.contentSubtype,
+			)
+		}
+	}
+	return stream, nil
+}
+
+// Close closes the idle connections with the server
+func (c *grpcWebConn) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+type grpcWebStream struct {
+	ctx            context.Context
+	conn           *grpcWebConn
+	method         string
+	contentSubtype string
+	// codec is nil for the default proto content-subtype
+	codec      encoding.Codec
+	headerAddr *metadata.MD
+	body       []byte
+	resp       *http.Response
+	header     metadata.MD
+	trailer    metadata.MD
+	err        error
+}
+
+func (s *grpcWebStream) Header() (metadata.MD, error) {
+	if s.resp == nil {
+		return nil, status.Error(codes.Internal, "request has not been sent")
+	}
+	return s.header, nil
+}
+
+func (s *grpcWebStream) Trailer() metadata.MD {
+	return s.trailer
+}
+
+func (s *grpcWebStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *grpcWebStream) SendMsg(m interface{}) error {
+	buf, err := s.marshal(m)
+	if err != nil {
+		return err
+	}
+
+	frame := make([]byte, grpcWebFrameHeaderLen, grpcWebFrameHeaderLen+len(buf))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(buf)))
+	s.body = append(s.body, append(frame, buf...)...)
+	return nil
+}
+
+func (s *grpcWebStream) CloseSend() error {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodPost,
+		s.conn.baseURL+s.method,
+		bytes.NewReader(s.body),
+	)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	contentType := grpcWebContentType + "+" + s.contentSubtype
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+	req.Header.Set("X-Grpc-Web", "1")
+	if deadline, ok := s.ctx.Deadline(); ok {
+		timeout := time.Until(deadline).Milliseconds()
+		if timeout <= 0 {
+			return status.Error(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+		}
+		req.Header.Set("Grpc-Timeout", fmt.Sprintf("%dm", timeout))
+	}
+	if md, ok := metadata.FromOutgoingContext(s.ctx); ok {
+		for k, values := range md {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+
+	resp, err := s.conn.client.Do(req)
+	if err != nil {
+		return status.FromContextError(s.ctxErr(err)).Err()
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return status.Errorf(
+			codes.Unavailable, "unexpected HTTP status: %s", resp.Status,
+		)
+	}
+
+	s.resp = resp
+	s.header = metadata.MD{}
+	for k, values := range resp.Header {
+		s.header.Append(k, values...)
+	}
+	if s.headerAddr != nil {
+		*s.headerAddr = s.header
+	}
+	// trailers-only responses carry the status in the headers
+	if len(resp.Header.Get("Grpc-Status")) > 0 {
+		s.trailer = s.header
+		s.err = statusFromMD(s.header)
+	}
+	return nil
+}
+
+func (s *grpcWebStream) RecvMsg(m interface{}) error {
+	if s.resp == nil {
+		return status.Error(codes.Internal, "request has not been sent")
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	header := make([]byte, grpcWebFrameHeaderLen)
+	if _, err := io.ReadFull(s.resp.Body, header); err != nil {
+		s.resp.Body.Close()
+		if err == io.EOF {
+			s.err = status.Error(codes.Internal, "response is missing trailers")
+			return s.err
+		}
+		s.err = status.FromContextError(s.ctxErr(err)).Err()
+		return s.err
+	}
+	buf := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(s.resp.Body, buf); err != nil {
+		s.resp.Body.Close()
+		s.err = status.FromContextError(s.ctxErr(err)).Err()
+		return s.err
+	}
+
+	if header[0]&grpcWebTrailerFlag != 0 {
+		s.resp.Body.Close()
+		trailer, err := parseTrailer(buf)
+		if err != nil {
+			s.err = status.Error(codes.Internal, err.Error())
+			return s.err
+		}
+		s.trailer = trailer
+		s.err = statusFromMD(trailer)
+		return s.err
+	}
+
+	return s.unmarshal(buf, m)
+}
+
+func (s *grpcWebStream) marshal(m interface{}) ([]byte, error) {
+	if s.codec != nil {
+		buf, err := s.codec.Marshal(m)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return buf, nil
+	}
+
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "message %T is not a proto message", m)
+	}
+	buf, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return buf, nil
+}
+
+func (s *grpcWebStream) unmarshal(buf []byte, m interface{}) error {
+	if s.codec != nil {
+		if err := s.codec.Unmarshal(buf, m); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		return nil
+	}
+
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "message %T is not a proto message", m)
+	}
+	if err := proto.Unmarshal(buf, msg); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// ctxErr returns the error of the stream context, if any, in place of the
+// given one, so that cancellations and expired deadlines are reported as such
+func (s *grpcWebStream) ctxErr(err error) error {
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// parseTrailer parses the trailers of a response, encoded like HTTP/1.1
+// headers
+func parseTrailer(buf []byte) (metadata.MD, error) {
+	reader := textproto.NewReader(
+		bufio.NewReader(bytes.NewReader(append(buf, '\r', '\n'))),
+	)
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	md := metadata.MD{}
+	for k, values := range header {
+		md.Append(k, values...)
+	}
+	return md, nil
+}
+
+// statusFromMD returns the status error of a call from its trailers. If the
+// call succeeded, EOF is returned to signal the end of the stream.
+func statusFromMD(md metadata.MD) error {
+	values := md.Get("grpc-status")
+	if len(values) <= 0 {
+		return status.Error(codes.Internal, "response is missing grpc-status")
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil {
+		return status.Errorf(codes.Internal, "invalid grpc-status %s", values[0])
+	}
+	if codes.Code(code) == codes.OK {
+		return io.EOF
+	}
+
+	msg := ""
+	if values := md.Get("grpc-message"); len(values) > 0 {
+		msg, _ = url.PathUnescape(values[0])
+	}
+	return status.Error(codes.Code(code), msg)
+}
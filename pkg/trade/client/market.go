@@ -1,9 +1,10 @@
 package tradeclient
 
 import (
-	"context"
 	"errors"
 
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+
 	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
 	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
 
@@ -13,7 +14,10 @@ import (
 
 // Markets calls the Markets rpc and returns its response
 func (c *Client) Markets() (*pbtrade.MarketsReply, error) {
-	return c.client.Markets(context.Background(), &pbtrade.MarketsRequest{})
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	return c.client.Markets(ctx, &pbtrade.MarketsRequest{})
 }
 
 // BalancesOpts is the struct given to Balances method
@@ -40,7 +44,10 @@ func (c *Client) Balances(opts BalancesOpts) (*pbtrade.BalancesReply, error) {
 			QuoteAsset: opts.Market.QuoteAsset,
 		},
 	}
-	return c.client.Balances(context.Background(), request)
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	return c.client.Balances(ctx, request)
 }
 
 // MarketPriceOpts is the struct given to MarketPrice method
@@ -77,5 +84,38 @@ func (c *Client) MarketPrice(opts MarketPriceOpts) (*pbtrade.MarketPriceReply, e
 		Type:   pbtypes.TradeType(opts.TradeType),
 		Amount: opts.Amount,
 	}
-	return c.client.MarketPrice(context.Background(), request)
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	return c.client.MarketPrice(ctx, request)
+}
+
+// QuoteOpts is the struct given to Quote method
+type QuoteOpts struct {
+	Market    trademarket.Market
+	TradeType tradetype.TradeType
+	Amount    uint64
+}
+
+func (o QuoteOpts) validate() error {
+	return MarketPriceOpts(o).validate()
+}
+
+// Quote crafts the request and calls the Quote rpc. The returned QuoteID can
+// be given to TradePropose before the quote expires.
+func (c *Client) Quote(opts QuoteOpts) (*rpcext.QuoteReply, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	request := &rpcext.QuoteRequest{
+		BaseAsset:  opts.Market.BaseAsset,
+		QuoteAsset: opts.Market.QuoteAsset,
+		Type:       int(opts.TradeType),
+		Amount:     opts.Amount,
+	}
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	return c.ext.Quote(ctx, request)
 }
@@ -0,0 +1,24 @@
+package tradeclient
+
+import (
+	"errors"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+)
+
+// ErrNullSwapID ...
+var ErrNullSwapID = errors.New("swap id must not be null")
+
+// SwapStatus crafts the request and calls the SwapStatus rpc. The given id
+// can be either that of a SwapRequest or of a SwapAccept message.
+func (c *Client) SwapStatus(swapID string) (*rpcext.SwapStatusReply, error) {
+	if len(swapID) <= 0 {
+		return nil, ErrNullSwapID
+	}
+
+	request := &rpcext.SwapStatusRequest{SwapID: swapID}
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	return c.ext.SwapStatus(ctx, request)
+}
@@ -1,7 +1,6 @@
 package tradeclient
 
 import (
-	"context"
 	"errors"
 
 	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
@@ -69,7 +68,10 @@ func (c *Client) TradeComplete(opts TradeCompleteOpts) (*pbtrade.TradeCompleteRe
 		SwapComplete: swapComplete,
 		SwapFail:     swapFail,
 	}
-	stream, err := c.client.TradeComplete(context.Background(), request)
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	stream, err := c.client.TradeComplete(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,10 @@
 package tradeclient
 
 import (
-	"context"
 	"errors"
 
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+
 	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
 	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
 
@@ -11,6 +12,7 @@ import (
 	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
 	pbtypes "github.com/tdex-network/tdex-protobuf/generated/go/types"
 
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -19,11 +21,14 @@ var (
 	ErrMalformedSwapRequestMessage = errors.New("swap request must be a valid serialized message")
 )
 
-// TradeProposeOpts is the struct given to TradePropose method
+// TradeProposeOpts is the struct given to TradePropose method. QuoteID is
+// optional and, if defined, the proposal must match exactly the quote
+// returned by the Quote method.
 type TradeProposeOpts struct {
 	Market      trademarket.Market
 	SwapRequest []byte
 	TradeType   tradetype.TradeType
+	QuoteID     string
 }
 
 func (o TradeProposeOpts) validate() error {
@@ -58,7 +63,15 @@ func (c *Client) TradePropose(opts TradeProposeOpts) (*pbtrade.TradeProposeReply
 		SwapRequest: swapRequest,
 		Type:        pbtypes.TradeType(opts.TradeType),
 	}
-	stream, err := c.client.TradePropose(context.Background(), request)
+	ctx, cancel := c.callContext()
+	defer cancel()
+	if len(opts.QuoteID) > 0 {
+		ctx = metadata.AppendToOutgoingContext(
+			ctx, rpcext.QuoteIDHeader, opts.QuoteID,
+		)
+	}
+
+	stream, err := c.client.TradePropose(ctx, request)
 	if err != nil {
 		return nil, err
 	}
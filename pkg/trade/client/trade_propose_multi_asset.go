@@ -0,0 +1,109 @@
+package tradeclient
+
+import (
+	"errors"
+
+	"github.com/tdex-network/tdex-daemon/pkg/rpcext"
+	"github.com/tdex-network/tdex-daemon/pkg/swap"
+
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrNullLegs ...
+	ErrNullLegs = errors.New("legs to send and to receive must not be empty")
+	// ErrInvalidLeg ...
+	ErrInvalidLeg = errors.New("every leg must have a valid asset and a non zero amount")
+)
+
+// SupportsMultiAssetSwaps calls the Markets rpc and returns whether the
+// provider accepts multi-asset swaps
+func (c *Client) SupportsMultiAssetSwaps() (bool, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	var header metadata.MD
+	if _, err := c.client.Markets(
+		ctx, &pbtrade.MarketsRequest{}, grpc.Header(&header),
+	); err != nil {
+		return false, err
+	}
+	values := header.Get(rpcext.MultiAssetSwapsHeader)
+	return len(values) > 0 && values[0] == "true", nil
+}
+
+// TradeProposeMultiAssetOpts is the struct given to TradeProposeMultiAsset
+// method. The first legs sent (LegsP) and received (LegsR) must match the
+// assets and amounts of the serialized SwapRequest message.
+type TradeProposeMultiAssetOpts struct {
+	SwapRequest []byte
+	LegsP       []swap.Leg
+	LegsR       []swap.Leg
+}
+
+func (o TradeProposeMultiAssetOpts) validate() error {
+	if err := proto.Unmarshal(o.SwapRequest, &pbswap.SwapRequest{}); err != nil {
+		return ErrMalformedSwapRequestMessage
+	}
+	if len(o.LegsP) <= 0 || len(o.LegsR) <= 0 {
+		return ErrNullLegs
+	}
+	for _, leg := range append(append([]swap.Leg{}, o.LegsP...), o.LegsR...) {
+		if isValidAsset(leg.Asset) || leg.Amount <= 0 {
+			return ErrInvalidLeg
+		}
+	}
+	return nil
+}
+
+// TradeProposeMultiAsset crafts the request and calls the
+// TradeProposeMultiAsset rpc. The response is returned as that of the
+// TradePropose rpc.
+func (c *Client) TradeProposeMultiAsset(
+	opts TradeProposeMultiAssetOpts,
+) (*pbtrade.TradeProposeReply, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	request := &rpcext.TradeProposeMultiAssetRequest{
+		SwapRequest: opts.SwapRequest,
+		LegsP:       legsToRPC(opts.LegsP),
+		LegsR:       legsToRPC(opts.LegsR),
+	}
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	reply, err := c.ext.TradeProposeMultiAsset(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pbtrade.TradeProposeReply{ExpiryTimeUnix: reply.ExpiryTimeUnix}
+	if len(reply.SwapAccept) > 0 {
+		res.SwapAccept = &pbswap.SwapAccept{}
+		if err := proto.Unmarshal(reply.SwapAccept, res.SwapAccept); err != nil {
+			return nil, err
+		}
+	}
+	if len(reply.SwapFail) > 0 {
+		res.SwapFail = &pbswap.SwapFail{}
+		if err := proto.Unmarshal(reply.SwapFail, res.SwapFail); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func legsToRPC(legs []swap.Leg) []*rpcext.Leg {
+	res := make([]*rpcext.Leg, 0, len(legs))
+	for _, leg := range legs {
+		res = append(res, &rpcext.Leg{Asset: leg.Asset, Amount: leg.Amount})
+	}
+	return res
+}
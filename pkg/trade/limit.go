@@ -0,0 +1,126 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
+	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
+)
+
+var (
+	// ErrInvalidLimitPrice ...
+	ErrInvalidLimitPrice = errors.New("limit price must be a positive number")
+	// ErrLimitPriceNotMet ...
+	ErrLimitPriceNotMet = errors.New("trade price does not respect the limit")
+)
+
+// LimitOrderOpts is the struct given to LimitBuy/LimitSell methods.
+// LimitPrice is the max (buy) or min (sell) acceptable price, expressed as
+// amount of quote asset for one unit of base asset. The trade is funded,
+// blinded and signed either by Wallet, if given, or by the single key pair
+// PrivateKey/BlindingKey.
+type LimitOrderOpts struct {
+	Market      trademarket.Market
+	Amount      uint64
+	LimitPrice  decimal.Decimal
+	PrivateKey  []byte
+	BlindingKey []byte
+	Wallet      TraderWallet
+}
+
+func (o LimitOrderOpts) validate() error {
+	if err := o.Market.Validate(); err != nil {
+		return err
+	}
+	if o.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if !o.LimitPrice.IsPositive() {
+		return ErrInvalidLimitPrice
+	}
+	return validateWalletOrKeys(o.Wallet, o.PrivateKey, o.BlindingKey)
+}
+
+// LimitBuy works like BuyAndComplete, but the trade is aborted if the price
+// previewed by the provider, or the one resulting from the amounts of the
+// transaction returned by it, is higher than the given limit price.
+func (t *Trade) LimitBuy(opts LimitOrderOpts) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+	return t.limitOrder(opts, tradetype.Buy)
+}
+
+// LimitSell works like SellAndComplete, but the trade is aborted if the price
+// previewed by the provider, or the one resulting from the amounts of the
+// transaction returned by it, is lower than the given limit price.
+func (t *Trade) LimitSell(opts LimitOrderOpts) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+	return t.limitOrder(opts, tradetype.Sell)
+}
+
+func (t *Trade) limitOrder(
+	opts LimitOrderOpts,
+	tradeType tradetype.TradeType,
+) (string, error) {
+	limit := &limitPrice{tradeType, opts.LimitPrice}
+
+	w := t.walletOrKeys(opts.Wallet, opts.PrivateKey, opts.BlindingKey)
+	swapRequestMsg, swapAcceptMsg, err := t.orderRequest(
+		opts.Market,
+		tradeType,
+		opts.Amount,
+		w,
+		limit,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return t.marketOrderComplete(swapRequestMsg, swapAcceptMsg, w, limit)
+}
+
+// limitPrice is the guard against unfavorable prices for limit orders.
+// A nil limit price accepts any price, like in case of market orders.
+type limitPrice struct {
+	tradeType tradetype.TradeType
+	price     decimal.Decimal
+}
+
+// check returns an error if the price resulting from the given amounts to
+// send and receive is worse than the limit one
+func (l *limitPrice) check(amountToSend, amountToReceive uint64) error {
+	if l == nil {
+		return nil
+	}
+	if amountToSend == 0 || amountToReceive == 0 {
+		return ErrInvalidAmount
+	}
+
+	toSend := decimal.NewFromInt(int64(amountToSend))
+	toReceive := decimal.NewFromInt(int64(amountToReceive))
+
+	// buying base asset means sending quote asset, the other way round when
+	// selling
+	if l.tradeType.IsBuy() {
+		if price := toSend.Div(toReceive); price.GreaterThan(l.price) {
+			return fmt.Errorf(
+				"%w: buy price %s is higher than %s",
+				ErrLimitPriceNotMet, price.String(), l.price.String(),
+			)
+		}
+		return nil
+	}
+
+	if price := toReceive.Div(toSend); price.LessThan(l.price) {
+		return fmt.Errorf(
+			"%w: sell price %s is lower than %s",
+			ErrLimitPriceNotMet, price.String(), l.price.String(),
+		)
+	}
+	return nil
+}
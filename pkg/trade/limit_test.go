@@ -0,0 +1,114 @@
+package trade
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
+	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
+	"github.com/vulpemventures/go-elements/network"
+)
+
+func TestLimitPriceCheck(t *testing.T) {
+	tests := []struct {
+		limit           *limitPrice
+		amountToSend    uint64
+		amountToReceive uint64
+		err             error
+	}{
+		// market orders accept any price
+		{nil, 100000, 1, nil},
+		// buy 1 base for 6500 quote, limit 6500
+		{&limitPrice{tradetype.Buy, decimal.NewFromInt(6500)}, 6500, 1, nil},
+		// buy 1 base for 6501 quote, limit 6500
+		{&limitPrice{tradetype.Buy, decimal.NewFromInt(6500)}, 6501, 1, ErrLimitPriceNotMet},
+		// sell 2 base for 13000 quote, limit 6500
+		{&limitPrice{tradetype.Sell, decimal.NewFromInt(6500)}, 2, 13000, nil},
+		// sell 2 base for 12999 quote, limit 6500
+		{&limitPrice{tradetype.Sell, decimal.NewFromInt(6500)}, 2, 12999, ErrLimitPriceNotMet},
+		{&limitPrice{tradetype.Sell, decimal.NewFromInt(6500)}, 0, 12999, ErrInvalidAmount},
+	}
+
+	for i, tt := range tests {
+		err := tt.limit.check(tt.amountToSend, tt.amountToReceive)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("test %d: expected error %v, got %v", i, tt.err, err)
+		}
+	}
+}
+
+func TestFailingLimitOrder(t *testing.T) {
+	tt, err := newTestTrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := LimitOrderOpts{
+		Market: trademarket.Market{
+			BaseAsset:  "5ac9f65c0efcc4775e0baec4ec03abdde22473cd3cf33c0419ca290e0751b225",
+			QuoteAsset: "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3",
+		},
+		Amount:      100,
+		PrivateKey:  []byte{1},
+		BlindingKey: []byte{1},
+	}
+
+	if _, err := tt.LimitBuy(opts); err != ErrInvalidLimitPrice {
+		t.Fatalf("expected error %v, got %v", ErrInvalidLimitPrice, err)
+	}
+	opts.LimitPrice = decimal.NewFromInt(-1)
+	if _, err := tt.LimitSell(opts); err != ErrInvalidLimitPrice {
+		t.Fatalf("expected error %v, got %v", ErrInvalidLimitPrice, err)
+	}
+}
+
+func TestOrderOptsWalletOrKeys(t *testing.T) {
+	w, err := NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	market := trademarket.Market{
+		BaseAsset:  "5ac9f65c0efcc4775e0baec4ec03abdde22473cd3cf33c0419ca290e0751b225",
+		QuoteAsset: "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3",
+	}
+
+	tests := []struct {
+		wallet      TraderWallet
+		privateKey  []byte
+		blindingKey []byte
+		err         error
+	}{
+		{w, nil, nil, nil},
+		{nil, []byte{1}, []byte{1}, nil},
+		{w, []byte{1}, nil, ErrWalletAndKeys},
+		{w, nil, []byte{1}, ErrWalletAndKeys},
+		{nil, nil, []byte{1}, ErrNullPrivateKey},
+		{nil, []byte{1}, nil, ErrNullBlindingKey},
+	}
+
+	for i, tt := range tests {
+		limitOpts := LimitOrderOpts{
+			Market:      market,
+			Amount:      100,
+			LimitPrice:  decimal.NewFromInt(6500),
+			PrivateKey:  tt.privateKey,
+			BlindingKey: tt.blindingKey,
+			Wallet:      tt.wallet,
+		}
+		if err := limitOpts.validate(); err != tt.err {
+			t.Fatalf("test %d: expected error %v, got %v", i, tt.err, err)
+		}
+
+		tradeOpts := BuyOrSellAndCompleteOpts{
+			Market:      market,
+			TradeType:   int(tradetype.Buy),
+			Amount:      100,
+			PrivateKey:  tt.privateKey,
+			BlindingKey: tt.blindingKey,
+			Wallet:      tt.wallet,
+		}
+		if err := tradeOpts.validate(); err != tt.err {
+			t.Fatalf("test %d: expected error %v, got %v", i, tt.err, err)
+		}
+	}
+}
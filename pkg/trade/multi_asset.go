@@ -0,0 +1,235 @@
+package trade
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/tdex-network/tdex-daemon/pkg/swap"
+	tradeclient "github.com/tdex-network/tdex-daemon/pkg/trade/client"
+	"github.com/vulpemventures/go-elements/address"
+	"github.com/vulpemventures/go-elements/network"
+	"github.com/vulpemventures/go-elements/pset"
+	"github.com/vulpemventures/go-elements/transaction"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrMultiAssetSwapsNotSupported ...
+	ErrMultiAssetSwapsNotSupported = errors.New(
+		"provider does not support multi-asset swaps",
+	)
+	// ErrNullLegs ...
+	ErrNullLegs = errors.New("legs to send and to receive must not be empty")
+	// ErrInvalidLeg ...
+	ErrInvalidLeg = errors.New("every leg must have a valid asset and a non zero amount")
+)
+
+// MultiAssetTradeOpts is the struct given to
+// TradeMultiAssetAndCompleteWithWallet method. Every leg is an amount of some
+// asset either sent to or received from the provider.
+type MultiAssetTradeOpts struct {
+	LegsToSend    []swap.Leg
+	LegsToReceive []swap.Leg
+	Wallet        TraderWallet
+}
+
+func (o MultiAssetTradeOpts) validate() error {
+	if len(o.LegsToSend) <= 0 || len(o.LegsToReceive) <= 0 {
+		return ErrNullLegs
+	}
+	for _, leg := range append(append([]swap.Leg{}, o.LegsToSend...), o.LegsToReceive...) {
+		if buf, err := hex.DecodeString(leg.Asset); err != nil || len(buf) != 32 {
+			return ErrInvalidLeg
+		}
+		if leg.Amount <= 0 {
+			return ErrInvalidLeg
+		}
+	}
+	if o.Wallet == nil {
+		return ErrNullWallet
+	}
+	return nil
+}
+
+// TradeMultiAssetAndCompleteWithWallet creates a new multi-asset trade
+// proposal funded by the given wallet, sending and receiving any number of
+// assets at once, and sends it to the connected server. The transaction of the
+// resulting SwapAccept message is then validated against every leg, signed
+// and sent back again to the server for finalizing and broadcasting it.
+// The provider must accept multi-asset swaps.
+func (t *Trade) TradeMultiAssetAndCompleteWithWallet(
+	opts MultiAssetTradeOpts,
+) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+
+	ok, err := t.client.SupportsMultiAssetSwaps()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrMultiAssetSwapsNotSupported
+	}
+
+	unspents, err := opts.Wallet.Unspents(t.explorer)
+	if err != nil {
+		return "", err
+	}
+	if len(unspents) <= 0 {
+		return "", ErrWalletNotFunded
+	}
+
+	swapRequestMsg, err := newMultiAssetSwapRequest(
+		opts.Wallet, unspents, opts.LegsToSend, opts.LegsToReceive, t.network,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	reply, err := t.client.TradeProposeMultiAsset(
+		tradeclient.TradeProposeMultiAssetOpts{
+			SwapRequest: swapRequestMsg,
+			LegsP:       opts.LegsToSend,
+			LegsR:       opts.LegsToReceive,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	if fail := reply.GetSwapFail(); fail != nil {
+		return "", fmt.Errorf(
+			"%w for reason: %s", ErrTradeProposalRejected, fail.GetFailureMessage(),
+		)
+	}
+
+	swapAcceptMsg, err := proto.Marshal(reply.GetSwapAccept())
+	if err != nil {
+		return "", err
+	}
+	if err := swap.ValidateAccept(swap.ValidateAcceptOpts{
+		Request: swapRequestMsg,
+		Accept:  swapAcceptMsg,
+		LegsP:   opts.LegsToSend,
+		LegsR:   opts.LegsToReceive,
+	}); err != nil {
+		return "", fmt.Errorf("invalid trade proposal accept: %w", err)
+	}
+
+	return t.completeSwap(swapAcceptMsg, opts.Wallet)
+}
+
+// newMultiAssetSwapRequest works like newSwapRequest for a swap with many
+// legs per side
+func newMultiAssetSwapRequest(
+	w fundingWallet,
+	unspents []explorer.Utxo,
+	legsToSend, legsToReceive []swap.Leg,
+	net *network.Network,
+) ([]byte, error) {
+	receiveAddress, err := w.ReceiveAddress()
+	if err != nil {
+		return nil, err
+	}
+	changeAddress, err := w.ChangeAddress()
+	if err != nil {
+		return nil, err
+	}
+	outputScript, err := address.ToOutputScript(receiveAddress, *net)
+	if err != nil {
+		return nil, err
+	}
+	changeScript, err := address.ToOutputScript(changeAddress, *net)
+	if err != nil {
+		return nil, err
+	}
+
+	blindingKeys, inputBlindingKeys, outputBlindingKeys, err := getBlindingKeys(
+		w, unspents, outputScript, changeScript,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	psetBase64, err := newMultiAssetSwapTx(
+		unspents,
+		blindingKeys,
+		legsToSend,
+		legsToReceive,
+		outputScript,
+		changeScript,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return swap.MultiAssetRequest(swap.MultiAssetRequestOpts{
+		LegsToBeSent:       legsToSend,
+		LegsToReceive:      legsToReceive,
+		PsetBase64:         psetBase64,
+		InputBlindingKeys:  inputBlindingKeys,
+		OutputBlindingKeys: outputBlindingKeys,
+	})
+}
+
+// newMultiAssetSwapTx works like newSwapTx, but the given unspents are
+// selected to cover every leg to send, and an output is added for every leg
+// to receive, before the change ones.
+func newMultiAssetSwapTx(
+	unspents []explorer.Utxo,
+	blindingKeys [][]byte,
+	legsToSend, legsToReceive []swap.Leg,
+	outScript []byte,
+	changeScript []byte,
+) (string, error) {
+	ptx, err := pset.New([]*transaction.TxInput{}, []*transaction.TxOutput{}, 2, 0)
+	if err != nil {
+		return "", err
+	}
+	updater, _ := pset.NewUpdater(ptx)
+
+	changeOutputs := make([]*transaction.TxOutput, 0)
+	for _, leg := range legsToSend {
+		selectedUnspents, change, err := explorer.SelectUnspents(
+			unspents,
+			blindingKeys,
+			leg.Amount,
+			leg.Asset,
+		)
+		if err != nil {
+			return "", err
+		}
+
+		for _, in := range selectedUnspents {
+			input, witnessUtxo, _ := in.Parse()
+			updater.AddInput(input)
+			err := updater.AddInWitnessUtxo(witnessUtxo, len(ptx.Inputs)-1)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if change > 0 {
+			changeOutput, err := newTxOutput(leg.Asset, change, changeScript)
+			if err != nil {
+				return "", err
+			}
+			changeOutputs = append(changeOutputs, changeOutput)
+		}
+	}
+
+	for _, leg := range legsToReceive {
+		output, err := newTxOutput(leg.Asset, leg.Amount, outScript)
+		if err != nil {
+			return "", err
+		}
+		updater.AddOutput(output)
+	}
+	for _, output := range changeOutputs {
+		updater.AddOutput(output)
+	}
+
+	return ptx.ToBase64()
+}
@@ -0,0 +1,108 @@
+package trade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/tdex-network/tdex-daemon/pkg/swap"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	"github.com/vulpemventures/go-elements/network"
+	"github.com/vulpemventures/go-elements/pset"
+	"google.golang.org/protobuf/proto"
+)
+
+const testOtherAsset = "0e99c1a6da379d1f4151fb9df90449d40d0608f6cb33a5bcbfc8c265f42bab0a"
+
+func TestNewMultiAssetSwapRequest(t *testing.T) {
+	w, err := newMultiKeyWallet(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.utxos = []explorer.Utxo{
+		newTestUtxo(w.wallets[0], 0, 6000, testQuoteAsset),
+		newTestUtxo(w.wallets[1], 1, 6000, testQuoteAsset),
+		newTestUtxo(w.wallets[1], 2, 3000, testOtherAsset),
+	}
+	legsToSend := []swap.Leg{
+		{Asset: testQuoteAsset, Amount: 10000},
+		{Asset: testOtherAsset, Amount: 3000},
+	}
+	legsToReceive := []swap.Leg{
+		{Asset: testBaseAsset, Amount: 1000},
+	}
+
+	swapRequestMsg, err := newMultiAssetSwapRequest(
+		w, w.utxos, legsToSend, legsToReceive, &network.Regtest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapRequest := &pb.SwapRequest{}
+	if err := proto.Unmarshal(swapRequestMsg, swapRequest); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testQuoteAsset, swapRequest.GetAssetP())
+	assert.Equal(t, uint64(10000), swapRequest.GetAmountP())
+	assert.Equal(t, testBaseAsset, swapRequest.GetAssetR())
+	assert.Equal(t, uint64(1000), swapRequest.GetAmountR())
+
+	ptx, err := pset.NewPsetFromBase64(swapRequest.GetTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, receiveScript := w.wallets[0].Script()
+	_, changeScript := w.wallets[2].Script()
+	// only the utxos of the quote asset give change
+	assert.Equal(t, 3, len(ptx.Inputs))
+	assert.Equal(t, 2, len(ptx.Outputs))
+	assert.Equal(t, receiveScript, ptx.UnsignedTx.Outputs[0].Script)
+	assert.Equal(t, changeScript, ptx.UnsignedTx.Outputs[1].Script)
+}
+
+func TestFailingMultiAssetTradeOpts(t *testing.T) {
+	w, err := NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opts MultiAssetTradeOpts
+		err  error
+	}{
+		{
+			opts: MultiAssetTradeOpts{
+				LegsToReceive: []swap.Leg{{Asset: testBaseAsset, Amount: 1}},
+				Wallet:        w,
+			},
+			err: ErrNullLegs,
+		},
+		{
+			opts: MultiAssetTradeOpts{
+				LegsToSend:    []swap.Leg{{Asset: "asset", Amount: 1}},
+				LegsToReceive: []swap.Leg{{Asset: testBaseAsset, Amount: 1}},
+				Wallet:        w,
+			},
+			err: ErrInvalidLeg,
+		},
+		{
+			opts: MultiAssetTradeOpts{
+				LegsToSend:    []swap.Leg{{Asset: testQuoteAsset, Amount: 0}},
+				LegsToReceive: []swap.Leg{{Asset: testBaseAsset, Amount: 1}},
+				Wallet:        w,
+			},
+			err: ErrInvalidLeg,
+		},
+		{
+			opts: MultiAssetTradeOpts{
+				LegsToSend:    []swap.Leg{{Asset: testQuoteAsset, Amount: 1}},
+				LegsToReceive: []swap.Leg{{Asset: testBaseAsset, Amount: 1}},
+			},
+			err: ErrNullWallet,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.err, tt.opts.validate())
+	}
+}
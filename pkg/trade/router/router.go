@@ -0,0 +1,210 @@
+package traderouter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	tradeclient "github.com/tdex-network/tdex-daemon/pkg/trade/client"
+	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
+)
+
+var (
+	// ErrNullProviders ...
+	ErrNullProviders = errors.New("providers must not be null")
+	// ErrInvalidProvider ...
+	ErrInvalidProvider = errors.New("provider must have a unique name and a client")
+	// ErrNoQuotes is returned when none of the providers could preview the trade
+	ErrNoQuotes = errors.New("none of the providers could quote the trade")
+	// ErrAllProvidersRejected is returned when all the providers quoting the
+	// trade replied with a SwapFail
+	ErrAllProvidersRejected = errors.New("trade has been rejected by all providers")
+)
+
+// Provider is a liquidity provider the router can trade with
+type Provider struct {
+	Name   string
+	Client *tradeclient.Client
+}
+
+// Router routes trades to the provider that offers the best price among
+// those it is connected to
+type Router struct {
+	providers []Provider
+	trades    map[string]*trade.Trade
+}
+
+// NewRouterOpts is the struct given to NewRouter method
+type NewRouterOpts struct {
+	Chain       string
+	ExplorerURL string
+	Providers   []Provider
+}
+
+func (o NewRouterOpts) validate() error {
+	if len(o.Providers) <= 0 {
+		return ErrNullProviders
+	}
+	names := make(map[string]bool)
+	for _, p := range o.Providers {
+		if len(p.Name) <= 0 || p.Client == nil || names[p.Name] {
+			return ErrInvalidProvider
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// NewRouter returns a new router for the given providers
+func NewRouter(opts NewRouterOpts) (*Router, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	trades := make(map[string]*trade.Trade)
+	for _, p := range opts.Providers {
+		t, err := trade.NewTrade(trade.NewTradeOpts{
+			Chain:       opts.Chain,
+			ExplorerURL: opts.ExplorerURL,
+			Client:      p.Client,
+		})
+		if err != nil {
+			return nil, err
+		}
+		trades[p.Name] = t
+	}
+
+	return &Router{
+		providers: opts.Providers,
+		trades:    trades,
+	}, nil
+}
+
+// Quote is the preview of a trade offered by a provider
+type Quote struct {
+	Provider string
+	Preview  *trade.PreviewResult
+}
+
+// Quotes concurrently queries all the providers for a preview of the given
+// trade and returns those obtained, sorted from the best to the worst one.
+// Since providers charge their fees on the previewed amounts, the best quote
+// is the one with the lowest amount to send when buying, and the one with the
+// highest amount to receive when selling.
+// Providers that fail to preview the trade are just left out.
+func (r *Router) Quotes(opts trade.PreviewOpts) ([]Quote, error) {
+	quotes := make([]*Quote, len(r.providers))
+	errs := make([]error, len(r.providers))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(r.providers))
+	for i, p := range r.providers {
+		go func(i int, p Provider) {
+			defer wg.Done()
+
+			preview, err := r.trades[p.Name].Preview(opts)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			quotes[i] = &Quote{p.Name, preview}
+		}(i, p)
+	}
+	wg.Wait()
+
+	result := make([]Quote, 0, len(quotes))
+	for _, q := range quotes {
+		if q != nil {
+			result = append(result, *q)
+		}
+	}
+	if len(result) <= 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNoQuotes, errs)
+	}
+
+	isBuy := tradetype.TradeType(opts.TradeType).IsBuy()
+	sort.SliceStable(result, func(i, j int) bool {
+		if isBuy {
+			return result[i].Preview.AmountToSend < result[j].Preview.AmountToSend
+		}
+		return result[i].Preview.AmountToReceive > result[j].Preview.AmountToReceive
+	})
+
+	return result, nil
+}
+
+// BestQuote returns the best quote among those of all the providers
+func (r *Router) BestQuote(opts trade.PreviewOpts) (*Quote, error) {
+	quotes, err := r.Quotes(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &quotes[0], nil
+}
+
+// TradeResult is the struct returned by BuyAndComplete/SellAndComplete
+// methods
+type TradeResult struct {
+	Provider string
+	TxID     string
+}
+
+// BuyAndComplete buys with the provider offering the best price. If the
+// provider rejects the trade with a SwapFail, the next best one is tried and
+// so on.
+func (r *Router) BuyAndComplete(
+	opts trade.BuyOrSellAndCompleteOpts,
+) (*TradeResult, error) {
+	opts.TradeType = int(tradetype.Buy)
+	return r.tradeAndComplete(opts)
+}
+
+// SellAndComplete sells with the provider offering the best price. If the
+// provider rejects the trade with a SwapFail, the next best one is tried and
+// so on.
+func (r *Router) SellAndComplete(
+	opts trade.BuyOrSellAndCompleteOpts,
+) (*TradeResult, error) {
+	opts.TradeType = int(tradetype.Sell)
+	return r.tradeAndComplete(opts)
+}
+
+func (r *Router) tradeAndComplete(
+	opts trade.BuyOrSellAndCompleteOpts,
+) (*TradeResult, error) {
+	quotes, err := r.Quotes(trade.PreviewOpts{
+		Market:    opts.Market,
+		TradeType: opts.TradeType,
+		Amount:    opts.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	isBuy := tradetype.TradeType(opts.TradeType).IsBuy()
+	rejections := make([]error, 0, len(quotes))
+	for _, q := range quotes {
+		t := r.trades[q.Provider]
+
+		var txID string
+		if isBuy {
+			txID, err = t.BuyAndComplete(opts)
+		} else {
+			txID, err = t.SellAndComplete(opts)
+		}
+		if err != nil {
+			if errors.Is(err, trade.ErrTradeProposalRejected) ||
+				errors.Is(err, trade.ErrTradeCompleteRejected) {
+				rejections = append(rejections, fmt.Errorf("%s: %w", q.Provider, err))
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", q.Provider, err)
+		}
+
+		return &TradeResult{q.Provider, txID}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrAllProvidersRejected, rejections)
+}
@@ -0,0 +1,470 @@
+package traderouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/internal/core/application"
+	"github.com/tdex-network/tdex-daemon/internal/infrastructure/storage/db/inmemory"
+	grpchandler "github.com/tdex-network/tdex-daemon/internal/interfaces/grpc/handler"
+	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
+	"github.com/tdex-network/tdex-daemon/pkg/swap"
+	"github.com/tdex-network/tdex-daemon/pkg/trade"
+	tradeclient "github.com/tdex-network/tdex-daemon/pkg/trade/client"
+	trademarket "github.com/tdex-network/tdex-daemon/pkg/trade/market"
+	tradetype "github.com/tdex-network/tdex-daemon/pkg/trade/type"
+	pbswap "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	pbtrade "github.com/tdex-network/tdex-protobuf/generated/go/trade"
+	"github.com/vulpemventures/go-elements/address"
+	"github.com/vulpemventures/go-elements/network"
+	"github.com/vulpemventures/go-elements/pset"
+	"github.com/vulpemventures/go-elements/transaction"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	baseAsset  = "5ac9f65c0efcc4775e0baec4ec03abdde22473cd3cf33c0419ca290e0751b225"
+	quoteAsset = "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3"
+)
+
+var market = trademarket.Market{
+	BaseAsset:  baseAsset,
+	QuoteAsset: quoteAsset,
+}
+
+func TestQuotes(t *testing.T) {
+	router, cleanup := newTestRouter(t, "", []*mockTradeService{
+		{name: "a", previewAmount: 1100},
+		{name: "b", previewAmount: 1000},
+		{name: "c", priceErr: errors.New("market is closed")},
+		{name: "d", previewAmount: 1200},
+	})
+	defer cleanup()
+
+	quotes, err := router.Quotes(trade.PreviewOpts{
+		Market:    market,
+		TradeType: int(tradetype.Buy),
+		Amount:    100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(quotes))
+	assert.Equal(t, "b", quotes[0].Provider)
+	assert.Equal(t, "a", quotes[1].Provider)
+	assert.Equal(t, "d", quotes[2].Provider)
+
+	quotes, err = router.Quotes(trade.PreviewOpts{
+		Market:    market,
+		TradeType: int(tradetype.Sell),
+		Amount:    100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, len(quotes))
+	assert.Equal(t, "d", quotes[0].Provider)
+	assert.Equal(t, "a", quotes[1].Provider)
+	assert.Equal(t, "b", quotes[2].Provider)
+}
+
+func TestFailingQuotes(t *testing.T) {
+	router, cleanup := newTestRouter(t, "", []*mockTradeService{
+		{name: "a", priceErr: errors.New("market is closed")},
+		{name: "b", priceErr: errors.New("market not found")},
+	})
+	defer cleanup()
+
+	_, err := router.BestQuote(trade.PreviewOpts{
+		Market:    market,
+		TradeType: int(tradetype.Buy),
+		Amount:    100,
+	})
+	assert.True(t, errors.Is(err, ErrNoQuotes))
+}
+
+func TestBuyAndCompleteWithFallback(t *testing.T) {
+	w, err := trade.NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	explorerSvc := newMockExplorer(t, w.Address(), quoteAsset, 100000)
+	defer explorerSvc.Close()
+
+	providers := []*mockTradeService{
+		{name: "a", previewAmount: 1100, accept: true},
+		{name: "b", previewAmount: 1000},
+		{name: "c", previewAmount: 1200, accept: true},
+	}
+	router, cleanup := newTestRouter(t, explorerSvc.URL, providers)
+	defer cleanup()
+
+	result, err := router.BuyAndComplete(trade.BuyOrSellAndCompleteOpts{
+		Market:      market,
+		Amount:      100,
+		PrivateKey:  w.PrivateKey(),
+		BlindingKey: w.BlindingKey(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the best provider rejects the trade, the second best one completes it
+	assert.Equal(t, "a", result.Provider)
+	assert.Equal(t, "txid-a", result.TxID)
+	assert.Equal(t, 1, providers[1].proposeCount)
+	assert.Equal(t, 1, providers[0].proposeCount)
+	assert.Equal(t, 0, providers[2].proposeCount)
+}
+
+func TestBuyAndCompleteAllRejected(t *testing.T) {
+	w, err := trade.NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	explorerSvc := newMockExplorer(t, w.Address(), quoteAsset, 100000)
+	defer explorerSvc.Close()
+
+	providers := []*mockTradeService{
+		{name: "a", previewAmount: 1100},
+		{name: "b", previewAmount: 1000},
+	}
+	router, cleanup := newTestRouter(t, explorerSvc.URL, providers)
+	defer cleanup()
+
+	_, err = router.BuyAndComplete(trade.BuyOrSellAndCompleteOpts{
+		Market:      market,
+		Amount:      100,
+		PrivateKey:  w.PrivateKey(),
+		BlindingKey: w.BlindingKey(),
+	})
+	assert.True(t, errors.Is(err, ErrAllProvidersRejected))
+	assert.Equal(t, 1, providers[0].proposeCount)
+	assert.Equal(t, 1, providers[1].proposeCount)
+}
+
+func TestFailingNewRouter(t *testing.T) {
+	client, err := tradeclient.NewTradeClient("localhost", 9945)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.CloseConnection()
+
+	tests := []struct {
+		providers []Provider
+		err       error
+	}{
+		{nil, ErrNullProviders},
+		{[]Provider{{Name: "", Client: client}}, ErrInvalidProvider},
+		{[]Provider{{Name: "a", Client: nil}}, ErrInvalidProvider},
+		{[]Provider{{Name: "a", Client: client}, {Name: "a", Client: client}}, ErrInvalidProvider},
+	}
+
+	for _, tt := range tests {
+		_, err := NewRouter(NewRouterOpts{
+			Chain:       network.Regtest.Name,
+			ExplorerURL: "http://localhost:3001",
+			Providers:   tt.providers,
+		})
+		assert.Equal(t, tt.err, err)
+	}
+}
+
+// newTestRouter starts an in-process gRPC server for every given mocked
+// service, exposed through the daemon's trade handler, and returns a router
+// connected to all of them
+func newTestRouter(
+	t *testing.T,
+	explorerURL string,
+	services []*mockTradeService,
+) (*Router, func()) {
+	if explorerURL == "" {
+		explorerURL = "http://localhost:3001"
+	}
+
+	providers := make([]Provider, 0, len(services))
+	cleanups := make([]func(), 0, len(services))
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+
+	for _, svc := range services {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+		server := grpc.NewServer()
+		pbtrade.RegisterTradeServer(
+			server,
+			grpchandler.NewTraderHandler(svc, inmemory.NewDbManager()),
+		)
+		go server.Serve(lis)
+
+		client, err := tradeclient.NewTradeClient(
+			"127.0.0.1",
+			lis.Addr().(*net.TCPAddr).Port,
+		)
+		if err != nil {
+			server.Stop()
+			cleanup()
+			t.Fatal(err)
+		}
+
+		providers = append(providers, Provider{svc.name, client})
+		cleanups = append(cleanups, func() {
+			client.CloseConnection()
+			server.Stop()
+		})
+	}
+
+	router, err := NewRouter(NewRouterOpts{
+		Chain:       network.Regtest.Name,
+		ExplorerURL: explorerURL,
+		Providers:   providers,
+	})
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return router, cleanup
+}
+
+// newMockExplorer returns an http server mocking the explorer endpoints used
+// by the trade package, that returns a single unspent of the given asset and
+// value for the given address
+func newMockExplorer(
+	t *testing.T,
+	addr, asset string,
+	value uint64,
+) *httptest.Server {
+	script, err := address.ToOutputScript(addr, network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assetBytes, err := bufferutil.AssetHashToBytes(asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valueBytes, err := bufferutil.ValueToBytes(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := transaction.NewTx(2)
+	tx.AddInput(transaction.NewTxInput(make([]byte, 32), 0))
+	tx.AddOutput(transaction.NewTxOutput(assetBytes, valueBytes, script))
+	txHex, err := tx.ToHex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txID := tx.TxHash().String()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/address/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, addr) {
+			json.NewEncoder(w).Encode([]interface{}{})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{
+				"txid":   txID,
+				"vout":   0,
+				"value":  value,
+				"asset":  asset,
+				"status": map[string]interface{}{"confirmed": true},
+			},
+		})
+	})
+	mux.HandleFunc("/tx/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == fmt.Sprintf("/tx/%s/hex", txID):
+			w.Write([]byte(txHex))
+		case r.URL.Path == fmt.Sprintf("/tx/%s/status", txID):
+			json.NewEncoder(w).Encode(map[string]interface{}{"confirmed": true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+// mockTradeService is an application.TradeService that previews any trade
+// with previewAmount, or fails with priceErr if defined. Trade proposals are
+// either accepted or rejected with a SwapFail depending on accept.
+type mockTradeService struct {
+	name          string
+	previewAmount uint64
+	priceErr      error
+	accept        bool
+	proposeCount  int
+}
+
+func (m *mockTradeService) GetTradableMarkets(
+	ctx context.Context,
+) ([]application.MarketWithFee, error) {
+	return nil, nil
+}
+
+func (m *mockTradeService) GetMarketPrice(
+	ctx context.Context,
+	market application.Market,
+	tradeType int,
+	amount uint64,
+) (*application.PriceWithFee, error) {
+	if m.priceErr != nil {
+		return nil, m.priceErr
+	}
+	return &application.PriceWithFee{
+		Fee: application.Fee{
+			FeeAsset:   market.BaseAsset,
+			BasisPoint: 25,
+		},
+		Amount: m.previewAmount,
+	}, nil
+}
+
+func (m *mockTradeService) GetMarketQuote(
+	ctx context.Context,
+	market application.Market,
+	tradeType int,
+	amount uint64,
+) (*application.Quote, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTradeService) TradeProposeWithQuote(
+	ctx context.Context,
+	market application.Market,
+	tradeType int,
+	swapRequest *pbswap.SwapRequest,
+	quoteID string,
+) (*pbswap.SwapAccept, *pbswap.SwapFail, uint64, error) {
+	return m.TradePropose(ctx, market, tradeType, swapRequest)
+}
+
+func (m *mockTradeService) TradePropose(
+	ctx context.Context,
+	market application.Market,
+	tradeType int,
+	swapRequest *pbswap.SwapRequest,
+) (*pbswap.SwapAccept, *pbswap.SwapFail, uint64, error) {
+	m.proposeCount++
+
+	if !m.accept {
+		return nil, &pbswap.SwapFail{
+			Id:             "fail",
+			MessageId:      swapRequest.GetId(),
+			FailureCode:    uint32(swap.ErrCodeRejectedSwapRequest),
+			FailureMessage: "not enough liquidity",
+		}, 0, nil
+	}
+
+	swapAccept, err := acceptSwapRequest(swapRequest)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return swapAccept, nil, 0, nil
+}
+
+func (m *mockTradeService) TradeProposeMultiAsset(
+	ctx context.Context,
+	swapRequest *pbswap.SwapRequest,
+	legsP, legsR []swap.Leg,
+) (*pbswap.SwapAccept, *pbswap.SwapFail, uint64, error) {
+	return nil, nil, 0, application.ErrMultiAssetSwapsDisabled
+}
+
+func (m *mockTradeService) TradeComplete(
+	ctx context.Context,
+	swapComplete *pbswap.SwapComplete,
+	swapFail *pbswap.SwapFail,
+) (string, *pbswap.SwapFail, error) {
+	return "txid-" + m.name, nil, nil
+}
+
+func (m *mockTradeService) GetSwapStatus(
+	ctx context.Context,
+	swapID string,
+) (*application.SwapStatus, error) {
+	return nil, application.ErrSwapNotFound
+}
+
+func (m *mockTradeService) GetMarketBalance(
+	ctx context.Context,
+	market application.Market,
+) (*application.BalanceWithFee, error) {
+	return nil, nil
+}
+
+// acceptSwapRequest adds to the transaction of the given request a signed
+// input with the amount of asset the proposer wants to receive, and an output
+// with the amount of asset it sends to the provider
+func acceptSwapRequest(swapRequest *pbswap.SwapRequest) (*pbswap.SwapAccept, error) {
+	ptx, err := pset.NewPsetFromBase64(swapRequest.GetTransaction())
+	if err != nil {
+		return nil, err
+	}
+	updater, err := pset.NewUpdater(ptx)
+	if err != nil {
+		return nil, err
+	}
+
+	providerWallet, err := trade.NewRandomWallet(&network.Regtest)
+	if err != nil {
+		return nil, err
+	}
+	_, providerScript := providerWallet.Script()
+	assetR, _ := bufferutil.AssetHashToBytes(swapRequest.GetAssetR())
+	amountR, _ := bufferutil.ValueToBytes(swapRequest.GetAmountR())
+	assetP, _ := bufferutil.AssetHashToBytes(swapRequest.GetAssetP())
+	amountP, _ := bufferutil.ValueToBytes(swapRequest.GetAmountP())
+
+	prevoutHash := make([]byte, 32)
+	prevoutHash[0] = 1
+	updater.AddInput(transaction.NewTxInput(prevoutHash, 0))
+	if err := updater.AddInWitnessUtxo(
+		transaction.NewTxOutput(assetR, amountR, providerScript),
+		len(ptx.Inputs)-1,
+	); err != nil {
+		return nil, err
+	}
+	updater.AddOutput(transaction.NewTxOutput(assetP, amountP, providerScript))
+
+	psetBase64, err := ptx.ToBase64()
+	if err != nil {
+		return nil, err
+	}
+	signedPsetBase64, err := providerWallet.Sign(psetBase64)
+	if err != nil {
+		return nil, err
+	}
+	swapRequestMsg, err := proto.Marshal(swapRequest)
+	if err != nil {
+		return nil, err
+	}
+	_, swapAcceptMsg, err := swap.Accept(swap.AcceptOpts{
+		Message:    swapRequestMsg,
+		PsetBase64: signedPsetBase64,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	swapAccept := &pbswap.SwapAccept{}
+	if err := proto.Unmarshal(swapAcceptMsg, swapAccept); err != nil {
+		return nil, err
+	}
+	return swapAccept, nil
+}
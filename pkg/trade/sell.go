@@ -33,17 +33,18 @@ func (t *Trade) SellAndComplete(opts BuyOrSellAndCompleteOpts) (string, error) {
 		return "", err
 	}
 
-	w := NewWalletFromKey(opts.PrivateKey, opts.BlindingKey, t.network)
-	swapAcceptMsg, err := t.marketOrderRequest(
-		opts.Market,
-		tradetype.Sell,
-		opts.Amount,
-		w.Address(),
-		opts.BlindingKey,
-	)
-	if err != nil {
+	w := t.walletOrKeys(opts.Wallet, opts.PrivateKey, opts.BlindingKey)
+	return t.tradeAndComplete(opts.Market, tradetype.Sell, opts.Amount, w)
+}
+
+// SellAndCompleteWithWallet works like SellAndComplete, but the trade is
+// funded, blinded and signed by the given wallet instead of a single private
+// key.
+func (t *Trade) SellAndCompleteWithWallet(
+	opts TradeWithWalletOpts,
+) (string, error) {
+	if err := opts.validate(); err != nil {
 		return "", err
 	}
-
-	return t.marketOrderComplete(swapAcceptMsg, w)
+	return t.tradeAndComplete(opts.Market, tradetype.Sell, opts.Amount, opts.Wallet)
 }
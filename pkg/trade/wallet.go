@@ -2,18 +2,111 @@ package trade
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/tdex-network/tdex-daemon/pkg/bufferutil"
 	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	"github.com/vulpemventures/go-elements/address"
 	"github.com/vulpemventures/go-elements/network"
 	"github.com/vulpemventures/go-elements/payment"
 	"github.com/vulpemventures/go-elements/pset"
 	"github.com/vulpemventures/go-elements/transaction"
 )
 
+var (
+	// ErrUnknownScript ...
+	ErrUnknownScript = errors.New("script does not belong to the wallet")
+)
+
+// CoinProvider is the source of the funds of a trader wallet and of the
+// addresses where to receive the swapped asset and the change.
+type CoinProvider interface {
+	// Unspents returns all the utxos owned by the wallet. The given explorer
+	// can be used to fetch them from the chain.
+	Unspents(explorerSvc explorer.Service) ([]explorer.Utxo, error)
+	// ReceiveAddress returns the confidential address where to receive the
+	// asset bought or sold.
+	ReceiveAddress() (string, error)
+	// ChangeAddress returns the confidential address where to send the change
+	// of the selected utxos.
+	ChangeAddress() (string, error)
+}
+
+// BlindingKeyProvider returns the private blinding key of any output script
+// owned by a trader wallet.
+type BlindingKeyProvider interface {
+	BlindingKeyForScript(script []byte) ([]byte, error)
+}
+
+// Signer signs those inputs of a partial transaction owned by a trader
+// wallet.
+type Signer interface {
+	Sign(psetBase64 string) (string, error)
+}
+
+// TraderWallet is the interface a wallet must implement to fund, blind and
+// sign swap requests. It allows to trade with wallets that own many
+// confidential utxos locked by different scripts, like HD ones. Wallet is the
+// default single-key implementation.
+type TraderWallet interface {
+	CoinProvider
+	BlindingKeyProvider
+	Signer
+}
+
+// fundingWallet is the part of a trader wallet needed to make a swap request
+type fundingWallet interface {
+	CoinProvider
+	BlindingKeyProvider
+}
+
+// watchOnlyWallet is the funding wallet of trade proposals made for a single
+// address, without the need of signing them
+type watchOnlyWallet struct {
+	address     string
+	blindingKey []byte
+	network     *network.Network
+}
+
+func newWatchOnlyWallet(
+	addr string,
+	blindingKey []byte,
+	net *network.Network,
+) *watchOnlyWallet {
+	return &watchOnlyWallet{addr, blindingKey, net}
+}
+
+func (w *watchOnlyWallet) Unspents(
+	explorerSvc explorer.Service,
+) ([]explorer.Utxo, error) {
+	return explorerSvc.GetUnspents(w.address, [][]byte{w.blindingKey})
+}
+
+func (w *watchOnlyWallet) ReceiveAddress() (string, error) {
+	return w.address, nil
+}
+
+func (w *watchOnlyWallet) ChangeAddress() (string, error) {
+	return w.address, nil
+}
+
+func (w *watchOnlyWallet) BlindingKeyForScript(script []byte) ([]byte, error) {
+	outputScript, err := address.ToOutputScript(w.address, *w.network)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(script, outputScript) {
+		return nil, ErrUnknownScript
+	}
+	return w.blindingKey, nil
+}
+
+// NewSwapTx returns a new partial transaction for a swap request spending the
+// given unspents, all of them blinded with the given key, and sending both
+// the asset to receive and the change to outScript.
 func NewSwapTx(
 	unspents []explorer.Utxo,
 	blindingKey []byte,
@@ -22,6 +115,28 @@ func NewSwapTx(
 	outAsset string,
 	outAmount uint64,
 	outScript []byte,
+) (string, error) {
+	return newSwapTx(
+		unspents,
+		[][]byte{blindingKey},
+		inAsset,
+		inAmount,
+		outAsset,
+		outAmount,
+		outScript,
+		outScript,
+	)
+}
+
+func newSwapTx(
+	unspents []explorer.Utxo,
+	blindingKeys [][]byte,
+	inAsset string,
+	inAmount uint64,
+	outAsset string,
+	outAmount uint64,
+	outScript []byte,
+	changeScript []byte,
 ) (string, error) {
 	ptx, err := pset.New([]*transaction.TxInput{}, []*transaction.TxOutput{}, 2, 0)
 	if err != nil {
@@ -30,7 +145,7 @@ func NewSwapTx(
 
 	selectedUnspents, change, err := explorer.SelectUnspents(
 		unspents,
-		[][]byte{blindingKey},
+		blindingKeys,
 		inAmount,
 		inAsset,
 	)
@@ -56,7 +171,7 @@ func NewSwapTx(
 	updater.AddOutput(output)
 
 	if change > 0 {
-		changeOutput, err := newTxOutput(inAsset, change, outScript)
+		changeOutput, err := newTxOutput(inAsset, change, changeScript)
 		if err != nil {
 			return "", err
 		}
@@ -66,6 +181,7 @@ func NewSwapTx(
 	return ptx.ToBase64()
 }
 
+// Wallet is a single-key, single-address trader wallet
 type Wallet struct {
 	privateKey         *btcec.PrivateKey
 	blindingPrivateKey *btcec.PrivateKey
@@ -102,6 +218,30 @@ func (w *Wallet) Script() ([]byte, []byte) {
 	return p2wpkh.Script, p2wpkh.WitnessScript
 }
 
+// Unspents returns the utxos locked by the address of the wallet
+func (w *Wallet) Unspents(explorerSvc explorer.Service) ([]explorer.Utxo, error) {
+	return explorerSvc.GetUnspents(w.Address(), [][]byte{w.BlindingKey()})
+}
+
+// ReceiveAddress returns the address of the wallet
+func (w *Wallet) ReceiveAddress() (string, error) {
+	return w.Address(), nil
+}
+
+// ChangeAddress returns the address of the wallet
+func (w *Wallet) ChangeAddress() (string, error) {
+	return w.Address(), nil
+}
+
+// BlindingKeyForScript returns the blinding key of the wallet if the given
+// script is the one of its address
+func (w *Wallet) BlindingKeyForScript(script []byte) ([]byte, error) {
+	if _, witnessScript := w.Script(); !bytes.Equal(script, witnessScript) {
+		return nil, ErrUnknownScript
+	}
+	return w.BlindingKey(), nil
+}
+
 func (w *Wallet) Sign(psetBase64 string) (string, error) {
 	ptx, err := pset.NewPsetFromBase64(psetBase64)
 	if err != nil {
@@ -148,6 +288,10 @@ func (w *Wallet) Sign(psetBase64 string) (string, error) {
 	return ptx.ToBase64()
 }
 
+func (w *Wallet) PrivateKey() []byte {
+	return w.privateKey.Serialize()
+}
+
 func (w *Wallet) BlindingKey() []byte {
 	return w.blindingPrivateKey.Serialize()
 }
@@ -0,0 +1,178 @@
+package trade
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdex-network/tdex-daemon/pkg/explorer"
+	pb "github.com/tdex-network/tdex-protobuf/generated/go/swap"
+	"github.com/vulpemventures/go-elements/network"
+	"github.com/vulpemventures/go-elements/pset"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testBaseAsset  = "5ac9f65c0efcc4775e0baec4ec03abdde22473cd3cf33c0419ca290e0751b225"
+	testQuoteAsset = "2dcf5a8834645654911964ec3602426fd3b9b4017554d3f9c19403e7fc1411d3"
+)
+
+func TestNewSwapRequestWithMultiKeyWallet(t *testing.T) {
+	w, err := newMultiKeyWallet(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the wallet owns 2 utxos locked by different scripts, none of them
+	// enough to cover the amount to send
+	w.utxos = []explorer.Utxo{
+		newTestUtxo(w.wallets[0], 0, 6000, testQuoteAsset),
+		newTestUtxo(w.wallets[1], 1, 6000, testQuoteAsset),
+	}
+	preview := &PreviewResult{
+		AssetToSend:     testQuoteAsset,
+		AmountToSend:    10000,
+		AssetToReceive:  testBaseAsset,
+		AmountToReceive: 1,
+	}
+
+	swapRequestMsg, err := newSwapRequest(
+		w, w.utxos, preview, &network.Regtest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapRequest := &pb.SwapRequest{}
+	if err := proto.Unmarshal(swapRequestMsg, swapRequest); err != nil {
+		t.Fatal(err)
+	}
+	ptx, err := pset.NewPsetFromBase64(swapRequest.GetTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, receiveScript := w.wallets[0].Script()
+	_, changeScript := w.wallets[2].Script()
+	assert.Equal(t, 2, len(ptx.Inputs))
+	assert.Equal(t, 2, len(ptx.Outputs))
+	assert.Equal(t, receiveScript, ptx.UnsignedTx.Outputs[0].Script)
+	assert.Equal(t, changeScript, ptx.UnsignedTx.Outputs[1].Script)
+
+	outputBlindingKeys := swapRequest.GetOutputBlindingKey()
+	assert.Equal(t, 2, len(outputBlindingKeys))
+	assert.Equal(
+		t,
+		w.wallets[0].BlindingKey(),
+		outputBlindingKeys[hex.EncodeToString(receiveScript)],
+	)
+	assert.Equal(
+		t,
+		w.wallets[2].BlindingKey(),
+		outputBlindingKeys[hex.EncodeToString(changeScript)],
+	)
+}
+
+func TestFailingNewSwapRequestWithMultiKeyWallet(t *testing.T) {
+	w, err := newMultiKeyWallet(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownWallet, err := NewRandomWallet(&network.Regtest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, unknownScript := unknownWallet.Script()
+	// confidential utxos can't be unblinded if the wallet doesn't know their
+	// blinding key
+	w.utxos = []explorer.Utxo{
+		explorer.NewConfidentialWitnessUtxo(
+			hex.EncodeToString(make([]byte, 32)),
+			0,
+			"08b9a0f3eb2e8a0a1bc3d8e1c9a5d1a6d2c77f0e7a1b5f8de4ac0b1cf7c3d4e5f6",
+			"0a8d7c4e6a2f8e1d0c3b5a7f9e2d4c6b8a0f1e3d5c7b9a1f2e4d6c8b0a2f4e6d8c",
+			unknownScript,
+			nil,
+			nil,
+			nil,
+		),
+	}
+	preview := &PreviewResult{
+		AssetToSend:     testQuoteAsset,
+		AmountToSend:    10000,
+		AssetToReceive:  testBaseAsset,
+		AmountToReceive: 1,
+	}
+
+	_, err = newSwapRequest(w, w.utxos, preview, &network.Regtest)
+	if !errors.Is(err, ErrUnknownScript) {
+		t.Fatalf("expected error %v, got %v", ErrUnknownScript, err)
+	}
+}
+
+var _ TraderWallet = &multiKeyWallet{}
+
+// multiKeyWallet is a trader wallet made of many single-key wallets, like an
+// HD one. The first wallet is used for receiving, the last one for change.
+type multiKeyWallet struct {
+	wallets []*Wallet
+	utxos   []explorer.Utxo
+}
+
+func newMultiKeyWallet(numOfKeys int) (*multiKeyWallet, error) {
+	wallets := make([]*Wallet, 0, numOfKeys)
+	for i := 0; i < numOfKeys; i++ {
+		w, err := NewRandomWallet(&network.Regtest)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+	return &multiKeyWallet{wallets: wallets}, nil
+}
+
+func (w *multiKeyWallet) Unspents(_ explorer.Service) ([]explorer.Utxo, error) {
+	return w.utxos, nil
+}
+
+func (w *multiKeyWallet) ReceiveAddress() (string, error) {
+	return w.wallets[0].Address(), nil
+}
+
+func (w *multiKeyWallet) ChangeAddress() (string, error) {
+	return w.wallets[len(w.wallets)-1].Address(), nil
+}
+
+func (w *multiKeyWallet) BlindingKeyForScript(script []byte) ([]byte, error) {
+	for _, ww := range w.wallets {
+		if blindingKey, err := ww.BlindingKeyForScript(script); err == nil {
+			return blindingKey, nil
+		}
+	}
+	return nil, ErrUnknownScript
+}
+
+func (w *multiKeyWallet) Sign(psetBase64 string) (string, error) {
+	var err error
+	for _, ww := range w.wallets {
+		if psetBase64, err = ww.Sign(psetBase64); err != nil {
+			return "", err
+		}
+	}
+	return psetBase64, nil
+}
+
+func newTestUtxo(
+	w *Wallet,
+	index uint32,
+	value uint64,
+	asset string,
+) explorer.Utxo {
+	_, script := w.Script()
+	return explorer.NewUnconfidentialWitnessUtxo(
+		hex.EncodeToString(make([]byte, 32)),
+		index,
+		value,
+		asset,
+		script,
+	)
+}
@@ -5,14 +5,105 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/scrypt"
 )
 
+const (
+	// KDFScrypt identifies the scrypt key derivation function
+	KDFScrypt = "scrypt"
+	// KDFArgon2id identifies the argon2id key derivation function
+	KDFArgon2id = "argon2id"
+
+	// envelopeVersion is the version of the format of the cyphertexts
+	// returned by Encrypt. Cyphertexts without version are in the legacy
+	// format nonce|cyphertext|salt, with key derived by scrypt with
+	// DefaultScryptParams
+	envelopeVersion = 1
+	keyLen          = 32
+	saltLen         = 32
+)
+
+var (
+	// DefaultScryptParams are the scrypt parameters used if none is provided
+	// to Encrypt. 2^20 = 1048576 is the recommended cost for key-stretching,
+	// check the doc for other recommended values:
+	// https://godoc.org/golang.org/x/crypto/scrypt
+	DefaultScryptParams = KDFParams{Type: KDFScrypt, N: 1048576, R: 8, P: 1}
+	// DefaultArgon2idParams are the argon2id parameters recommended by
+	// RFC 9106 for memory constrained environments
+	DefaultArgon2idParams = KDFParams{
+		Type:    KDFArgon2id,
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+)
+
+// KDFParams are the type and the parameters of the key derivation function
+// used to derive the encryption key from a passphrase. N, R and P are scrypt
+// parameters, Time, Memory (in KiB) and Threads are argon2id ones
+type KDFParams struct {
+	Type    string `json:"type"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	Salt    []byte `json:"salt,omitempty"`
+}
+
+func (p KDFParams) validate() error {
+	switch p.Type {
+	case KDFScrypt:
+		if p.N <= 1 || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 {
+			return ErrInvalidKDFParams
+		}
+	case KDFArgon2id:
+		if p.Time == 0 || p.Threads == 0 || p.Memory < 8*uint32(p.Threads) {
+			return ErrInvalidKDFParams
+		}
+	default:
+		return ErrUnsupportedKDF
+	}
+	return nil
+}
+
+// sameCost returns whether the given params make use of the same function
+// with the same cost, regardless of the salt
+func (p KDFParams) sameCost(other KDFParams) bool {
+	return p.Type == other.Type &&
+		p.N == other.N && p.R == other.R && p.P == other.P &&
+		p.Time == other.Time && p.Memory == other.Memory &&
+		p.Threads == other.Threads
+}
+
+// envelope is the versioned format of the cyphertexts returned by Encrypt.
+// The version and the KDF params are authenticated as additional data of the
+// AES-GCM encryption, so that they can't be tampered with to weaken the
+// derivation of the key
+type envelope struct {
+	Version    int       `json:"version"`
+	KDF        KDFParams `json:"kdf"`
+	Nonce      []byte    `json:"nonce,omitempty"`
+	CypherText []byte    `json:"cyphertext,omitempty"`
+}
+
+func (e envelope) additionalData() []byte {
+	header, _ := json.Marshal(envelope{Version: e.Version, KDF: e.KDF})
+	return header
+}
+
 // EncryptOpts is the struct given to Encrypt method
 type EncryptOpts struct {
 	PlainText  string
 	Passphrase string
+	// KDF is optional and defaults to DefaultScryptParams. The salt, if
+	// given, is ignored since a random one is generated for every encryption
+	KDF *KDFParams
 }
 
 func (o EncryptOpts) validate() error {
@@ -22,25 +113,36 @@ func (o EncryptOpts) validate() error {
 	if len(o.Passphrase) <= 0 {
 		return ErrNullPassphrase
 	}
+	if o.KDF != nil {
+		if err := o.KDF.validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Encrypt encrypts (with AES-128) a plaintext with the provided passphrase
+// Encrypt encrypts (with AES-256-GCM) a plaintext with a key derived from the
+// provided passphrase and returns it in the versioned format recording the
+// params of the key derivation function
 func Encrypt(opts EncryptOpts) (string, error) {
 	if err := opts.validate(); err != nil {
 		return "", err
 	}
 
-	key, salt, err := DeriveKey([]byte(opts.Passphrase), nil)
-	if err != nil {
+	kdf := DefaultScryptParams
+	if opts.KDF != nil {
+		kdf = *opts.KDF
+	}
+	kdf.Salt = make([]byte, saltLen)
+	if _, err := rand.Read(kdf.Salt); err != nil {
 		return "", err
 	}
 
-	blockCipher, err := aes.NewCipher(key)
+	key, err := DeriveKey([]byte(opts.Passphrase), kdf)
 	if err != nil {
 		return "", err
 	}
-	gcm, err := cipher.NewGCM(blockCipher)
+	gcm, err := newGCM(key)
 	if err != nil {
 		return "", err
 	}
@@ -49,10 +151,16 @@ func Encrypt(opts EncryptOpts) (string, error) {
 		return "", err
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(opts.PlainText), nil)
-	ciphertext = append(ciphertext, salt...)
+	env := envelope{Version: envelopeVersion, KDF: kdf, Nonce: nonce}
+	env.CypherText = gcm.Seal(
+		nil, nonce, []byte(opts.PlainText), env.additionalData(),
+	)
 
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
 }
 
 // DecryptOpts is the struct given to Decrypt method
@@ -65,8 +173,8 @@ func (o DecryptOpts) validate() error {
 	if len(o.CypherText) <= 0 {
 		return ErrNullCypherText
 	}
-	if _, err := base64.StdEncoding.DecodeString(o.CypherText); err != nil {
-		return ErrInvalidCypherText
+	if _, err := parseCypherText(o.CypherText); err != nil {
+		return err
 	}
 	if len(o.Passphrase) <= 0 {
 		return ErrNullPassphrase
@@ -74,50 +182,102 @@ func (o DecryptOpts) validate() error {
 	return nil
 }
 
-// Decrypt decrypts (with AES-128) a cyphertext with the provided passphrase
+// Decrypt decrypts a cyphertext, either in the versioned or in the legacy
+// format, with the provided passphrase. ErrInvalidPassphrase is returned if
+// the cyphertext can't be authenticated with the key derived from it
 func Decrypt(opts DecryptOpts) (string, error) {
 	if err := opts.validate(); err != nil {
 		return "", err
 	}
 
-	data, _ := base64.StdEncoding.DecodeString(opts.CypherText)
-	salt, data := data[len(data)-32:], data[:len(data)-32]
-
-	key, _, err := DeriveKey([]byte(opts.Passphrase), salt)
+	env, _ := parseCypherText(opts.CypherText)
+	key, err := DeriveKey([]byte(opts.Passphrase), env.KDF)
 	if err != nil {
 		return "", err
 	}
-
-	blockCipher, err := aes.NewCipher(key)
+	gcm, err := newGCM(key)
 	if err != nil {
 		return "", err
 	}
-	gcm, err := cipher.NewGCM(blockCipher)
-	if err != nil {
-		return "", err
+
+	var additionalData []byte
+	if env.Version > 0 {
+		additionalData = env.additionalData()
 	}
-	nonce, text := data[:gcm.NonceSize()], data[gcm.NonceSize():]
-	plaintext, err := gcm.Open(nil, nonce, text, nil)
+	plaintext, err := gcm.Open(nil, env.Nonce, env.CypherText, additionalData)
 	if err != nil {
 		return "", ErrInvalidPassphrase
 	}
 	return string(plaintext), nil
 }
 
-// DeriveKey derives a 32 byte array key from a custom passhprase
-func DeriveKey(passphrase, salt []byte) ([]byte, []byte, error) {
-	if salt == nil {
-		salt = make([]byte, 32)
-		if _, err := rand.Read(salt); err != nil {
-			return nil, nil, err
-		}
+// NeedsMigration returns whether the given cyphertext is in the legacy format
+// or its key is derived with params other than the given ones, meaning that
+// it should be encrypted again with them
+func NeedsMigration(cypherText string, kdf KDFParams) bool {
+	env, err := parseCypherText(cypherText)
+	if err != nil {
+		return false
 	}
-	// 2^20 = 1048576 recommended length for key-stretching
-	// check the doc for other recommended values:
-	// https://godoc.org/golang.org/x/crypto/scrypt
-	key, err := scrypt.Key(passphrase, salt, 1048576, 8, 1, 32)
+	return env.Version != envelopeVersion || !env.KDF.sameCost(kdf)
+}
+
+// DeriveKey derives a 32 byte array key from a custom passhprase with the
+// given key derivation function
+func DeriveKey(passphrase []byte, kdf KDFParams) ([]byte, error) {
+	if err := kdf.validate(); err != nil {
+		return nil, err
+	}
+	if len(kdf.Salt) <= 0 {
+		return nil, ErrNullSalt
+	}
+
+	if kdf.Type == KDFArgon2id {
+		return argon2.IDKey(
+			passphrase, kdf.Salt, kdf.Time, kdf.Memory, kdf.Threads, keyLen,
+		), nil
+	}
+	return scrypt.Key(passphrase, kdf.Salt, kdf.N, kdf.R, kdf.P, keyLen)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	blockCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(blockCipher)
+}
+
+// parseCypherText returns the envelope of the given cyphertext. Those in the
+// legacy format are returned with version 0
+func parseCypherText(cypherText string) (*envelope, error) {
+	data, err := base64.StdEncoding.DecodeString(cypherText)
 	if err != nil {
-		return nil, nil, err
+		return nil, ErrInvalidCypherText
+	}
+
+	env := &envelope{}
+	if err := json.Unmarshal(data, env); err == nil && env.Version > 0 {
+		if env.Version != envelopeVersion {
+			return nil, ErrUnsupportedCypherVersion
+		}
+		if len(env.Nonce) <= 0 || len(env.CypherText) <= 0 {
+			return nil, ErrInvalidCypherText
+		}
+		return env, nil
+	}
+
+	// legacy format: nonce|cyphertext|salt
+	nonceLen := 12
+	if len(data) <= nonceLen+saltLen {
+		return nil, ErrInvalidCypherText
 	}
-	return key, salt, nil
+	kdf := DefaultScryptParams
+	kdf.Salt = data[len(data)-saltLen:]
+	data = data[:len(data)-saltLen]
+	return &envelope{
+		KDF:        kdf,
+		Nonce:      data[:nonceLen],
+		CypherText: data[nonceLen:],
+	}, nil
 }
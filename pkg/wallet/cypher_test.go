@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +33,105 @@ func TestEncryptDecrypt(t *testing.T) {
 	assert.Equal(t, plaintext, revealedtext)
 }
 
+func TestEncryptDecryptWithKDFParams(t *testing.T) {
+	plaintext := "super secret message"
+	passphrase := "supersecurekey"
+
+	tests := []KDFParams{
+		{Type: KDFScrypt, N: 1024, R: 8, P: 1},
+		{Type: KDFArgon2id, Time: 1, Memory: 1024, Threads: 1},
+	}
+	for _, kdf := range tests {
+		kdf := kdf
+		cyphertext, err := Encrypt(EncryptOpts{
+			PlainText:  plaintext,
+			Passphrase: passphrase,
+			KDF:        &kdf,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, false, NeedsMigration(cyphertext, kdf))
+
+		revealedtext, err := Decrypt(DecryptOpts{
+			CypherText: cyphertext,
+			Passphrase: passphrase,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, plaintext, revealedtext)
+
+		_, err = Decrypt(DecryptOpts{
+			CypherText: cyphertext,
+			Passphrase: "wrongkey",
+		})
+		assert.Equal(t, ErrInvalidPassphrase, err)
+	}
+}
+
+func TestDecryptTamperedKDFParams(t *testing.T) {
+	passphrase := "supersecurekey"
+	cyphertext, err := Encrypt(EncryptOpts{
+		PlainText:  "super secret message",
+		Passphrase: passphrase,
+		KDF:        &KDFParams{Type: KDFScrypt, N: 1024, R: 8, P: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lowering the cost of the key derivation makes the authentication fail
+	// even with the right passphrase
+	data, _ := base64.StdEncoding.DecodeString(cyphertext)
+	env := envelope{}
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatal(err)
+	}
+	env.KDF.N = 2
+	data, _ = json.Marshal(env)
+
+	_, err = Decrypt(DecryptOpts{
+		CypherText: base64.StdEncoding.EncodeToString(data),
+		Passphrase: passphrase,
+	})
+	assert.Equal(t, ErrInvalidPassphrase, err)
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	cyphertext := "dVoBFte1oeRkPl8Vf8DzBP3PRnzPA3fxtyvDHXFGYAS9MP8V2Sc9nHcQW4PrMkQNnf2uGrDg81dFgBrwqv1n3frXxRBKhp83fSsTm4xqj8+jdwTI3nouFmi1W/O4UqpHdQ62EYoabJQtKpptWO11TFJzw8WF02pfS6git8YjLR4xrnfp2LkOEjSU9CI82ZasF46WZFKcpeUJTAsxU/03ONpAdwwEsC96f1KAvh8tqaO0yLDOcmPf8a5B82jefgncCRrt32kCpbpIE4YiCFrqqdUHXKH+"
+	assert.Equal(t, true, NeedsMigration(cyphertext, DefaultScryptParams))
+
+	revealedtext, err := Decrypt(DecryptOpts{
+		CypherText: cyphertext,
+		Passphrase: "pass",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "leave dice fine", revealedtext[:15])
+}
+
+func TestNeedsMigration(t *testing.T) {
+	kdf := KDFParams{Type: KDFScrypt, N: 1024, R: 8, P: 1}
+	cyphertext, err := Encrypt(EncryptOpts{
+		PlainText:  "super secret message",
+		Passphrase: "supersecurekey",
+		KDF:        &kdf,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, false, NeedsMigration(cyphertext, kdf))
+	kdf.N = 2048
+	assert.Equal(t, true, NeedsMigration(cyphertext, kdf))
+	assert.Equal(t, true, NeedsMigration(cyphertext, DefaultArgon2idParams))
+}
+
 func TestFailingEncrypt(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
@@ -53,6 +154,30 @@ func TestFailingEncrypt(t *testing.T) {
 			},
 			err: ErrNullPassphrase,
 		},
+		{
+			opts: EncryptOpts{
+				PlainText:  "super secret message",
+				Passphrase: "supersecurekey",
+				KDF:        &KDFParams{Type: "pbkdf2"},
+			},
+			err: ErrUnsupportedKDF,
+		},
+		{
+			opts: EncryptOpts{
+				PlainText:  "super secret message",
+				Passphrase: "supersecurekey",
+				KDF:        &KDFParams{Type: KDFScrypt, N: 1000, R: 8, P: 1},
+			},
+			err: ErrInvalidKDFParams,
+		},
+		{
+			opts: EncryptOpts{
+				PlainText:  "super secret message",
+				Passphrase: "supersecurekey",
+				KDF:        &KDFParams{Type: KDFArgon2id, Time: 1, Memory: 8, Threads: 4},
+			},
+			err: ErrInvalidKDFParams,
+		},
 	}
 	for _, tt := range tests {
 		_, err := Encrypt(tt.opts)
@@ -24,6 +24,8 @@ var (
 	ErrNullPlainText = errors.New("text to encrypt must not be null")
 	// ErrNullCypherText ...
 	ErrNullCypherText = errors.New("cypher to decrypt must not be null")
+	// ErrNullSalt ...
+	ErrNullSalt = errors.New("salt of key derivation function must not be null")
 	// ErrNullDerivationPath ...
 	ErrNullDerivationPath = errors.New("derivation path must not be null")
 	// ErrNullOutputDerivationPath ...
@@ -49,6 +51,11 @@ var (
 	ErrInvalidBlindingMnemonic = errors.New("blinding mnemonic is invalid")
 	// ErrInvalidCypherText ...
 	ErrInvalidCypherText = errors.New("cypher must be in base64 format")
+	// ErrInvalidKDFParams ...
+	ErrInvalidKDFParams = errors.New(
+		"scrypt requires N to be a power of 2 greater than 1 and positive r and p, " +
+			"argon2id requires positive time and threads and memory >= 8*threads KiB",
+	)
 	// ErrInvalidDerivationPath ...
 	ErrInvalidDerivationPath = errors.New("invalid derivation path")
 	// ErrInvalidDerivationPathLength ...
@@ -93,6 +100,13 @@ var (
 		"account index must be in hardened range [0, %d]",
 		MaxHardenedValue,
 	)
+	// ErrUnsupportedKDF ...
+	ErrUnsupportedKDF = fmt.Errorf(
+		"key derivation function must be either '%s' or '%s'",
+		KDFScrypt, KDFArgon2id,
+	)
+	// ErrUnsupportedCypherVersion ...
+	ErrUnsupportedCypherVersion = errors.New("version of cypher is not supported")
 	// ErrZeroInputAmount ...
 	ErrZeroInputAmount = errors.New("input amount must not be zero")
 	// ErrZeroOutputAmount ...
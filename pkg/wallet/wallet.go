@@ -109,24 +109,51 @@ func NewWalletFromMnemonic(opts NewWalletFromMnemonicOpts) (*Wallet, error) {
 	}, nil
 }
 
+// NewWalletFromMasterKeysOpts is the struct given to the
+// NewWalletFromMasterKeys method
+type NewWalletFromMasterKeysOpts struct {
+	SigningMasterKey  []byte
+	BlindingMasterKey []byte
+}
+
+func (o NewWalletFromMasterKeysOpts) validate() error {
+	if len(o.SigningMasterKey) <= 0 {
+		return ErrNullSigningMasterKey
+	}
+	if len(o.BlindingMasterKey) <= 0 {
+		return ErrNullBlindingMasterKey
+	}
+	return nil
+}
+
+// NewWalletFromMasterKeys returns a wallet made of the given signing and
+// blinding master keys, that doesn't hold the mnemonics they are generated
+// from. The keys are not copied, therefore they must not be modified while
+// the wallet is in use.
+func NewWalletFromMasterKeys(opts NewWalletFromMasterKeysOpts) (*Wallet, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Wallet{
+		signingMasterKey:  opts.SigningMasterKey,
+		blindingMasterKey: opts.BlindingMasterKey,
+	}, nil
+}
+
 func (w *Wallet) validate() error {
 	if len(w.signingMasterKey) <= 0 {
 		return ErrNullSigningMasterKey
 	}
-	if len(w.signingMnemonic) <= 0 {
-		return ErrNullSigningMnemonic
+	if len(w.blindingMasterKey) <= 0 {
+		return ErrNullBlindingMasterKey
 	}
-	if !isMnemonicValid(w.signingMnemonic) {
+	// a wallet made of master keys only doesn't hold any mnemonic
+	if len(w.signingMnemonic) > 0 && !isMnemonicValid(w.signingMnemonic) {
 		return ErrInvalidSigningMnemonic
 	}
-
-	if len(w.blindingMnemonic) > 0 {
-		if !isMnemonicValid(w.blindingMnemonic) {
-			return ErrInvalidBlindingMnemonic
-		}
-		if len(w.blindingMasterKey) <= 0 {
-			return ErrNullBlindingMasterKey
-		}
+	if len(w.blindingMnemonic) > 0 && !isMnemonicValid(w.blindingMnemonic) {
+		return ErrInvalidBlindingMnemonic
 	}
 	return nil
 }
@@ -136,6 +163,9 @@ func (w *Wallet) SigningMnemonic() ([]string, error) {
 	if err := w.validate(); err != nil {
 		return nil, err
 	}
+	if len(w.signingMnemonic) <= 0 {
+		return nil, ErrNullSigningMnemonic
+	}
 	return w.signingMnemonic, nil
 }
 
@@ -144,5 +174,24 @@ func (w *Wallet) BlindingMnemonic() ([]string, error) {
 	if err := w.validate(); err != nil {
 		return nil, err
 	}
+	if len(w.blindingMnemonic) <= 0 {
+		return nil, ErrNullBlindingMnemonic
+	}
 	return w.blindingMnemonic, nil
 }
+
+// SigningMasterKey is getter for signing master key
+func (w *Wallet) SigningMasterKey() ([]byte, error) {
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+	return w.signingMasterKey, nil
+}
+
+// BlindingMasterKey is getter for blinding master key
+func (w *Wallet) BlindingMasterKey() ([]byte, error) {
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+	return w.blindingMasterKey, nil
+}
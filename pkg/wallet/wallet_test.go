@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vulpemventures/go-elements/network"
 )
 
 func TestNewWallet(t *testing.T) {
@@ -102,6 +103,59 @@ func TestFailingNewWalletFromMnemonic(t *testing.T) {
 	}
 }
 
+func TestNewWalletFromMasterKeys(t *testing.T) {
+	wallet, err := newTestWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingMasterKey, _ := wallet.SigningMasterKey()
+	blindingMasterKey, _ := wallet.BlindingMasterKey()
+	otherWallet, err := NewWalletFromMasterKeys(NewWalletFromMasterKeysOpts{
+		SigningMasterKey:  signingMasterKey,
+		BlindingMasterKey: blindingMasterKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DeriveConfidentialAddressOpts{
+		DerivationPath: "0'/0/0",
+		Network:        &network.Liquid,
+	}
+	addr, _, err := wallet.DeriveConfidentialAddress(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAddr, _, err := otherWallet.DeriveConfidentialAddress(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, addr, otherAddr)
+
+	_, err = otherWallet.SigningMnemonic()
+	assert.Equal(t, ErrNullSigningMnemonic, err)
+}
+
+func TestFailingNewWalletFromMasterKeys(t *testing.T) {
+	tests := []struct {
+		opts NewWalletFromMasterKeysOpts
+		err  error
+	}{
+		{
+			opts: NewWalletFromMasterKeysOpts{BlindingMasterKey: []byte{1}},
+			err:  ErrNullSigningMasterKey,
+		},
+		{
+			opts: NewWalletFromMasterKeysOpts{SigningMasterKey: []byte{1}},
+			err:  ErrNullBlindingMasterKey,
+		},
+	}
+	for _, tt := range tests {
+		_, err := NewWalletFromMasterKeys(tt.opts)
+		assert.Equal(t, tt.err, err)
+	}
+}
+
 func newTestWallet() (*Wallet, error) {
 	return NewWallet(NewWalletOpts{})
 }